@@ -0,0 +1,253 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/oob"
+	"gosssrf-client/plugins"
+	"gosssrf-client/scanner"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	pollInterval     = 2 * time.Second // 暂无可分配的任务或控制节点不可达时的重试间隔
+	maxConnectErrors = 5               // 连续无法连接控制节点的次数上限
+)
+
+// errLeaseLost 任务已被控制节点收回（心跳超时后重新分配或扫描已停止）
+var errLeaseLost = errors.New("任务已被控制节点收回")
+
+// Agent 分布式扫描代理节点：从控制节点领取任务，扫描后回传发现的漏洞
+type Agent struct {
+	controller string
+	token      string
+	name       string
+	local      *config.Config // 本地命令行参数，输出级别以本地为准
+	console    *logging.Console
+	client     *http.Client
+}
+
+// NewAgent 创建代理节点，cfg 为本地命令行参数（-agent）
+func NewAgent(cfg *config.Config, console *logging.Console) *Agent {
+	host, _ := os.Hostname()
+	return &Agent{
+		controller: strings.TrimRight(cfg.AgentOf, "/"),
+		token:      cfg.ClusterToken,
+		name:       fmt.Sprintf("%s-%d", host, os.Getpid()),
+		local:      cfg,
+		console:    console,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run 循环领取并执行任务，控制节点没有剩余任务时返回；ctx 取消时回传当前任务的已有结果后返回
+func (a *Agent) Run(ctx context.Context) error {
+	slog.Info(fmt.Sprintf("代理节点 %s 已启动，控制节点 %s", a.name, a.controller))
+	failures := 0
+	for ctx.Err() == nil {
+		assignment, status, err := a.lease(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			failures++
+			if failures >= maxConnectErrors {
+				return fmt.Errorf("无法连接控制节点: %v", err)
+			}
+			slog.Warn(fmt.Sprintf("连接控制节点失败，%s 后重试: %v", pollInterval, err))
+			a.sleep(ctx, pollInterval)
+			continue
+		}
+		failures = 0
+
+		switch status {
+		case http.StatusNoContent:
+			slog.Info("控制节点已没有剩余任务")
+			return nil
+		case http.StatusAccepted:
+			a.sleep(ctx, pollInterval)
+			continue
+		}
+
+		job := assignment.Job
+		slog.Info(fmt.Sprintf("开始执行任务 %d: %s [%s]", job.ID, job.Target, job.Tags), "target", job.Target)
+		report := Report{JobID: job.ID, Agent: a.name}
+		results, err := a.runJob(ctx, assignment)
+		if errors.Is(err, errLeaseLost) {
+			slog.Warn(fmt.Sprintf("任务 %d 已被控制节点收回", job.ID), "target", job.Target)
+		} else if err != nil {
+			slog.Error(fmt.Sprintf("任务 %d 执行失败: %v", job.ID, err), "target", job.Target)
+			report.Error = err.Error()
+		}
+		report.Results = results
+		// 中断后仍需回传已有结果，不使用已取消的 ctx
+		if err := a.post(context.Background(), "/api/result", report, nil); err != nil {
+			slog.Error(fmt.Sprintf("回传任务 %d 的结果失败: %v", job.ID, err), "target", job.Target)
+		}
+	}
+	return nil
+}
+
+// runJob 按下发的配置扫描任务中的目标和模块，扫描期间定期发送心跳
+func (a *Agent) runJob(ctx context.Context, assignment *Assignment) ([]scanner.ScanResult, error) {
+	cfg, err := a.jobConfig(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lost := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(leaseTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			var status int
+			body := map[string]interface{}{"agent": a.name, "job_id": assignment.Job.ID}
+			if err := a.post(ctx, "/api/heartbeat", body, &status); err != nil {
+				slog.Warn(fmt.Sprintf("发送心跳失败: %v", err))
+				continue
+			}
+			if status == http.StatusGone {
+				close(lost)
+				cancel()
+				return
+			}
+		}
+	}()
+
+	det := detector.NewDetector(cfg)
+	if cfg.Script != "" {
+		if err := det.StartScript(); err != nil {
+			return nil, err
+		}
+		defer det.Close()
+	}
+	if cfg.LoginRequest != nil || cfg.LoginScript != "" {
+		if err := det.Login(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var registry *oob.Registry
+	if cfg.ShouldScanOOB() {
+		if registry, err = oob.NewRegistry(""); err != nil {
+			return nil, err
+		}
+		defer registry.Close()
+	}
+
+	sm := scanner.NewScanManager(cfg, det, a.console, nil, registry, nil)
+	sm.RunScan(ctx)
+	results := sm.Results()
+	select {
+	case <-lost:
+		return results, errLeaseLost
+	default:
+		return results, nil
+	}
+}
+
+// jobConfig 解析下发的扫描配置，并按任务设置目标、扫描模块和内网IP分片
+func (a *Agent) jobConfig(assignment *Assignment) (*config.Config, error) {
+	cfg := &config.Config{CustomHeaders: make(map[string]string)}
+	if err := yaml.Unmarshal([]byte(assignment.Config), cfg); err != nil {
+		return nil, fmt.Errorf("解析下发的配置失败: %v", err)
+	}
+
+	job := assignment.Job
+	cfg.TargetURL = job.Target
+	cfg.Tags = job.Tags
+	if job.Internal != "" {
+		cfg.InternalNet = job.Internal
+	}
+	if !job.Extra {
+		cfg.Timing, cfg.OpenRedirect, cfg.RebindDomain, cfg.HostNames = false, false, "", nil
+	}
+	cfg.Verbose, cfg.VeryVerbose, cfg.Silent = a.local.Verbose, a.local.VeryVerbose, a.local.Silent
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("下发的配置无效: %v", err)
+	}
+	// 插件payload与时间盲注等阶段一样只在一个任务中发送，其他任务只用插件分析响应
+	if !job.Extra {
+		for i, p := range cfg.Plugins {
+			cfg.Plugins[i] = plugins.AnalyzeOnly(p)
+		}
+	}
+	return cfg, nil
+}
+
+// lease 领取任务，返回控制节点的状态码
+func (a *Agent) lease(ctx context.Context) (*Assignment, int, error) {
+	var assignment Assignment
+	var status int
+	if err := a.post(ctx, "/api/lease", map[string]string{"agent": a.name}, &status, &assignment); err != nil {
+		return nil, 0, err
+	}
+	return &assignment, status, nil
+}
+
+// post 向控制节点发送JSON请求；status 不为nil时写入状态码，返回200时将响应解析到 out
+func (a *Agent) post(ctx context.Context, path string, body interface{}, status *int, out ...interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.controller+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.token != "" {
+		req.Header.Set(tokenHeader, a.token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		for _, v := range out {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				return fmt.Errorf("解析控制节点响应失败: %v", err)
+			}
+		}
+	case http.StatusAccepted, http.StatusNoContent, http.StatusGone:
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("控制节点返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if status != nil {
+		*status = resp.StatusCode
+	}
+	return nil
+}
+
+// sleep 等待 d 或 ctx 取消
+func (a *Agent) sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
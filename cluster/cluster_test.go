@@ -0,0 +1,258 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/logging"
+	"gosssrf-client/scanner"
+)
+
+// newTestConfig 创建已验证的控制节点配置
+func newTestConfig(t *testing.T, target string, modify func(cfg *config.Config)) *config.Config {
+	t.Helper()
+	cfg := &config.Config{
+		CustomHeaders:   make(map[string]string),
+		TargetURL:       target,
+		ParamName:       "url",
+		Method:          "GET",
+		Threads:         2,
+		Timeout:         5,
+		NoWAFBypass:     true,
+		Controller:      "127.0.0.1:0",
+		OOBMode:         "path",
+		TimingThreshold: 2000,
+	}
+	if modify != nil {
+		modify(cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestBuildJobs(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+		want   []string // 每个任务的 标签|内网分片|是否执行额外阶段
+	}{
+		{"按模块拆分", func(cfg *config.Config) { cfg.Tags = "files,cloud" }, []string{"files||false", "cloud||false"}},
+		{"端口扫描按内网IP分片", func(cfg *config.Config) {
+			cfg.Tags = "ports"
+			cfg.InternalNet = "10.0.0.1-10.0.1.44"
+			cfg.Ports = "80"
+		}, []string{"ports|10.0.0.1-10.0.1.0|false", "ports|10.0.1.1-10.0.1.44|false"}},
+		{"额外阶段只在第一个任务执行", func(cfg *config.Config) {
+			cfg.Tags = "k8s,docker"
+			cfg.Timing = true
+		}, []string{"k8s||true", "docker||false"}},
+		{"未配置OOB时跳过oob模块", func(cfg *config.Config) { cfg.Tags = "oob,docker" }, []string{"docker||false"}},
+		{"未启用模块时单独执行额外阶段", func(cfg *config.Config) {
+			cfg.Tags = "oob"
+			cfg.OpenRedirect = true
+		}, []string{noTags() + "||true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t, "http://target.example/?url=x", tt.modify)
+			var got []string
+			for i, job := range buildJobs(cfg) {
+				if job.ID != i+1 || job.Target != cfg.Targets[0] {
+					t.Errorf("任务 %d 的ID或目标错误: %+v", i, job)
+				}
+				got = append(got, job.Tags+"|"+job.Internal+"|"+map[bool]string{true: "true", false: "false"}[job.Extra])
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("得到 %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobConfig(t *testing.T) {
+	cfg := newTestConfig(t, "http://target.example/", func(cfg *config.Config) {
+		cfg.Method = "POST"
+		cfg.BodyData = `{"url":"x"}`
+		cfg.FileHeaders = map[string]string{"X-Api-Key": "secret"}
+		cfg.Timing = true
+		cfg.HostNames = []string{"jenkins.internal"}
+		cfg.OutputFile = "/tmp/controller-only.txt"
+		cfg.ClusterToken = "cluster-secret"
+	})
+	data, err := agentConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(data, "controller-only") || strings.Contains(data, "cluster-secret") {
+		t.Errorf("下发的配置不应包含只在控制节点生效的参数:\n%s", data)
+	}
+
+	agent := NewAgent(&config.Config{AgentOf: "http://127.0.0.1:9300", Silent: true}, nil)
+	got, err := agent.jobConfig(&Assignment{Job: Job{Target: "http://other.example/", Tags: "files"}, Config: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Targets) != 1 || got.Targets[0] != "http://other.example/" {
+		t.Errorf("目标 = %v", got.Targets)
+	}
+	if !got.HasTag(config.TagFiles) || got.HasTag(config.TagCloud) {
+		t.Errorf("扫描模块 = %v", got.TagSet)
+	}
+	if got.Method != "POST" || got.BodyTemplate != `{"url":"x"}` || got.CustomHeaders["X-Api-Key"] != "secret" {
+		t.Errorf("请求参数未下发: %s %q %v", got.Method, got.BodyTemplate, got.CustomHeaders)
+	}
+	if got.Timing || len(got.HostNames) > 0 {
+		t.Error("非额外阶段的任务不应启用时间盲注和内网主机名探测")
+	}
+	extra, err := agent.jobConfig(&Assignment{Job: Job{Target: "http://other.example/", Tags: "files", Extra: true}, Config: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extra.HostNames) != 1 || extra.HostNames[0] != "jenkins.internal" {
+		t.Errorf("内网主机名应随配置下发: %v", extra.HostNames)
+	}
+	if !got.Silent || got.OutputFile != "" {
+		t.Error("输出级别应以代理节点本地参数为准，且不写入输出文件")
+	}
+}
+
+// postJSON 以代理节点身份调用控制节点接口
+func postJSON(t *testing.T, url, token string, body interface{}, out interface{}) int {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	req.Header.Set(tokenHeader, token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func TestControllerRequeue(t *testing.T) {
+	cfg := newTestConfig(t, "http://target.example/?url=x", func(cfg *config.Config) {
+		cfg.Tags = "files"
+		cfg.ClusterToken = "token"
+	})
+	var mu sync.Mutex
+	var merged []scanner.ScanResult
+	c, err := NewController(cfg, func(target string, results []scanner.ScanResult) {
+		mu.Lock()
+		merged = append(merged, results...)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	if status := postJSON(t, srv.URL+"/api/lease", "wrong", map[string]string{"agent": "a"}, nil); status != http.StatusUnauthorized {
+		t.Fatalf("错误令牌返回 %d，期望 401", status)
+	}
+
+	// 节点a领取任务后掉线，租约超时后任务重新分配给节点b
+	var first Assignment
+	if status := postJSON(t, srv.URL+"/api/lease", "token", map[string]string{"agent": "a"}, &first); status != http.StatusOK {
+		t.Fatalf("领取任务返回 %d", status)
+	}
+	if status := postJSON(t, srv.URL+"/api/lease", "token", map[string]string{"agent": "b"}, nil); status != http.StatusAccepted {
+		t.Fatalf("没有待分配的任务时返回 %d，期望 202", status)
+	}
+	c.expireLeases(time.Now().Add(leaseTimeout))
+	if status := postJSON(t, srv.URL+"/api/heartbeat", "token", map[string]interface{}{"agent": "a", "job_id": first.Job.ID}, nil); status != http.StatusGone {
+		t.Fatalf("租约超时后心跳返回 %d，期望 410", status)
+	}
+
+	// 节点b执行失败，任务再分配给节点c并完成
+	var second Assignment
+	postJSON(t, srv.URL+"/api/lease", "token", map[string]string{"agent": "b"}, &second)
+	if second.Job.ID != first.Job.ID {
+		t.Fatalf("重新分配的任务 = %d，期望 %d", second.Job.ID, first.Job.ID)
+	}
+	postJSON(t, srv.URL+"/api/result", "token", Report{JobID: second.Job.ID, Agent: "b", Error: "连接被拒绝"}, nil)
+	var third Assignment
+	postJSON(t, srv.URL+"/api/lease", "token", map[string]string{"agent": "c"}, &third)
+	if third.Job.ID != first.Job.ID {
+		t.Fatalf("执行失败的任务未重新分配")
+	}
+	result := scanner.ScanResult{Target: third.Job.Target, Payload: "file:///etc/passwd", Vulnerable: true}
+	postJSON(t, srv.URL+"/api/result", "token", Report{JobID: third.Job.ID, Agent: "c", Results: []scanner.ScanResult{result}}, nil)
+	// 节点a恢复后回传的重复结果被忽略
+	postJSON(t, srv.URL+"/api/result", "token", Report{JobID: first.Job.ID, Agent: "a", Results: []scanner.ScanResult{result}}, nil)
+
+	select {
+	case <-c.finished:
+	default:
+		t.Fatal("所有任务完成后控制节点未结束")
+	}
+	if len(merged) != 1 || merged[0].Payload != result.Payload {
+		t.Errorf("合并的结果 = %+v", merged)
+	}
+	if status := postJSON(t, srv.URL+"/api/lease", "token", map[string]string{"agent": "a"}, nil); status != http.StatusNoContent {
+		t.Errorf("扫描结束后领取任务返回 %d，期望 204", status)
+	}
+}
+
+func TestAgentRun(t *testing.T) {
+	// 存在SSRF的目标：url参数为文件读取payload时返回 /etc/passwd 内容
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("url"), "/etc/passwd") {
+			w.Write([]byte("root:x:0:0:root:/root:/bin/bash\ndaemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin\n"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	cfg := newTestConfig(t, target.URL+"/?url=x", func(cfg *config.Config) { cfg.Tags = "files,docker" })
+	var mu sync.Mutex
+	var merged []scanner.ScanResult
+	c, err := NewController(cfg, func(target string, results []scanner.ScanResult) {
+		mu.Lock()
+		merged = append(merged, results...)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	var out bytes.Buffer
+	agent := NewAgent(&config.Config{AgentOf: srv.URL, Silent: true}, logging.NewConsole(&out, &out))
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := agent.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.finished:
+	default:
+		t.Fatal("代理节点退出时任务未全部完成")
+	}
+	if len(merged) == 0 {
+		t.Fatal("未合并代理节点发现的漏洞")
+	}
+	for _, result := range merged {
+		if !strings.Contains(result.Payload, "passwd") {
+			t.Errorf("意外的漏洞: %+v", result)
+		}
+	}
+}
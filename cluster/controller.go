@@ -0,0 +1,322 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/scanner"
+)
+
+// 任务租约：代理节点领取任务后需定期发送心跳，超时未续约的任务重新分配给其他节点
+var (
+	leaseTimeout = 60 * time.Second
+	maxAttempts  = 3 // 每个任务最多分配的次数（代理节点掉线或执行失败时重新分配）
+)
+
+// drainTimeout 扫描结束后继续等待代理节点领取任务（得到结束响应）的最长时间
+var drainTimeout = 2 * pollInterval
+
+// lease 已分配的任务
+type lease struct {
+	job      *Job
+	agent    string
+	deadline time.Time
+}
+
+// Controller 分布式扫描控制节点：拆分任务并通过HTTP接口分配给代理节点，汇总回传的结果
+type Controller struct {
+	addr   string
+	token  string
+	config string // 下发给代理节点的扫描配置（YAML）
+	merge  func(target string, results []scanner.ScanResult)
+
+	mu         sync.Mutex
+	jobs       map[int]*Job
+	pending    []*Job         // 等待分配的任务
+	leases     map[int]*lease // 已分配的任务
+	attempts   map[int]int    // 每个任务已分配的次数
+	done       map[int]bool   // 已完成或放弃的任务
+	merging    int            // 正在合并结果的任务数量
+	stopped    bool           // 已停止分配新任务
+	finished   chan struct{}  // 所有任务完成或停止后已分配的任务全部结束时关闭
+	finishOnce sync.Once
+	agents     map[string]bool // 连接过的代理节点，值表示是否已通知扫描结束
+	released   chan struct{}   // 有代理节点得到扫描结束响应
+}
+
+// NewController 创建控制节点，merge 用于合并代理节点回传的漏洞
+func NewController(cfg *config.Config, merge func(target string, results []scanner.ScanResult)) (*Controller, error) {
+	data, err := agentConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("生成代理节点配置失败: %v", err)
+	}
+
+	c := &Controller{
+		addr:     cfg.Controller,
+		token:    cfg.ClusterToken,
+		config:   data,
+		merge:    merge,
+		jobs:     make(map[int]*Job),
+		leases:   make(map[int]*lease),
+		attempts: make(map[int]int),
+		done:     make(map[int]bool),
+		finished: make(chan struct{}),
+		agents:   make(map[string]bool),
+		released: make(chan struct{}, 1),
+	}
+	c.pending = buildJobs(cfg)
+	for _, job := range c.pending {
+		c.jobs[job.ID] = job
+	}
+	c.checkFinished()
+	return c, nil
+}
+
+// Handler 返回控制节点的HTTP接口
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/lease", c.handleLease)
+	mux.HandleFunc("/api/heartbeat", c.handleHeartbeat)
+	mux.HandleFunc("/api/result", c.handleResult)
+	return c.authorize(mux)
+}
+
+// Run 监听 -controller 地址并等待所有任务完成，ctx 取消时与 Stop 相同
+// 任务完成后等待代理节点得到结束响应再关闭接口，避免空闲的节点因连接失败报错
+func (c *Controller) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return fmt.Errorf("控制节点监听 %s 失败: %v", c.addr, err)
+	}
+	server := &http.Server{Handler: c.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	slog.Info(fmt.Sprintf("控制节点已启动，监听 %s，共 %d 个任务，等待代理节点连接...", listener.Addr(), len(c.jobs)))
+
+	ticker := time.NewTicker(leaseTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.finished:
+			c.drain()
+			return nil
+		case <-ctx.Done():
+			c.Stop()
+			ctx = context.Background()
+		case <-ticker.C:
+			c.expireLeases(time.Now())
+		}
+	}
+}
+
+// drain 等待所有代理节点得到扫描结束响应，最多等待 drainTimeout
+func (c *Controller) drain() {
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+	for !c.allReleased() {
+		select {
+		case <-c.released:
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// allReleased 判断是否已通知所有代理节点扫描结束
+func (c *Controller) allReleased() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, released := range c.agents {
+		if !released {
+			return false
+		}
+	}
+	return true
+}
+
+// release 记录已通知代理节点扫描结束（调用方持有锁）
+func (c *Controller) release(agent string) {
+	c.agents[agent] = true
+	select {
+	case c.released <- struct{}{}:
+	default:
+	}
+}
+
+// Stop 停止分配新任务，代理节点在下一次心跳时中止正在执行的任务并回传已有结果（可重复调用）
+func (c *Controller) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	c.pending = nil
+	c.checkFinished()
+}
+
+// authorize 校验共享令牌（-cluster-token），未设置令牌时不校验
+func (c *Controller) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "只支持POST请求", http.StatusMethodNotAllowed)
+			return
+		}
+		if c.token != "" && r.Header.Get(tokenHeader) != c.token {
+			http.Error(w, "令牌无效", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleLease 分配任务：200 返回任务，202 表示暂无可分配的任务（其他节点的任务可能重新分配），204 表示扫描已结束
+func (c *Controller) handleLease(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Agent string `json:"agent"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求格式错误", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	if c.stopped || len(c.done) == len(c.jobs) {
+		c.release(req.Agent)
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	c.agents[req.Agent] = false
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	job := c.pending[0]
+	c.pending = c.pending[1:]
+	c.attempts[job.ID]++
+	c.leases[job.ID] = &lease{job: job, agent: req.Agent, deadline: time.Now().Add(leaseTimeout)}
+	c.mu.Unlock()
+
+	slog.Info(fmt.Sprintf("任务 %d 已分配给 %s: %s [%s]", job.ID, req.Agent, job.Target, job.Tags), "target", job.Target)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Assignment{Job: *job, Config: c.config})
+}
+
+// handleHeartbeat 续约任务，任务已重新分配或扫描已停止时返回 410
+func (c *Controller) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Agent string `json:"agent"`
+		JobID int    `json:"job_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求格式错误", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.leases[req.JobID]
+	if !ok || l.agent != req.Agent || c.stopped {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	l.deadline = time.Now().Add(leaseTimeout)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleResult 接收任务结果，执行失败的任务重新分配
+func (c *Controller) handleResult(w http.ResponseWriter, r *http.Request) {
+	var report Report
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "请求格式错误", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	job, ok := c.jobs[report.JobID]
+	if !ok || c.done[report.JobID] {
+		// 任务已由其他节点完成，忽略重复的结果
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	delete(c.leases, job.ID)
+	if report.Error != "" {
+		slog.Warn(fmt.Sprintf("任务 %d 在 %s 上执行失败: %s", job.ID, report.Agent, report.Error), "target", job.Target)
+		c.retry(job)
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	c.removePending(job.ID)
+	c.done[job.ID] = true
+	c.merging++
+	finished := len(c.done)
+	c.mu.Unlock()
+
+	// 先合并结果再检查是否全部完成，Run 返回时所有结果均已输出
+	c.merge(job.Target, report.Results)
+	slog.Info(fmt.Sprintf("任务 %d 已完成 (%d/%d)，%s 发现 %d 个SSRF测试点", job.ID, finished, len(c.jobs), report.Agent, len(report.Results)), "target", job.Target)
+	w.WriteHeader(http.StatusNoContent)
+
+	c.mu.Lock()
+	c.merging--
+	c.checkFinished()
+	c.mu.Unlock()
+}
+
+// expireLeases 重新分配租约已超时的任务（代理节点掉线）
+func (c *Controller) expireLeases(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, l := range c.leases {
+		if now.Before(l.deadline) {
+			continue
+		}
+		delete(c.leases, id)
+		slog.Warn(fmt.Sprintf("代理节点 %s 的任务 %d 心跳超时", l.agent, id), "target", l.job.Target)
+		c.retry(l.job)
+	}
+	c.checkFinished()
+}
+
+// retry 将任务放回队列，超过最大分配次数时放弃该任务（调用方持有锁）
+func (c *Controller) retry(job *Job) {
+	if c.stopped {
+		c.done[job.ID] = true
+		return
+	}
+	if c.attempts[job.ID] >= maxAttempts {
+		slog.Error(fmt.Sprintf("任务 %d 已分配 %d 次仍未完成，跳过: %s [%s]", job.ID, maxAttempts, job.Target, job.Tags), "target", job.Target)
+		c.done[job.ID] = true
+		return
+	}
+	c.pending = append(c.pending, job)
+}
+
+// removePending 从队列中移除任务（超时后重新排队的任务被原节点完成）
+func (c *Controller) removePending(id int) {
+	for i, job := range c.pending {
+		if job.ID == id {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// checkFinished 所有任务完成，或已停止且没有进行中的任务时结束扫描（调用方持有锁）
+func (c *Controller) checkFinished() {
+	if c.merging > 0 {
+		return
+	}
+	if len(c.done) == len(c.jobs) || c.stopped && len(c.leases) == 0 {
+		c.finishOnce.Do(func() { close(c.finished) })
+	}
+}
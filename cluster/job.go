@@ -0,0 +1,119 @@
+package cluster
+
+import (
+	"strings"
+
+	"gosssrf-client/config"
+	"gosssrf-client/scanner"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ipShardSize 端口扫描任务每个分片包含的内网IP数量
+const ipShardSize = 256
+
+// tokenHeader 控制节点校验共享令牌（-cluster-token）使用的请求头
+const tokenHeader = "X-GoSSRF-Token"
+
+// Job 分布式扫描任务：一个目标的一个扫描模块，端口扫描再按内网IP分片
+type Job struct {
+	ID       int    `json:"id"`
+	Target   string `json:"target"`
+	Tags     string `json:"tags"`               // 任务启用的扫描模块（-tags格式）
+	Internal string `json:"internal,omitempty"` // 端口扫描分片的内网IP（-i格式），为空时使用下发配置中的地址
+	Extra    bool   `json:"extra,omitempty"`    // 是否执行与模块无关的阶段（时间盲注、开放重定向、DNS重绑定、内网主机名探测）
+}
+
+// Assignment 代理节点领取到的任务及扫描配置
+type Assignment struct {
+	Job    Job    `json:"job"`
+	Config string `json:"config"` // YAML格式的扫描配置
+}
+
+// Report 代理节点回传的任务结果
+type Report struct {
+	JobID   int                  `json:"job_id"`
+	Agent   string               `json:"agent"`
+	Results []scanner.ScanResult `json:"results"`
+	Error   string               `json:"error,omitempty"` // 任务执行失败的原因，失败的任务会重新分配
+}
+
+// buildJobs 将 目标×扫描模块 拆分为任务
+// 与模块无关的阶段只在每个目标的第一个任务中执行；指定 -w 时每个目标一个任务
+func buildJobs(cfg *config.Config) []*Job {
+	extra := cfg.Timing || cfg.OpenRedirect || cfg.RebindDomain != "" || len(cfg.HostNames) > 0
+	var jobs []*Job
+	add := func(job Job) {
+		job.ID = len(jobs) + 1
+		jobs = append(jobs, &job)
+	}
+
+	for _, target := range cfg.Targets {
+		if cfg.PayloadFile != "" {
+			add(Job{Target: target, Tags: cfg.Tags, Extra: true})
+			continue
+		}
+
+		first := len(jobs)
+		for _, tag := range config.AllTags {
+			if !cfg.HasTag(tag) || tag == config.TagOOB && !cfg.ShouldScanOOB() {
+				continue
+			}
+			if tag == config.TagPorts && cfg.InternalIPs != nil {
+				for _, shard := range cfg.InternalIPs.Shards(ipShardSize) {
+					add(Job{Target: target, Tags: tag, Internal: shard})
+				}
+				continue
+			}
+			add(Job{Target: target, Tags: tag})
+		}
+		if !extra {
+			continue
+		}
+		if len(jobs) > first {
+			jobs[first].Extra = true
+		} else {
+			// 未启用任何模块时单独执行与模块无关的阶段
+			add(Job{Target: target, Tags: noTags(), Extra: true})
+		}
+	}
+	return jobs
+}
+
+// noTags 返回排除全部扫描模块的 -tags 参数
+func noTags() string {
+	excluded := make([]string, len(config.AllTags))
+	for i, tag := range config.AllTags {
+		excluded[i] = "-" + tag
+	}
+	return strings.Join(excluded, ",")
+}
+
+// agentConfig 生成下发给代理节点的扫描配置：去掉目标来源、输出、通知和本地服务等只在控制节点生效的参数，
+// 请求方式、请求体模板和自定义Header按控制节点解析后的结果下发
+func agentConfig(cfg *config.Config) (string, error) {
+	c := *cfg
+	c.TargetURL, c.TargetFile, c.FileTargets, c.RawRequestFile, c.BurpFile, c.BurpScope = "", "", nil, "", "", ""
+	c.BodyData = cfg.BodyTemplate
+	c.GraphQLFile, c.GraphQLOperation = "", ""
+	c.HeaderFile = ""
+	// 内网主机名字典已在控制节点上加载，随 HostNames 下发
+	c.HostsWordlist = ""
+	c.FileHeaders = cfg.CustomHeaders
+	c.OutputFile, c.OutputFormat, c.ReportTemplate, c.DBFile, c.ResumeFile, c.LogFile = "", "", "", "", "", ""
+	c.Workspace = ""
+	c.S3, c.S3Endpoint, c.S3Region = "", "", ""
+	c.Webhook, c.Syslog, c.Slack, c.Discord, c.TelegramToken, c.TelegramChat = "", "", "", "", "", ""
+	c.EmailReport, c.SMTPServer, c.SMTPUser, c.SMTPPassword, c.SMTPFrom = "", "", "", "", ""
+	c.OOBListen, c.DNSListen, c.OOBMapFile = "", "", ""
+	c.MaxScanTime = 0
+	c.Controller, c.AgentOf, c.ClusterToken = "", "", ""
+	// 扫描预设已在控制节点上应用，代理节点直接使用展开后的参数
+	c.Profile = ""
+
+	data, err := yaml.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
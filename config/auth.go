@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 认证方式（-auth参数）
+const (
+	AuthBasic     = "basic"
+	AuthBearer    = "bearer"
+	AuthNTLM      = "ntlm"
+	AuthNegotiate = "negotiate"
+)
+
+// Auth 解析后的认证信息
+type Auth struct {
+	Scheme   string // basic/bearer/ntlm/negotiate
+	Domain   string // NTLM域名，可为空
+	User     string
+	Password string
+	Token    string // bearer 令牌
+}
+
+// parseAuth 解析 -auth 参数：basic:用户名:密码、bearer:令牌、ntlm:[域\]用户名:密码、negotiate:[域\]用户名:密码
+// 密码中可以包含冒号；NTLM用户名也可写作 用户名@域
+func parseAuth(s string) (*Auth, error) {
+	scheme, rest, ok := strings.Cut(s, ":")
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("无效的认证参数: %s (格式: basic:用户名:密码、bearer:令牌、ntlm:域\\用户名:密码)", s)
+	}
+
+	auth := &Auth{Scheme: strings.ToLower(scheme)}
+	switch auth.Scheme {
+	case AuthBearer:
+		auth.Token = rest
+		return auth, nil
+	case AuthBasic, AuthNTLM, AuthNegotiate:
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s (支持 basic/bearer/ntlm/negotiate)", scheme)
+	}
+
+	user, password, ok := strings.Cut(rest, ":")
+	if !ok || user == "" {
+		return nil, fmt.Errorf("%s认证缺少用户名或密码: %s", auth.Scheme, s)
+	}
+	auth.User, auth.Password = user, password
+	if auth.Scheme != AuthBasic {
+		if domain, name, ok := strings.Cut(user, `\`); ok {
+			auth.Domain, auth.User = domain, name
+		} else if name, domain, ok := strings.Cut(user, "@"); ok {
+			auth.User, auth.Domain = name, domain
+		}
+	}
+	return auth, nil
+}
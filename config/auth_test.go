@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestParseAuth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Auth
+	}{
+		{"basic:admin:p@ss:word", Auth{Scheme: AuthBasic, User: "admin", Password: "p@ss:word"}},
+		{"Bearer:eyJhbGciOi.x.y", Auth{Scheme: AuthBearer, Token: "eyJhbGciOi.x.y"}},
+		{`ntlm:CORP\admin:secret`, Auth{Scheme: AuthNTLM, Domain: "CORP", User: "admin", Password: "secret"}},
+		{"negotiate:admin@corp.local:secret", Auth{Scheme: AuthNegotiate, Domain: "corp.local", User: "admin", Password: "secret"}},
+		{"ntlm:admin:", Auth{Scheme: AuthNTLM, User: "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAuth(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("得到 %+v，期望 %+v", *got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"admin:pass", "basic:", "basic:admin", "digest:admin:pass", "bearer"} {
+		if _, err := parseAuth(bad); err == nil {
+			t.Errorf("%q 期望返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// staticExtensions 导入Burp请求和爬取页面时跳过的静态资源扩展名
+var staticExtensions = map[string]bool{
+	"js": true, "css": true, "map": true, "png": true, "jpg": true, "jpeg": true, "gif": true, "ico": true, "svg": true,
+	"webp": true, "bmp": true, "woff": true, "woff2": true, "ttf": true, "eot": true, "otf": true, "mp3": true, "mp4": true,
+	"webm": true, "avi": true, "pdf": true, "zip": true, "gz": true,
+}
+
+// burpItems Burp "Save items" 导出的XML文件
+type burpItems struct {
+	Items []burpItem `xml:"item"`
+}
+
+// burpItem 导出文件中的一个请求
+type burpItem struct {
+	URL      string `xml:"url"`
+	Protocol string `xml:"protocol"`
+	Request  struct {
+		Base64 bool   `xml:"base64,attr"`
+		Data   string `xml:",chardata"`
+	} `xml:"request"`
+}
+
+// loadBurpItems 读取Burp导出的XML文件（-burp参数），返回要扫描的请求（按地址去重）
+// 跳过没有请求内容、请求方式无法注入、静态资源和不匹配 scope 正则的请求
+func loadBurpItems(filePath, scope string, forceSSL bool) ([]*RawRequest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取Burp导出文件失败: %v", err)
+	}
+	var items burpItems
+	if err := xml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("解析Burp导出文件失败: %v", err)
+	}
+	var scopePattern *regexp.Regexp
+	if scope != "" {
+		if scopePattern, err = regexp.Compile(scope); err != nil {
+			return nil, fmt.Errorf("无效的 -burp-scope 正则: %v", err)
+		}
+	}
+
+	var requests []*RawRequest
+	seen := make(map[string]bool)
+	for i, item := range items.Items {
+		content := []byte(item.Request.Data)
+		if item.Request.Base64 {
+			if content, err = base64.StdEncoding.DecodeString(strings.TrimSpace(item.Request.Data)); err != nil {
+				return nil, fmt.Errorf("Burp导出文件第 %d 个请求不是有效的base64: %v", i+1, err)
+			}
+		}
+		if len(strings.TrimSpace(string(content))) == 0 {
+			continue
+		}
+		raw, err := parseRawRequestData(content, forceSSL || strings.EqualFold(item.Protocol, "https"))
+		if err != nil {
+			return nil, fmt.Errorf("Burp导出文件第 %d 个请求: %v", i+1, err)
+		}
+		// 导出文件中的地址包含协议和端口，优先使用
+		if u, err := url.Parse(strings.TrimSpace(item.URL)); err == nil && u.Host != "" {
+			raw.URL = u.String()
+		}
+
+		switch raw.Method {
+		case "GET", "POST", "PUT", "PATCH":
+		default:
+			continue
+		}
+		if IsStaticResource(raw.URL) {
+			continue
+		}
+		if (scopePattern != nil && !scopePattern.MatchString(raw.URL)) || seen[raw.URL] {
+			continue
+		}
+		seen[raw.URL] = true
+		raw.BodyType = bodyTypeOf(raw.Headers)
+		requests = append(requests, raw)
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("Burp导出文件中没有可扫描的请求: %s", filePath)
+	}
+	return requests, nil
+}
+
+// IsStaticResource 判断地址是否为静态资源（脚本、样式、图片、字体、媒体和压缩包等）
+func IsStaticResource(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && staticExtensions[strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")]
+}
+
+// TargetRequest 返回扫描目标使用的请求方式、请求体类型和请求体模板，-burp 导入的目标使用导入的请求
+func (c *Config) TargetRequest(target string) (method, bodyType, body string) {
+	if req, ok := c.Requests[target]; ok {
+		return req.Method, req.BodyType, req.Body
+	}
+	return c.Method, c.BodyType, c.BodyTemplate
+}
+
+// TargetHeaders 返回 -burp 导入的目标请求中的Header，其他目标返回nil
+func (c *Config) TargetHeaders(target string) map[string]string {
+	if req, ok := c.Requests[target]; ok {
+		return req.Headers
+	}
+	return nil
+}
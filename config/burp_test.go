@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// burpXMLItem 生成导出文件中的一个请求，base64 为 false 时直接写入CDATA
+func burpXMLItem(url, protocol, request string, encoded bool) string {
+	data := request
+	if encoded {
+		data = base64.StdEncoding.EncodeToString([]byte(request))
+	}
+	b64 := "false"
+	if encoded {
+		b64 = "true"
+	}
+	return "<item><url><![CDATA[" + url + "]]></url><protocol>" + protocol + "</protocol>" +
+		`<request base64="` + b64 + `"><![CDATA[` + data + "]]></request><response base64=\"true\"></response></item>"
+}
+
+func TestLoadBurpItems(t *testing.T) {
+	items := []string{
+		burpXMLItem("https://a.example:8443/api?url=x", "https", "POST /api?url=x HTTP/1.1\r\nHost: a.example:8443\r\nContent-Type: application/json\r\nCookie: sid=1\r\nContent-Length: 11\r\n\r\n{\"img\":\"a\"}", true),
+		burpXMLItem("http://a.example/fetch?u=1", "http", "GET /fetch?u=1 HTTP/1.1\nHost: a.example\n\n", false),
+		burpXMLItem("http://a.example/app.js", "http", "GET /app.js HTTP/1.1\nHost: a.example\n\n", false),
+		burpXMLItem("http://a.example/fetch?u=1", "http", "GET /fetch?u=1 HTTP/1.1\nHost: a.example\n\n", false),
+		burpXMLItem("http://a.example/api", "http", "OPTIONS /api HTTP/1.1\nHost: a.example\n\n", false),
+		burpXMLItem("http://b.example/proxy?url=1", "http", "GET /proxy?url=1 HTTP/1.1\nHost: b.example\n\n", false),
+		"<item><url><![CDATA[http://a.example/empty]]></url><request base64=\"true\"></request></item>",
+	}
+	content := `<?xml version="1.0"?>
+<!DOCTYPE items [
+<!ELEMENT items (item*)>
+]>
+<items burpVersion="2023.10">` + strings.Join(items, "\n") + "</items>"
+	path := filepath.Join(t.TempDir(), "burp.xml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		scope   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "跳过静态资源、重复地址和无法注入的请求方式", want: []string{"https://a.example:8443/api?url=x", "http://a.example/fetch?u=1", "http://b.example/proxy?url=1"}},
+		{name: "按地址正则过滤", scope: `^https?://b\.example/`, want: []string{"http://b.example/proxy?url=1"}},
+		{name: "没有匹配的请求", scope: `c\.example`, wantErr: true},
+		{name: "无效的正则", scope: `(`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadBurpItems(path, tt.scope, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadBurpItems() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("导入 %d 个请求，期望 %d", len(got), len(tt.want))
+			}
+			for i, req := range got {
+				if req.URL != tt.want[i] {
+					t.Errorf("第 %d 个请求地址为 %s，期望 %s", i, req.URL, tt.want[i])
+				}
+			}
+		})
+	}
+
+	requests, _ := loadBurpItems(path, "", false)
+	api := requests[0]
+	if api.Method != "POST" || api.Body != `{"img":"a"}` || api.BodyType != BodyTypeJSON || api.Headers["Cookie"] != "sid=1" {
+		t.Errorf("导入的请求内容不正确: %+v", api)
+	}
+
+	c := &Config{Method: "GET", BodyType: BodyTypeForm, Requests: map[string]*RawRequest{api.URL: api}}
+	if method, bodyType, body := c.TargetRequest(api.URL); method != "POST" || bodyType != BodyTypeJSON || body != api.Body {
+		t.Errorf("导入的目标应使用导入的请求，得到 %s %s %s", method, bodyType, body)
+	}
+	if method, bodyType, _ := c.TargetRequest("http://other.example/"); method != "GET" || bodyType != BodyTypeForm || c.TargetHeaders("http://other.example/") != nil {
+		t.Errorf("其他目标应使用命令行参数，得到 %s %s", method, bodyType)
+	}
+}
@@ -8,6 +8,7 @@ const (
 	ColorRed    ColorType = "red"
 	ColorGreen  ColorType = "green"
 	ColorYellow ColorType = "yellow"
+	ColorNone   ColorType = "" // 不使用颜色
 )
 
 func Colors(colorType ColorType) *color.Color {
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 子命令
+const (
+	CommandScan     = "scan"     // 扫描目标（未指定子命令时的默认行为）
+	CommandServe    = "serve"    // 作为分布式扫描的代理节点运行
+	CommandPayloads = "payloads" // 列出扫描会发送的payload
+	CommandReport   = "report"   // 渲染或比较保存的报告
+	CommandOOB      = "oob"      // 独立运行内置OOB回连服务和DNS重绑定服务
+	CommandReplay   = "replay"   // 重放已保存漏洞的请求
+	CommandLint     = "lint"     // 扫描前检查配置文件、Header文件和payload字典
+	CommandVersion  = "version"  // 输出版本、编译信息和内置字典
+)
+
+// command 子命令的说明和接受的参数
+type command struct {
+	name  string
+	usage string   // 用法，程序名之后的部分
+	short string   // 一行说明
+	flags []string // 接受的参数（按帮助信息输出顺序）
+}
+
+// scanFlags scan 子命令的参数，也是 replay 子命令的请求参数
+var scanFlags = []string{"config", "profile", "u", "l", "r", "force-ssl", "burp", "burp-scope", "crawl", "depth", "X", "d", "graphql", "graphql-operation", "body-type", "p", "H", "random-agent", "header-sets", "o", "format", "report-template", "evidence-dir", "db", "s3", "s3-endpoint", "s3-region", "webhook", "syslog", "slack", "discord", "telegram-token", "telegram-chat", "notify-severity", "notify-template", "email-report", "smtp", "smtp-user", "smtp-pass", "smtp-from", "v", "vv", "silent", "log-file", "log-format", "workspace", "resume", "w", "oob", "serve-oob", "oob-wait", "oob-mode", "oob-map", "rebind-domain", "rebind-ip", "serve-dns", "timing", "timing-threshold", "timing-hosts", "open-redirect", "second-order", "second-order-delay", "exploit-metadata", "exploit", "redis-addr", "redis-mode", "redis-data", "redis-path", "no-baseline", "calibrate-url", "no-waf-bypass", "match-regex", "match-code", "match-size", "filter-regex", "filter-code", "filter-size", "keywords", "severity-map", "encoders", "tags", "exclude-payload", "exclude-type", "payload-allow", "payload-deny", "force", "safe", "secret-rules", "no-secrets", "plugin", "cloud", "i", "ports", "hosts-wordlist", "proxy", "proxy-file", "proxy-rotate", "resolver", "resolve", "auth", "login", "login-script", "logout-regex", "script", "http2", "cert", "key", "ca", "sni", "tls-min", "follow-redirects", "timeout", "max-body", "max-idle-per-host", "idle-timeout", "keep-alive", "no-keep-alive", "t", "threads-per-host", "adaptive", "delaytime", "jitter", "retries", "max-scan-time", "watch", "all", "controller", "cluster-token", "verify"}
+
+// logFlags 所有子命令共用的日志参数
+var logFlags = []string{"v", "vv", "silent", "log-file", "log-format"}
+
+// commands 全部子命令（按帮助信息输出顺序）
+var commands = []command{
+	{
+		name:  CommandScan,
+		usage: "scan [参数] [-]",
+		short: "扫描目标中的SSRF漏洞（未指定子命令时按旧版命令行解析，接受全部参数）",
+		flags: scanFlags,
+	},
+	{
+		name:  CommandServe,
+		usage: "serve -agent <控制节点地址> [参数]",
+		short: "作为分布式扫描的代理节点运行，执行控制节点 (scan -controller) 下发的扫描任务",
+		flags: append([]string{"config", "agent", "cluster-token"}, logFlags...),
+	},
+	{
+		name:  CommandPayloads,
+		usage: "payloads list [参数] | payloads gen redis|ip|cloud [参数]",
+		short: "按扫描顺序列出每个参数会发送的payload及其类型和关键字 (list)，或生成单独使用的payload (gen)，不发送请求",
+		flags: []string{"config", "profile", "u", "w", "tags", "all", "cloud", "i", "ports", "hosts-wordlist", "open-redirect", "oob", "rebind-domain", "rebind-ip", "encoders", "exclude-payload", "exclude-type", "payload-allow", "payload-deny", "safe", "plugin", "redis-addr", "redis-mode", "redis-data", "redis-path", "v", "silent"},
+	},
+	{
+		name:  CommandReport,
+		usage: "report [参数] <报告> | report -diff [参数] <旧报告> <新报告>",
+		short: "将保存的漏洞 (-format json/jsonl) 渲染为其他格式的报告，或比较两次扫描的报告",
+		flags: append([]string{"config", "diff", "o", "format", "report-template"}, logFlags...),
+	},
+	{
+		name:  CommandOOB,
+		usage: "oob -serve-oob <监听地址> | -serve-dns <监听地址> [参数]",
+		short: "独立运行内置OOB回连服务和DNS重绑定服务，输出收到的每一次回连和DNS查询",
+		flags: append([]string{"config", "serve-oob", "oob", "serve-dns", "rebind-domain"}, logFlags...),
+	},
+	{
+		name:  CommandReplay,
+		usage: "replay -finding <漏洞> [参数]",
+		short: "重新发送已保存漏洞的原始请求，输出完整的请求和响应报文",
+		flags: append([]string{"finding"}, scanFlags...),
+	},
+	{
+		name:  CommandLint,
+		usage: "lint [参数]",
+		short: "扫描前检查配置文件、Header文件、payload字典等文件中扫描时会被跳过的无效行、未知的模板变量和重复项，并确认OOB服务器可以连接",
+		flags: append([]string{"config", "H", "header-sets", "w", "severity-map", "secret-rules", "oob", "timeout"}, logFlags...),
+	},
+	{
+		name:  CommandVersion,
+		usage: "version [-v]",
+		short: "输出版本号，-v 时同时输出编译提交、内置字典的来源和SHA-256以及各扫描模块的payload数量",
+		flags: []string{"v"},
+	},
+}
+
+// lookupCommand 查找子命令，diff 是 report -diff 的别名
+func lookupCommand(name string) *command {
+	if name == "diff" {
+		name = CommandReport
+	}
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// Parse 解析命令行参数（不含程序名）：第一个参数为子命令时只接受该子命令的参数，
+// 以 - 开头或为空时按 scan 解析并接受全部参数，兼容旧版的命令行
+// 参数错误时已输出错误和帮助信息，返回 flag.ErrHelp 表示只输出了帮助信息
+func Parse(args []string) (*Config, error) {
+	return parse(args, os.Stderr)
+}
+
+// parse 解析命令行参数，帮助和错误信息写入 output
+func parse(args []string, output io.Writer) (*Config, error) {
+	cfg, all := newConfig()
+	all.SetOutput(output)
+
+	// 旧版命令行：不指定子命令，全部参数都可用
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		cfg.Command = CommandScan
+		cfg.flags = all
+		all.Usage = func() { printCommands(output) }
+		if err := all.Parse(args); err != nil {
+			return nil, err
+		}
+		if err := cfg.parseArgs(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
+	if args[0] == "help" {
+		if len(args) > 1 {
+			if cmd := lookupCommand(args[1]); cmd != nil {
+				printCommand(output, cmd, all)
+				return nil, flag.ErrHelp
+			}
+		}
+		printCommands(output)
+		return nil, flag.ErrHelp
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		err := fmt.Errorf("未知的子命令: %s", args[0])
+		fmt.Fprintln(output, err)
+		printCommands(output)
+		return nil, err
+	}
+	cfg.Command = cmd.name
+	cfg.Replay = cmd.name == CommandReplay
+	cfg.Diff = args[0] == "diff"
+
+	// 子命令的参数与全部参数共用同一个值，配置文件和扫描预设仍按参数名设置
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	for _, name := range cmd.flags {
+		f := all.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	fs.Usage = func() { printCommand(output, cmd, fs) }
+	cfg.flags = fs
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if err := cfg.parseArgs(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseArgs 处理参数之后的位置参数
+func (c *Config) parseArgs() error {
+	// report 的报告文件可以写在参数之前或之后
+	if c.Command == CommandReport {
+		for args := c.flags.Args(); len(args) > 0; args = c.flags.Args() {
+			c.ReportFiles = append(c.ReportFiles, args[0])
+			if err := c.flags.Parse(args[1:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// payloads 的操作（list 可以省略，gen 之后为生成的种类）可以写在参数之前或之后
+	if c.Command == CommandPayloads {
+		for args := c.flags.Args(); len(args) > 0; args = c.flags.Args() {
+			switch {
+			case args[0] == "list" && c.Generate == "":
+			case args[0] == "gen" && len(args) > 1 && c.Generate == "":
+				c.Generate = strings.ToLower(args[1])
+				args = args[1:]
+			default:
+				err := fmt.Errorf("无法识别的payloads操作: %s (支持 list、gen <种类>)", strings.Join(args, " "))
+				fmt.Fprintln(c.flags.Output(), err)
+				c.flags.Usage()
+				return err
+			}
+			if err := c.flags.Parse(args[1:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// 最后的 - 等同于 -l -，从标准输入读取目标，例如: cat urls.txt | gossrf scan -p url -
+	args := c.flags.Args()
+	if len(args) == 1 && args[0] == StdinFile && c.TargetFile == "" && c.flags.Lookup("l") != nil {
+		return c.flags.Set("l", StdinFile)
+	}
+	if len(args) > 0 {
+		err := fmt.Errorf("无法识别的参数: %s", strings.Join(args, " "))
+		fmt.Fprintln(c.flags.Output(), err)
+		c.flags.Usage()
+		return err
+	}
+	return nil
+}
+
+// visitFlags 遍历命令行中显式指定的参数
+func (c *Config) visitFlags(fn func(*flag.Flag)) {
+	if c.flags != nil {
+		c.flags.Visit(fn)
+	}
+}
+
+// Usage 输出当前子命令的帮助信息
+func (c *Config) Usage() {
+	if c.flags != nil {
+		c.flags.Usage()
+	}
+}
+
+// printCommands 输出子命令列表
+func printCommands(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <子命令> [参数]\n\n子命令:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(w, "\n使用 \"%s <子命令> -h\" 查看子命令的参数\n", os.Args[0])
+}
+
+// printCommand 输出子命令的用法和参数
+func printCommand(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s %s\n\n%s\n\n参数:\n", os.Args[0], cmd.usage, cmd.short)
+	for _, name := range cmd.flags {
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  -%s", f.Name)
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(w, " %s", f.DefValue)
+		}
+		fmt.Fprintf(w, "\n    \t%s\n", f.Usage)
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+		check   func(*Config) bool
+		wantErr bool
+	}{
+		{"旧版命令行按scan解析", []string{"-u", "http://a.example/", "-agent", "http://10.0.0.1:9300"}, CommandScan,
+			func(c *Config) bool { return c.TargetURL == "http://a.example/" && c.AgentOf == "http://10.0.0.1:9300" }, false},
+		{"旧版命令行最后的-从标准输入读取", []string{"-p", "url", "-"}, CommandScan,
+			func(c *Config) bool { return c.TargetFile == StdinFile }, false},
+		{"scan子命令", []string{"scan", "-u", "http://a.example/", "-t", "5"}, CommandScan,
+			func(c *Config) bool { return c.TargetURL == "http://a.example/" && c.Threads == 5 }, false},
+		{"scan不接受代理节点参数", []string{"scan", "-agent", "http://10.0.0.1:9300"}, "", nil, true},
+		{"serve子命令", []string{"serve", "-agent", "http://10.0.0.1:9300", "-cluster-token", "s3cret"}, CommandServe,
+			func(c *Config) bool { return c.AgentOf == "http://10.0.0.1:9300" && c.ClusterToken == "s3cret" }, false},
+		{"serve不接受扫描参数", []string{"serve", "-u", "http://a.example/"}, "", nil, true},
+		{"payloads子命令", []string{"payloads", "-tags", "cloud", "-cloud", "aws"}, CommandPayloads,
+			func(c *Config) bool { return c.Tags == "cloud" && c.Cloud == "aws" }, false},
+		{"payloads list子命令", []string{"payloads", "list", "-i", "10.0.0.1-3", "-ports", "6379"}, CommandPayloads,
+			func(c *Config) bool { return c.InternalNet == "10.0.0.1-3" && c.Ports == "6379" }, false},
+		{"payloads操作在参数之后", []string{"payloads", "-encoders", "url", "list"}, CommandPayloads,
+			func(c *Config) bool { return c.Encoders == "url" }, false},
+		{"payloads gen子命令", []string{"payloads", "-cloud", "gcp", "gen", "Cloud", "-encoders", "url"}, CommandPayloads,
+			func(c *Config) bool { return c.Generate == GenerateCloud && c.Cloud == "gcp" && c.Encoders == "url" }, false},
+		{"payloads gen缺少种类", []string{"payloads", "gen"}, "", nil, true},
+		{"payloads未知操作", []string{"payloads", "send"}, "", nil, true},
+		{"report报告文件在参数之前", []string{"report", "scan.json", "-o", "report.html"}, CommandReport,
+			func(c *Config) bool { return len(c.ReportFiles) == 1 && c.OutputFile == "report.html" && !c.Diff }, false},
+		{"report -diff", []string{"report", "-diff", "old.json", "new.json", "-o", "diff.json"}, CommandReport,
+			func(c *Config) bool { return c.Diff && strings.Join(c.ReportFiles, ",") == "old.json,new.json" }, false},
+		{"diff是report -diff的别名", []string{"diff", "old.json", "-format", "json", "new.json"}, CommandReport,
+			func(c *Config) bool {
+				return c.Diff && strings.Join(c.ReportFiles, ",") == "old.json,new.json" && c.OutputFormat == "json"
+			}, false},
+		{"oob子命令", []string{"oob", "-serve-oob", ":8080", "-oob", "http://oob.example.com"}, CommandOOB,
+			func(c *Config) bool { return c.OOBListen == ":8080" && c.IsOOBServeOnly() }, false},
+		{"replay子命令", []string{"replay", "-finding", "12", "-db", "results.sqlite"}, CommandReplay,
+			func(c *Config) bool { return c.Replay && c.Finding == "12" && c.DBFile == "results.sqlite" }, false},
+		{"scan不接受-finding", []string{"scan", "-finding", "12"}, "", nil, true},
+		{"多余的位置参数", []string{"scan", "-u", "http://a.example/", "extra"}, "", nil, true},
+		{"未知子命令", []string{"crawl"}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parse(tt.args, io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Command != tt.command {
+				t.Errorf("Command = %q, want %q", cfg.Command, tt.command)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("parse(%q) 解析结果不正确: %+v", tt.args, cfg)
+			}
+		})
+	}
+}
+
+func TestParseHelp(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"help"}, {"help", "payloads"}, {"payloads", "-h"}} {
+		var out strings.Builder
+		if _, err := parse(args, &out); err != flag.ErrHelp {
+			t.Errorf("parse(%q) error = %v, want flag.ErrHelp", args, err)
+		}
+		if !strings.Contains(out.String(), "Usage:") {
+			t.Errorf("parse(%q) 没有输出帮助信息: %q", args, out.String())
+		}
+	}
+
+	var out strings.Builder
+	parse([]string{"help", "payloads"}, &out)
+	if !strings.Contains(out.String(), "-tags") || strings.Contains(out.String(), "-proxy") {
+		t.Errorf("payloads 的帮助信息应只包含该子命令的参数: %q", out.String())
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	_, all := newConfig()
+	used := make(map[string]bool)
+	for _, cmd := range commands {
+		for _, name := range cmd.flags {
+			if all.Lookup(name) == nil {
+				t.Errorf("%s 子命令的参数 -%s 未注册", cmd.name, name)
+			}
+			used[name] = true
+		}
+	}
+	// 每个参数至少属于一个子命令，否则只能在旧版命令行中使用
+	all.VisitAll(func(f *flag.Flag) {
+		if !used[f.Name] {
+			t.Errorf("参数 -%s 不属于任何子命令", f.Name)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile 从YAML配置文件加载配置
+// 配置文件中的值会覆盖默认值，命令行中显式指定的参数再覆盖配置文件
+func (c *Config) loadConfigFile() error {
+	data, err := os.ReadFile(c.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	// 记录命令行中显式指定的参数
+	explicit := make(map[string]string)
+	c.visitFlags(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析配置文件失败: %v", err)
+	}
+
+	// 记录配置文件中出现的字段，扫描预设不覆盖这些字段
+	var fields map[string]interface{}
+	yaml.Unmarshal(data, &fields)
+	c.fileKeys = make(map[string]bool, len(fields))
+	for key := range fields {
+		c.fileKeys[key] = true
+	}
+
+	// 命令行中指定了任一目标来源（-u/-l/-r/-burp）时，忽略配置文件中的全部目标来源
+	_, hasURL := explicit["u"]
+	_, hasList := explicit["l"]
+	_, hasRaw := explicit["r"]
+	_, hasBurp := explicit["burp"]
+	if hasURL || hasList || hasRaw || hasBurp {
+		c.TargetURL = ""
+		c.TargetFile = ""
+		c.RawRequestFile = ""
+		c.BurpFile = ""
+		c.FileTargets = nil
+	}
+
+	// 重新应用命令行参数
+	for name, value := range explicit {
+		if err := c.flags.Set(name, value); err != nil {
+			return fmt.Errorf("应用命令行参数 -%s 失败: %v", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"gosssrf-client/payloads"
+)
+
+// 利用模块（-exploit参数）
+const (
+	ExploitRedisWrite = "redis-write" // 通过gopher向内网Redis写入文件
+)
+
+// validateExploit 检查 -exploit 参数：利用只针对单个目标的单个参数，不能与安全模式和其他运行模式同时使用
+func (c *Config) validateExploit() error {
+	if c.Exploit == "" {
+		return nil
+	}
+	c.Exploit = strings.ToLower(strings.TrimSpace(c.Exploit))
+	if c.Exploit != ExploitRedisWrite {
+		return fmt.Errorf("不支持的利用模块: %s (支持 %s)", c.Exploit, ExploitRedisWrite)
+	}
+	if c.Safe {
+		return errors.New("-exploit 会修改目标内网服务的状态，不能与 -safe 同时使用")
+	}
+	if c.Replay || c.VerifyFile != "" || c.Controller != "" || c.AgentOf != "" || c.ResumeFile != "" || c.Watch > 0 || c.Crawl {
+		return errors.New("-exploit 不能与 replay、-verify、-controller、-agent、-resume、-watch 和 -crawl 同时使用")
+	}
+	// 发送前需要从标准输入读取确认
+	if c.StdinTargets() || len(c.Targets) != 1 || c.ParamName == "" {
+		return errors.New("-exploit 需要通过 -u 或 -r 指定一个目标，并通过 -p 指定已确认存在SSRF的参数")
+	}
+	return c.validateRedisWrite()
+}
+
+// validateRedisWrite 检查Redis写文件的参数（-exploit redis-write 和 payloads gen redis）
+func (c *Config) validateRedisWrite() error {
+	host, port, err := net.SplitHostPort(c.RedisAddr)
+	if err != nil {
+		return fmt.Errorf("无效的Redis地址: %s (格式: 主机:端口)", c.RedisAddr)
+	}
+	c.RedisPort, err = strconv.Atoi(port)
+	if err != nil || c.RedisPort < 1 || c.RedisPort > 65535 {
+		return fmt.Errorf("无效的Redis端口: %s", port)
+	}
+	c.RedisHost = host
+
+	c.RedisMode = strings.ToLower(strings.TrimSpace(c.RedisMode))
+	supported := false
+	for _, mode := range payloads.RedisWriteModes {
+		supported = supported || c.RedisMode == mode
+	}
+	if !supported {
+		return fmt.Errorf("redis-write 需要通过 -redis-mode 指定写入方式 (支持 %s)", strings.Join(payloads.RedisWriteModes, "/"))
+	}
+	if strings.TrimSpace(c.RedisData) == "" {
+		return errors.New("redis-write 需要通过 -redis-data 指定写入的内容")
+	}
+	c.RedisContent = c.RedisData
+	if c.RedisMode == payloads.RedisModeSSH {
+		data, err := os.ReadFile(c.RedisData)
+		if err != nil {
+			return fmt.Errorf("读取SSH公钥文件失败: %v", err)
+		}
+		c.RedisContent = strings.TrimSpace(string(data))
+		if strings.Contains(c.RedisContent, "PRIVATE KEY") {
+			return fmt.Errorf("%s 是私钥，-redis-data 需要指定公钥文件 (例如 ~/.ssh/id_rsa.pub)", c.RedisData)
+		}
+	}
+	if c.RedisPath != "" && !strings.HasPrefix(c.RedisPath, "/") {
+		return fmt.Errorf("写入的文件 (-redis-path) 需要是绝对路径: %s", c.RedisPath)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// payloads gen 生成的payload种类
+const (
+	GenerateRedis = "redis" // 通过gopher向Redis写文件的payload和清理payload
+	GenerateIP    = "ip"    // IP地址的十进制、十六进制、八进制等等价写法
+	GenerateCloud = "cloud" // 需要特定请求头或请求方式的云元数据请求（gopher）
+)
+
+// Generators payloads gen 支持的种类
+var Generators = []string{GenerateRedis, GenerateIP, GenerateCloud}
+
+// validateGenerate 检查 payloads gen 的参数
+func (c *Config) validateGenerate() error {
+	if c.Generate == "" {
+		return nil
+	}
+	switch c.Generate {
+	case GenerateRedis:
+		return c.validateRedisWrite()
+	case GenerateIP, GenerateCloud:
+		return nil
+	default:
+		return fmt.Errorf("不支持的payload种类: %s (支持 %s)", c.Generate, strings.Join(Generators, "/"))
+	}
+}
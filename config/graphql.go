@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// graphqlRequest GraphQL请求体（application/json）
+type graphqlRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName,omitempty"`
+	Variables     json.RawMessage `json:"variables"`
+}
+
+// loadGraphQL 读取 -graphql 指定的查询或变更文档，与 -d 指定的变量（JSON对象）组成GraphQL请求体
+// GraphQL请求固定使用POST发送
+func (c *Config) loadGraphQL() error {
+	data, err := os.ReadFile(c.GraphQLFile)
+	if err != nil {
+		return fmt.Errorf("读取GraphQL查询文件失败: %v", err)
+	}
+	query := strings.TrimSpace(string(data))
+	if query == "" {
+		return fmt.Errorf("GraphQL查询文件为空: %s", c.GraphQLFile)
+	}
+	if c.BodyType != "" && !strings.EqualFold(c.BodyType, BodyTypeGraphQL) {
+		return fmt.Errorf("-graphql 不能与 -body-type %s 同时使用", c.BodyType)
+	}
+
+	variables := strings.TrimSpace(c.BodyData)
+	if variables == "" {
+		variables = "{}"
+	}
+	if !json.Valid([]byte(variables)) || !strings.HasPrefix(variables, "{") {
+		return errors.New("GraphQL变量 (-d) 需要是JSON对象，例如: {\"url\":\"x\"}")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(graphqlRequest{Query: query, OperationName: c.GraphQLOperation, Variables: json.RawMessage(variables)}); err != nil {
+		return err
+	}
+	c.BodyTemplate = strings.TrimRight(buf.String(), "\n")
+	c.BodyType = BodyTypeGraphQL
+	c.Method = "POST"
+	return nil
+}
+
+// validateGraphQL 检查GraphQL请求体模板：需要是包含 query 字段的JSON对象，variables 为空或JSON对象
+func validateGraphQL(body string) error {
+	var req struct {
+		Query     *string         `json:"query"`
+		Variables json.RawMessage `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil || req.Query == nil {
+		return errors.New("GraphQL请求体需要是包含 query 字段的JSON对象 (或使用 -graphql 指定查询文件)")
+	}
+	if vars := strings.TrimSpace(string(req.Variables)); vars != "" && vars != "null" && !strings.HasPrefix(vars, "{") {
+		return errors.New("GraphQL请求体中的 variables 需要是JSON对象")
+	}
+	return nil
+}
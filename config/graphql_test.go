@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadGraphQL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avatar.graphql")
+	query := "mutation Import($url: String!) {\n  importAvatar(url: $url) { id }\n}\n"
+	if err := os.WriteFile(path, []byte(query), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		bodyData  string
+		bodyType  string
+		operation string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:     "变量",
+			bodyData: `{"url":"http://a/?x=<1>&y=2"}`,
+			want:     `{"query":"mutation Import($url: String!) {\n  importAvatar(url: $url) { id }\n}","variables":{"url":"http://a/?x=<1>&y=2"}}`,
+		},
+		{
+			name:      "没有变量时使用空对象",
+			operation: "Import",
+			want:      `{"query":"mutation Import($url: String!) {\n  importAvatar(url: $url) { id }\n}","operationName":"Import","variables":{}}`,
+		},
+		{name: "变量不是JSON对象", bodyData: `["a"]`, wantErr: true},
+		{name: "与其他请求体类型冲突", bodyType: BodyTypeJSON, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{GraphQLFile: path, GraphQLOperation: tt.operation, BodyData: tt.bodyData, BodyType: tt.bodyType, Method: "GET"}
+			err := c.loadGraphQL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadGraphQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if c.BodyTemplate != tt.want {
+				t.Errorf("BodyTemplate = %s, want %s", c.BodyTemplate, tt.want)
+			}
+			if c.BodyType != BodyTypeGraphQL || c.Method != "POST" {
+				t.Errorf("BodyType = %s, Method = %s, want graphql POST", c.BodyType, c.Method)
+			}
+			if err := validateGraphQL(c.BodyTemplate); err != nil {
+				t.Errorf("validateGraphQL() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateGraphQL(t *testing.T) {
+	tests := []struct {
+		body    string
+		wantErr bool
+	}{
+		{`{"query":"{ a }","variables":null}`, false},
+		{`{"query":"{ a }"}`, false},
+		{`{"variables":{}}`, true},
+		{`{"query":"{ a }","variables":[1]}`, true},
+		{`query { a }`, true},
+	}
+	for _, tt := range tests {
+		if err := validateGraphQL(tt.body); (err != nil) != tt.wantErr {
+			t.Errorf("validateGraphQL(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadHeaderSets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sets.txt")
+	os.WriteFile(path, []byte("# 第一组\nUser-Agent: curl/8.0\nX-Forwarded-For: 10.0.0.1\n\n\r\nUser-Agent: Wget/1.21\r\nAccept: */*\n"), 0644)
+
+	sets, err := loadHeaderSets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 || sets[0]["X-Forwarded-For"] != "10.0.0.1" || sets[1]["User-Agent"] != "Wget/1.21" || sets[1]["Accept"] != "*/*" {
+		t.Errorf("Header组解析错误: %v", sets)
+	}
+
+	for name, content := range map[string]string{"格式错误": "User-Agent curl\n", "空文件": "# 只有注释\n\n"} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := loadHeaderSets(path); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadHostNames 加载内网主机名字典（-hosts-wordlist参数）
+// 每行一个主机名（例如 jenkins.internal、gitlab:8080），也可以是完整地址，# 开头的行为注释，重复的主机名只保留一个
+func loadHostNames(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取内网主机名字典失败: %v", err)
+	}
+	defer file.Close()
+
+	var names []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		if strings.ContainsAny(line, " \t") {
+			return nil, fmt.Errorf("内网主机名字典第 %d 行包含空白字符: %s", lineNum, line)
+		}
+		seen[line] = true
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取内网主机名字典失败: %v", err)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("内网主机名字典 %s 中没有主机名", path)
+	}
+	return names, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadHostNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "internal_names.txt")
+	os.WriteFile(path, []byte("# CI/CD\njenkins.internal\ngitlab\n\n  grafana:3000  \njenkins.internal\nhttps://vault.internal:8200/\n"), 0644)
+
+	names, err := loadHostNames(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"jenkins.internal", "gitlab", "grafana:3000", "https://vault.internal:8200/"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("loadHostNames = %q, want %q", names, want)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"只有注释", "# 只有注释\n"},
+		{"包含空白字符", "jenkins internal\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := filepath.Join(dir, "bad.txt")
+			os.WriteFile(bad, []byte(tt.content), 0644)
+			if _, err := loadHostNames(bad); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+	if _, err := loadHostNames(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("文件不存在时期望返回错误")
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"math"
+	"math/big"
+	"net"
+)
+
+// ipSegment IP列表中的一段：连续地址范围或单个主机名
+type ipSegment struct {
+	start net.IP // 起始地址（含）
+	end   net.IP // 结束地址（含）
+	host  string // 主机名/单个IP，不为空时忽略地址范围
+}
+
+// IPList 内网扫描目标，按需逐个生成IP，避免大网段一次性展开占用大量内存
+type IPList struct {
+	segments []ipSegment
+}
+
+// addHost 添加单个主机名或IP
+func (l *IPList) addHost(host string) {
+	l.segments = append(l.segments, ipSegment{host: host})
+}
+
+// addRange 添加连续地址范围（含起止地址）
+func (l *IPList) addRange(start, end net.IP) {
+	l.segments = append(l.segments, ipSegment{start: start, end: end})
+}
+
+// Len 返回IP总数
+func (l *IPList) Len() int {
+	if l == nil {
+		return 0
+	}
+
+	total := 0
+	for _, seg := range l.segments {
+		if seg.host != "" {
+			total++
+			continue
+		}
+		size := rangeSize(seg.start, seg.end)
+		if total > math.MaxInt-size {
+			return math.MaxInt
+		}
+		total += size
+	}
+	return total
+}
+
+// Each 依次生成每个IP，fn 返回 false 时停止
+func (l *IPList) Each(fn func(ip string) bool) {
+	if l == nil {
+		return
+	}
+
+	for _, seg := range l.segments {
+		if seg.host != "" {
+			if !fn(seg.host) {
+				return
+			}
+			continue
+		}
+
+		current := make(net.IP, len(seg.start))
+		copy(current, seg.start)
+		for {
+			if !fn(current.String()) {
+				return
+			}
+			if compareIP(current, seg.end) >= 0 {
+				break
+			}
+			inc(current)
+		}
+	}
+}
+
+// rangeSize 计算地址范围内的IP数量，超过int范围时返回最大值（大IPv6网段）
+func rangeSize(start, end net.IP) int {
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end), new(big.Int).SetBytes(start))
+	size.Add(size, big.NewInt(1))
+	if !size.IsInt64() || size.Int64() > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(size.Int64())
+}
+
+// Shards 将IP列表拆分为每段最多 size 个IP，每段使用 -i 参数的格式（单个地址或 起始-结束），用于分布式扫描分片
+func (l *IPList) Shards(size int) []string {
+	if l == nil || size <= 0 {
+		return nil
+	}
+
+	var shards []string
+	step := big.NewInt(int64(size))
+	one := big.NewInt(1)
+	for _, seg := range l.segments {
+		if seg.host != "" {
+			shards = append(shards, seg.host)
+			continue
+		}
+		start := new(big.Int).SetBytes(seg.start)
+		end := new(big.Int).SetBytes(seg.end)
+		for start.Cmp(end) <= 0 {
+			last := new(big.Int).Add(start, step)
+			last.Sub(last, one)
+			if last.Cmp(end) > 0 {
+				last.Set(end)
+			}
+			first, final := bigToIP(start, len(seg.start)), bigToIP(last, len(seg.start))
+			if first.Equal(final) {
+				shards = append(shards, first.String())
+			} else {
+				shards = append(shards, first.String()+"-"+final.String())
+			}
+			start = last.Add(last, one)
+		}
+	}
+	return shards
+}
+
+// bigToIP 将整数转换为指定字节长度的IP地址
+func bigToIP(n *big.Int, size int) net.IP {
+	ip := make(net.IP, size)
+	n.FillBytes(ip)
+	return ip
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func collectIPs(l *IPList) []string {
+	var ips []string
+	l.Each(func(ip string) bool {
+		ips = append(ips, ip)
+		return true
+	})
+	return ips
+}
+
+func TestParseInternalIPs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "CIDR /30 去掉网络地址和广播地址",
+			input:   "10.0.0.0/30",
+			want:    []string{"10.0.0.1", "10.0.0.2"},
+			wantLen: 2,
+		},
+		{
+			name:    "CIDR /32",
+			input:   "10.0.0.5/32",
+			want:    []string{"10.0.0.5"},
+			wantLen: 1,
+		},
+		{
+			name:    "CIDR /31 保留两个地址",
+			input:   "10.0.0.4/31",
+			want:    []string{"10.0.0.4", "10.0.0.5"},
+			wantLen: 2,
+		},
+		{
+			name:    "IPv6 CIDR",
+			input:   "fd00::/126",
+			want:    []string{"fd00::1", "fd00::2"},
+			wantLen: 2,
+		},
+		{
+			name:    "IPv6 带方括号",
+			input:   "[::1]",
+			want:    []string{"::1"},
+			wantLen: 1,
+		},
+		{
+			name:    "IPv6 区域标识",
+			input:   "fe80::1%br-lan",
+			want:    []string{"fe80::1%br-lan"},
+			wantLen: 1,
+		},
+		{
+			name:    "IPv6 范围简写",
+			input:   "fd00::fe-100",
+			want:    []string{"fd00::fe", "fd00::ff", "fd00::100"},
+			wantLen: 3,
+		},
+		{
+			name:    "IPv6 完整范围",
+			input:   "fd00::1-fd00::2",
+			want:    []string{"fd00::1", "fd00::2"},
+			wantLen: 2,
+		},
+		{
+			name:    "起止地址类型不一致",
+			input:   "fd00::1-10.0.0.1",
+			wantErr: true,
+		},
+		{
+			name:    "范围简写",
+			input:   "192.168.1.254-255",
+			want:    []string{"192.168.1.254", "192.168.1.255"},
+			wantLen: 2,
+		},
+		{
+			name:    "完整范围跨网段",
+			input:   "192.168.1.255-192.168.2.1",
+			want:    []string{"192.168.1.255", "192.168.2.0", "192.168.2.1"},
+			wantLen: 3,
+		},
+		{
+			name:    "主机名",
+			input:   "redis.internal",
+			want:    []string{"redis.internal"},
+			wantLen: 1,
+		},
+		{
+			name:    "起始IP大于结束IP",
+			input:   "10.0.0.5-1",
+			wantErr: true,
+		},
+		{
+			name:    "非法主机名",
+			input:   "a b",
+			wantErr: true,
+		},
+		{
+			name:    "无效CIDR",
+			input:   "10.0.0.0/33",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := parseInternalIPs(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if got := collectIPs(list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Each 得到 %v，期望 %v", got, tt.want)
+			}
+			if got := list.Len(); got != tt.wantLen {
+				t.Errorf("Len 得到 %d，期望 %d", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestIPListEachStop(t *testing.T) {
+	list, err := parseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list.Len(); got != 1<<24-2 {
+		t.Errorf("Len 得到 %d，期望 %d", got, 1<<24-2)
+	}
+
+	var got []string
+	list.Each(func(ip string) bool {
+		got = append(got, ip)
+		return len(got) < 3
+	})
+	want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("提前停止得到 %v，期望 %v", got, want)
+	}
+}
+
+func TestIPListShards(t *testing.T) {
+	tests := []struct {
+		input string
+		size  int
+		want  []string
+	}{
+		{"192.168.1.0/24", 100, []string{"192.168.1.1-192.168.1.100", "192.168.1.101-192.168.1.200", "192.168.1.201-192.168.1.254"}},
+		{"10.0.0.1-10.0.0.3", 2, []string{"10.0.0.1-10.0.0.2", "10.0.0.3"}},
+		{"fd00::1-fd00::4", 4, []string{"fd00::1-fd00::4"}},
+		{"redis.internal", 256, []string{"redis.internal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ips, err := parseInternalIPs(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ips.Shards(tt.size)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("得到 %v，期望 %v", got, tt.want)
+			}
+			// 每个分片都能重新解析，IP总数不变
+			total := 0
+			for _, shard := range got {
+				part, err := parseInternalIPs(shard)
+				if err != nil {
+					t.Fatalf("分片 %s 无法解析: %v", shard, err)
+				}
+				total += part.Len()
+			}
+			if total != ips.Len() {
+				t.Errorf("分片IP总数 %d，期望 %d", total, ips.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseJitter 解析随机间隔（-jitter参数），返回间隔范围（毫秒）
+// 支持 800（0 到 800 毫秒）、100-800ms、1s-3s 等格式，不写单位时为毫秒
+func parseJitter(raw string) (int, int, error) {
+	s := strings.TrimSpace(raw)
+	low, high, isRange := strings.Cut(s, "-")
+	if !isRange {
+		low, high = "0", s
+	}
+	// 范围只在最后写单位时（100-800ms），单位同时作用于下限
+	unit := strings.TrimLeft(high, "0123456789. ")
+	minMs, err := parseMillis(low, unit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的随机间隔: %s (例如: 800、100-800ms、1s-3s)", raw)
+	}
+	maxMs, err := parseMillis(high, unit)
+	if err != nil || minMs > maxMs {
+		return 0, 0, fmt.Errorf("无效的随机间隔: %s (例如: 800、100-800ms、1s-3s)", raw)
+	}
+	return minMs, maxMs, nil
+}
+
+// parseMillis 解析时间长度（毫秒），没有单位时使用 unit，unit 也为空时为毫秒
+func parseMillis(s, unit string) (int, error) {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		if unit == "" {
+			unit = "ms"
+		}
+		s += unit
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的时间: %s", s)
+	}
+	return int(d.Milliseconds()), nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestParseJitter(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+		wantErr  bool
+	}{
+		{"800", 0, 800, false},
+		{"800ms", 0, 800, false},
+		{"100-800ms", 100, 800, false},
+		{"100ms-800ms", 100, 800, false},
+		{"1s-3s", 1000, 3000, false},
+		{"0.5-2s", 500, 2000, false},
+		{" 200 - 400 ", 200, 400, false},
+		{"800-100ms", 0, 0, true},
+		{"fast", 0, 0, true},
+		{"-800", 0, 0, true},
+	}
+	for _, tt := range tests {
+		min, max, err := parseJitter(tt.in)
+		if (err != nil) != tt.wantErr || min != tt.min || max != tt.max {
+			t.Errorf("parseJitter(%q) = %d, %d, %v，期望 %d, %d", tt.in, min, max, err, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadKeywords 加载自定义检测关键字文件（-keywords参数）
+// 每行一个关键字（去掉首尾空白，区分大小写），# 开头的行为注释，重复的关键字只保留一个
+func loadKeywords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取检测关键字文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var keywords []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		keywords = append(keywords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取检测关键字文件失败: %v", err)
+	}
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("检测关键字文件 %s 中没有关键字", path)
+	}
+	return keywords, nil
+}
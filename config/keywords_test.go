@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadKeywords(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keywords.txt")
+	os.WriteFile(path, []byte("# 内网主机名\nintranet.corp.local\n\n  X-Powered-By: CorpPortal  \nintranet.corp.local\n"), 0644)
+
+	keywords, err := loadKeywords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"intranet.corp.local", "X-Powered-By: CorpPortal"}; !reflect.DeepEqual(keywords, want) {
+		t.Errorf("loadKeywords = %q, want %q", keywords, want)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	os.WriteFile(empty, []byte("# 只有注释\n"), 0644)
+	if _, err := loadKeywords(empty); err == nil {
+		t.Error("没有关键字时期望返回错误")
+	}
+	if _, err := loadKeywords(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("文件不存在时期望返回错误")
+	}
+}
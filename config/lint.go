@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"gosssrf-client/payloads"
+)
+
+// LintIssue lint 子命令发现的问题
+type LintIssue struct {
+	File    string // 文件路径或OOB服务器地址
+	Line    int    // 行号，整个文件的问题为0
+	Message string
+}
+
+// String 返回 文件:行号: 问题 格式的说明
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// lintTemplateVar 匹配 {{...}} 形式的模板变量
+var lintTemplateVar = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// lintHeaderName HTTP头名称允许的字符（RFC 9110 token）
+var lintHeaderName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// Lint 检查扫描前加载的配置文件、Header文件、Header组、payload字典（-w 和覆盖内置字典的本地文件）、
+// 内网主机名字典、严重程度映射和敏感信息规则，并确认OOB服务器可以连接（lint 子命令）
+// 扫描时会被跳过的无效行、未知的模板变量和重复的payload都作为问题返回，files 为检查过的文件
+func (c *Config) Lint(evidenceKinds []string) (files []string, issues []LintIssue) {
+	// 配置文件按严格模式检查语法和未知字段，通过后再加载，检查配置文件中引用的其他文件
+	if c.ConfigFile != "" {
+		files = append(files, c.ConfigFile)
+		fileIssues := lintConfigFile(c.ConfigFile)
+		issues = append(issues, fileIssues...)
+		if len(fileIssues) > 0 {
+			return files, issues
+		}
+		if err := c.loadConfigFile(); err != nil {
+			return files, append(issues, LintIssue{File: c.ConfigFile, Message: err.Error()})
+		}
+	}
+
+	// 默认的 Header.txt 不存在时不检查，扫描时同样使用默认Header
+	if _, err := os.Stat(c.HeaderFile); err == nil || c.HeaderFile != "Header.txt" && c.HeaderFile != "" {
+		files = append(files, c.HeaderFile)
+		issues = append(issues, lintFile(c.HeaderFile, func(data []byte) []LintIssue {
+			return lintHeaders(c.HeaderFile, data, false)
+		})...)
+	}
+	if c.HeaderSetsFile != "" {
+		files = append(files, c.HeaderSetsFile)
+		issues = append(issues, lintFile(c.HeaderSetsFile, func(data []byte) []LintIssue {
+			return lintHeaders(c.HeaderSetsFile, data, true)
+		})...)
+	}
+
+	// 自定义字典和覆盖内置字典的本地文件
+	var payloadFiles []string
+	if c.PayloadFile != "" {
+		payloadFiles = append(payloadFiles, c.PayloadFile)
+	}
+	dictFiles, err := payloads.DictFiles()
+	if err != nil {
+		issues = append(issues, LintIssue{File: "dict", Message: fmt.Sprintf("读取内置字典失败: %v", err)})
+	}
+	for _, file := range dictFiles {
+		if file.Path != "" {
+			payloadFiles = append(payloadFiles, file.Path)
+		}
+	}
+	oobEnabled := c.ShouldScanOOB()
+	for _, path := range payloadFiles {
+		files = append(files, path)
+		issues = append(issues, lintFile(path, func(data []byte) []LintIssue {
+			return lintPayloads(path, data, oobEnabled)
+		})...)
+	}
+
+	if c.HostsWordlist != "" {
+		files = append(files, c.HostsWordlist)
+		if _, err := loadHostNames(c.HostsWordlist); err != nil {
+			issues = append(issues, LintIssue{File: c.HostsWordlist, Message: err.Error()})
+		}
+	}
+	if c.SeverityFile != "" {
+		files = append(files, c.SeverityFile)
+		if m, err := loadSeverityMap(c.SeverityFile); err != nil {
+			issues = append(issues, LintIssue{File: c.SeverityFile, Message: err.Error()})
+		} else if err := m.CheckEvidence(evidenceKinds); err != nil {
+			issues = append(issues, LintIssue{File: c.SeverityFile, Message: err.Error()})
+		}
+	}
+	if c.SecretRulesFile != "" {
+		files = append(files, c.SecretRulesFile)
+		if _, err := loadSecretRules(c.SecretRulesFile); err != nil {
+			issues = append(issues, LintIssue{File: c.SecretRulesFile, Message: err.Error()})
+		}
+	}
+
+	if c.OOBServer != "" {
+		if err := checkOOBServer(c.OOBServer, time.Duration(c.Timeout)*time.Second); err != nil {
+			issues = append(issues, LintIssue{File: c.OOBServer, Message: err.Error()})
+		}
+	}
+	return files, issues
+}
+
+// lintFile 读取文件后交给 check 检查，读取失败时作为问题返回
+func lintFile(path string, check func(data []byte) []LintIssue) []LintIssue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []LintIssue{{File: path, Message: fmt.Sprintf("读取文件失败: %v", err)}}
+	}
+	return check(data)
+}
+
+// lintConfigFile 检查YAML配置文件的语法和未知字段（加载配置时未知字段会被忽略）
+func lintConfigFile(path string) []LintIssue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []LintIssue{{File: path, Message: fmt.Sprintf("读取配置文件失败: %v", err)}}
+	}
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&cfg)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		issues := make([]LintIssue, 0, len(typeErr.Errors))
+		for _, msg := range typeErr.Errors {
+			issue := LintIssue{File: path, Message: msg}
+			// yaml.v3 的错误以 "line N: " 开头
+			if n, err := fmt.Sscanf(msg, "line %d: ", &issue.Line); n == 1 && err == nil {
+				issue.Message = msg[strings.Index(msg, ": ")+2:]
+			}
+			issues = append(issues, issue)
+		}
+		return issues
+	}
+	return []LintIssue{{File: path, Message: err.Error()}}
+}
+
+// lintHeaders 检查Header文件（Burp格式：每行一个 名称: 值），sets 为 true 时按 -header-sets 的格式以空行分组
+func lintHeaders(path string, data []byte, sets bool) []LintIssue {
+	var issues []LintIssue
+	seen := make(map[string]int)
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" && sets {
+			seen = make(map[string]int)
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		switch {
+		case !ok:
+			issues = append(issues, LintIssue{path, lineNum, "缺少冒号，应为: 名称: 值"})
+			continue
+		case name == "":
+			issues = append(issues, LintIssue{path, lineNum, "Header名称为空"})
+			continue
+		case !lintHeaderName.MatchString(name):
+			issues = append(issues, LintIssue{path, lineNum, fmt.Sprintf("Header名称 %q 包含非法字符", name)})
+		}
+
+		key := strings.ToLower(name)
+		if first, ok := seen[key]; ok {
+			issues = append(issues, LintIssue{path, lineNum, fmt.Sprintf("重复的Header %s (第 %d 行)，只有最后一个生效", name, first)})
+		} else {
+			seen[key] = lineNum
+		}
+		for _, v := range lintTemplateVar.FindAllString(value, -1) {
+			issues = append(issues, LintIssue{path, lineNum, fmt.Sprintf("模板变量 %s 只在payload中展开，Header中会原样发送", v)})
+		}
+	}
+	return issues
+}
+
+// lintPayloads 检查payload字典（每行 payload | 关键字1,关键字2 | 类型）
+// oobEnabled 为 false 时包含 {{OOB}} 的payload在扫描时会被跳过
+func lintPayloads(path string, data []byte, oobEnabled bool) []LintIssue {
+	var issues []LintIssue
+	seen := make(map[string]int)
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// 字段之间以两侧带空格的 | 分隔，多出的字段会并入类型
+		if strings.Count(line, " | ") > 2 {
+			issues = append(issues, LintIssue{path, lineNum, "字段过多，应为: payload | 关键字1,关键字2 | 类型"})
+		}
+		p := payloads.ParseDictLine(line, "")
+		for _, v := range lintTemplateVar.FindAllString(p.Value, -1) {
+			if !containsString(payloads.TemplateVarNames, v) {
+				issues = append(issues, LintIssue{path, lineNum, fmt.Sprintf("未知的模板变量 %s (支持 %s)", v, strings.Join(payloads.TemplateVarNames, "、"))})
+			}
+		}
+		if !oobEnabled && strings.Contains(p.Value, payloads.VarOOB) {
+			issues = append(issues, LintIssue{path, lineNum, "包含 {{OOB}}，未指定 -oob 或 -serve-oob 时扫描会跳过"})
+		}
+		if first, ok := seen[p.Value]; ok {
+			issues = append(issues, LintIssue{path, lineNum, fmt.Sprintf("重复的payload (第 %d 行)", first)})
+		} else {
+			seen[p.Value] = lineNum
+		}
+	}
+	return issues
+}
+
+// checkOOBServer 解析OOB服务器的域名并建立TCP连接，确认目标回连时可以访问
+func checkOOBServer(server string, timeout time.Duration) error {
+	u, err := url.Parse(server)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("无效的OOB服务器地址: %s", server)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), timeout)
+	if err != nil {
+		return fmt.Errorf("无法连接OOB服务器: %v", err)
+	}
+	conn.Close()
+	return nil
+}
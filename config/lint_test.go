@@ -0,0 +1,99 @@
+package config
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issueLines 返回问题的行号
+func issueLines(issues []LintIssue) []int {
+	var lines []int
+	for _, issue := range issues {
+		lines = append(lines, issue.Line)
+	}
+	return lines
+}
+
+func TestLintHeaders(t *testing.T) {
+	data := "# 注释\nUser-Agent: curl/8.0\nInvalid header\n: 空名称\nX Forwarded: 1\nuser-agent: Wget\nX-Token: {{OOB}}\n"
+	issues := lintHeaders("Header.txt", []byte(data), false)
+	if got := issueLines(issues); len(got) != 5 || got[0] != 3 || got[1] != 4 || got[2] != 5 || got[3] != 6 || got[4] != 7 {
+		t.Errorf("lintHeaders() = %v", issues)
+	}
+
+	// Header组之间可以有同名Header
+	sets := "User-Agent: curl\n\nUser-Agent: Wget\nuser-agent: x\n"
+	if got := issueLines(lintHeaders("sets.txt", []byte(sets), true)); len(got) != 1 || got[0] != 4 {
+		t.Errorf("lintHeaders(sets) 行号 = %v, want [4]", got)
+	}
+}
+
+func TestLintPayloads(t *testing.T) {
+	data := strings.Join([]string{
+		"# 注释",
+		"http://127.0.0.1:{{PORT}}/",
+		"http://{{IP}}/ | root: | 文件读取",
+		"http://{{HOST}}/",
+		"http://127.0.0.1:{{PORT}}/ | redis_version",
+		"http://a/ | b | c | d",
+		"{{OOB}}/ssrf",
+	}, "\n")
+
+	issues := lintPayloads("payloads.txt", []byte(data), true)
+	if got := issueLines(issues); len(got) != 3 || got[0] != 4 || got[1] != 5 || got[2] != 6 {
+		t.Errorf("lintPayloads() = %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "{{HOST}}") || !strings.Contains(issues[1].Message, "第 2 行") {
+		t.Errorf("问题说明不正确: %v", issues)
+	}
+
+	// 未启用OOB时 {{OOB}} payload会被跳过
+	if got := issueLines(lintPayloads("payloads.txt", []byte(data), false)); len(got) != 4 || got[3] != 7 {
+		t.Errorf("lintPayloads(未启用OOB) 行号 = %v", got)
+	}
+}
+
+func TestLintConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.yaml")
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"正确的配置", "threads: 20\ntags: cloud\n", 0},
+		{"空文件", "", 0},
+		{"未知字段", "threads: 20\nthread: 10\nproxys: http://127.0.0.1:8080\n", 2},
+		{"语法错误", "threads: [20\n", 1},
+		{"类型错误", "threads: many\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.WriteFile(path, []byte(tt.content), 0644)
+			if issues := lintConfigFile(path); len(issues) != tt.want {
+				t.Errorf("lintConfigFile() = %v, want %d 个问题", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOOBServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	if err := checkOOBServer("http://"+addr, time.Second); err != nil {
+		t.Errorf("checkOOBServer(监听中) error = %v", err)
+	}
+	ln.Close()
+
+	for _, server := range []string{"http://" + addr, "not a url", "http://"} {
+		if err := checkOOBServer(server, time.Second); err == nil {
+			t.Errorf("checkOOBServer(%q) 期望返回错误", server)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "os"
+
 const logo = `
  ____            ____    ____    ____    ____    
 /\  _ \         /\  _ \ /\  _ \ /\  _ \ /\  _ \  
@@ -7,10 +9,10 @@ const logo = `
  \ \ \L_L   / __ \/_\__ \\/_\__ \\ \ ,  /\ \  _\/
   \ \ \/, \/\ \L\ \/\ \L\ \/\ \L\ \ \ \\ \\ \ \/ 
    \ \____/\ \____/\  \____\  \____\ \_\ \_\ \_\ 
-    \/___/  \/___/  \/_____/\/_____/\/_/\/ /\/_/   version: 1.1.2        
+    \/___/  \/___/  \/_____/\/_____/\/_/\/ /\/_/   version: ` + Version + `        
 `
 
+// Logo 输出Banner（标准错误，标准输出只保留扫描结果）
 func Logo() {
-
-	Colors(ColorYellow).Printf(logo)
+	Colors(ColorYellow).Fprint(os.Stderr, logo)
 }
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// 扫描预设（-profile参数）
+const (
+	ProfileFast     = "fast"     // 高并发、短超时，跳过端口扫描和WAF绕过
+	ProfileThorough = "thorough" // 全部字典和编码器，启用时间盲注和开放重定向检测，失败重试
+	ProfileStealth  = "stealth"  // 单线程、随机间隔、随机User-Agent，不发送高危协议payload
+)
+
+// Profiles 支持的扫描预设
+var Profiles = []string{ProfileFast, ProfileThorough, ProfileStealth}
+
+// profileSetting 扫描预设中的一项参数
+type profileSetting struct {
+	flag  string // 对应的命令行参数名
+	key   string // 对应的配置文件字段名
+	apply func(c *Config)
+}
+
+// profileSettings 每个扫描预设包含的参数
+var profileSettings = map[string][]profileSetting{
+	ProfileFast: {
+		{"t", "threads", func(c *Config) { c.Threads = 50 }},
+		{"timeout", "timeout", func(c *Config) { c.Timeout = 5 }},
+		{"retries", "retries", func(c *Config) { c.Retries = 0 }},
+		{"tags", "tags", func(c *Config) { c.Tags = "-ports" }},
+		{"no-waf-bypass", "no_waf_bypass", func(c *Config) { c.NoWAFBypass = true }},
+	},
+	ProfileThorough: {
+		{"t", "threads", func(c *Config) { c.Threads = 10 }},
+		{"timeout", "timeout", func(c *Config) { c.Timeout = 15 }},
+		{"retries", "retries", func(c *Config) { c.Retries = 2 }},
+		{"all", "all", func(c *Config) { c.ScanAll = true }},
+		{"encoders", "encoders", func(c *Config) { c.Encoders = "url,double-url,unicode,case" }},
+		{"timing", "timing", func(c *Config) { c.Timing = true }},
+		{"open-redirect", "open_redirect", func(c *Config) { c.OpenRedirect = true }},
+	},
+	ProfileStealth: {
+		{"t", "threads", func(c *Config) { c.Threads = 1 }},
+		{"timeout", "timeout", func(c *Config) { c.Timeout = 15 }},
+		{"retries", "retries", func(c *Config) { c.Retries = 1 }},
+		{"delaytime", "delay", func(c *Config) { c.DelayTime = 1 }},
+		{"jitter", "jitter", func(c *Config) { c.Jitter = "2000" }},
+		{"random-agent", "random_agent", func(c *Config) { c.RandomAgent = true }},
+		{"tags", "tags", func(c *Config) { c.Tags = "-ports,-protocol" }},
+		{"no-waf-bypass", "no_waf_bypass", func(c *Config) { c.NoWAFBypass = true }},
+	},
+}
+
+// applyProfile 应用 -profile 指定的扫描预设
+// 预设只覆盖默认值，命令行中显式指定的参数和配置文件中出现的字段优先
+func (c *Config) applyProfile() error {
+	name := strings.ToLower(strings.TrimSpace(c.Profile))
+	if name == "" {
+		return nil
+	}
+	settings, ok := profileSettings[name]
+	if !ok {
+		return fmt.Errorf("不支持的扫描预设: %s (支持 %s)", c.Profile, strings.Join(Profiles, "/"))
+	}
+	c.Profile = name
+
+	explicit := make(map[string]bool)
+	c.visitFlags(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for _, s := range settings {
+		if explicit[s.flag] || c.fileKeys[s.key] {
+			continue
+		}
+		s.apply(c)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		file        string // 配置文件内容，为空时不使用配置文件
+		threads     int
+		tags        string
+		jitter      string
+		retries     int
+		noWAFBypass bool
+		wantErr     bool
+	}{
+		{name: "未指定预设", profile: "", threads: 10},
+		{name: "stealth", profile: "stealth", threads: 1, tags: "-ports,-protocol", jitter: "2000", retries: 1, noWAFBypass: true},
+		{name: "大小写不敏感", profile: " Fast ", threads: 50, tags: "-ports", noWAFBypass: true},
+		{name: "配置文件中的字段优先", profile: "thorough", file: "threads: 3\ntags: cloud\n", threads: 3, tags: "cloud", retries: 2},
+		{name: "配置文件中指定预设", file: "profile: stealth\nretries: 0\n", threads: 1, tags: "-ports,-protocol", jitter: "2000", noWAFBypass: true},
+		{name: "不支持的预设", profile: "slow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Profile: tt.profile, Threads: 10}
+			if tt.file != "" {
+				cfg.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(cfg.ConfigFile, []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := cfg.loadConfigFile(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := cfg.applyProfile()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyProfile() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Threads != tt.threads || cfg.Tags != tt.tags || cfg.Jitter != tt.jitter || cfg.Retries != tt.retries || cfg.NoWAFBypass != tt.noWAFBypass {
+				t.Errorf("得到 threads=%d tags=%q jitter=%q retries=%d no-waf-bypass=%v", cfg.Threads, cfg.Tags, cfg.Jitter, cfg.Retries, cfg.NoWAFBypass)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// 代理池轮换方式（-proxy-rotate参数）
+const (
+	ProxyRoundRobin = "roundrobin"
+	ProxyRandom     = "random"
+)
+
+// loadProxyList 加载代理池文件（-proxy-file参数），每行一个代理地址，# 开头的行为注释
+// 未写协议的地址按 http:// 处理
+func loadProxyList(path string) ([]*url.URL, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取代理文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var proxies []*url.URL
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "://") {
+			line = "http://" + line
+		}
+		proxyURL, err := parseProxyURL(line)
+		if err != nil {
+			return nil, fmt.Errorf("代理文件第 %d 行: %v", lineNum, err)
+		}
+		if !seen[proxyURL.String()] {
+			seen[proxyURL.String()] = true
+			proxies = append(proxies, proxyURL)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取代理文件失败: %v", err)
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("代理文件中没有代理地址: %s", path)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxRawHeaderLine 原始请求中单行Header的最大长度（大Cookie等）
+const maxRawHeaderLine = 4 * 1024 * 1024
+
+// RawRequest 从原始HTTP请求文件（Burp格式）解析出的请求
+type RawRequest struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	Body     string
+	BodyType string // 请求体类型，只用于 -burp 导入的请求（根据Content-Type判断）
+}
+
+// parseRawRequest 解析原始HTTP请求文件
+// 文件格式与Burp "Copy to file" 一致：请求行、Header、空行、请求体
+// forceSSL 为 true 时使用 https 构造目标URL
+func parseRawRequest(filePath string, forceSSL bool) (*RawRequest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取原始请求文件失败: %v", err)
+	}
+	raw, err := parseRawRequestData(data, forceSSL)
+	if err == errEmptyRequest {
+		return nil, errEmptyRequest
+	}
+	return raw, err
+}
+
+// errEmptyRequest 原始请求中没有请求行
+var errEmptyRequest = errors.New("原始请求为空")
+
+// parseRawRequestData 解析原始HTTP请求内容（请求行、Header、空行、请求体）
+func parseRawRequestData(data []byte, forceSSL bool) (*RawRequest, error) {
+	// 统一换行符
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimLeft(content, "\n")
+
+	// 分离头部和请求体
+	head, body := content, ""
+	if idx := strings.Index(content, "\n\n"); idx != -1 {
+		head = content[:idx]
+		body = strings.TrimRight(content[idx+2:], "\n")
+	}
+
+	reader := bufio.NewScanner(strings.NewReader(head))
+	reader.Buffer(make([]byte, 0, 64*1024), maxRawHeaderLine)
+	if !reader.Scan() {
+		if err := reader.Err(); err != nil {
+			return nil, fmt.Errorf("读取请求行失败: %v", err)
+		}
+		return nil, errEmptyRequest
+	}
+
+	// 解析请求行: METHOD PATH HTTP/1.1
+	requestLine := strings.Fields(reader.Text())
+	if len(requestLine) < 2 {
+		return nil, fmt.Errorf("无效的请求行: %s", reader.Text())
+	}
+
+	raw := &RawRequest{
+		Method:  strings.ToUpper(requestLine[0]),
+		Headers: make(map[string]string),
+		Body:    body,
+	}
+	path := requestLine[1]
+
+	// 解析Header
+	host := ""
+	for reader.Scan() {
+		line := strings.TrimSpace(reader.Text())
+		colonIdx := strings.Index(line, ":")
+		if colonIdx == -1 {
+			continue // 跳过无效行
+		}
+
+		headerName := strings.TrimSpace(line[:colonIdx])
+		headerValue := strings.TrimSpace(line[colonIdx+1:])
+
+		switch strings.ToLower(headerName) {
+		case "host":
+			host = headerValue
+		case "content-length":
+			// 请求体会被重新构造，长度由HTTP客户端计算
+		default:
+			raw.Headers[headerName] = headerValue
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("读取请求头失败（单行过长或格式错误）: %v", err)
+	}
+
+	// 请求行中是完整URL时直接使用（代理格式）
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		raw.URL = path
+	} else {
+		if host == "" {
+			return nil, fmt.Errorf("原始请求缺少Host头")
+		}
+		scheme := "http"
+		if forceSSL || strings.HasSuffix(host, ":443") {
+			scheme = "https"
+		}
+		raw.URL = scheme + "://" + host + path
+	}
+
+	if _, err := url.Parse(raw.URL); err != nil {
+		return nil, fmt.Errorf("无效的请求地址 %s: %v", raw.URL, err)
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRawRequest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "req.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseRawRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		forceSSL   bool
+		wantMethod string
+		wantURL    string
+		wantBody   string
+		wantHeader map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "CRLF换行",
+			content:    "POST /api?x=1 HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/json\r\nContent-Length: 13\r\n\r\n{\"url\":\"a\"}\r\n",
+			wantMethod: "POST",
+			wantURL:    "http://example.com/api?x=1",
+			wantBody:   `{"url":"a"}`,
+			wantHeader: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:       "请求行为完整URL",
+			content:    "get http://proxy.example.com/a HTTP/1.1\nHost: other.com\n\n",
+			wantMethod: "GET",
+			wantURL:    "http://proxy.example.com/a",
+		},
+		{
+			name:       "443端口使用https",
+			content:    "GET / HTTP/1.1\nHost: example.com:443\n\n",
+			wantMethod: "GET",
+			wantURL:    "https://example.com:443/",
+		},
+		{
+			name:       "force-ssl",
+			content:    "GET /x HTTP/1.1\nHost: example.com\n\n",
+			forceSSL:   true,
+			wantMethod: "GET",
+			wantURL:    "https://example.com/x",
+		},
+		{
+			name:    "缺少Host头",
+			content: "GET /x HTTP/1.1\nAccept: */*\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "无效请求行",
+			content: "GET\n",
+			wantErr: true,
+		},
+		{
+			name:    "空文件",
+			content: "\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "Header过长",
+			content: "GET / HTTP/1.1\nHost: example.com\nCookie: " + strings.Repeat("a", maxRawHeaderLine) + "\n\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := parseRawRequest(writeRawRequest(t, tt.content), tt.forceSSL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %+v", raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if raw.Method != tt.wantMethod || raw.URL != tt.wantURL || raw.Body != tt.wantBody {
+				t.Errorf("得到 %s %s %q，期望 %s %s %q", raw.Method, raw.URL, raw.Body, tt.wantMethod, tt.wantURL, tt.wantBody)
+			}
+			for name, value := range tt.wantHeader {
+				if raw.Headers[name] != value {
+					t.Errorf("Header %s = %q，期望 %q", name, raw.Headers[name], value)
+				}
+			}
+			if _, ok := raw.Headers["Host"]; ok {
+				t.Errorf("Host头不应保留在Headers中")
+			}
+			if _, ok := raw.Headers["Content-Length"]; ok {
+				t.Errorf("Content-Length头不应保留在Headers中")
+			}
+		})
+	}
+}
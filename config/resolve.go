@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// parseResolver 解析DNS服务器地址（-resolver参数），未写端口时使用53
+func parseResolver(raw string) (string, error) {
+	addr := strings.TrimSpace(raw)
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = strings.Trim(addr, "[]"), "53"
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("无效的DNS服务器地址: %s (需要使用IP，例如: 10.0.0.53:53)", raw)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// parseResolveHosts 解析静态域名解析（-resolve参数）：逗号分隔的 host:ip，IPv6地址不需要加方括号
+func parseResolveHosts(raw string) (map[string]string, error) {
+	hosts := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, ip, ok := strings.Cut(entry, ":")
+		host = strings.ToLower(strings.TrimSpace(host))
+		ip = strings.Trim(strings.TrimSpace(ip), "[]")
+		if !ok || host == "" || net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("无效的静态解析: %s (格式: host:ip，例如: staging.example.com:10.0.0.5)", entry)
+		}
+		hosts[host] = ip
+	}
+	return hosts, nil
+}
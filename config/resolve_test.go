@@ -0,0 +1,43 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResolver(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.53:53", "10.0.0.53:53", false},
+		{"10.0.0.53", "10.0.0.53:53", false},
+		{"[fd00::53]:5353", "[fd00::53]:5353", false},
+		{"fd00::53", "[fd00::53]:53", false},
+		{"dns.example.com", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseResolver(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseResolver(%q) = %q, %v，期望 %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseResolveHosts(t *testing.T) {
+	got, err := parseResolveHosts("Staging.Example.com:10.0.0.5, api.staging:fd00::5,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"staging.example.com": "10.0.0.5", "api.staging": "fd00::5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseResolveHosts() = %v, want %v", got, want)
+	}
+
+	for _, in := range []string{"staging.example.com", "staging.example.com:not-an-ip", ":10.0.0.5"} {
+		if _, err := parseResolveHosts(in); err == nil {
+			t.Errorf("parseResolveHosts(%q) 期望返回错误", in)
+		}
+	}
+}
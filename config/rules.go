@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// intRange 闭区间 [min, max]
+type intRange struct {
+	min, max int
+}
+
+// ResponseRule 自定义响应匹配规则（-match-*/-filter-* 参数），任一条件满足即视为匹配
+type ResponseRule struct {
+	Regex *regexp.Regexp
+	codes []intRange
+	sizes []intRange
+}
+
+// newResponseRule 解析响应匹配规则，三个条件均为空时返回nil
+func newResponseRule(regex, codes, sizes string) (*ResponseRule, error) {
+	if regex == "" && codes == "" && sizes == "" {
+		return nil, nil
+	}
+
+	rule := &ResponseRule{}
+	if regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("无效的正则表达式 %s: %v", regex, err)
+		}
+		rule.Regex = re
+	}
+
+	var err error
+	if rule.codes, err = parseIntRanges(codes); err != nil {
+		return nil, fmt.Errorf("无效的状态码: %v", err)
+	}
+	if rule.sizes, err = parseIntRanges(sizes); err != nil {
+		return nil, fmt.Errorf("无效的响应长度: %v", err)
+	}
+	return rule, nil
+}
+
+// Match 判断响应是否满足规则，返回匹配到的条件描述
+func (r *ResponseRule) Match(statusCode, size int, body string) (bool, string) {
+	if r == nil {
+		return false, ""
+	}
+	for _, c := range r.codes {
+		if statusCode >= c.min && statusCode <= c.max {
+			return true, fmt.Sprintf("状态码 %d", statusCode)
+		}
+	}
+	for _, s := range r.sizes {
+		if size >= s.min && size <= s.max {
+			return true, fmt.Sprintf("响应长度 %d", size)
+		}
+	}
+	if r.Regex != nil {
+		if m := r.Regex.FindString(body); m != "" {
+			return true, fmt.Sprintf("正则 %s", truncate(m, 64))
+		}
+	}
+	return false, ""
+}
+
+// parseIntRanges 解析逗号分隔的数字或范围，例如: 200,301-302
+func parseIntRanges(s string) ([]intRange, error) {
+	var ranges []intRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		min, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%s", part)
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil || max < min {
+				return nil, fmt.Errorf("%s", part)
+			}
+		}
+		ranges = append(ranges, intRange{min, max})
+	}
+	return ranges, nil
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
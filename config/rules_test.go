@@ -0,0 +1,48 @@
+package config
+
+import "testing"
+
+func TestResponseRule(t *testing.T) {
+	rule, err := newResponseRule(`ami-id|redis_version`, "200,301-302", "0,1000-2000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		size   int
+		want   bool
+	}{
+		{"状态码", 200, "", 5, true},
+		{"状态码范围", 302, "", 5, true},
+		{"长度范围", 404, "", 1500, true},
+		{"正则", 500, "x redis_version:7 x", 5, true},
+		{"均不满足", 404, "not found", 9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, reason := rule.Match(tt.status, tt.size, tt.body); got != tt.want {
+				t.Errorf("得到 %v (%s)，期望 %v", got, reason, tt.want)
+			}
+		})
+	}
+
+	if rule, err := newResponseRule("", "", ""); rule != nil || err != nil {
+		t.Errorf("未指定条件时应返回nil")
+	}
+	if ok, _ := (*ResponseRule)(nil).Match(200, 0, ""); ok {
+		t.Errorf("nil规则不应匹配")
+	}
+
+	for _, bad := range []struct{ regex, codes, sizes string }{
+		{"(", "", ""},
+		{"", "abc", ""},
+		{"", "", "20-10"},
+	} {
+		if _, err := newResponseRule(bad.regex, bad.codes, bad.sizes); err == nil {
+			t.Errorf("%+v 期望返回错误", bad)
+		}
+	}
+}
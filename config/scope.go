@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// hostRule payload允许或禁止引用的一个主机（-payload-allow/-payload-deny参数）
+type hostRule struct {
+	network *net.IPNet // IP或网段，为nil时按域名匹配
+	name    string     // 小写的域名
+	suffix  bool       // *.example.com：匹配所有子域名
+}
+
+// match 判断主机是否匹配规则，ip 为主机解析出的IP（域名时为nil）
+func (r hostRule) match(host string, ip net.IP) bool {
+	if r.network != nil {
+		return ip != nil && r.network.Contains(ip)
+	}
+	if r.suffix {
+		return strings.HasSuffix(host, "."+r.name)
+	}
+	return host == r.name
+}
+
+// parseHostRules 解析逗号分隔的主机列表：IP、CIDR网段、域名或 *.example.com
+func parseHostRules(raw string) ([]hostRule, error) {
+	var rules []hostRule
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("无法解析的网段 %s", entry)
+			}
+			rules = append(rules, hostRule{network: network})
+		case net.ParseIP(strings.Trim(entry, "[]")) != nil:
+			ip := net.ParseIP(strings.Trim(entry, "[]"))
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			rules = append(rules, hostRule{network: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}})
+		case strings.HasPrefix(entry, "*."):
+			rules = append(rules, hostRule{name: entry[2:], suffix: true})
+		default:
+			rules = append(rules, hostRule{name: entry})
+		}
+	}
+	return rules, nil
+}
+
+// matchAny 判断主机是否匹配其中一条规则
+func matchAny(rules []hostRule, host string, ip net.IP) bool {
+	for _, r := range rules {
+		if r.match(host, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPayloadScope 是否指定了 -payload-allow 或 -payload-deny
+func (c *Config) HasPayloadScope() bool {
+	return len(c.payloadAllow) > 0 || len(c.payloadDeny) > 0
+}
+
+// PayloadInScope 判断payload引用的主机是否允许发送：不在 -payload-deny 中，指定 -payload-allow 时必须在其中
+// 不引用主机的payload（例如 file:///etc/passwd）和包含模板变量的主机始终允许；返回超出范围的主机
+func (c *Config) PayloadInScope(value string) (string, bool) {
+	if !c.HasPayloadScope() {
+		return "", true
+	}
+	for _, host := range payloadHosts(value) {
+		ip := parseLooseIP(host)
+		if matchAny(c.payloadDeny, host, ip) || (len(c.payloadAllow) > 0 && !matchAny(c.payloadAllow, host, ip)) {
+			return host, false
+		}
+	}
+	return "", true
+}
+
+// payloadHosts 提取payload引用的主机（小写）：地址中的主机，以及 http://a.com@b.com 中 @ 之前的部分（不同的URL解析器可能访问其中任意一个）
+// 不带协议的payload按 //host/path 解析，只保留IP、localhost 和带点的域名
+func payloadHosts(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "://") && !strings.HasPrefix(value, "//") {
+		value = "//" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		// 包含控制字符等无法解析的payload，取 // 之后到第一个 /、? 或 # 之前的部分
+		authority := value[strings.Index(value, "//")+2:]
+		if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+			authority = authority[:i]
+		}
+		u = &url.URL{Host: authority}
+		if i := strings.LastIndex(authority, "@"); i >= 0 {
+			u.User, u.Host = url.User(authority[:i]), authority[i+1:]
+		}
+	}
+
+	candidates := []string{u.Hostname()}
+	if u.User != nil {
+		candidates = append(candidates, u.User.Username())
+	}
+	var hosts []string
+	for _, host := range candidates {
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		if host == "" || strings.Contains(host, "{{") {
+			continue
+		}
+		if parseLooseIP(host) != nil || host == "localhost" || strings.Contains(host, ".") {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// parseLooseIP 解析IP，同时支持绕过payload常用的十进制（2130706433）、十六进制（0x7f000001）、八进制（0177.0.0.1）和省略写法（127.1）
+func parseLooseIP(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 0, 32)
+		if err != nil {
+			return nil
+		}
+		values[i] = n
+	}
+	// 最后一段填充剩余的字节，前面每段一个字节
+	last := len(values) - 1
+	if values[last] >= 1<<(8*uint(4-last)) {
+		return nil
+	}
+	addr := values[last]
+	for i := 0; i < last; i++ {
+		if values[i] > 0xff {
+			return nil
+		}
+		addr |= values[i] << (8 * uint(3-i))
+	}
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
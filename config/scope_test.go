@@ -0,0 +1,97 @@
+package config
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseLooseIP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"127.0.0.1", "127.0.0.1"},
+		{"2130706433", "127.0.0.1"},
+		{"0x7f000001", "127.0.0.1"},
+		{"0177.0.0.1", "127.0.0.1"},
+		{"127.1", "127.0.0.1"},
+		{"0xa9.254.43518", "169.254.169.254"},
+		{"::ffff:a9fe:a9fe", "169.254.169.254"},
+		{"example.com", "<nil>"},
+		{"256.0.0.1", "<nil>"},
+		{"1.2.3.4.5", "<nil>"},
+	}
+	for _, tt := range tests {
+		if got := parseLooseIP(tt.in).String(); got != tt.want {
+			t.Errorf("parseLooseIP(%q) = %s，期望 %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPayloadHosts(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"http://169.254.169.254/latest/meta-data/", []string{"169.254.169.254"}},
+		{"gopher://127.0.0.1:6379/_INFO", []string{"127.0.0.1"}},
+		{"http://evil.com@10.0.0.1:8080/", []string{"10.0.0.1", "evil.com"}},
+		{"http://[::1]:80/", []string{"::1"}},
+		{"//Internal.Example.com./admin", []string{"internal.example.com"}},
+		{"localhost:22", []string{"localhost"}},
+		{"file:///etc/passwd", nil},
+		{"http://{{oob}}/x", nil},
+		{"http://10.0.0.1/\x00a b", []string{"10.0.0.1"}},
+	}
+	for _, tt := range tests {
+		if got := payloadHosts(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("payloadHosts(%q) = %v，期望 %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPayloadInScope(t *testing.T) {
+	allow, err := parseHostRules("10.0.0.0/8, 127.0.0.1, *.internal.example.com, metadata.google.internal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deny, err := parseHostRules("10.20.0.0/16,prod.internal.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{payloadAllow: allow, payloadDeny: deny}
+
+	tests := []struct {
+		value string
+		host  string
+		ok    bool
+	}{
+		{"http://10.1.2.3/", "", true},
+		{"http://0x7f000001:6379/", "", true},
+		{"http://api.internal.example.com/", "", true},
+		{"http://metadata.google.internal/computeMetadata/v1/", "", true},
+		{"file:///etc/passwd", "", true},
+		{"http://10.20.1.1/", "10.20.1.1", false},
+		{"http://prod.internal.example.com/", "prod.internal.example.com", false},
+		{"http://169.254.169.254/", "169.254.169.254", false},
+		{"http://127.0.0.1@partner.example.com/", "partner.example.com", false},
+	}
+	for _, tt := range tests {
+		host, ok := cfg.PayloadInScope(tt.value)
+		if host != tt.host || ok != tt.ok {
+			t.Errorf("PayloadInScope(%q) = %q, %v，期望 %q, %v", tt.value, host, ok, tt.host, tt.ok)
+		}
+	}
+
+	// 未指定范围时不限制
+	if _, ok := (&Config{}).PayloadInScope("http://169.254.169.254/"); !ok {
+		t.Error("未指定 -payload-allow/-payload-deny 时应该允许所有payload")
+	}
+	if _, err := parseHostRules("10.0.0.0/33"); err == nil {
+		t.Error("无效的网段应该返回错误")
+	}
+	if rules, _ := parseHostRules("::1"); !rules[0].match("::1", net.ParseIP("::1")) {
+		t.Error("IPv6地址应该匹配")
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SecretRule 敏感信息规则：响应中出现匹配内容时报告通过SSRF泄露的敏感信息
+type SecretRule struct {
+	Name     string
+	Severity string // 严重程度：critical/high
+	Pattern  *regexp.Regexp
+}
+
+// defaultSecretRules 内置敏感信息规则
+var defaultSecretRules = []SecretRule{
+	{"AWS访问密钥ID", "critical", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"AWS访问密钥", "critical", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`)},
+	{"GCP服务账号密钥", "critical", regexp.MustCompile(`"type"\s*:\s*"service_account"`)},
+	{"私钥", "critical", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY( BLOCK)?-----`)},
+	{"JWT", "high", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"环境变量中的密码", "high", regexp.MustCompile(`\b[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_KEY)[A-Z0-9_]*=[^\x00\s"'&<]{4,}`)},
+	{"Slack令牌", "high", regexp.MustCompile(`\bxox[baprs]-[0-9A-Za-z-]{10,}`)},
+	{"GitHub令牌", "high", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`)},
+}
+
+// loadSecretRules 加载自定义敏感信息规则文件（-secret-rules参数）
+// 每行一条规则：名称 正则（以第一个空白分隔），# 开头的行为注释；自定义规则的严重程度为 high
+func loadSecretRules(path string) ([]SecretRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取敏感信息规则文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var rules []SecretRule
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("敏感信息规则第 %d 行格式错误，应为: 名称 正则", lineNum)
+		}
+		pattern, err := regexp.Compile(strings.TrimSpace(line[len(fields[0]):]))
+		if err != nil {
+			return nil, fmt.Errorf("敏感信息规则第 %d 行正则无效: %v", lineNum, err)
+		}
+		rules = append(rules, SecretRule{Name: fields[0], Severity: "high", Pattern: pattern})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取敏感信息规则文件失败: %v", err)
+	}
+	return rules, nil
+}
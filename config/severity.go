@@ -0,0 +1,85 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// severityLevels 支持的严重程度（与 detector.Severities 一致）
+var severityLevels = []string{"critical", "high", "medium", "low", "info"}
+
+// SeverityMap 自定义严重程度映射（-severity-map参数），按证据类别和payload类型覆盖默认的严重程度
+//
+//	evidence:
+//	  auth-status: low
+//	types:
+//	  端口扫描: low
+type SeverityMap struct {
+	Evidence map[string]string `yaml:"evidence"` // 证据类别（keyword、credential、oob 等）对应的严重程度
+	Types    map[string]string `yaml:"types"`    // payload类型（云元数据、端口扫描等）对应的严重程度
+}
+
+// Severity 返回漏洞的严重程度：证据类别的映射优先，其次是payload类型的映射，都没有配置时返回 fallback
+func (m *SeverityMap) Severity(payloadType, kind, fallback string) string {
+	if m == nil {
+		return fallback
+	}
+	if severity, ok := m.Evidence[kind]; ok {
+		return severity
+	}
+	if severity, ok := m.Types[payloadType]; ok {
+		return severity
+	}
+	return fallback
+}
+
+// CheckEvidence 检查映射中的证据类别是否都在 kinds 中，避免拼写错误的类别不生效
+func (m *SeverityMap) CheckEvidence(kinds []string) error {
+	if m == nil {
+		return nil
+	}
+	for kind := range m.Evidence {
+		if !containsString(kinds, kind) {
+			return fmt.Errorf("严重程度映射中未知的证据类别 %s (支持 %s)", kind, strings.Join(kinds, "/"))
+		}
+	}
+	return nil
+}
+
+// loadSeverityMap 加载严重程度映射文件，严重程度统一为小写并检查是否支持
+func loadSeverityMap(path string) (*SeverityMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取严重程度映射文件失败: %v", err)
+	}
+	var m SeverityMap
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析严重程度映射文件失败: %v", err)
+	}
+	for _, mapping := range []map[string]string{m.Evidence, m.Types} {
+		for key, severity := range mapping {
+			severity = strings.ToLower(strings.TrimSpace(severity))
+			if !containsString(severityLevels, severity) {
+				return nil, fmt.Errorf("严重程度映射 %s 的严重程度 %q 不支持 (支持 %s)", key, mapping[key], strings.Join(severityLevels, "/"))
+			}
+			mapping[key] = severity
+		}
+	}
+	return &m, nil
+}
+
+// containsString 判断列表中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSeverityMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "severity.yaml")
+	os.WriteFile(path, []byte("evidence:\n  auth-status: Low\n  oob: critical\ntypes:\n  端口扫描: low\n  云元数据: critical\n"), 0644)
+
+	m, err := loadSeverityMap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		payloadType, kind, fallback string
+		want                        string
+	}{
+		{"端口扫描", "auth-status", "info", "low"},
+		{"端口扫描", "http-service", "medium", "low"},
+		{"云元数据", "oob", "high", "critical"},
+		{"文件读取", "keyword", "critical", "critical"},
+	}
+	for _, tt := range tests {
+		if got := m.Severity(tt.payloadType, tt.kind, tt.fallback); got != tt.want {
+			t.Errorf("Severity(%s, %s) = %s, want %s", tt.payloadType, tt.kind, got, tt.want)
+		}
+	}
+	if err := m.CheckEvidence([]string{"auth-status", "oob"}); err != nil {
+		t.Errorf("CheckEvidence: %v", err)
+	}
+	if err := m.CheckEvidence([]string{"oob"}); err == nil {
+		t.Error("未知的证据类别期望返回错误")
+	}
+
+	var unset *SeverityMap
+	if got := unset.Severity("端口扫描", "oob", "high"); got != "high" {
+		t.Errorf("未指定映射时应返回默认值, got %s", got)
+	}
+
+	for _, bad := range []string{"types:\n  端口扫描: severe\n", "severity:\n  oob: high\n", "types: [\n"} {
+		path := filepath.Join(dir, "bad.yaml")
+		os.WriteFile(path, []byte(bad), 0644)
+		if _, err := loadSeverityMap(path); err == nil {
+			t.Errorf("%q 期望返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits 大小单位（1024进制）
+var sizeUnits = map[string]int64{"": 1, "b": 1, "k": 1 << 10, "kb": 1 << 10, "m": 1 << 20, "mb": 1 << 20, "g": 1 << 30, "gb": 1 << 30}
+
+// parseByteSize 解析带单位的大小（-max-body参数），例如 512KB、1MB，不写单位时为字节
+func parseByteSize(raw string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	digits := strings.TrimRight(s, "bkmg")
+	unit, ok := sizeUnits[strings.TrimSpace(s[len(digits):])]
+	n, err := strconv.ParseInt(strings.TrimSpace(digits), 10, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的响应大小上限: %s (例如: 512KB、1MB，0 表示不限制)", raw)
+	}
+	return n * unit, nil
+}
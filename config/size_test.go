@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1MB", 1 << 20, false},
+		{"512kb", 512 << 10, false},
+		{"2 G", 2 << 30, false},
+		{"4096", 4096, false},
+		{"0", 0, false},
+		{"1TB", 0, true},
+		{"-1MB", 0, true},
+		{"MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, %v，期望 %d", tt.in, got, err, tt.want)
+		}
+	}
+}
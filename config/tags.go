@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 扫描模块标签（-tags参数）
+const (
+	TagPorts    = "ports"    // 端口扫描
+	TagFiles    = "files"    // 文件读取
+	TagProtocol = "protocol" // dict/gopher等高危协议探测
+	TagCloud    = "cloud"    // 云元数据
+	TagK8s      = "k8s"      // Kubernetes集群内部接口
+	TagDocker   = "docker"   // Docker API
+	TagInfra    = "infra"    // Consul、etcd、Vault 内部API
+	TagBypass   = "bypass"   // 内置字典中的绕过技术和编码变种（等同 -all）
+	TagOOB      = "oob"      // OOB回连（需要 -oob 或 -serve-oob）
+)
+
+// AllTags 支持的扫描模块标签
+var AllTags = []string{TagPorts, TagFiles, TagProtocol, TagCloud, TagK8s, TagDocker, TagInfra, TagBypass, TagOOB}
+
+// defaultTags 未指定 -tags 时启用的模块
+var defaultTags = []string{TagPorts, TagFiles, TagProtocol, TagCloud, TagK8s, TagDocker, TagInfra, TagOOB}
+
+// parseTags 解析逗号分隔的模块标签
+// 只写 -ports 等排除项时在默认模块的基础上排除；写了启用项时只启用这些模块。指定 -all 时总是启用 bypass
+func parseTags(s string, scanAll bool) (map[string]bool, error) {
+	var include, exclude []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		excluded := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		supported := false
+		for _, tag := range AllTags {
+			if name == tag {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("不支持的扫描模块: %s (支持 %s)", name, strings.Join(AllTags, "/"))
+		}
+
+		if excluded {
+			exclude = append(exclude, name)
+		} else {
+			include = append(include, name)
+		}
+	}
+
+	if len(include) == 0 {
+		include = defaultTags
+	}
+	tags := make(map[string]bool)
+	for _, tag := range include {
+		tags[tag] = true
+	}
+	if scanAll {
+		tags[TagBypass] = true
+	}
+	for _, tag := range exclude {
+		delete(tags, tag)
+	}
+	return tags, nil
+}
+
+// HasTag 判断是否启用指定扫描模块
+func (c *Config) HasTag(tag string) bool {
+	return c.TagSet[tag]
+}
+
+// Excluded 判断payload是否被 -exclude-payload 或 -exclude-type 排除（类型不区分大小写）
+func (c *Config) Excluded(value, payloadType string) bool {
+	if c.ExcludePattern != nil && c.ExcludePattern.MatchString(value) {
+		return true
+	}
+	for _, t := range c.ExcludeTypes {
+		if strings.EqualFold(t, payloadType) {
+			return true
+		}
+	}
+	return false
+}
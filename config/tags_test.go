@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		scanAll bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "默认模块", input: "", want: []string{"cloud", "docker", "files", "infra", "k8s", "oob", "ports", "protocol"}},
+		{name: "-all 启用 bypass", input: "", scanAll: true, want: []string{"bypass", "cloud", "docker", "files", "infra", "k8s", "oob", "ports", "protocol"}},
+		{name: "只启用指定模块", input: "cloud, K8S", want: []string{"cloud", "k8s"}},
+		{name: "排除默认模块", input: "-ports,-files", want: []string{"cloud", "docker", "infra", "k8s", "oob", "protocol"}},
+		{name: "启用和排除同时指定", input: "cloud,bypass,-bypass", want: []string{"cloud"}},
+		{name: "-all 与启用项", input: "files", scanAll: true, want: []string{"bypass", "files"}},
+		{name: "不支持的模块", input: "ports,web", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := parseTags(tt.input, tt.scanAll)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTags(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for tag := range tags {
+				got = append(got, tag)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTags(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	cfg := &Config{
+		ExcludePattern: regexp.MustCompile(`shadow|^gopher://`),
+		ExcludeTypes:   []string{"文件读取", "docker api"},
+	}
+
+	tests := []struct {
+		value       string
+		payloadType string
+		want        bool
+	}{
+		{"file:///etc/shadow", "自定义字典", true},
+		{"gopher://127.0.0.1:6379/_INFO", "协议探测", true},
+		{"dict://127.0.0.1:6379/info", "协议探测", false},
+		{"file:///etc/passwd", "文件读取", true},
+		{"http://127.0.0.1:2375/version", "Docker API", true},
+		{"http://169.254.169.254/latest/meta-data/", "云元数据", false},
+	}
+	for _, tt := range tests {
+		if got := cfg.Excluded(tt.value, tt.payloadType); got != tt.want {
+			t.Errorf("Excluded(%q, %q) = %v, want %v", tt.value, tt.payloadType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsVersions -tls-min 参数支持的TLS版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// buildTLSConfig 根据 -cert/-key/-ca/-sni/-tls-min 参数创建TLS配置
+// 未指定 -ca 时不验证证书；-cert 文件同时包含证书和私钥时可省略 -key
+func (c *Config) buildTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, // 未指定CA时忽略证书验证
+		ServerName:         c.TLSServerName,
+	}
+
+	if c.TLSMinVersion != "" {
+		version, ok := tlsVersions[c.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("无效的TLS版本: %s (支持 1.0/1.1/1.2/1.3)", c.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if c.TLSCert != "" {
+		keyFile := c.TLSKey
+		if keyFile == "" {
+			keyFile = c.TLSCert
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if c.TLSKey != "" {
+		return nil, fmt.Errorf("-key 需要与 -cert 同时指定")
+	}
+
+	if c.TLSCA != "" {
+		data, err := os.ReadFile(c.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA证书文件中没有有效的PEM证书: %s", c.TLSCA)
+		}
+		tlsConfig.RootCAs = pool
+		tlsConfig.InsecureSkipVerify = false
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert 生成自签名证书，返回证书和私钥文件路径
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gossrf-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	certData, _ := os.ReadFile(certFile)
+	keyData, _ := os.ReadFile(keyFile)
+	bundle := filepath.Join(dir, "bundle.pem")
+	os.WriteFile(bundle, append(certData, keyData...), 0600)
+
+	cfg := &Config{TLSCert: certFile, TLSKey: keyFile, TLSCA: certFile, TLSServerName: "internal.example", TLSMinVersion: "1.2"}
+	got, err := cfg.buildTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Certificates) != 1 || got.RootCAs == nil || got.InsecureSkipVerify || got.ServerName != "internal.example" || got.MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLS配置错误: %+v", got)
+	}
+
+	// 证书文件同时包含私钥时可省略 -key
+	if got, err := (&Config{TLSCert: bundle}).buildTLSConfig(); err != nil || len(got.Certificates) != 1 {
+		t.Errorf("证书文件包含私钥时应能加载: %v", err)
+	}
+	if got, err := (&Config{}).buildTLSConfig(); err != nil || !got.InsecureSkipVerify {
+		t.Errorf("未指定CA时应忽略证书验证")
+	}
+
+	for name, bad := range map[string]*Config{
+		"TLS版本":    {TLSMinVersion: "1.4"},
+		"只有私钥":     {TLSKey: keyFile},
+		"私钥不匹配":    {TLSCert: certFile, TLSKey: certFile},
+		"CA文件不存在":  {TLSCA: filepath.Join(dir, "missing.pem")},
+		"CA文件没有证书": {TLSCA: keyFile},
+	} {
+		if _, err := bad.buildTLSConfig(); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
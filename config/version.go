@@ -0,0 +1,35 @@
+package config
+
+import "runtime/debug"
+
+// Version 程序版本
+const Version = "1.1.2"
+
+// BuildInfo go build 记录的编译信息，不在版本控制目录中编译或使用 go run 时提交信息为空
+type BuildInfo struct {
+	GoVersion  string
+	Commit     string
+	CommitTime string
+	Modified   bool // 编译时工作区有未提交的修改
+}
+
+// ReadBuildInfo 读取程序的编译信息
+func ReadBuildInfo() BuildInfo {
+	var info BuildInfo
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = build.GoVersion
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
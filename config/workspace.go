@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// workspaceTimeFormat 工作区目录名使用的时间格式
+const workspaceTimeFormat = "20060102-150405"
+
+// 工作区中的文件
+const (
+	WorkspaceConfig   = "config.yaml" // 配置快照
+	WorkspaceState    = "state.json"  // 扫描进度（-resume）
+	WorkspaceEvidence = "evidence"    // 漏洞证据目录（-evidence-dir）
+	WorkspaceLog      = "scan.log"    // 运行日志（-log-file）
+	WorkspaceReport   = "report.json" // 报告（-o），其他格式的报告同时保存一份JSON报告
+)
+
+// redactedValue 配置快照中替换密码、令牌和通知地址的值
+const redactedValue = "******"
+
+// resolveWorkspace 确定 -workspace 本次扫描使用的目录：已有的工作区（包含配置快照）直接使用，用于继续中断的扫描，
+// 否则为其下以扫描开始时间命名的新目录；未指定的进度状态、证据、日志和报告使用工作区中的默认文件，
+// 相对路径的 -o、-evidence-dir、-log-file、-resume、-db、-oob-map 也放在工作区中
+func (c *Config) resolveWorkspace() {
+	c.WorkspaceDir = c.Workspace
+	if _, err := os.Stat(filepath.Join(c.Workspace, WorkspaceConfig)); err != nil {
+		c.WorkspaceDir = filepath.Join(c.Workspace, time.Now().Format(workspaceTimeFormat))
+	}
+
+	inside := func(path *string, name string) {
+		if *path == "" {
+			*path = name
+		}
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(c.WorkspaceDir, *path)
+		}
+	}
+	inside(&c.OutputFile, WorkspaceReport)
+	inside(&c.EvidenceDir, WorkspaceEvidence)
+	inside(&c.LogFile, WorkspaceLog)
+	inside(&c.DBFile, "")
+	inside(&c.OOBMapFile, "")
+	// 分布式扫描、持续监控、复测、重放和利用不支持断点续扫
+	if c.Controller == "" && c.Watch == 0 && c.VerifyFile == "" && !c.Replay && c.Exploit == "" {
+		inside(&c.ResumeFile, WorkspaceState)
+	} else {
+		inside(&c.ResumeFile, "")
+	}
+}
+
+// WorkspacePath 返回工作区中的文件路径，未指定 -workspace 时返回空
+func (c *Config) WorkspacePath(name string) string {
+	if c.WorkspaceDir == "" {
+		return ""
+	}
+	return filepath.Join(c.WorkspaceDir, name)
+}
+
+// CreateWorkspace 创建工作区目录并保存配置快照，未指定 -workspace 时不执行任何操作
+// 快照中的密码、令牌、认证信息、通知地址和认证相关的Header替换为 ******
+func (c *Config) CreateWorkspace() error {
+	if c.WorkspaceDir == "" {
+		return nil
+	}
+	for _, path := range []string{c.OutputFile, c.LogFile, c.ResumeFile, c.DBFile, c.OOBMapFile} {
+		if path == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("创建工作区目录失败: %v", err)
+		}
+	}
+	if err := os.MkdirAll(c.WorkspaceDir, 0755); err != nil {
+		return fmt.Errorf("创建工作区目录失败: %v", err)
+	}
+
+	snapshot := *c
+	for _, value := range []*string{&snapshot.Auth, &snapshot.SMTPPassword, &snapshot.TelegramToken, &snapshot.ClusterToken, &snapshot.Webhook, &snapshot.Slack, &snapshot.Discord} {
+		if *value != "" {
+			*value = redactedValue
+		}
+	}
+	snapshot.FileHeaders = make(map[string]string, len(c.FileHeaders))
+	for name, value := range c.FileHeaders {
+		if sensitiveHeader(name) {
+			value = redactedValue
+		}
+		snapshot.FileHeaders[name] = value
+	}
+	data, err := yaml.Marshal(&snapshot)
+	if err != nil {
+		return fmt.Errorf("生成配置快照失败: %v", err)
+	}
+	header := fmt.Sprintf("# GoSSRF %s 配置快照 (%s)\n", Version, time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(c.WorkspacePath(WorkspaceConfig), append([]byte(header), data...), 0600); err != nil {
+		return fmt.Errorf("保存配置快照失败: %v", err)
+	}
+	return nil
+}
+
+// sensitiveHeader 判断Header是否包含认证信息（Authorization、Cookie、令牌和密钥）
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"auth", "cookie", "token", "key", "secret"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
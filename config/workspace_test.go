@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	abs := filepath.Join(dir, "outside.log")
+	cfg := &Config{Workspace: dir, OutputFile: "reports/scan.html", LogFile: abs, DBFile: "results.sqlite"}
+	cfg.resolveWorkspace()
+
+	if filepath.Dir(cfg.WorkspaceDir) != dir || cfg.WorkspaceDir == dir {
+		t.Fatalf("WorkspaceDir = %s, 应为 %s 下以时间命名的目录", cfg.WorkspaceDir, dir)
+	}
+	tests := []struct {
+		name, got, want string
+	}{
+		{"相对路径的 -o", cfg.OutputFile, filepath.Join(cfg.WorkspaceDir, "reports", "scan.html")},
+		{"绝对路径的 -log-file", cfg.LogFile, abs},
+		{"默认证据目录", cfg.EvidenceDir, filepath.Join(cfg.WorkspaceDir, WorkspaceEvidence)},
+		{"默认进度文件", cfg.ResumeFile, filepath.Join(cfg.WorkspaceDir, WorkspaceState)},
+		{"相对路径的 -db", cfg.DBFile, filepath.Join(cfg.WorkspaceDir, "results.sqlite")},
+		{"未指定 -oob-map", cfg.OOBMapFile, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// 分布式扫描不支持断点续扫
+	controller := &Config{Workspace: dir, Controller: ":9300"}
+	controller.resolveWorkspace()
+	if controller.ResumeFile != "" || controller.OutputFile != filepath.Join(controller.WorkspaceDir, WorkspaceReport) {
+		t.Errorf("-controller 的工作区文件不正确: resume=%q o=%q", controller.ResumeFile, controller.OutputFile)
+	}
+
+	// 已有的工作区直接使用，继续中断的扫描
+	os.WriteFile(filepath.Join(dir, WorkspaceConfig), nil, 0600)
+	existing := &Config{Workspace: dir}
+	existing.resolveWorkspace()
+	if existing.WorkspaceDir != dir || existing.ResumeFile != filepath.Join(dir, WorkspaceState) {
+		t.Errorf("已有的工作区: WorkspaceDir=%s resume=%s", existing.WorkspaceDir, existing.ResumeFile)
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	cfg := &Config{
+		Workspace:    t.TempDir(),
+		SMTPPassword: "p@ss",
+		Slack:        "https://hooks.slack.com/services/T000/B000/XXXX",
+		FileHeaders:  map[string]string{"Authorization": "Bearer abc", "X-Scan": "gossrf"},
+		Threads:      20,
+	}
+	cfg.resolveWorkspace()
+	if err := cfg.CreateWorkspace(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(cfg.WorkspacePath(WorkspaceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := string(data)
+	for _, secret := range []string{"p@ss", "hooks.slack.com", "Bearer abc"} {
+		if strings.Contains(snapshot, secret) {
+			t.Errorf("配置快照中包含 %q", secret)
+		}
+	}
+	if !strings.Contains(snapshot, "threads: 20") || !strings.Contains(snapshot, "X-Scan: gossrf") {
+		t.Errorf("配置快照缺少扫描参数:\n%s", snapshot)
+	}
+	if cfg.FileHeaders["Authorization"] != "Bearer abc" {
+		t.Error("生成快照不应修改原配置")
+	}
+}
@@ -0,0 +1,25 @@
+package detector
+
+import (
+	"io"
+	"net/http"
+)
+
+// readBody 读取响应体，最多读取 limit 字节（-max-body参数，0 表示不限制），超出部分不再读取
+// 返回的 truncated 表示响应体超过上限，只读取了前 limit 字节
+func readBody(r io.Reader, limit int64) (body []byte, truncated bool, err error) {
+	if limit <= 0 {
+		body, err = io.ReadAll(r)
+		return body, false, err
+	}
+	body, err = io.ReadAll(io.LimitReader(r, limit+1))
+	if int64(len(body)) > limit {
+		return body[:limit], true, err
+	}
+	return body, false, err
+}
+
+// readResponse 按 -max-body 读取响应体
+func (d *Detector) readResponse(resp *http.Response) ([]byte, bool, error) {
+	return readBody(resp.Body, d.config.MaxBodySize)
+}
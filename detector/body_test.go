@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestReadBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int64
+		want      string
+		truncated bool
+	}{
+		{"不限制", 0, "0123456789", false},
+		{"未超过上限", 10, "0123456789", false},
+		{"超过上限", 4, "0123", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, truncated, err := readBody(strings.NewReader("0123456789"), tt.limit)
+			if err != nil || string(body) != tt.want || truncated != tt.truncated {
+				t.Errorf("readBody() = %q, %v, %v，期望 %q, %v", body, truncated, err, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestDetectMaxBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passwd := "root:x:0:0:root:/root:/bin/bash\n"
+		padding := strings.Repeat("A", 1<<20)
+		if r.URL.Query().Get("tail") != "" {
+			passwd, padding = padding, passwd
+		}
+		w.Write([]byte(passwd + padding))
+	}))
+	defer server.Close()
+
+	payload := payloads.Payload{Value: "file:///etc/passwd", Keywords: []string{"root:"}}
+	cfg := &config.Config{Timeout: 5, MaxBody: "1KB", MaxBodySize: 1 << 10}
+	got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodGet, server.URL, "", payload, nil)
+	if !got.Vulnerable || got.ResponseLen != 1<<10 {
+		t.Errorf("前 1KB 中的关键字应该命中，响应长度为上限: vulnerable=%v len=%d", got.Vulnerable, got.ResponseLen)
+	}
+	if got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodGet, server.URL+"/?tail=1", "", payload, nil); got.Vulnerable {
+		t.Errorf("超过上限部分的内容不应该检测: %s", got.Evidence)
+	}
+
+	// 0 表示不限制
+	cfg.MaxBody, cfg.MaxBodySize = "0", 0
+	if got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodGet, server.URL+"/?tail=1", "", payload, nil); !got.Vulnerable {
+		t.Error("不限制大小时应该检测完整的响应")
+	}
+}
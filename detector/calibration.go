@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minContentLength 默认的判定响应包含内网资源内容的最小长度
+const minContentLength = 200
+
+// SetReachable 记录注入点访问可访问的外部地址（-calibrate-url参数）的响应，作为目标成功获取地址时的响应特征
+func (b *Baseline) SetReachable(page *Baseline) {
+	if b != nil && page != nil {
+		b.reachable = page
+	}
+}
+
+// Blind 判断目标成功访问外部地址时的响应是否与请求失败时相同，相同时说明注入点不回显获取的内容
+func (b *Baseline) Blind() bool {
+	if b == nil || b.reachable == nil {
+		return false
+	}
+	_, generic := b.Generic(b.reachable.StatusCode, b.reachable.body)
+	return generic || !b.Differs(b.reachable.StatusCode, b.reachable.body)
+}
+
+// Calibration 校准阶段学习到的每种情况的状态码、长度和回显的错误类型，用于输出
+func (b *Baseline) Calibration() string {
+	if b == nil {
+		return ""
+	}
+	cases := []struct {
+		name string
+		page *Baseline
+	}{{"不存在的主机", b}, {"关闭端口", b.closedPort}, {"外部地址", b.reachable}}
+	var parts []string
+	for _, c := range cases {
+		if c.page == nil {
+			continue
+		}
+		part := fmt.Sprintf("%s %d/%d字节", c.name, c.page.StatusCode, c.page.Length)
+		if class := c.page.errorClass(); class != "" {
+			part += "/" + class
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "，")
+}
+
+// failurePages 目标请求失败时的响应：基线（不存在的主机）和通用错误页面（无效地址、关闭端口）
+func (b *Baseline) failurePages() []*Baseline {
+	if b == nil {
+		return nil
+	}
+	return append([]*Baseline{b}, b.errorPages...)
+}
+
+// errorClass 页面中回显的错误类型，没有时为空
+func (b *Baseline) errorClass() string {
+	class, _ := portErrorClassOf(b.body, nil)
+	return class.name
+}
+
+// fetched 判断状态码是否表示目标成功获取了payload地址：校准时为访问外部地址的状态码，未校准时为200
+func (b *Baseline) fetched(statusCode int) bool {
+	if b != nil && b.reachable != nil {
+		return statusCode == b.reachable.StatusCode
+	}
+	return statusCode == 200
+}
+
+// failureStatus 判断状态码是否与某个请求失败时的响应相同
+func (b *Baseline) failureStatus(statusCode int) bool {
+	for _, page := range b.failurePages() {
+		if page.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// contentLength 判定响应包含内网资源内容的最小长度
+// 校准时目标成功访问外部地址的响应比所有失败响应都长（获取的内容嵌入在错误页面模板中），需要超过最长的失败响应
+func (b *Baseline) contentLength() int {
+	if b == nil || b.reachable == nil {
+		return minContentLength
+	}
+	longest := 0
+	for _, page := range b.failurePages() {
+		longest = max(longest, page.Length)
+	}
+	if longest > minContentLength && b.reachable.Length > longest {
+		return longest
+	}
+	return minContentLength
+}
+
+// failureError 判断响应是否回显了与请求失败时相同类型的错误（例如访问关闭端口时的 connection refused），返回错误类型
+// 错误信息中通常包含payload的地址，其中的 127.0.0.1、internal 等关键字不能作为证据
+func (b *Baseline) failureError(body string) (string, bool) {
+	class, ok := portErrorClassOf(body, nil)
+	if !ok {
+		return "", false
+	}
+	for _, page := range b.failurePages() {
+		if page.errorClass() == class.name {
+			return class.name, true
+		}
+	}
+	return "", false
+}
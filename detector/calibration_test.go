@@ -0,0 +1,89 @@
+package detector
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestCalibratedAnalyze(t *testing.T) {
+	// 目标请求失败时返回502错误页面，成功时返回201并把获取的内容嵌入到同一模板中
+	template := func(content string) string {
+		return "<html><body><h1>Fetch</h1>" + strings.Repeat("<p>help text</p>", 20) + "<pre>" + content + "</pre></body></html>"
+	}
+	baseline := pageBaseline(502, template("getaddrinfo failed: name or service not known"))
+	baseline.SetClosedPort(pageBaseline(502, template("connect to 127.0.0.1:1 failed: connection refused")))
+	baseline.SetReachable(pageBaseline(201, template(strings.Repeat("<div>example domain</div>", 10))))
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		payload  payloads.Payload
+		baseline *Baseline
+		want     bool
+	}{
+		{"学习到的成功状态码", 201, template("<html><title>Jenkins</title>") + "Server: jetty", payloads.Payload{Type: "端口扫描"}, baseline, true},
+		{"未校准时只按200判断", 201, template("<html><title>Jenkins</title>") + "Server: jetty", payloads.Payload{Type: "端口扫描"}, pageBaseline(502, template("x")), false},
+		{"短于失败响应的内容不判定", 201, "internal " + strings.Repeat("a", 210), payloads.Payload{}, baseline, false},
+		{"未校准时按默认长度判定", 201, "internal " + strings.Repeat("a", 210), payloads.Payload{}, pageBaseline(201, "x"), true},
+		{"超过最长的失败响应", 201, template("db password=" + strings.Repeat("x", 40)), payloads.Payload{}, baseline, true},
+		{"失败时的状态码不作为认证证据", 401, "unauthorized", payloads.Payload{}, func() *Baseline {
+			b := pageBaseline(500, "error")
+			b.AddErrorPage(pageBaseline(401, "denied"))
+			return b
+		}(), false},
+	}
+	d := &Detector{config: &config.Config{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if got := d.analyzeResponse(resp, tt.body, tt.payload, tt.baseline); got.Vulnerable != tt.want {
+				t.Errorf("得到 %v (%s)，期望 %v", got.Vulnerable, got.Evidence, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureError(t *testing.T) {
+	baseline := pageBaseline(500, "curl: (6) Could not resolve host: gossrf-baseline.invalid")
+	baseline.SetClosedPort(pageBaseline(500, "curl: (7) Failed to connect to 127.0.0.1 port 1: Connection refused"))
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"curl: (7) Failed to connect to 127.0.0.1 port 6379: Connection refused", "连接被拒绝"},
+		{"curl: (6) Could not resolve host: internal.example", "域名解析失败"},
+		{"curl: (52) Empty reply from server", ""},
+		{"redis_version:7.0", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := baseline.failureError(tt.body); got != tt.want {
+			t.Errorf("failureError(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	baseline := pageBaseline(500, "Could not resolve host")
+	baseline.SetClosedPort(pageBaseline(502, "Connection refused"))
+	if got, want := baseline.Calibration(), "不存在的主机 500/22字节/域名解析失败，关闭端口 502/18字节/连接被拒绝"; got != want {
+		t.Errorf("Calibration() = %q, want %q", got, want)
+	}
+	if baseline.Blind() {
+		t.Error("未校准时不应判定为不回显")
+	}
+
+	baseline.SetReachable(pageBaseline(500, "Could not resolve host"))
+	if !baseline.Blind() {
+		t.Error("访问外部地址与请求失败时的响应相同，应判定为不回显")
+	}
+	baseline.SetReachable(pageBaseline(200, "<html>Example Domain</html>"))
+	if baseline.Blind() {
+		t.Error("访问外部地址返回了不同的内容")
+	}
+}
@@ -0,0 +1,102 @@
+package detector
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Credential 从云元数据响应中提取的临时凭据
+type Credential struct {
+	Provider    string    // 云厂商：AWS/阿里云/腾讯云/GCP/Azure
+	AccessKeyID string    // 访问密钥ID，OAuth令牌为空
+	SecretKey   string    // 访问密钥，OAuth令牌为空
+	Token       string    // 会话令牌或OAuth access_token
+	Expiration  time.Time // 过期时间，响应中没有时为零值
+}
+
+// Expired 判断凭据在 now 时是否已过期（过期时间未知时返回 false）
+func (c Credential) Expired(now time.Time) bool {
+	return !c.Expiration.IsZero() && !now.Before(c.Expiration)
+}
+
+// ExtractCredentials 从元数据响应中提取临时凭据：
+// AWS/阿里云的 AccessKeyId、SecretAccessKey/AccessKeySecret、Token/SecurityToken，腾讯云的 TmpSecretId/TmpSecretKey，
+// GCP/Azure 的 access_token；now 用于由 expires_in 计算过期时间
+func ExtractCredentials(body string, now time.Time) []Credential {
+	fields := credentialFields(body)
+	if fields == nil {
+		return nil
+	}
+
+	var cred Credential
+	switch {
+	case fields["AccessKeyId"] != "" && fields["SecretAccessKey"] != "":
+		cred = Credential{Provider: "AWS", AccessKeyID: fields["AccessKeyId"], SecretKey: fields["SecretAccessKey"], Token: fields["Token"]}
+		cred.Expiration = parseTime(fields["Expiration"])
+	case fields["AccessKeyId"] != "" && fields["AccessKeySecret"] != "":
+		cred = Credential{Provider: "阿里云", AccessKeyID: fields["AccessKeyId"], SecretKey: fields["AccessKeySecret"], Token: fields["SecurityToken"]}
+		cred.Expiration = parseTime(fields["Expiration"])
+	case fields["TmpSecretId"] != "" && fields["TmpSecretKey"] != "":
+		cred = Credential{Provider: "腾讯云", AccessKeyID: fields["TmpSecretId"], SecretKey: fields["TmpSecretKey"], Token: fields["Token"]}
+		cred.Expiration = parseTime(fields["Expiration"])
+		if cred.Expiration.IsZero() {
+			cred.Expiration = parseUnix(fields["ExpiredTime"])
+		}
+	case fields["access_token"] != "":
+		// Azure 托管标识返回 expires_on（Unix时间戳），GCP 返回 expires_in（剩余秒数）
+		cred = Credential{Provider: "GCP", Token: fields["access_token"]}
+		if fields["expires_on"] != "" || fields["resource"] != "" {
+			cred.Provider = "Azure"
+			cred.Expiration = parseUnix(fields["expires_on"])
+		} else if seconds, err := strconv.Atoi(fields["expires_in"]); err == nil {
+			cred.Expiration = now.Add(time.Duration(seconds) * time.Second).Truncate(time.Second)
+		}
+	default:
+		return nil
+	}
+	return []Credential{cred}
+}
+
+// credentialFields 解析响应中的JSON对象，返回字符串和数字字段；gopher返回原始HTTP响应时跳过响应头
+func credentialFields(body string) map[string]string {
+	start := strings.Index(body, "{")
+	end := strings.LastIndex(body, "}")
+	if start == -1 || end < start {
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(body[start:end+1]), &raw); err != nil {
+		return nil
+	}
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			fields[key] = v
+		case float64:
+			fields[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return fields
+}
+
+// parseTime 解析RFC3339格式的过期时间，失败时返回零值
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseUnix 解析Unix时间戳格式的过期时间，失败时返回零值
+func parseUnix(s string) time.Time {
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
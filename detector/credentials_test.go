@@ -0,0 +1,59 @@
+package detector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractCredentials(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		body string
+		want *Credential
+	}{
+		{
+			"AWS",
+			`{"Code":"Success","Type":"AWS-HMAC","AccessKeyId":"ASIAEXAMPLE","SecretAccessKey":"wJalr","Token":"IQoJb3","Expiration":"2024-01-02T09:00:00Z"}`,
+			&Credential{Provider: "AWS", AccessKeyID: "ASIAEXAMPLE", SecretKey: "wJalr", Token: "IQoJb3", Expiration: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			"gopher返回的阿里云原始响应",
+			"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" +
+				`{"AccessKeyId":"STS.N","AccessKeySecret":"3d","SecurityToken":"CAIS","Expiration":"2024-01-02T10:00:00Z","Code":"Success"}`,
+			&Credential{Provider: "阿里云", AccessKeyID: "STS.N", SecretKey: "3d", Token: "CAIS", Expiration: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			"腾讯云",
+			`{"TmpSecretId":"AKID","TmpSecretKey":"key","ExpiredTime":1704171600,"Token":"tok","Code":"Success"}`,
+			&Credential{Provider: "腾讯云", AccessKeyID: "AKID", SecretKey: "key", Token: "tok", Expiration: time.Unix(1704171600, 0).UTC()},
+		},
+		{
+			"GCP",
+			`{"access_token":"ya29.c","expires_in":3599,"token_type":"Bearer"}`,
+			&Credential{Provider: "GCP", Token: "ya29.c", Expiration: now.Add(3599 * time.Second)},
+		},
+		{
+			"Azure",
+			`{"access_token":"eyJ0","expires_on":"1704171600","resource":"https://management.azure.com/","token_type":"Bearer"}`,
+			&Credential{Provider: "Azure", Token: "eyJ0", Expiration: time.Unix(1704171600, 0).UTC()},
+		},
+		{"角色列表", "ec2-instance-role", nil},
+		{"不含凭据的JSON", `{"instanceId":"i-123"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractCredentials(tt.body, now)
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Errorf("不应提取到凭据: %+v", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0] != *tt.want {
+				t.Errorf("ExtractCredentials() = %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
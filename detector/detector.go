@@ -1,20 +1,28 @@
 package detector
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"gosssrf-client/config"
 	"gosssrf-client/payloads"
-	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"regexp"
 	"strings"
 	"time"
 )
 
 // Detector 检测器
 type Detector struct {
-	config *config.Config
-	client *http.Client
+	config  *config.Config
+	client  *http.Client
+	session session     // 登录会话（-login/-login-script）
+	hooks   *scriptHook // 请求和响应钩子脚本（-script），未启动时为nil
+
+	headerSeq uint64 // 已使用的Header组数量（-header-sets轮换）
 }
 
 // NewDetector 创建检测器
@@ -22,15 +30,30 @@ func NewDetector(cfg *config.Config) *Detector {
 	// 创建HTTP客户端
 	client := &http.Client{
 		Timeout: time.Duration(cfg.Timeout) * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// 不跟随重定向
-			return http.ErrUseLastResponse
-		},
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, // 忽略证书验证
-			},
-		},
+		// 默认不跟随重定向，-follow-redirects 指定最多跟随的次数
+		CheckRedirect: checkRedirect(cfg.FollowRedirects),
+	}
+	var pool *proxyPool
+	if len(cfg.ProxyList) > 0 {
+		pool = newProxyPool(cfg.ProxyList, cfg.ProxyRotate == config.ProxyRandom)
+	}
+	// 所有 Transport 共用同一个域名解析器和DNS缓存
+	dns := newResolver(cfg)
+	client.Transport = newTransport(cfg, false, pool, dns)
+	if cfg.HTTP2 {
+		client.Transport = &http2Transport{h2: newTransport(cfg, false, pool, dns), h1: newTransport(cfg, true, pool, dns)}
+	}
+	if pool != nil {
+		client.Transport = &proxyPoolTransport{base: client.Transport, pool: pool}
+	}
+	// NTLM/Negotiate 需要多次往返完成握手，Basic/Bearer 在 send 中直接设置请求头
+	if auth := cfg.Authentication; auth != nil && (auth.Scheme == config.AuthNTLM || auth.Scheme == config.AuthNegotiate) {
+		client.Transport = &ntlmTransport{base: client.Transport, auth: auth}
+	}
+
+	// 登录请求返回的Cookie以及扫描过程中目标更新的Cookie保存在CookieJar中
+	if cfg.LoginRequest != nil {
+		client.Jar, _ = cookiejar.New(nil)
 	}
 
 	return &Detector{
@@ -39,67 +62,363 @@ func NewDetector(cfg *config.Config) *Detector {
 	}
 }
 
-// DetectWithMethod 使用指定HTTP方法检测是否存在SSRF漏洞
-// 返回: vulnerable, evidence, statusCode, responseLen, responseTime, errorMsg
-func (d *Detector) DetectWithMethod(method, testURL, body string, payload payloads.Payload) (bool, string, int, int, int64, string) {
-	startTime := time.Now()
+// newTransport 根据配置创建HTTP Transport，http1 为 true 时即使指定了 -http2 也只使用HTTP/1.1
+// pool 为 -proxy-file 代理池，未指定时为nil；dns 为连接目标和代理时使用的域名解析器
+func newTransport(cfg *config.Config, http1 bool, pool *proxyPool, dns *resolver) *http.Transport {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: time.Duration(cfg.KeepAlive) * time.Second}
+	// 客户端证书、CA、SNI和最低TLS版本由 -cert/-key/-ca/-sni/-tls-min 参数指定，未指定 -ca 时忽略证书验证
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if cfg.TLS != nil {
+		tlsConfig = cfg.TLS.Clone()
+	}
+	// 复用到目标的连接：每个主机保持 -max-idle-per-host（默认与 -t 相同）个空闲连接，并发请求不会在每次请求后重新建立连接
+	idlePerHost := cfg.MaxIdlePerHost
+	if idlePerHost == 0 {
+		idlePerHost = cfg.Threads
+	}
+	transport := &http.Transport{
+		DialContext:         dns.dialContext(dialer),
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConnsPerHost: idlePerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleTimeout) * time.Second,
+		DisableKeepAlives:   cfg.NoKeepAlive,
+	}
+	if cfg.NoKeepAlive {
+		dialer.KeepAlive = -1
+	}
+	// 自定义 DialContext 和 TLSClientConfig 后标准库不再自动启用HTTP/2，-http2 时显式开启ALPN协商
+	if cfg.HTTP2 && !http1 {
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	// 上游代理（认证信息通过代理地址中的 user:pass@ 传递），指定代理池时每个请求从代理池选择
+	if pool != nil {
+		transport.Proxy = pool.proxy
+	} else if cfg.Proxy != "" {
+		if proxyURL, err := cfg.ProxyURL(); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return transport
+}
+
+// Baseline 无害payload的基线响应，用于与payload响应做差异对比
+type Baseline struct {
+	StatusCode int
+	Length     int
+	Server     string
+	Structure  string // 响应结构指纹（HTML标签和JSON键序列）
+	body       string // 小写的响应体，用于排除页面本身就包含的关键字
+	redirects  []Redirect
+	signature  uint64      // 响应体的模糊哈希
+	errorPages []*Baseline // 目标的通用错误页面（AddErrorPage）
+	closedPort *Baseline   // 访问本机关闭端口的响应（SetClosedPort），与基线相同时为nil
+	reachable  *Baseline   // 访问可访问的外部地址的响应（SetReachable），未校准时为nil
+}
+
+// structurePattern 提取HTML标签名和JSON键，作为响应结构指纹
+var structurePattern = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9]*)|"([^"\\]{1,64})"\s*:`)
+
+// responseStructure 计算响应结构指纹
+func responseStructure(body string) string {
+	var parts []string
+	for _, m := range structurePattern.FindAllStringSubmatch(body, 200) {
+		parts = append(parts, strings.ToLower(m[1]+m[2]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Differs 判断响应与基线是否存在明显差异（状态码、结构或长度变化超过10%）
+func (b *Baseline) Differs(statusCode int, body string) bool {
+	if b == nil {
+		return true
+	}
+	if statusCode != b.StatusCode {
+		return true
+	}
+	if responseStructure(body) != b.Structure {
+		return true
+	}
+	diff := len(body) - b.Length
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > 32 && diff*10 > b.Length
+}
+
+// Contains 判断基线响应中是否已包含关键字（不区分大小写）
+func (b *Baseline) Contains(keyword string) bool {
+	return b != nil && strings.Contains(b.body, strings.ToLower(keyword))
+}
+
+// HasRedirect 判断基线请求是否也重定向到该地址
+func (b *Baseline) HasRedirect(location string) bool {
+	if b == nil {
+		return false
+	}
+	for _, hop := range b.redirects {
+		if hop.Location == location {
+			return true
+		}
+	}
+	return false
+}
+
+// AddErrorPage 记录注入点的通用错误页面（例如无效地址、关闭端口的响应）
+func (b *Baseline) AddErrorPage(page *Baseline) {
+	if b != nil && page != nil {
+		b.errorPages = append(b.errorPages, page)
+	}
+}
+
+// Generic 判断响应是否与基线或通用错误页面几乎相同（状态码一致且模糊哈希相似度达到 genericSimilarity），返回相似度
+func (b *Baseline) Generic(statusCode int, body string) (float64, bool) {
+	if b == nil {
+		return 0, false
+	}
+	signature := simhash(body)
+	for _, page := range append([]*Baseline{b}, b.errorPages...) {
+		if page.StatusCode != statusCode {
+			continue
+		}
+		if sim := similarity(signature, page.signature); sim >= genericSimilarity {
+			return sim, true
+		}
+	}
+	return 0, false
+}
+
+// FetchBaseline 发送无害请求获取基线响应
+func (d *Detector) FetchBaseline(ctx context.Context, method, testURL, body string) (*Baseline, error) {
+	resp, redirects, err := d.do(ctx, method, testURL, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _, err := d.readResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	bodyStr := string(respBody)
+	return &Baseline{
+		StatusCode: resp.StatusCode,
+		Length:     len(respBody),
+		Server:     resp.Header.Get("Server"),
+		Structure:  responseStructure(bodyStr),
+		body:       strings.ToLower(bodyStr),
+		redirects:  redirects,
+		signature:  simhash(bodyStr),
+	}, nil
+}
+
+// Fetch 发送请求并返回状态码和响应体（用于需要读取前一步响应内容的多步检测）
+func (d *Detector) Fetch(ctx context.Context, method, testURL, body string) (int, string, error) {
+	resp, err := d.send(ctx, method, testURL, body)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _, err := d.readResponse(resp)
+	if err != nil {
+		return resp.StatusCode, "", fmt.Errorf("读取响应失败: %v", err)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+// targetHeadersKey 请求上下文中目标请求Header的键
+type targetHeadersKey struct{}
 
-	// 创建请求
+// WithHeaders 返回携带目标请求Header的ctx，使用该ctx发送的请求在自定义Header之后设置这些Header
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, targetHeadersKey{}, headers)
+}
+
+// send 构造并发送请求（设置Content-Type和自定义Header），ctx 取消时中止请求
+func (d *Detector) send(ctx context.Context, method, testURL, body string) (*http.Response, error) {
 	var req *http.Request
 	var err error
 
 	if body != "" {
-		req, err = http.NewRequest(method, testURL, strings.NewReader(body))
+		req, err = http.NewRequestWithContext(ctx, method, testURL, strings.NewReader(body))
 		if err != nil {
-			return false, "", 0, 0, 0, fmt.Sprintf("创建请求失败: %v", err)
+			return nil, fmt.Errorf("创建请求失败: %v", err)
 		}
-		// POST请求需要设置Content-Type
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		// POST请求需要设置Content-Type（自定义Header中的Content-Type优先）
+		req.Header.Set("Content-Type", d.config.BodyContentType())
 	} else {
-		req, err = http.NewRequest(method, testURL, nil)
+		req, err = http.NewRequestWithContext(ctx, method, testURL, nil)
 		if err != nil {
-			return false, "", 0, 0, 0, fmt.Sprintf("创建请求失败: %v", err)
+			return nil, fmt.Errorf("创建请求失败: %v", err)
 		}
 	}
 
-	// 添加自定义Header
+	// -auth 指定的认证（Header.txt中的Authorization优先）
+	if auth := d.config.Authentication; auth != nil {
+		switch auth.Scheme {
+		case config.AuthBasic:
+			req.SetBasicAuth(auth.User, auth.Password)
+		case config.AuthBearer:
+			req.Header.Set("Authorization", "Bearer "+auth.Token)
+		}
+	}
+
+	// 添加自定义Header，目标请求中的Header（-burp 导入的请求）覆盖同名Header
 	for key, value := range d.config.CustomHeaders {
 		req.Header.Set(key, value)
 	}
+	if headers, ok := ctx.Value(targetHeadersKey{}).(map[string]string); ok {
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+	}
+	d.rotateHeaders(req)
+
+	// 钩子脚本最后修改请求，签名覆盖所有自定义Header
+	if d.hooks != nil {
+		if err := d.hooks.request(req, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.doRetry(req)
+}
+
+// DetectWithMethod 使用指定HTTP方法检测是否存在SSRF漏洞
+// baseline 为同一注入点的基线响应，不为nil时只有与基线存在差异的响应才按启发式规则判定
+// ctx 取消时中止请求，返回的结果 Canceled 为 true
+func (d *Detector) DetectWithMethod(ctx context.Context, method, testURL, body string, payload payloads.Payload, baseline *Baseline) Result {
+	startTime := time.Now()
 
 	// 发送请求
-	resp, err := d.client.Do(req)
+	resp, redirects, err := d.do(ctx, method, testURL, body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return Result{Canceled: true, Error: "扫描已取消"}
+		}
 		// 返回错误信息
 		if strings.Contains(err.Error(), "connection refused") {
-			return false, "", 0, 0, 0, "连接被拒绝"
+			return Result{Error: "连接被拒绝"}
 		}
 		if strings.Contains(err.Error(), "timeout") {
-			return false, "", 0, 0, 0, "请求超时"
+			return Result{Error: "请求超时"}
 		}
 		if strings.Contains(err.Error(), "no such host") {
-			return false, "", 0, 0, 0, "域名解析失败"
+			return Result{Error: "域名解析失败"}
 		}
-		return false, "", 0, 0, 0, fmt.Sprintf("请求失败: %v", err)
+		return Result{Error: fmt.Sprintf("请求失败: %v", err)}
 	}
 	defer resp.Body.Close()
 
 	// 计算响应时间
 	responseTime := time.Since(startTime).Milliseconds()
 
-	// 读取响应体
-	respBody, err := io.ReadAll(resp.Body)
+	// 读取响应体，超过 -max-body 的部分不再读取，只检测前面的内容
+	respBody, truncated, err := d.readResponse(resp)
+	if err != nil && ctx.Err() != nil {
+		return Result{Canceled: true, Error: "扫描已取消"}
+	}
 	if err != nil {
-		return false, "", resp.StatusCode, 0, responseTime, "读取响应失败"
+		return Result{StatusCode: resp.StatusCode, ResponseTime: responseTime, Error: "读取响应失败"}
+	}
+	if truncated {
+		slog.Debug(fmt.Sprintf("%s 的响应超过 %s，只检测前 %d 字节", testURL, d.config.MaxBody, len(respBody)))
 	}
 
+	// 检测SSRF特征，再应用自定义命中和过滤规则
 	bodyStr := string(respBody)
-	responseLen := len(respBody)
-
-	// 检测SSRF特征
-	vulnerable, evidence := d.analyzeResponse(resp, bodyStr, payload)
+	graphql, isGraphQL := d.parseGraphQL(bodyStr)
+	// 被WAF拦截的响应不再按403等状态码判定，只记录拦截原因
+	var result Result
+	if blocked := wafBlock(resp, bodyStr, baseline); blocked != "" {
+		result.Blocked = blocked
+	} else if msg := graphqlValidationError(graphql); msg != "" {
+		return Result{StatusCode: resp.StatusCode, ResponseLen: len(respBody), ResponseTime: responseTime, Error: msg}
+	} else {
+		result = d.analyzeResponse(resp, bodyStr, payload, baseline)
+	}
+	// 启发式规则命中的响应与目标的通用错误页面几乎相同时，属于模板页面的误报
+	if result.Vulnerable && result.Confidence != ConfidenceConfirmed {
+		if sim, ok := baseline.Generic(resp.StatusCode, bodyStr); ok {
+			result = Result{Suppressed: fmt.Sprintf("%s（与通用页面相似度 %.0f%%）", result.Evidence, sim*100)}
+		} else if class, ok := baseline.failureError(bodyStr); ok {
+			// 回显与请求失败时相同类型的错误，说明目标没有获取到payload地址
+			result = Result{Suppressed: fmt.Sprintf("%s（回显的错误与请求失败时相同: %s）", result.Evidence, class)}
+		}
+	}
+	if !result.Vulnerable && result.Blocked == "" && isGraphQL {
+		if fetch := graphqlFetchEvidence(graphql, baseline); fetch.Vulnerable {
+			result = fetch
+		}
+	}
+	if !result.Vulnerable && result.Blocked == "" && d.config.OpenRedirect && payload.Type == payloads.TypeOpenRedirect {
+		if redirect := openRedirectEvidence(resp, bodyStr, redirects, testURL, payload.Value); redirect.Vulnerable {
+			result = redirect
+		}
+	}
+	if !result.Vulnerable && result.Blocked == "" {
+		if redirect := redirectEvidence(redirects, testURL, payload.Value, baseline); redirect.Vulnerable {
+			result = redirect
+		}
+	}
+	// 内置规则未命中时交给自定义插件分析
+	if !result.Vulnerable && result.Blocked == "" && len(d.config.Plugins) > 0 {
+		if plugin, ok := pluginFinding(d.config.Plugins, resp, bodyStr, testURL, payload); ok {
+			result = plugin
+		}
+	}
+	// 按 -severity-map 调整内置规则和插件判定的严重程度，钩子脚本指定的严重程度优先
+	if result.Vulnerable {
+		result.Severity = d.config.SeverityMap.Severity(payload.Type, result.Kind, result.Severity)
+	}
+	// 钩子脚本可以确认新的漏洞或推翻之前的判定，自定义过滤规则仍然优先
+	if d.hooks != nil && result.Blocked == "" {
+		evaluated, err := d.hooks.evaluate(result, resp, bodyStr, testURL, payload)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("钩子脚本判定响应失败: %v", err))
+		}
+		result = evaluated
+	}
+	result = d.applyRules(result, payload.Type, resp.StatusCode, bodyStr)
+	if portScanType(payload.Type) {
+		result.PortState, result.PortReason = portState(result, resp.StatusCode, bodyStr, baseline)
+		// 关键字等其他规则判定的漏洞同样附加识别出的服务
+		if result.Vulnerable && result.Service == "" {
+			if service, web, ok := identifyService(resp, bodyStr, baseline); ok {
+				result.Service, result.WebService = service, web
+				result.Evidence += fmt.Sprintf("（识别为 %s）", service)
+			} else {
+				result.WebService = webResponse(bodyStr, baseline)
+			}
+		}
+	}
+	result.Redirects = redirects
+	result.StatusCode = resp.StatusCode
+	result.ResponseLen = len(respBody)
+	result.ResponseTime = responseTime
+	// 泄露的敏感信息单独报告，与是否判定为漏洞无关
+	result.Secrets = findSecrets(d.config.SecretRules, bodyStr, payload.Value, baseline)
+	found := result.Vulnerable || len(result.Secrets) > 0
+	// -vv 时未命中的响应也保留片段，用于输出请求详情
+	if found || d.config.Verbosity() >= config.LevelDebug {
+		result.Response = responseExcerpt(bodyStr)
+	}
+	if result.Vulnerable {
+		result.Credentials = ExtractCredentials(bodyStr, time.Now())
+	}
+	if found && d.config.EvidenceDir != "" {
+		result.Exchange = dumpExchange(resp, body, respBody)
+	}
 
-	return vulnerable, evidence, resp.StatusCode, responseLen, responseTime, ""
+	return result
 }
 
 // Detect 检测是否存在SSRF漏洞
@@ -126,60 +445,88 @@ func (d *Detector) Detect(testURL string, payload payloads.Payload) (bool, strin
 	responseTime := time.Since(startTime).Milliseconds()
 
 	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	body, _, err := d.readResponse(resp)
 	if err != nil {
 		return false, "", resp.StatusCode, 0, responseTime
 	}
 
-	bodyStr := string(body)
-	responseLen := len(body)
-
 	// 检测SSRF特征
-	vulnerable, evidence := d.analyzeResponse(resp, bodyStr, payload)
+	result := d.analyzeResponse(resp, string(body), payload, nil)
 
-	return vulnerable, evidence, resp.StatusCode, responseLen, responseTime
+	return result.Vulnerable, result.Evidence, resp.StatusCode, len(body), responseTime
 }
 
-// analyzeResponse 分析响应判断是否存在SSRF
-func (d *Detector) analyzeResponse(resp *http.Response, body string, payload payloads.Payload) (bool, string) {
+// analyzeResponse 分析响应判断是否存在SSRF，并给出置信度和严重程度
+// baseline 不为nil时，基线中已存在的关键字、状态码和Server头不作为证据，启发式规则只对与基线存在差异的响应生效，
+// 并按校准阶段学习到的成功状态码和失败响应的状态码、长度判定
+func (d *Detector) analyzeResponse(resp *http.Response, body string, payload payloads.Payload, baseline *Baseline) Result {
 	// 1. 检查关键字（最可靠的证据）
-	if len(payload.Keywords) > 0 {
-		for _, keyword := range payload.Keywords {
-			if strings.Contains(body, keyword) {
-				return true, fmt.Sprintf("响应中包含特征关键字: %s", keyword)
+	if keyword, ok := d.matchKeyword(body, payload, baseline); ok {
+		evidence := fmt.Sprintf("响应中包含特征关键字: %s", keyword)
+		// Docker API 响应解析为版本、容器和镜像信息
+		if payload.Type == "Docker API" {
+			if docker := dockerEvidence(body); docker != "" {
+				evidence = docker
+			}
+		}
+		// Elasticsearch 响应解析为集群状态、索引和文档数量
+		if payload.Type == payloads.TypeElasticsearch {
+			if es := elasticsearchEvidence(body); es != "" {
+				evidence = es
 			}
 		}
+		return finding(keywordKind(keyword), ConfidenceConfirmed, keywordSeverity(payload.Type, keyword), evidence)
+	}
+
+	// 对于OOB类型，需要检查回连服务器（使用内置OOB服务时以实际回连为准）
+	if payload.Type == "OOB检测" && d.config.OOBListen == "" {
+		// 这里只是发送请求，实际需要在OOB服务器上查看是否收到回连
+		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+			return finding(EvidenceOOBSent, ConfidenceTentative, SeverityInfo, "OOB请求已发送，请检查OOB服务器是否收到回连")
+		}
+	}
+
+	// 与基线响应一致时，说明目标没有因payload返回不同内容
+	if !baseline.Differs(resp.StatusCode, body) {
+		return Result{}
 	}
 
 	// 2. 检查状态码
-	// 200状态码通常意味着成功访问了内网资源
-	if resp.StatusCode == 200 {
-		// 对于端口扫描，200状态码是重要证据
-		if payload.Type == "端口扫描" && len(body) > 0 {
+	// 与目标成功访问外部地址时相同的状态码（未校准时为200）通常意味着成功访问了内网资源
+	if baseline.fetched(resp.StatusCode) {
+		// 对于端口扫描和内网主机名探测，200状态码是重要证据
+		if portScanType(payload.Type) && len(body) > 0 {
+			// 按服务指纹识别具体的服务和版本
+			if service, web, ok := identifyService(resp, body, baseline); ok {
+				return serviceFinding(service, web)
+			}
+
 			// 检查是否返回了HTTP服务的响应
-			if strings.Contains(body, "HTTP/") ||
-				strings.Contains(body, "Server:") ||
-				strings.Contains(body, "<html") {
-				return true, "成功访问内网HTTP服务"
+			if webResponse(body, baseline) {
+				result := finding(EvidenceHTTPService, ConfidenceProbable, SeverityMedium, "成功访问内网HTTP服务")
+				result.WebService = true
+				return result
 			}
 
 			// 检查服务特征
-			if containsAny(body, []string{"redis", "mysql", "MongoDB", "Elasticsearch"}) {
-				return true, "检测到内网服务特征"
+			for _, feature := range []string{"redis", "mysql", "MongoDB", "Elasticsearch"} {
+				if containsAny(body, []string{feature}) && !baseline.Contains(feature) {
+					return finding(EvidenceService, ConfidenceProbable, SeverityHigh, "检测到内网服务特征")
+				}
 			}
 		}
 
 		// 对于文件读取，检查文件内容特征
 		if payload.Type == "文件读取" {
 			if len(body) > 50 { // 文件内容通常有一定长度
-				return true, fmt.Sprintf("可能成功读取文件，响应长度: %d", len(body))
+				return finding(EvidenceFileRead, ConfidenceTentative, SeverityCritical, fmt.Sprintf("可能成功读取文件，响应长度: %d", len(body)))
 			}
 		}
 	}
 
 	// 3. 检查响应长度异常
 	// 如果响应长度大于某个阈值，可能意味着成功读取了内网资源
-	if len(body) > 200 {
+	if len(body) > baseline.contentLength() {
 		// 检查是否包含敏感信息
 		sensitiveKeywords := []string{
 			"root:", "password", "secret", "token", "api_key",
@@ -188,8 +535,12 @@ func (d *Detector) analyzeResponse(resp *http.Response, body string, payload pay
 		}
 
 		for _, keyword := range sensitiveKeywords {
-			if strings.Contains(strings.ToLower(body), strings.ToLower(keyword)) {
-				return true, fmt.Sprintf("响应中包含敏感信息: %s", keyword)
+			if strings.Contains(strings.ToLower(body), strings.ToLower(keyword)) && !baseline.Contains(keyword) {
+				severity := SeverityLow
+				if keyword == "root:" || containsAny(keyword, credentialKeywords) {
+					severity = SeverityCritical
+				}
+				return finding(EvidenceSensitive, ConfidenceProbable, severity, fmt.Sprintf("响应中包含敏感信息: %s", keyword))
 			}
 		}
 	}
@@ -197,26 +548,52 @@ func (d *Detector) analyzeResponse(resp *http.Response, body string, payload pay
 	// 4. 检查特殊状态码
 	// 某些状态码可能表示内网资源的存在
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
-		return true, fmt.Sprintf("状态码 %d - 资源存在但需要认证", resp.StatusCode)
+		if !baseline.failureStatus(resp.StatusCode) {
+			return finding(EvidenceAuthStatus, ConfidenceTentative, SeverityInfo, fmt.Sprintf("状态码 %d - 资源存在但需要认证", resp.StatusCode))
+		}
 	}
 
 	// 5. 检查响应头
-	// 某些响应头可能泄露内网信息
-	if server := resp.Header.Get("Server"); server != "" {
+	// 某些响应头可能泄露内网信息（与基线相同的Server头属于目标自身）
+	if server := resp.Header.Get("Server"); server != "" && (baseline == nil || baseline.Server != server) {
 		if containsAny(server, []string{"Redis", "MySQL", "nginx", "Apache", "Microsoft"}) {
-			return true, fmt.Sprintf("Server头泄露内网服务信息: %s", server)
+			return finding(EvidenceServerHeader, ConfidenceTentative, SeverityLow, fmt.Sprintf("Server头泄露内网服务信息: %s", server))
 		}
 	}
 
-	// 6. 对于OOB类型，需要检查回连服务器
-	if payload.Type == "OOB检测" {
-		// 这里只是发送请求，实际需要在OOB服务器上查看是否收到回连
-		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-			return true, "OOB请求已发送，请检查OOB服务器是否收到回连"
+	return Result{}
+}
+
+// matchKeyword 返回响应中第一个出现、且基线中没有的特征关键字：先检查payload自身的关键字，再检查 -keywords 中的自定义关键字
+// 自定义关键字对所有payload生效，payload地址中包含的关键字（例如内网主机名）可能只是被目标回显，不作为证据
+func (d *Detector) matchKeyword(body string, payload payloads.Payload, baseline *Baseline) (string, bool) {
+	for _, keyword := range payload.Keywords {
+		if strings.Contains(body, keyword) && !baseline.Contains(keyword) {
+			return keyword, true
+		}
+	}
+	for _, keyword := range d.config.Keywords {
+		if strings.Contains(body, keyword) && !baseline.Contains(keyword) && !strings.Contains(payload.Value, keyword) {
+			return keyword, true
 		}
 	}
+	return "", false
+}
 
-	return false, ""
+// applyRules 应用自定义规则：满足命中规则的响应视为漏洞，满足过滤规则的响应不视为漏洞（过滤优先）
+func (d *Detector) applyRules(result Result, payloadType string, statusCode int, body string) Result {
+	if !result.Vulnerable {
+		if ok, reason := d.config.MatchRule.Match(statusCode, len(body), body); ok {
+			severity := d.config.SeverityMap.Severity(payloadType, EvidenceCustomRule, SeverityMedium)
+			result = finding(EvidenceCustomRule, ConfidenceProbable, severity, "匹配自定义规则: "+reason)
+		}
+	}
+	if result.Vulnerable {
+		if ok, _ := d.config.FilterRule.Match(statusCode, len(body), body); ok {
+			return Result{}
+		}
+	}
+	return result
 }
 
 // containsAny 检查字符串是否包含列表中的任意一个
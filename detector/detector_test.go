@@ -0,0 +1,249 @@
+package detector
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func newBaseline(status int, server, body string) *Baseline {
+	return &Baseline{
+		StatusCode: status,
+		Length:     len(body),
+		Server:     server,
+		Structure:  responseStructure(body),
+		body:       strings.ToLower(body),
+	}
+}
+
+func TestAnalyzeResponseBaseline(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("internal portal ", 20) + "</body></html>"
+	baseline := newBaseline(200, "nginx", page)
+
+	tests := []struct {
+		name     string
+		status   int
+		server   string
+		body     string
+		payload  payloads.Payload
+		baseline *Baseline
+		want     bool
+		level    string // 期望的 严重程度/置信度
+	}{
+		{
+			name:     "与基线相同的页面不判定",
+			status:   200,
+			server:   "nginx",
+			body:     page,
+			payload:  payloads.Payload{Type: "端口扫描"},
+			baseline: baseline,
+			want:     false,
+		},
+		{
+			name:     "无基线时按固定规则判定",
+			status:   200,
+			server:   "nginx",
+			body:     page,
+			payload:  payloads.Payload{Type: "端口扫描"},
+			baseline: nil,
+			want:     true,
+			level:    "medium/probable",
+		},
+		{
+			name:     "基线中已有的关键字不作为证据",
+			status:   200,
+			server:   "nginx",
+			body:     page,
+			payload:  payloads.Payload{Keywords: []string{"internal"}},
+			baseline: baseline,
+			want:     false,
+		},
+		{
+			name:     "新出现的特征关键字",
+			status:   200,
+			server:   "nginx",
+			body:     page + "redis_version:7.0",
+			payload:  payloads.Payload{Type: "协议探测", Keywords: []string{"redis_version"}},
+			baseline: baseline,
+			want:     true,
+			level:    "high/confirmed",
+		},
+		{
+			name:     "文件读取关键字",
+			status:   200,
+			body:     "root:x:0:0:root:/root:/bin/bash",
+			payload:  payloads.Payload{Type: "文件读取", Keywords: []string{"root:"}},
+			baseline: baseline,
+			want:     true,
+			level:    "critical/confirmed",
+		},
+		{
+			name:     "云元数据凭据",
+			status:   200,
+			body:     `{"AccessKeyId":"ASIA"}`,
+			payload:  payloads.Payload{Type: "云元数据", Keywords: []string{"AccessKeyId"}},
+			baseline: baseline,
+			want:     true,
+			level:    "critical/confirmed",
+		},
+		{
+			name:     "与基线相同的403不判定",
+			status:   403,
+			body:     "forbidden",
+			baseline: newBaseline(403, "", "forbidden"),
+			want:     false,
+		},
+		{
+			name:     "状态码变为401",
+			status:   401,
+			body:     "unauthorized",
+			baseline: baseline,
+			want:     true,
+			level:    "info/tentative",
+		},
+	}
+
+	d := &Detector{config: &config.Config{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.server != "" {
+				resp.Header.Set("Server", tt.server)
+			}
+			got := d.analyzeResponse(resp, tt.body, tt.payload, tt.baseline)
+			if got.Vulnerable != tt.want {
+				t.Errorf("得到 %v (%s)，期望 %v", got.Vulnerable, got.Evidence, tt.want)
+			}
+			if level := got.Severity + "/" + got.Confidence; tt.level != "" && level != tt.level {
+				t.Errorf("严重程度/置信度 得到 %s，期望 %s", level, tt.level)
+			}
+		})
+	}
+}
+
+func TestCustomKeywords(t *testing.T) {
+	d := &Detector{config: &config.Config{Keywords: []string{"CorpPortal", "intranet.corp"}}}
+	baseline := newBaseline(200, "", "<html>not found</html>")
+	tests := []struct {
+		name    string
+		body    string
+		payload payloads.Payload
+		want    string
+	}{
+		{"所有payload都检查自定义关键字", "<title>CorpPortal v2</title>", payloads.Payload{Value: "http://10.0.0.5/", Type: "内网探测"}, "CorpPortal"},
+		{"payload自身关键字优先", "redis_version:7 CorpPortal", payloads.Payload{Type: "协议探测", Keywords: []string{"redis_version"}}, "redis_version"},
+		{"payload地址中的关键字被回显", "failed to fetch http://intranet.corp/", payloads.Payload{Value: "http://intranet.corp/"}, ""},
+		{"其他payload响应中出现内网主机名", "upstream intranet.corp ok", payloads.Payload{Value: "http://10.0.0.5/"}, "intranet.corp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := d.matchKeyword(tt.body, tt.payload, baseline); got != tt.want {
+				t.Errorf("matchKeyword = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, ok := d.matchKeyword("<footer>CorpPortal</footer>", payloads.Payload{}, newBaseline(200, "", "<footer>CorpPortal</footer>")); ok {
+		t.Errorf("基线页面中已有的关键字不应作为证据: %q", got)
+	}
+}
+
+func TestSeverityMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "6379") {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Timeout: 5, SeverityMap: &config.SeverityMap{Evidence: map[string]string{EvidenceAuthStatus: SeverityHigh}}}
+	d := NewDetector(cfg)
+	baseline := newBaseline(200, "", "ok")
+	payload := payloads.Payload{Value: "http://127.0.0.1:6379/", Type: "端口扫描"}
+	got := d.DetectWithMethod(context.Background(), http.MethodGet, server.URL+"/?url=6379", "", payload, baseline)
+	if !got.Vulnerable || got.Kind != EvidenceAuthStatus || got.Severity != SeverityHigh {
+		t.Errorf("得到 %s/%s (%s)，期望按映射调整为 high", got.Kind, got.Severity, got.Evidence)
+	}
+
+	cfg.SeverityMap = &config.SeverityMap{Types: map[string]string{"端口扫描": SeverityLow}}
+	if got := d.DetectWithMethod(context.Background(), http.MethodGet, server.URL+"/?url=6379", "", payload, baseline); got.Severity != SeverityLow {
+		t.Errorf("按payload类型映射得到 %s，期望 low", got.Severity)
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		wantConns func(n int32) bool
+	}{
+		{"默认按 -t 保持空闲连接", &config.Config{Timeout: 5, Threads: 5, IdleTimeout: 90}, func(n int32) bool { return n < 20 }},
+		{"每个请求使用新连接", &config.Config{Timeout: 5, Threads: 5, NoKeepAlive: true}, func(n int32) bool { return n == 50 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&conns, 0)
+			d := NewDetector(tt.cfg)
+			// 5个并发各发送10个请求
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						if _, _, err := d.Fetch(context.Background(), http.MethodGet, server.URL, ""); err != nil {
+							t.Error(err)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if n := atomic.LoadInt32(&conns); !tt.wantConns(n) {
+				t.Errorf("建立了 %d 个连接", n)
+			}
+		})
+	}
+}
+
+func TestKeywordSeverity(t *testing.T) {
+	tests := []struct {
+		payloadType string
+		keyword     string
+		want        string
+	}{
+		{"Docker API", "ApiVersion", SeverityCritical},
+		{payloads.TypeConsul, "LockIndex", SeverityCritical}, // KV键值列表
+		{payloads.TypeEtcd, "createdIndex", SeverityCritical},
+		{payloads.TypeConsul, "NodeName", SeverityHigh},
+		{payloads.TypeVault, "sealed", SeverityHigh},
+		{payloads.TypeAdmin, "JAVA PROFILE", SeverityCritical}, // 堆转储
+		{payloads.TypeAdmin, "Active connections", SeverityHigh},
+		{"端口扫描", "redis_version", SeverityMedium},
+		{"云元数据", "AccessKeyId", SeverityCritical},
+	}
+	for _, tt := range tests {
+		if got := keywordSeverity(tt.payloadType, tt.keyword); got != tt.want {
+			t.Errorf("keywordSeverity(%q, %q) = %q, want %q", tt.payloadType, tt.keyword, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxListedItems 证据中最多列出的容器/镜像数量
+const maxListedItems = 5
+
+// dockerVersion /version 接口响应
+type dockerVersion struct {
+	Version    string
+	APIVersion string `json:"ApiVersion"`
+	Os         string
+	Arch       string
+}
+
+// dockerContainer /containers/json 接口响应中的容器
+type dockerContainer struct {
+	ID    string `json:"Id"`
+	Names []string
+	Image string
+	State string
+}
+
+// dockerImage /images/json 接口响应中的镜像
+type dockerImage struct {
+	ID       string `json:"Id"`
+	RepoTags []string
+}
+
+// dockerEvidence 解析Docker API响应，生成包含版本、容器和镜像信息的证据，无法解析时返回空
+func dockerEvidence(body string) string {
+	data := jsonPayload(body)
+	if data == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(data, "{") {
+		var version dockerVersion
+		if json.Unmarshal([]byte(data), &version) != nil || version.APIVersion == "" {
+			return ""
+		}
+		return fmt.Sprintf("Docker API 未授权访问: 版本 %s（API %s，%s/%s）", version.Version, version.APIVersion, version.Os, version.Arch)
+	}
+
+	// 容器列表和镜像列表均为数组，按字段区分
+	var items []map[string]json.RawMessage
+	if json.Unmarshal([]byte(data), &items) != nil || len(items) == 0 {
+		return ""
+	}
+	if _, ok := items[0]["Names"]; ok {
+		var containers []dockerContainer
+		if json.Unmarshal([]byte(data), &containers) != nil {
+			return ""
+		}
+		var listed []string
+		for _, c := range containers {
+			if len(listed) == maxListedItems {
+				break
+			}
+			name := strings.TrimPrefix(strings.Join(c.Names, ","), "/")
+			listed = append(listed, fmt.Sprintf("%s（%s，%s）", name, c.Image, c.State))
+		}
+		return fmt.Sprintf("Docker API 未授权访问: %d 个容器 %s", len(containers), strings.Join(listed, "; "))
+	}
+	if _, ok := items[0]["RepoTags"]; ok {
+		var images []dockerImage
+		if json.Unmarshal([]byte(data), &images) != nil {
+			return ""
+		}
+		var listed []string
+		for _, img := range images {
+			if len(listed) == maxListedItems {
+				break
+			}
+			if len(img.RepoTags) > 0 {
+				listed = append(listed, img.RepoTags[0])
+			}
+		}
+		return fmt.Sprintf("Docker API 未授权访问: %d 个镜像 %s", len(images), strings.Join(listed, ", "))
+	}
+	return ""
+}
+
+// jsonPayload 提取响应中的JSON内容（gopher等方式返回原始HTTP响应时跳过响应头）
+func jsonPayload(body string) string {
+	if strings.HasPrefix(body, "HTTP/1.") {
+		idx := strings.Index(body, "\r\n\r\n")
+		if idx == -1 {
+			return ""
+		}
+		body = body[idx+4:]
+	}
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[") {
+		return body
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package detector
+
+import "testing"
+
+func TestDockerEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "版本",
+			body: `{"Version":"24.0.5","ApiVersion":"1.43","Os":"linux","Arch":"amd64","KernelVersion":"5.15.0"}`,
+			want: "Docker API 未授权访问: 版本 24.0.5（API 1.43，linux/amd64）",
+		},
+		{
+			name: "容器列表",
+			body: `[{"Id":"a1","Names":["/web"],"Image":"nginx:latest","ImageID":"sha256:1","State":"running"},{"Id":"b2","Names":["/db"],"Image":"mysql:8","State":"exited"}]`,
+			want: "Docker API 未授权访问: 2 个容器 web（nginx:latest，running）; db（mysql:8，exited）",
+		},
+		{
+			name: "gopher返回的原始响应中的镜像列表",
+			body: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + `[{"Id":"sha256:1","RepoTags":["nginx:latest"]},{"Id":"sha256:2","RepoTags":["<none>:<none>"]}]`,
+			want: "Docker API 未授权访问: 2 个镜像 nginx:latest, <none>:<none>",
+		},
+		{
+			name: "空容器列表",
+			body: `[]`,
+			want: "",
+		},
+		{
+			name: "非Docker响应",
+			body: `{"gitVersion":"v1.28.0"}`,
+			want: "",
+		},
+		{
+			name: "HTML页面",
+			body: `<html>ApiVersion</html>`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dockerEvidence(tt.body); got != tt.want {
+				t.Errorf("dockerEvidence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
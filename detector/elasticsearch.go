@@ -0,0 +1,53 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// elasticsearchHealth /_cluster/health 接口响应
+type elasticsearchHealth struct {
+	ClusterName   string `json:"cluster_name"`
+	Status        string `json:"status"`
+	NumberOfNodes int    `json:"number_of_nodes"`
+}
+
+// elasticsearchIndex /_cat/indices?format=json 接口响应中的索引（数值字段为字符串）
+type elasticsearchIndex struct {
+	Index     string `json:"index"`
+	DocsCount string `json:"docs.count"`
+	StoreSize string `json:"store.size"`
+}
+
+// elasticsearchEvidence 解析 Elasticsearch 集群状态和索引列表响应，生成包含集群名称、索引和文档数量的证据，无法解析时返回空
+func elasticsearchEvidence(body string) string {
+	data := jsonPayload(body)
+	if data == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(data, "{") {
+		var health elasticsearchHealth
+		if json.Unmarshal([]byte(data), &health) != nil || health.ClusterName == "" {
+			return ""
+		}
+		return fmt.Sprintf("Elasticsearch 未授权访问: 集群 %s（状态 %s，%d 个节点）", health.ClusterName, health.Status, health.NumberOfNodes)
+	}
+
+	var indices []elasticsearchIndex
+	if json.Unmarshal([]byte(data), &indices) != nil || len(indices) == 0 || indices[0].Index == "" {
+		return ""
+	}
+	total := 0
+	var listed []string
+	for _, index := range indices {
+		docs, _ := strconv.Atoi(index.DocsCount)
+		total += docs
+		if len(listed) < maxListedItems {
+			listed = append(listed, fmt.Sprintf("%s（%s 条文档，%s）", index.Index, index.DocsCount, index.StoreSize))
+		}
+	}
+	return fmt.Sprintf("Elasticsearch 数据可读取: %d 个索引共 %d 条文档 %s", len(indices), total, strings.Join(listed, "; "))
+}
@@ -0,0 +1,50 @@
+package detector
+
+import "testing"
+
+func TestElasticsearchEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "集群状态",
+			body: `{"cluster_name":"prod-logs","status":"yellow","timed_out":false,"number_of_nodes":3,"number_of_data_nodes":3}`,
+			want: "Elasticsearch 未授权访问: 集群 prod-logs（状态 yellow，3 个节点）",
+		},
+		{
+			name: "索引列表",
+			body: `[{"index":"users","docs.count":"120000","store.size":"35.2mb"},{"index":"orders","docs.count":"8000","store.size":"4mb"},{"index":".kibana_1","docs.count":"12","store.size":"40kb"}]`,
+			want: "Elasticsearch 数据可读取: 3 个索引共 128012 条文档 users（120000 条文档，35.2mb）; orders（8000 条文档，4mb）; .kibana_1（12 条文档，40kb）",
+		},
+		{
+			name: "最多列出5个索引",
+			body: `[{"index":"a","docs.count":"6","store.size":"1kb"},{"index":"b","docs.count":"5","store.size":"1kb"},{"index":"c","docs.count":"4","store.size":"1kb"},{"index":"d","docs.count":"3","store.size":"1kb"},{"index":"e","docs.count":"2","store.size":"1kb"},{"index":"f","docs.count":"1","store.size":"1kb"}]`,
+			want: "Elasticsearch 数据可读取: 6 个索引共 21 条文档 a（6 条文档，1kb）; b（5 条文档，1kb）; c（4 条文档，1kb）; d（3 条文档，1kb）; e（2 条文档，1kb）",
+		},
+		{
+			name: "空索引列表",
+			body: `[]`,
+			want: "",
+		},
+		{
+			name: "非Elasticsearch响应",
+			body: `{"Version":"24.0.5","ApiVersion":"1.43"}`,
+			want: "",
+		},
+		{
+			name: "HTML页面",
+			body: `<html>cluster_name</html>`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := elasticsearchEvidence(tt.body); got != tt.want {
+				t.Errorf("elasticsearchEvidence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// dumpExchange 按HTTP报文格式输出完整的请求和响应（跟随重定向时为最后一次请求）
+func dumpExchange(resp *http.Response, reqBody string, respBody []byte) string {
+	var b strings.Builder
+	b.WriteString("===== 请求 =====\n")
+	if req := resp.Request; req != nil {
+		proto := "HTTP/1.1"
+		if resp.ProtoMajor == 2 {
+			proto = "HTTP/2"
+		}
+		fmt.Fprintf(&b, "%s %s %s\r\n", req.Method, req.URL.RequestURI(), proto)
+		host := req.Host
+		if host == "" {
+			host = req.URL.Host
+		}
+		fmt.Fprintf(&b, "Host: %s\r\n", host)
+		req.Header.Write(&b)
+		b.WriteString("\r\n")
+		// 跟随重定向后的请求不再携带请求体
+		if req.Response == nil {
+			b.WriteString(reqBody)
+		}
+	}
+
+	b.WriteString("\n\n===== 响应 =====\n")
+	if head, err := httputil.DumpResponse(resp, false); err == nil {
+		b.Write(head)
+	}
+	b.Write(respBody)
+	return b.String()
+}
+
+// Replay 重新发送请求，返回完整的请求和响应报文（replay子命令复现已发现的漏洞）
+func (d *Detector) Replay(ctx context.Context, method, testURL, body string) (string, error) {
+	resp, _, err := d.do(ctx, method, testURL, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _, err := d.readResponse(resp)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+	return dumpExchange(resp, body, respBody), nil
+}
@@ -0,0 +1,38 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestEvidenceExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "1")
+		w.Write([]byte("root:x:0:0:root:/root:/bin/bash\n"))
+	}))
+	defer server.Close()
+
+	payload := payloads.Payload{Value: "file:///etc/passwd", Type: "文件读取", Keywords: []string{"root:"}}
+	cfg := &config.Config{Timeout: 5, EvidenceDir: t.TempDir(), CustomHeaders: map[string]string{"Cookie": "session=1"}}
+	got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodPost, server.URL+"/api", "url=file%3A%2F%2F%2Fetc%2Fpasswd", payload, nil)
+	if !got.Vulnerable {
+		t.Fatalf("应判定为漏洞: %+v", got)
+	}
+	for _, want := range []string{"POST /api HTTP/1.1", "Cookie: session=1", "url=file%3A%2F%2F%2Fetc%2Fpasswd", "HTTP/1.1 200 OK", "X-Test: 1", "root:x:0:0"} {
+		if !strings.Contains(got.Exchange, want) {
+			t.Errorf("请求和响应中缺少 %q:\n%s", want, got.Exchange)
+		}
+	}
+
+	// 未指定 -evidence-dir 时不保存
+	cfg.EvidenceDir = ""
+	if got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodGet, server.URL, "", payload, nil); got.Exchange != "" {
+		t.Error("未指定证据目录时不应保存完整请求和响应")
+	}
+}
@@ -0,0 +1,71 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gosssrf-client/config"
+)
+
+// graphqlResponse GraphQL响应的外层结构
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// graphqlValidationCodes 请求未执行（查询语法错误、变量类型不匹配）时常见的错误码
+var graphqlValidationCodes = []string{"GRAPHQL_PARSE_FAILED", "GRAPHQL_VALIDATION_FAILED", "BAD_USER_INPUT"}
+
+// graphqlValidationMessages 未返回错误码的服务端中表示请求未执行的错误信息
+var graphqlValidationMessages = []string{"Syntax Error", "Cannot query field", "Variable \"$", "Unknown argument", "Unknown type"}
+
+// graphqlFetchErrors 解析器请求payload地址失败时错误信息中的特征（小写），说明服务端确实发起了请求
+var graphqlFetchErrors = []string{
+	"econnrefused", "connection refused", "enotfound", "no such host", "etimedout", "timeout",
+	"ehostunreach", "socket hang up", "econnreset", "connection reset",
+}
+
+// parseGraphQL 解析GraphQL响应，请求体类型不是graphql或响应中没有 errors 字段时 ok 为false
+func (d *Detector) parseGraphQL(body string) (graphqlResponse, bool) {
+	var resp graphqlResponse
+	if d.config.BodyType != config.BodyTypeGraphQL {
+		return resp, false
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || len(resp.Errors) == 0 {
+		return resp, false
+	}
+	return resp, true
+}
+
+// graphqlValidationError 查询校验失败（语法错误、变量类型不匹配）时payload没有到达解析器，返回错误信息，否则返回空字符串
+func graphqlValidationError(resp graphqlResponse) string {
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		return ""
+	}
+	for _, e := range resp.Errors {
+		if containsAny(e.Extensions.Code, graphqlValidationCodes) || containsAny(e.Message, graphqlValidationMessages) {
+			return "GraphQL请求校验失败: " + e.Message
+		}
+	}
+	return ""
+}
+
+// graphqlFetchEvidence 解析器的错误信息中出现连接失败的特征（基线中没有）时判定为无回显SSRF
+// 连接失败的错误信息对所有不可达地址都相同，不与通用错误页面比较
+func graphqlFetchEvidence(resp graphqlResponse, baseline *Baseline) Result {
+	for _, e := range resp.Errors {
+		lower := strings.ToLower(e.Message)
+		for _, keyword := range graphqlFetchErrors {
+			if strings.Contains(lower, keyword) && !baseline.Contains(keyword) {
+				return finding(EvidenceGraphQL, ConfidenceProbable, SeverityMedium, fmt.Sprintf("GraphQL错误信息显示服务端向payload地址发起了请求: %s", e.Message))
+			}
+		}
+	}
+	return Result{}
+}
@@ -0,0 +1,84 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestAnalyzeGraphQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		baseline   *Baseline
+		wantOK     bool
+		vulnerable bool
+		wantError  bool
+	}{
+		{
+			name:   "没有错误时按普通响应分析",
+			body:   `{"data":{"importAvatar":{"id":"1"}}}`,
+			wantOK: false,
+		},
+		{
+			name:   "不是GraphQL响应",
+			body:   `<html>root:x:0:0</html>`,
+			wantOK: false,
+		},
+		{
+			name:      "变量类型校验失败",
+			body:      `{"errors":[{"message":"Variable \"$url\" got invalid value 1; String cannot represent a non string value","extensions":{"code":"BAD_USER_INPUT"}}]}`,
+			wantOK:    true,
+			wantError: true,
+		},
+		{
+			name:      "查询语法错误",
+			body:      `{"errors":[{"message":"Syntax Error: Expected Name, found <EOF>."}],"data":null}`,
+			wantOK:    true,
+			wantError: true,
+		},
+		{
+			name:       "解析器连接payload地址失败",
+			body:       `{"errors":[{"message":"request to http://127.0.0.1:6379/ failed, reason: connect ECONNREFUSED 127.0.0.1:6379","path":["importAvatar"]}],"data":{"importAvatar":null}}`,
+			wantOK:     true,
+			vulnerable: true,
+		},
+		{
+			name:     "基线中已有相同错误",
+			body:     `{"errors":[{"message":"upstream timeout"}],"data":{"importAvatar":null}}`,
+			baseline: &Baseline{body: strings.ToLower(`{"errors":[{"message":"upstream timeout"}]}`)},
+			wantOK:   false,
+		},
+		{
+			name:   "普通业务错误",
+			body:   `{"errors":[{"message":"Not authorized"}],"data":{"importAvatar":null}}`,
+			wantOK: false,
+		},
+	}
+
+	d := &Detector{config: &config.Config{BodyType: config.BodyTypeGraphQL}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, ok := d.parseGraphQL(tt.body)
+			msg := graphqlValidationError(resp)
+			result := graphqlFetchEvidence(resp, tt.baseline)
+			if got := ok && (msg != "" || result.Vulnerable); got != tt.wantOK {
+				t.Fatalf("GraphQL分析命中 = %v, want %v", got, tt.wantOK)
+			}
+			if result.Vulnerable != tt.vulnerable {
+				t.Errorf("Vulnerable = %v, want %v (evidence: %s)", result.Vulnerable, tt.vulnerable, result.Evidence)
+			}
+			if (msg != "") != tt.wantError {
+				t.Errorf("校验错误 = %q, wantError %v", msg, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestParseGraphQLBodyType(t *testing.T) {
+	d := &Detector{config: &config.Config{BodyType: config.BodyTypeJSON}}
+	if _, ok := d.parseGraphQL(`{"errors":[{"message":"Syntax Error"}]}`); ok {
+		t.Error("请求体类型不是graphql时不应按GraphQL响应分析")
+	}
+}
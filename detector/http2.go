@@ -0,0 +1,80 @@
+package detector
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// http2Transport -http2 模式的 RoundTripper：HTTPS目标通过ALPN优先协商HTTP/2（服务器不支持时由ALPN协商为HTTP/1.1），
+// 协商为HTTP/2后请求失败、改用HTTP/1.1成功时（服务器或中间设备的HTTP/2实现有问题），之后该主机只使用HTTP/1.1
+type http2Transport struct {
+	h2       *http.Transport
+	h1       *http.Transport
+	fallback sync.Map // 已回退到HTTP/1.1的主机
+}
+
+// RoundTrip 发送请求，HTTP/2出错时回退到HTTP/1.1
+func (t *http2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := t.fallback.Load(req.URL.Host); ok {
+		return t.h1.RoundTrip(req)
+	}
+
+	resp, err := t.h2.RoundTrip(req)
+	if err == nil || !retryHTTP1(req, err) {
+		return resp, err
+	}
+	retry, rewindErr := rewindRequest(req)
+	if rewindErr != nil {
+		return nil, err
+	}
+	resp, retryErr := t.h1.RoundTrip(retry)
+	if retryErr != nil {
+		return nil, retryErr
+	}
+	// HTTP/1.1 成功才说明是HTTP/2的问题
+	if _, loaded := t.fallback.LoadOrStore(req.URL.Host, true); !loaded {
+		slog.Warn(fmt.Sprintf("%s 的HTTP/2请求失败（%v），回退到HTTP/1.1", req.URL.Host, err), "host", req.URL.Host)
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections 关闭两个 Transport 的空闲连接
+func (t *http2Transport) CloseIdleConnections() {
+	t.h2.CloseIdleConnections()
+	t.h1.CloseIdleConnections()
+}
+
+// retryHTTP1 判断出错的请求是否改用HTTP/1.1重发：只有HTTPS会协商HTTP/2，
+// 超时、连接失败和请求取消与协议无关，直接返回错误
+func retryHTTP1(req *http.Request, err error) bool {
+	if req.URL.Scheme != "https" || req.Context().Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	var opErr *net.OpError
+	return !errors.As(err, &opErr) || opErr.Op != "dial"
+}
+
+// rewindRequest 复制请求用于重发，请求体无法重新读取时返回错误
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("请求体无法重发")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+	return retry, nil
+}
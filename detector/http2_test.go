@@ -0,0 +1,80 @@
+package detector
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestHTTP2Negotiation(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, tt := range []struct {
+		http2 bool
+		want  string
+	}{
+		{false, "HTTP/1.1"},
+		{true, "HTTP/2.0"},
+	} {
+		d := NewDetector(&config.Config{Timeout: 5, HTTP2: tt.http2})
+		_, body, err := d.Fetch(context.Background(), http.MethodGet, server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != tt.want {
+			t.Errorf("-http2=%v 使用了 %s，期望 %s", tt.http2, body, tt.want)
+		}
+	}
+}
+
+func TestHTTP2Fallback(t *testing.T) {
+	// 通过ALPN协商HTTP/2后立即断开连接，模拟有问题的HTTP/2实现
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, r.Proto+" "+string(body))
+	}))
+	server.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	server.Config.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){
+		"h2": func(_ *http.Server, conn *tls.Conn, _ http.Handler) { conn.Close() },
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	d := NewDetector(&config.Config{Timeout: 5, HTTP2: true})
+	for i := 0; i < 2; i++ {
+		_, body, err := d.Fetch(context.Background(), http.MethodPost, server.URL, "url=x")
+		if err != nil {
+			t.Fatalf("第 %d 次请求应回退到HTTP/1.1: %v", i+1, err)
+		}
+		if !strings.HasPrefix(body, "HTTP/1.1 url=x") {
+			t.Errorf("第 %d 次请求得到 %q，期望 HTTP/1.1 并重发请求体", i+1, body)
+		}
+	}
+}
+
+func TestHTTP2Proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "proxied "+r.URL.String())
+	}))
+	defer proxy.Close()
+
+	d := NewDetector(&config.Config{Timeout: 5, HTTP2: true, Proxy: proxy.URL})
+	_, body, err := d.Fetch(context.Background(), http.MethodGet, "http://internal.invalid/x", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "proxied http://internal.invalid/x" {
+		t.Errorf("-http2 时应继续使用代理，得到 %q", body)
+	}
+}
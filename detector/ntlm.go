@@ -0,0 +1,277 @@
+package detector
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"gosssrf-client/config"
+)
+
+// NTLM 协商标志
+const (
+	ntlmNegotiateUnicode    = 0x00000001
+	ntlmRequestTarget       = 0x00000004
+	ntlmNegotiateNTLM       = 0x00000200
+	ntlmAlwaysSign          = 0x00008000
+	ntlmExtendedSession     = 0x00080000
+	ntlmNegotiateTargetInfo = 0x00800000
+	ntlmNegotiate128        = 0x20000000
+	ntlmNegotiate56         = 0x80000000
+
+	ntlmFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmAlwaysSign |
+		ntlmExtendedSession | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+)
+
+// ntlmSignature NTLM消息头
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmTransport NTLM/Negotiate 认证（-auth ntlm:/negotiate:）：每个请求先发送协商消息，
+// 收到401质询后用NTLMv2响应重发；Negotiate 方式同样使用NTLM令牌（不支持Kerberos）
+type ntlmTransport struct {
+	base http.RoundTripper
+	auth *config.Auth
+}
+
+// RoundTrip 完成NTLM握手并返回认证后的响应，服务器未返回质询时直接返回第一次的响应
+func (t *ntlmTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Header.txt 中已指定 Authorization 时不再认证
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	scheme := "NTLM"
+	if t.auth.Scheme == config.AuthNegotiate {
+		scheme = "Negotiate"
+	}
+
+	first, err := rewindRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	first.Header.Set("Authorization", scheme+" "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	resp, err := t.base.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := ntlmChallenge(resp.Header.Values("WWW-Authenticate"), scheme)
+	if challenge == nil {
+		return resp, nil
+	}
+	authenticate, err := ntlmAuthenticateMessage(challenge, t.auth, time.Now())
+	if err != nil {
+		return resp, nil
+	}
+	// 读完响应体以便在同一连接上发送认证消息（NTLM认证的是连接）
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+
+	second, err := rewindRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	second.Header.Set("Authorization", scheme+" "+base64.StdEncoding.EncodeToString(authenticate))
+	return t.base.RoundTrip(second)
+}
+
+// CloseIdleConnections 关闭底层 Transport 的空闲连接
+func (t *ntlmTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// ntlmChallenge 从 WWW-Authenticate 头中取出服务器的质询消息
+func ntlmChallenge(headers []string, scheme string) []byte {
+	for _, header := range headers {
+		name, value, ok := strings.Cut(strings.TrimSpace(header), " ")
+		if !ok || !strings.EqualFold(name, scheme) {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err == nil && len(data) >= 32 && bytes.HasPrefix(data, ntlmSignature) && binary.LittleEndian.Uint32(data[8:]) == 2 {
+			return data
+		}
+	}
+	return nil
+}
+
+// ntlmNegotiateMessage 生成协商消息（Type 1）
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	return msg
+}
+
+// ntlmAuthenticateMessage 根据质询消息（Type 2）生成NTLMv2认证消息（Type 3）
+func ntlmAuthenticateMessage(challenge []byte, auth *config.Auth, now time.Time) ([]byte, error) {
+	if len(challenge) < 48 {
+		return nil, fmt.Errorf("NTLM质询消息过短")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:])
+	serverChallenge := challenge[24:32]
+	targetInfo, err := ntlmField(challenge, 40)
+	if err != nil {
+		return nil, err
+	}
+
+	clientChallenge := make([]byte, 8)
+	rand.Read(clientChallenge)
+
+	// 质询中带有时间戳时使用服务器时间，此时LM响应为全零
+	timestamp := ntlmFileTime(now)
+	lmResponse := []byte(nil)
+	if ts := ntlmAvTimestamp(targetInfo); ts != nil {
+		timestamp = ts
+		lmResponse = make([]byte, 24)
+	}
+
+	key := ntowfv2(auth.User, auth.Password, auth.Domain)
+	temp := make([]byte, 0, 28+len(targetInfo)+4)
+	temp = append(temp, 1, 1, 0, 0, 0, 0, 0, 0)
+	temp = append(temp, timestamp...)
+	temp = append(temp, clientChallenge...)
+	temp = append(temp, 0, 0, 0, 0)
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+	ntResponse := append(hmacMD5(key, serverChallenge, temp), temp...)
+	if lmResponse == nil {
+		lmResponse = append(hmacMD5(key, serverChallenge, clientChallenge), clientChallenge...)
+	}
+
+	fields := [][]byte{lmResponse, ntResponse, utf16LE(auth.Domain), utf16LE(auth.User), nil, nil}
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := len(msg)
+	for i, field := range fields {
+		pos := 12 + i*8
+		binary.LittleEndian.PutUint16(msg[pos:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[pos+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[pos+4:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags&ntlmFlags|ntlmNegotiateUnicode)
+	for _, field := range fields {
+		msg = append(msg, field...)
+	}
+	return msg, nil
+}
+
+// ntlmField 读取消息中 pos 处安全缓冲区描述的字段
+func ntlmField(msg []byte, pos int) ([]byte, error) {
+	length := int(binary.LittleEndian.Uint16(msg[pos:]))
+	offset := int(binary.LittleEndian.Uint32(msg[pos+4:]))
+	if offset+length > len(msg) {
+		return nil, fmt.Errorf("NTLM消息字段越界")
+	}
+	return msg[offset : offset+length], nil
+}
+
+// ntlmAvTimestamp 从 TargetInfo 中取出 MsvAvTimestamp，不存在时返回nil
+func ntlmAvTimestamp(targetInfo []byte) []byte {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == 0 || len(targetInfo) < 4+length {
+			return nil
+		}
+		if id == 7 && length == 8 {
+			return targetInfo[4:12]
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil
+}
+
+// ntlmFileTime 将时间转换为Windows FILETIME（1601年起的100纳秒数）
+func ntlmFileTime(t time.Time) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(t.UnixNano()/100+116444736000000000))
+	return buf
+}
+
+// ntowfv2 计算NTLMv2密钥：HMAC-MD5(MD4(密码), 大写用户名+域名)
+func ntowfv2(user, password, domain string) []byte {
+	hash := md4Sum(utf16LE(password))
+	return hmacMD5(hash[:], utf16LE(strings.ToUpper(user)+domain))
+}
+
+// hmacMD5 计算 HMAC-MD5
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// utf16LE 将字符串编码为UTF-16LE
+func utf16LE(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	buf := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(buf[i*2:], c)
+	}
+	return buf
+}
+
+// md4Sum 计算MD4摘要（RFC 1320，标准库未提供）
+func md4Sum(data []byte) [16]byte {
+	msg := append([]byte{}, data...)
+	msg = append(msg, 0x80)
+	for len(msg)%64 != 56 {
+		msg = append(msg, 0)
+	}
+	msg = binary.LittleEndian.AppendUint64(msg, uint64(len(data))*8)
+
+	f := func(x, y, z uint32) uint32 { return x&y | ^x&z }
+	g := func(x, y, z uint32) uint32 { return x&y | x&z | y&z }
+	h := func(x, y, z uint32) uint32 { return x ^ y ^ z }
+
+	a, b, c, d := uint32(0x67452301), uint32(0xefcdab89), uint32(0x98badcfe), uint32(0x10325476)
+	for i := 0; i < len(msg); i += 64 {
+		var x [16]uint32
+		for j := range x {
+			x[j] = binary.LittleEndian.Uint32(msg[i+4*j:])
+		}
+		aa, bb, cc, dd := a, b, c, d
+		for _, j := range []int{0, 4, 8, 12} {
+			a = bits.RotateLeft32(a+f(b, c, d)+x[j], 3)
+			d = bits.RotateLeft32(d+f(a, b, c)+x[j+1], 7)
+			c = bits.RotateLeft32(c+f(d, a, b)+x[j+2], 11)
+			b = bits.RotateLeft32(b+f(c, d, a)+x[j+3], 19)
+		}
+		for j := 0; j < 4; j++ {
+			a = bits.RotateLeft32(a+g(b, c, d)+x[j]+0x5a827999, 3)
+			d = bits.RotateLeft32(d+g(a, b, c)+x[j+4]+0x5a827999, 5)
+			c = bits.RotateLeft32(c+g(d, a, b)+x[j+8]+0x5a827999, 9)
+			b = bits.RotateLeft32(b+g(c, d, a)+x[j+12]+0x5a827999, 13)
+		}
+		for _, j := range []int{0, 2, 1, 3} {
+			a = bits.RotateLeft32(a+h(b, c, d)+x[j]+0x6ed9eba1, 3)
+			d = bits.RotateLeft32(d+h(a, b, c)+x[j+8]+0x6ed9eba1, 9)
+			c = bits.RotateLeft32(c+h(d, a, b)+x[j+4]+0x6ed9eba1, 11)
+			b = bits.RotateLeft32(b+h(c, d, a)+x[j+12]+0x6ed9eba1, 15)
+		}
+		a, b, c, d = a+aa, b+bb, c+cc, d+dd
+	}
+
+	var sum [16]byte
+	binary.LittleEndian.PutUint32(sum[0:], a)
+	binary.LittleEndian.PutUint32(sum[4:], b)
+	binary.LittleEndian.PutUint32(sum[8:], c)
+	binary.LittleEndian.PutUint32(sum[12:], d)
+	return sum
+}
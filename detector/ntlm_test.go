@@ -0,0 +1,115 @@
+package detector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+)
+
+func TestMD4(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+	for _, tt := range tests {
+		if got := md4Sum([]byte(tt.input)); hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("MD4(%q) = %x，期望 %s", tt.input, got, tt.want)
+		}
+	}
+
+	// MS-NLMP 4.2.4.1.1 测试向量
+	if got := hex.EncodeToString(ntowfv2("User", "Password", "Domain")); got != "0c868a403bfd7a93a3001ef22ef02e3f" {
+		t.Errorf("NTOWFv2 = %s", got)
+	}
+}
+
+// ntlmTestServer 模拟需要NTLM认证的服务器，校验认证消息中的NTLMv2响应
+func ntlmTestServer(user, password, domain string) *httptest.Server {
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	targetInfo := []byte{7, 0, 8, 0}
+	targetInfo = append(targetInfo, ntlmFileTime(time.Now())...)
+	targetInfo = append(targetInfo, 0, 0, 0, 0)
+
+	challenge := make([]byte, 48)
+	copy(challenge, ntlmSignature)
+	binary.LittleEndian.PutUint32(challenge[8:], 2)
+	binary.LittleEndian.PutUint32(challenge[20:], ntlmFlags)
+	copy(challenge[24:], serverChallenge)
+	binary.LittleEndian.PutUint16(challenge[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(challenge[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(challenge[44:], 48)
+	challenge = append(challenge, targetInfo...)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		msg, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "NTLM "))
+		switch {
+		case len(msg) >= 12 && binary.LittleEndian.Uint32(msg[8:]) == 1:
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case len(msg) >= 64 && binary.LittleEndian.Uint32(msg[8:]) == 3:
+			ntResponse, _ := ntlmField(msg, 20)
+			gotUser, _ := ntlmField(msg, 36)
+			key := ntowfv2(user, password, domain)
+			if len(ntResponse) > 16 && bytes.Equal(gotUser, utf16LE(user)) && bytes.Equal(hmacMD5(key, serverChallenge, ntResponse[16:]), ntResponse[:16]) {
+				w.Write([]byte("authenticated"))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", "NTLM")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+}
+
+func TestAuth(t *testing.T) {
+	ntlmServer := ntlmTestServer("admin", "P@ss:word", "CORP")
+	defer ntlmServer.Close()
+	basicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); ok && user == "admin" && pass == "secret" || r.Header.Get("Authorization") == "Bearer tok" {
+			w.Write([]byte("authenticated"))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer basicServer.Close()
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		auth   *config.Auth
+		body   string
+		want   int
+	}{
+		{"Basic", basicServer, &config.Auth{Scheme: config.AuthBasic, User: "admin", Password: "secret"}, "", 200},
+		{"Bearer", basicServer, &config.Auth{Scheme: config.AuthBearer, Token: "tok"}, "", 200},
+		{"未认证", basicServer, nil, "", 401},
+		{"NTLM", ntlmServer, &config.Auth{Scheme: config.AuthNTLM, Domain: "CORP", User: "admin", Password: "P@ss:word"}, "url=x", 200},
+		{"NTLM密码错误", ntlmServer, &config.Auth{Scheme: config.AuthNTLM, Domain: "CORP", User: "admin", Password: "wrong"}, "", 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(&config.Config{Timeout: 5, Authentication: tt.auth})
+			status, _, err := d.Fetch(context.Background(), http.MethodPost, tt.server.URL, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.want {
+				t.Errorf("状态码 %d，期望 %d", status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package detector
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// metaRefreshPattern 匹配 <meta http-equiv="refresh" content="0;url=..."> 标签
+var metaRefreshPattern = regexp.MustCompile(`(?is)<meta[^>]+http-equiv\s*=\s*["']?refresh["']?[^>]*>`)
+
+// metaContentPattern 提取 meta refresh 标签 content 属性中的跳转地址
+var metaContentPattern = regexp.MustCompile(`(?is)content\s*=\s*["']?\s*\d*\s*;?\s*url\s*=\s*['"]?([^"'>\s]+)`)
+
+// metaRefreshURL 返回响应体中 meta refresh 的跳转地址，没有时返回空字符串
+func metaRefreshURL(body string) string {
+	tag := metaRefreshPattern.FindString(body)
+	if tag == "" {
+		return ""
+	}
+	m := metaContentPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// redirectHost 按浏览器的处理方式解析跳转地址的主机：反斜杠视为斜杠，// 开头的地址使用请求的协议
+func redirectHost(location string) string {
+	location = strings.ReplaceAll(strings.TrimSpace(location), "\\", "/")
+	if strings.HasPrefix(location, "//") {
+		location = "http:" + location
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Hostname(), ".")
+}
+
+// openRedirectEvidence 检测开放重定向：目标的第一跳 Location 头或 meta refresh 跳转到payload中的外部域名
+// 只看目标自身返回的第一跳，-follow-redirects 跟随后的页面不属于目标的跳转
+func openRedirectEvidence(resp *http.Response, body string, chain []Redirect, testURL, payload string) Result {
+	payloadHost := redirectHost(payload)
+	if payloadHost == "" {
+		return Result{}
+	}
+	var targetHost string
+	if u, err := url.Parse(testURL); err == nil {
+		targetHost = u.Hostname()
+	}
+	if strings.EqualFold(payloadHost, targetHost) {
+		return Result{}
+	}
+
+	// 使用第一跳响应中 Location 头的原值，// 和 /\ 开头的地址也按浏览器的方式判断
+	location, source := "", "Location"
+	if len(chain) > 0 {
+		location = firstResponse(resp).Header.Get("Location")
+	} else if refresh := metaRefreshURL(body); refresh != "" {
+		location, source = refresh, "meta refresh"
+	}
+	if location == "" || !strings.EqualFold(redirectHost(location), payloadHost) {
+		return Result{}
+	}
+	return finding(EvidenceOpenRedirect, ConfidenceConfirmed, SeverityLow, "开放重定向: "+source+" 跳转到 "+location)
+}
+
+// firstResponse 沿重定向链回溯到目标返回的第一个响应
+func firstResponse(resp *http.Response) *http.Response {
+	for resp.Request != nil && resp.Request.Response != nil {
+		resp = resp.Request.Response
+	}
+	return resp
+}
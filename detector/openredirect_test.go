@@ -0,0 +1,94 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestOpenRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := r.URL.Query().Get("next")
+		switch r.URL.Path {
+		case "/location":
+			w.Header().Set("Location", next)
+			w.WriteHeader(http.StatusFound)
+		case "/meta":
+			w.Write([]byte(`<html><head><meta http-equiv="refresh" content="0; url=` + next + `"></head></html>`))
+		case "/fixed":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		payload string
+		enabled bool
+		want    bool
+	}{
+		{"Location反射完整URL", "/location", "https://www.example.com/", true, true},
+		{"Location反射协议相对地址", "/location", "//www.example.com/", true, true},
+		{"Location反射反斜杠地址", "/location", "/\\www.example.com/", true, true},
+		{"meta refresh反射", "/meta", "https://www.example.com/", true, true},
+		{"跳转到固定地址", "/fixed", "https://www.example.com/", true, false},
+		{"未启用-open-redirect", "/location", "https://www.example.com/", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(&config.Config{Timeout: 5, OpenRedirect: tt.enabled})
+			got := d.DetectWithMethod(context.Background(), http.MethodGet, server.URL+tt.path+"?next="+url.QueryEscape(tt.payload), "",
+				payloads.Payload{Value: tt.payload, Type: "开放重定向"}, nil)
+			if got.Error != "" {
+				t.Fatalf("请求失败: %s", got.Error)
+			}
+			if got.Vulnerable != tt.want {
+				t.Errorf("得到 %v (%s)，期望 %v", got.Vulnerable, got.Evidence, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRedirectFollowed(t *testing.T) {
+	// 跟随重定向后仍按第一跳的 Location 原值判断
+	var target *httptest.Server
+	target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/go" {
+			w.Header().Set("Location", target.URL+"/landing")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	d := NewDetector(&config.Config{Timeout: 5, OpenRedirect: true, FollowRedirects: 3})
+	payload := payloads.Payload{Value: "https://www.example.com/", Type: "开放重定向"}
+	if got := d.DetectWithMethod(context.Background(), http.MethodGet, target.URL+"/go", "", payload, nil); got.Vulnerable {
+		t.Errorf("跳转到目标自身不应判定: %s", got.Evidence)
+	}
+}
+
+func TestMetaRefreshURL(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`<meta http-equiv="refresh" content="0;url=https://www.example.com/">`, "https://www.example.com/"},
+		{`<META HTTP-EQUIV=Refresh CONTENT="5; URL='//www.example.com/'">`, "//www.example.com/"},
+		{`<meta name="viewport" content="width=device-width">`, ""},
+		{`<p>url=https://www.example.com/</p>`, ""},
+	}
+	for _, tt := range tests {
+		if got := metaRefreshURL(tt.body); got != tt.want {
+			t.Errorf("metaRefreshURL(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
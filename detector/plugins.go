@@ -0,0 +1,33 @@
+package detector
+
+import (
+	"fmt"
+	"net/http"
+
+	"gosssrf-client/payloads"
+	"gosssrf-client/plugins"
+)
+
+// pluginFinding 交给 -plugin 加载的插件分析响应，命中时证据前标注插件名称
+// 插件未指定或指定了不支持的严重程度/置信度时使用 medium/probable
+func pluginFinding(list []plugins.Plugin, resp *http.Response, body, testURL string, payload payloads.Payload) (Result, bool) {
+	p, f := plugins.Analyze(list, &plugins.Response{
+		URL:        testURL,
+		Payload:    payload,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+	if f == nil {
+		return Result{}, false
+	}
+
+	severity, confidence := f.Severity, f.Confidence
+	if !containsString(Severities, severity) {
+		severity = SeverityMedium
+	}
+	if !containsString(Confidences, confidence) {
+		confidence = ConfidenceProbable
+	}
+	return finding(EvidencePlugin, confidence, severity, fmt.Sprintf("[插件 %s] %s", p.Name(), f.Evidence)), true
+}
@@ -0,0 +1,69 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+	"gosssrf-client/plugins"
+)
+
+// bannerPlugin 响应中出现内部系统标识时判定为漏洞
+type bannerPlugin struct {
+	severity string
+}
+
+func (bannerPlugin) Name() string { return "banner" }
+
+func (bannerPlugin) ProvidePayloads(string) []payloads.Payload { return nil }
+
+func (p bannerPlugin) AnalyzeResponse(resp *plugins.Response) *plugins.Finding {
+	if resp.Header.Get("X-Internal") != "" || strings.Contains(resp.Body, "CorpERP") {
+		return &plugins.Finding{Severity: p.severity, Confidence: ConfidenceConfirmed, Evidence: "内部ERP系统"}
+	}
+	return nil
+}
+
+func TestPluginFinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "http://erp.corp/":
+			w.Write([]byte("CorpERP v3"))
+		case "file:///etc/passwd":
+			w.Write([]byte("root:x:0:0:root:/root:/bin/bash"))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		value    string
+		severity string
+		want     string // 期望的证据，为空表示未命中
+		wantSev  string
+	}{
+		{"插件命中", "http://erp.corp/", SeverityHigh, "[插件 banner] 内部ERP系统", SeverityHigh},
+		{"不支持的严重程度", "http://erp.corp/", "urgent", "[插件 banner] 内部ERP系统", SeverityMedium},
+		{"内置规则优先", "file:///etc/passwd", SeverityHigh, "响应中包含特征关键字: root:", SeverityCritical},
+		{"未命中", "http://other/", SeverityHigh, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Timeout: 5, Plugins: []plugins.Plugin{bannerPlugin{severity: tt.severity}}}
+			payload := payloads.Payload{Value: tt.value, Type: "文件读取", Keywords: []string{"root:"}}
+			got := NewDetector(cfg).DetectWithMethod(context.Background(), http.MethodGet, server.URL+"/?url="+tt.value, "", payload, nil)
+			if got.Evidence != tt.want || got.Vulnerable != (tt.want != "") {
+				t.Fatalf("证据 = %q，期望 %q", got.Evidence, tt.want)
+			}
+			if got.Severity != tt.wantSev {
+				t.Errorf("严重程度 = %s，期望 %s", got.Severity, tt.wantSev)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package detector
+
+import "strings"
+
+// 端口扫描payload推断的端口状态
+const (
+	PortOpen     = "open"     // 响应中有服务特征，或与基线和关闭端口的响应明显不同
+	PortFiltered = "filtered" // 目标连接端口超时
+	PortClosed   = "closed"   // 目标连接端口被拒绝，或与关闭端口的响应相同
+)
+
+// portErrorClass 目标连接端口失败时在响应中回显的一类错误信息
+type portErrorClass struct {
+	state   string   // 推断的端口状态，为空时表示无法判断
+	name    string   // 错误类型，作为推断依据输出
+	markers []string // 小写的错误信息特征（curl、Java、.NET、PHP、Python、Go、Node.js 等）
+}
+
+// portErrorClasses 按顺序匹配的错误类型，较具体的类型在前（例如 read timed out 在 timed out 之前）
+var portErrorClasses = []portErrorClass{
+	// 域名解析失败与端口无关；连接被重置可能是防火墙拒绝，也可能是服务不接受HTTP请求
+	{"", "域名解析失败", []string{"could not resolve", "name or service not known", "no such host", "getaddrinfo", "enotfound", "unknownhostexception", "nodename nor servname", "temporary failure in name resolution"}},
+	{"", "连接被重置", []string{"connection reset", "econnreset", "broken pipe", "connection was aborted"}},
+	{PortClosed, "连接被拒绝", []string{"connection refused", "econnrefused", "actively refused", "errno 111", "连接被拒绝"}},
+	// 已经建立连接，服务没有返回HTTP响应
+	{PortOpen, "读取响应超时", []string{"read timed out", "read timeout", "readtimeout"}},
+	{PortOpen, "非HTTP服务", []string{"empty reply from server", "badstatusline", "remotedisconnected", "remote end closed connection", "malformed http response", "invalid http response", "wrong version number", "http/0.9", "unexpected end of file from server", "expected http/", "hpe_invalid", "server returned nothing", "invalid status line", "socket hang up"}},
+	{PortFiltered, "主机不可达", []string{"no route to host", "ehostunreach", "host is unreachable", "network is unreachable", "enetunreach"}},
+	{PortFiltered, "连接超时", []string{"timed out", "etimedout", "esockettimedout", "i/o timeout", "timeout was reached", "deadline exceeded", "did not properly respond", "连接超时"}},
+}
+
+// SetClosedPort 记录注入点访问本机关闭端口的响应，用于推断端口扫描的端口状态，同时作为通用错误页面
+// 与基线响应几乎相同时无法区分端口状态，只作为通用错误页面
+func (b *Baseline) SetClosedPort(page *Baseline) {
+	if b == nil || page == nil {
+		return
+	}
+	b.AddErrorPage(page)
+	if page.StatusCode != b.StatusCode || similarity(page.signature, b.signature) < genericSimilarity {
+		b.closedPort = page
+	}
+}
+
+// portState 根据端口扫描payload的响应推断端口状态和推断依据，无法判断（例如响应与基线相同）时状态为空
+// 错误信息只在基线响应中没有时作为依据
+func portState(result Result, statusCode int, body string, baseline *Baseline) (string, string) {
+	if result.Vulnerable {
+		return PortOpen, "识别出服务特征"
+	}
+	if result.Blocked != "" {
+		return "", ""
+	}
+	if class, ok := portErrorClassOf(body, baseline); ok {
+		return class.state, class.name
+	}
+	if baseline == nil {
+		return "", ""
+	}
+	if page := baseline.closedPort; page != nil && page.StatusCode == statusCode && similarity(simhash(body), page.signature) >= genericSimilarity {
+		return PortClosed, "与关闭端口的响应相同"
+	}
+	if _, generic := baseline.Generic(statusCode, body); generic || !baseline.Differs(statusCode, body) {
+		return "", ""
+	}
+	// 存在关闭端口的响应可供对比时，与其不同的响应说明端口上有服务响应
+	if baseline.closedPort != nil {
+		return PortOpen, "与基线和关闭端口的响应不同"
+	}
+	return "", ""
+}
+
+// portErrorClassOf 返回响应中第一个匹配的错误类型，基线响应中已有的特征不匹配
+func portErrorClassOf(body string, baseline *Baseline) (portErrorClass, bool) {
+	lower := strings.ToLower(body)
+	for _, class := range portErrorClasses {
+		for _, marker := range class.markers {
+			if strings.Contains(lower, marker) && !baseline.Contains(marker) {
+				return class, true
+			}
+		}
+	}
+	return portErrorClass{}, false
+}
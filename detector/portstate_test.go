@@ -0,0 +1,68 @@
+package detector
+
+import "testing"
+
+// pageBaseline 创建带模糊哈希的基线响应
+func pageBaseline(status int, body string) *Baseline {
+	b := newBaseline(status, "", body)
+	b.signature = simhash(body)
+	return b
+}
+
+func TestPortState(t *testing.T) {
+	baseline := pageBaseline(500, "<html><body>error: Name or service not known</body></html>")
+	baseline.SetClosedPort(pageBaseline(500, "<html><body>error: could not connect to 127.0.0.1:1</body></html>"))
+
+	tests := []struct {
+		name     string
+		result   Result
+		status   int
+		body     string
+		baseline *Baseline
+		want     string
+	}{
+		{"识别出服务", Result{Vulnerable: true}, 200, "redis_version:6.2", baseline, PortOpen},
+		{"连接超时", Result{}, 500, "<html><body>error: Connection timed out</body></html>", baseline, PortFiltered},
+		{"连接被拒绝", Result{}, 500, "curl: (7) Failed to connect to 10.0.0.1 port 22: Connection refused", baseline, PortClosed},
+		{"与关闭端口的响应相同", Result{}, 500, "<html><body>error: could not connect to 10.0.0.1:22</body></html>", baseline, PortClosed},
+		{"Java 连接被拒绝", Result{}, 500, "java.net.ConnectException: Connection refused (Connection refused)", baseline, PortClosed},
+		{".NET 连接被拒绝", Result{}, 500, "No connection could be made because the target machine actively refused it", baseline, PortClosed},
+		{"Node.js 连接被拒绝", Result{}, 500, `{"error":"connect ECONNREFUSED 10.0.0.1:22"}`, baseline, PortClosed},
+		{"Go 连接超时", Result{}, 502, "dial tcp 10.0.0.1:22: i/o timeout", baseline, PortFiltered},
+		{".NET 连接超时", Result{}, 500, "A connection attempt failed because the connected party did not properly respond after a period of time", baseline, PortFiltered},
+		{"主机不可达", Result{}, 500, "connect: No route to host", baseline, PortFiltered},
+		{"读取响应超时", Result{}, 500, "java.net.SocketTimeoutException: Read timed out", baseline, PortOpen},
+		{"非HTTP服务", Result{}, 500, "curl: (52) Empty reply from server", baseline, PortOpen},
+		{"Python 非HTTP服务", Result{}, 500, "http.client.RemoteDisconnected: Remote end closed connection without response", baseline, PortOpen},
+		{"TLS端口", Result{}, 500, "SSL routines:ssl3_get_record:wrong version number", baseline, PortOpen},
+		{"域名解析失败", Result{}, 500, "curl: (6) Could not resolve host: internal.example", baseline, ""},
+		{"与基线相同", Result{}, 500, "<html><body>error: Name or service not known</body></html>", baseline, ""},
+		{"连接被重置", Result{}, 500, "<html><body>error: Connection reset by peer</body></html>", baseline, ""},
+		{"返回其他内容", Result{}, 200, `{"status":"ok","version":"1.2"}`, baseline, PortOpen},
+		{"被WAF拦截", Result{Blocked: "403"}, 403, "blocked", baseline, ""},
+		{"没有基线", Result{}, 200, "anything", nil, ""},
+		{"基线本身包含超时字样", Result{}, 500, "request timed out", pageBaseline(500, "request timed out"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := portState(tt.result, tt.status, tt.body, tt.baseline)
+			if got != tt.want {
+				t.Errorf("portState = %q (%s), want %q", got, reason, tt.want)
+			}
+			if got != "" && reason == "" {
+				t.Error("推断出端口状态时应该返回推断依据")
+			}
+		})
+	}
+}
+
+func TestSetClosedPortSameAsBaseline(t *testing.T) {
+	baseline := pageBaseline(500, "<html><body>请求失败</body></html>")
+	baseline.SetClosedPort(pageBaseline(500, "<html><body>请求失败</body></html>"))
+	if baseline.closedPort != nil {
+		t.Error("关闭端口的响应与基线相同时不能用于推断端口状态")
+	}
+	if len(baseline.errorPages) != 1 {
+		t.Error("关闭端口的响应仍然应该作为通用错误页面")
+	}
+}
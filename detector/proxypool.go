@@ -0,0 +1,136 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// maxProxyFailures 代理连续连接失败达到该次数后从代理池移除
+const maxProxyFailures = 3
+
+// errNoProxy 代理池中的代理均已移除
+var errNoProxy = errors.New("代理池中的代理均不可用")
+
+// proxyPool 代理池（-proxy-file）：每个请求按轮换方式选择代理，连续连接失败的代理自动移除
+type proxyPool struct {
+	mu       sync.Mutex
+	proxies  []*url.URL
+	failures map[string]int // 每个代理连续连接失败的次数
+	next     int
+	random   bool
+}
+
+// newProxyPool 创建代理池，random 为 true 时随机选择代理，否则轮流使用
+func newProxyPool(proxies []*url.URL, random bool) *proxyPool {
+	return &proxyPool{
+		proxies:  append([]*url.URL(nil), proxies...),
+		failures: make(map[string]int),
+		random:   random,
+	}
+}
+
+// proxySlotKey 请求上下文中记录所选代理的键
+type proxySlotKey struct{}
+
+// proxy 供 http.Transport.Proxy 使用，为请求选择代理并记录到请求上下文中
+func (p *proxyPool) proxy(req *http.Request) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.proxies) == 0 {
+		return nil, errNoProxy
+	}
+
+	var proxyURL *url.URL
+	if p.random {
+		proxyURL = p.proxies[rand.Intn(len(p.proxies))]
+	} else {
+		proxyURL = p.proxies[p.next%len(p.proxies)]
+		p.next++
+	}
+	if slot, ok := req.Context().Value(proxySlotKey{}).(**url.URL); ok {
+		*slot = proxyURL
+	}
+	return proxyURL, nil
+}
+
+// report 记录代理的请求结果，连续失败 maxProxyFailures 次后移除，返回代理池是否还有可用代理
+func (p *proxyPool) report(proxyURL *url.URL, failed bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := proxyURL.String()
+	if !failed {
+		delete(p.failures, key)
+		return true
+	}
+
+	p.failures[key]++
+	if p.failures[key] == maxProxyFailures {
+		for i, candidate := range p.proxies {
+			if candidate.String() == key {
+				p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+				slog.Warn(fmt.Sprintf("代理 %s 连续 %d 次连接失败，已从代理池移除（剩余 %d 个）", proxyURL.Redacted(), maxProxyFailures, len(p.proxies)))
+				break
+			}
+		}
+	}
+	return len(p.proxies) > 0
+}
+
+// proxyPoolTransport 记录每个请求使用的代理，连接代理失败时换一个代理重发
+type proxyPoolTransport struct {
+	base http.RoundTripper
+	pool *proxyPool
+}
+
+// RoundTrip 发送请求，代理连接失败时最多再换 maxProxyFailures-1 个代理
+func (t *proxyPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		var used *url.URL
+		attemptReq, err := rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		attemptReq = attemptReq.WithContext(context.WithValue(req.Context(), proxySlotKey{}, &used))
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if used == nil {
+			return resp, err
+		}
+		failed := err != nil && proxyFailure(err) && req.Context().Err() == nil
+		if !t.pool.report(used, failed) || !failed || attempt == maxProxyFailures {
+			return resp, err
+		}
+	}
+}
+
+// CloseIdleConnections 关闭底层 Transport 的空闲连接
+func (t *proxyPoolTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// proxyFailure 判断错误是否由代理本身不可用引起：无法连接代理或代理拒绝建立隧道
+// 使用代理时 dial 的对象就是代理；超时可能是目标响应慢，不计入
+func proxyFailure(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	switch opErr.Op {
+	case "dial", "proxyconnect", "socks connect":
+		return true
+	}
+	return false
+}
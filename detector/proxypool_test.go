@@ -0,0 +1,57 @@
+package detector
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+// closedAddr 返回一个没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestProxyPool(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "via proxy "+r.URL.String())
+	}))
+	defer proxy.Close()
+
+	live, _ := url.Parse(proxy.URL)
+	dead, _ := url.Parse("http://" + closedAddr(t))
+	d := NewDetector(&config.Config{Timeout: 5, ProxyList: []*url.URL{dead, live}, ProxyRotate: config.ProxyRoundRobin})
+	pool := d.client.Transport.(*proxyPoolTransport).pool
+
+	for i := 0; i < 6; i++ {
+		_, body, err := d.Fetch(context.Background(), http.MethodGet, "http://internal.invalid/x", "")
+		if err != nil {
+			t.Fatalf("第 %d 次请求: 连接代理失败时应换一个代理重发: %v", i+1, err)
+		}
+		if body != "via proxy http://internal.invalid/x" {
+			t.Errorf("第 %d 次请求得到 %q", i+1, body)
+		}
+	}
+	if len(pool.proxies) != 1 || pool.proxies[0] != live {
+		t.Errorf("连续连接失败的代理应被移除，剩余 %v", pool.proxies)
+	}
+
+	// 所有代理都不可用
+	d = NewDetector(&config.Config{Timeout: 5, ProxyList: []*url.URL{dead}})
+	for i := 0; i < 2; i++ {
+		if _, _, err := d.Fetch(context.Background(), http.MethodGet, "http://internal.invalid/x", ""); err == nil {
+			t.Fatalf("代理均不可用时应返回错误")
+		}
+	}
+}
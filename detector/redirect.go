@@ -0,0 +1,92 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redirect 重定向链中的一跳
+type Redirect struct {
+	StatusCode int    // 重定向响应的状态码
+	Location   string // 解析为绝对地址的 Location 头
+}
+
+// FormatRedirects 格式化重定向链，例如 302 http://a/ -> 301 http://b/
+func FormatRedirects(chain []Redirect) string {
+	parts := make([]string, len(chain))
+	for i, hop := range chain {
+		parts[i] = fmt.Sprintf("%d %s", hop.StatusCode, hop.Location)
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// redirectChainKey 请求上下文中记录重定向链的键
+type redirectChainKey struct{}
+
+// checkRedirect 最多跟随 limit 次重定向（-follow-redirects参数），跟随的每一跳记录到请求上下文中的重定向链
+func checkRedirect(limit int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > limit {
+			return http.ErrUseLastResponse
+		}
+		if chain, ok := req.Context().Value(redirectChainKey{}).(*[]Redirect); ok && req.Response != nil {
+			*chain = append(*chain, Redirect{StatusCode: req.Response.StatusCode, Location: req.URL.String()})
+		}
+		return nil
+	}
+}
+
+// do 发送请求并返回重定向链，最终响应仍为重定向（未跟随或达到次数上限）时也记录其 Location
+func (d *Detector) do(ctx context.Context, method, testURL, body string) (*http.Response, []Redirect, error) {
+	var chain []Redirect
+	resp, err := d.send(context.WithValue(ctx, redirectChainKey{}, &chain), method, testURL, body)
+	if err != nil {
+		return nil, chain, err
+	}
+	if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if u, err := resp.Request.URL.Parse(location); err == nil {
+			location = u.String()
+		}
+		chain = append(chain, Redirect{StatusCode: resp.StatusCode, Location: location})
+	}
+	return resp, chain, nil
+}
+
+// redirectEvidence 分析重定向链：跳转到内网地址说明目标请求的资源返回了指向内网的重定向
+// Location 中包含payload本身属于直接反射（开放重定向），跳转到目标自身或基线响应中已有的重定向属于目标站点的正常跳转，均不作为证据
+func redirectEvidence(chain []Redirect, testURL, payload string, baseline *Baseline) Result {
+	var targetHost string
+	if u, err := url.Parse(testURL); err == nil {
+		targetHost = u.Hostname()
+	}
+	for _, hop := range chain {
+		if strings.Contains(hop.Location, payload) || baseline.HasRedirect(hop.Location) {
+			continue
+		}
+		u, err := url.Parse(hop.Location)
+		if err != nil || u.Hostname() == targetHost || !isInternalHost(u.Hostname()) {
+			continue
+		}
+		return finding(EvidenceRedirect, ConfidenceProbable, SeverityMedium, "重定向到内网地址: "+FormatRedirects(chain))
+	}
+	return Result{}
+}
+
+// internalHostnames 指向内网或云元数据服务的常见域名
+var internalHostnames = []string{"localhost", "metadata.google.internal", "metadata.tencentyun.com", "kubernetes.default.svc"}
+
+// isInternalHost 判断主机是否为回环、私有、链路本地（云元数据）或未指定地址
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, name := range internalHostnames {
+		if host == name || strings.HasSuffix(host, "."+name) {
+			return true
+		}
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified())
+}
@@ -0,0 +1,103 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestRedirectChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+		case "/c":
+			w.Write([]byte("done"))
+		case "/meta":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		case "/reflect":
+			http.Redirect(w, r, r.URL.Query().Get("url"), http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		follow    int
+		path      string
+		payload   string
+		status    int
+		redirects string
+		want      bool
+	}{
+		{"默认不跟随", 0, "/a", "http://example.com/", 302, "302 " + server.URL + "/b", false},
+		{"跟随到最终页面", 5, "/a", "http://example.com/", 200, "302 " + server.URL + "/b -> 301 " + server.URL + "/c", false},
+		{"达到跟随次数上限", 1, "/a", "http://example.com/", 301, "302 " + server.URL + "/b -> 301 " + server.URL + "/c", false},
+		{"重定向到元数据地址", 0, "/meta", "http://example.com/", 302, "302 http://169.254.169.254/latest/meta-data/", true},
+		{"直接反射payload不判定", 0, "/reflect?url=http://127.0.0.1/", "http://127.0.0.1/", 302, "302 http://127.0.0.1/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(&config.Config{Timeout: 5, FollowRedirects: tt.follow})
+			got := d.DetectWithMethod(context.Background(), http.MethodGet, server.URL+tt.path, "",
+				payloads.Payload{Value: tt.payload, Type: "内网探测"}, nil)
+			if got.Error != "" {
+				t.Fatalf("请求失败: %s", got.Error)
+			}
+			if got.StatusCode != tt.status {
+				t.Errorf("状态码 = %d, want %d", got.StatusCode, tt.status)
+			}
+			if chain := FormatRedirects(got.Redirects); chain != tt.redirects {
+				t.Errorf("重定向链 = %q, want %q", chain, tt.redirects)
+			}
+			if got.Vulnerable != tt.want {
+				t.Errorf("得到 %v (%s)，期望 %v", got.Vulnerable, got.Evidence, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedirectEvidence(t *testing.T) {
+	chain := []Redirect{{StatusCode: 302, Location: "http://127.0.0.1/login"}}
+	baseline := &Baseline{redirects: chain}
+	const testURL = "http://target.example.com/api?url=http%3A%2F%2Fexample.com%2F"
+	if got := redirectEvidence(chain, testURL, "http://example.com/", baseline); got.Vulnerable {
+		t.Errorf("基线中已有的重定向不应判定: %s", got.Evidence)
+	}
+	if got := redirectEvidence(chain, testURL, "http://example.com/", nil); !got.Vulnerable {
+		t.Error("没有基线时重定向到内网地址应判定")
+	}
+	if got := redirectEvidence(chain, "http://127.0.0.1:8080/api", "http://example.com/", nil); got.Vulnerable {
+		t.Errorf("跳转到目标自身不应判定: %s", got.Evidence)
+	}
+}
+
+func TestIsInternalHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"10.0.0.5", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"::1", true},
+		{"0.0.0.0", true},
+		{"localhost", true},
+		{"metadata.google.internal.", true},
+		{"8.8.8.8", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		if got := isInternalHost(tt.host); got != tt.want {
+			t.Errorf("isInternalHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
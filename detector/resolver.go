@@ -0,0 +1,133 @@
+package detector
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"gosssrf-client/config"
+)
+
+// DNS缓存时间：解析成功的结果缓存较长时间，解析失败的结果短时间缓存，避免每个payload都重复等待超时
+const (
+	dnsCacheTTL    = 5 * time.Minute
+	dnsNegativeTTL = 30 * time.Second
+)
+
+// resolver 扫描器自身的域名解析：-resolve 指定的静态解析优先，其次是缓存，
+// 最后通过 -resolver 指定的DNS服务器（未指定时使用系统解析）查询，同一域名同时只发出一次查询
+type resolver struct {
+	hosts   map[string]string
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	timeout time.Duration // 单次查询的超时时间（-timeout）
+	mu      sync.Mutex
+	cache   map[string]*dnsEntry
+}
+
+// dnsEntry 一个域名的解析结果，ready 关闭后 addrs 和 err 可读
+type dnsEntry struct {
+	ready   chan struct{}
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// newResolver 根据 -resolver 和 -resolve 参数创建域名解析器
+func newResolver(cfg *config.Config) *resolver {
+	dns := net.DefaultResolver
+	if cfg.Resolver != "" {
+		server := cfg.Resolver
+		dns = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return &resolver{
+		hosts:   cfg.ResolveHosts,
+		lookup:  dns.LookupHost,
+		timeout: time.Duration(cfg.Timeout) * time.Second,
+		cache:   make(map[string]*dnsEntry),
+	}
+}
+
+// resolve 返回域名的IP地址列表，IP地址直接返回
+func (r *resolver) resolve(ctx context.Context, host string) ([]string, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	if ip, ok := r.hosts[host]; ok {
+		return []string{ip}, nil
+	}
+
+	r.mu.Lock()
+	entry, ok := r.cache[host]
+	if ok && !isExpired(entry) {
+		r.mu.Unlock()
+		select {
+		case <-entry.ready:
+			return entry.addrs, entry.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	entry = &dnsEntry{ready: make(chan struct{})}
+	r.cache[host] = entry
+	r.mu.Unlock()
+
+	// 查询不跟随单个请求取消，每个等待的请求各自响应取消
+	lookupCtx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		lookupCtx, cancel = context.WithTimeout(lookupCtx, r.timeout)
+	}
+	entry.addrs, entry.err = r.lookup(lookupCtx, host)
+	cancel()
+	ttl := dnsCacheTTL
+	if entry.err != nil {
+		ttl = dnsNegativeTTL
+	}
+	r.mu.Lock()
+	entry.expires = time.Now().Add(ttl)
+	r.mu.Unlock()
+	close(entry.ready)
+	return entry.addrs, entry.err
+}
+
+// isExpired 判断缓存的解析结果是否过期（需持有 mu），查询中的结果不过期
+func isExpired(entry *dnsEntry) bool {
+	return !entry.expires.IsZero() && time.Now().After(entry.expires)
+}
+
+// dialContext 解析域名后依次连接每个地址，直到连接成功
+func (r *resolver) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := r.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if lastErr == nil {
+			lastErr = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, lastErr
+	}
+}
@@ -0,0 +1,91 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestResolverStaticHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("host=" + r.Host))
+	}))
+	defer server.Close()
+
+	// staging.invalid 不在公共DNS中，通过 -resolve 指向本机
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	cfg := &config.Config{Timeout: 5, ResolveHosts: map[string]string{"staging.invalid": "127.0.0.1"}}
+	d := NewDetector(cfg)
+	status, body, err := d.Fetch(context.Background(), http.MethodGet, "http://STAGING.invalid:"+port+"/", "")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if status != http.StatusOK || body != "host=STAGING.invalid:"+port {
+		t.Errorf("Fetch() = %d %q，期望保留原始Host头", status, body)
+	}
+}
+
+func TestResolverCache(t *testing.T) {
+	var calls int32
+	r := &resolver{
+		cache: make(map[string]*dnsEntry),
+		lookup: func(ctx context.Context, host string) ([]string, error) {
+			atomic.AddInt32(&calls, 1)
+			if host == "missing.example" {
+				return nil, errors.New("no such host")
+			}
+			return []string{"10.0.0.5"}, nil
+		},
+	}
+
+	// 并发解析同一域名只查询一次
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if addrs, err := r.resolve(context.Background(), "api.example."); err != nil || len(addrs) != 1 || addrs[0] != "10.0.0.5" {
+				t.Errorf("resolve() = %v, %v", addrs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := r.resolve(context.Background(), "API.example"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("查询次数 = %d，期望缓存后只查询1次", calls)
+	}
+
+	// 解析失败的结果同样缓存
+	for i := 0; i < 2; i++ {
+		if _, err := r.resolve(context.Background(), "missing.example"); err == nil {
+			t.Error("resolve() 期望返回解析失败")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("查询次数 = %d，期望解析失败的结果也被缓存", calls)
+	}
+
+	// IP地址不查询
+	if addrs, _ := r.resolve(context.Background(), "192.168.1.1"); len(addrs) != 1 || calls != 2 {
+		t.Errorf("resolve(IP) = %v，查询次数 %d", addrs, calls)
+	}
+}
+
+func TestResolverDialFailure(t *testing.T) {
+	cfg := &config.Config{Timeout: 5, ResolveHosts: map[string]string{"down.invalid": "127.0.0.1"}}
+	d := NewDetector(cfg)
+	// 端口1一般无服务监听，连接被拒绝而不是域名解析失败
+	_, _, err := d.Fetch(context.Background(), http.MethodGet, "http://down.invalid:1/", "")
+	if err == nil || strings.Contains(err.Error(), "no such host") {
+		t.Errorf("Fetch() error = %v，期望连接失败", err)
+	}
+}
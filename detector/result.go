@@ -0,0 +1,145 @@
+package detector
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"gosssrf-client/payloads"
+)
+
+// 置信度
+const (
+	ConfidenceConfirmed = "confirmed" // 响应中出现payload对应的特征，或收到实际回连
+	ConfidenceProbable  = "probable"  // 响应与基线存在差异并出现内网服务/敏感信息特征
+	ConfidenceTentative = "tentative" // 仅有状态码、响应头等间接迹象
+)
+
+// 严重程度
+const (
+	SeverityCritical = "critical" // 凭据泄露、任意文件读取
+	SeverityHigh     = "high"     // 可访问内网服务或云元数据
+	SeverityMedium   = "medium"   // 可访问内网HTTP服务、无回显SSRF
+	SeverityLow      = "low"      // 内网信息泄露
+	SeverityInfo     = "info"     // 401/403等需人工确认的提示
+)
+
+// 证据类别，-severity-map 按类别覆盖默认的严重程度
+const (
+	EvidenceKeyword      = "keyword"       // 响应中出现payload的特征关键字
+	EvidenceCredential   = "credential"    // 命中的特征关键字属于凭据（AccessKeyId、password 等）
+	EvidenceHTTPService  = "http-service"  // 端口扫描访问到内网HTTP服务
+	EvidenceService      = "service"       // 端口扫描出现内网服务特征
+	EvidenceFileRead     = "file-read"     // 文件读取payload的响应较长
+	EvidenceSensitive    = "sensitive"     // 响应中出现敏感信息关键字
+	EvidenceAuthStatus   = "auth-status"   // 401/403状态码
+	EvidenceServerHeader = "server-header" // Server头泄露内网服务
+	EvidenceCustomRule   = "custom-rule"   // 匹配 -match-* 自定义规则
+	EvidenceRedirect     = "redirect"      // 重定向到内网地址
+	EvidenceOpenRedirect = "open-redirect" // 开放重定向
+	EvidenceGraphQL      = "graphql"       // GraphQL错误信息显示发起了请求
+	EvidencePlugin       = "plugin"        // 自定义插件判定
+	EvidenceScript       = "script"        // 钩子脚本判定
+	EvidenceOOBSent      = "oob-sent"      // 已发送OOB请求（未使用内置OOB服务）
+	EvidenceOOB          = "oob"           // 收到OOB回连
+	EvidenceTiming       = "timing"        // 时间盲注
+	EvidenceSecret       = "secret"        // 响应中泄露敏感信息（-secret-rules）
+)
+
+// EvidenceKinds 全部证据类别
+var EvidenceKinds = []string{
+	EvidenceKeyword, EvidenceCredential, EvidenceHTTPService, EvidenceService, EvidenceFileRead, EvidenceSensitive,
+	EvidenceAuthStatus, EvidenceServerHeader, EvidenceCustomRule, EvidenceRedirect, EvidenceOpenRedirect,
+	EvidenceGraphQL, EvidencePlugin, EvidenceScript, EvidenceOOBSent, EvidenceOOB, EvidenceTiming, EvidenceSecret,
+}
+
+// Severities 严重程度从高到低排列
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// Confidences 置信度从高到低排列
+var Confidences = []string{ConfidenceConfirmed, ConfidenceProbable, ConfidenceTentative}
+
+// credentialKeywords 出现即视为凭据泄露的关键字
+var credentialKeywords = []string{
+	"AccessKeyId", "SecretAccessKey", "AccessKeySecret", "access_token", "Token",
+	"password", "secret", "api_key",
+}
+
+// maxResponseExcerpt 检测结果中保存的响应内容长度上限
+const maxResponseExcerpt = 2048
+
+// responseExcerpt 截取响应内容片段，避免截断多字节字符
+func responseExcerpt(body string) string {
+	if len(body) <= maxResponseExcerpt {
+		return body
+	}
+	end := maxResponseExcerpt
+	for end > 0 && !utf8.RuneStart(body[end]) {
+		end--
+	}
+	return body[:end] + "\n...（已截断，共 " + strconv.Itoa(len(body)) + " 字节）"
+}
+
+// Result 单次检测结果
+type Result struct {
+	Vulnerable   bool
+	Evidence     string
+	Kind         string // 证据类别（EvidenceKeyword 等），用于 -severity-map 按类别调整严重程度
+	Confidence   string
+	Severity     string
+	StatusCode   int
+	ResponseLen  int
+	ResponseTime int64
+	Response     string       // 响应内容片段（最多 maxResponseExcerpt 字节），用于报告中的证据
+	Redirects    []Redirect   // 重定向链（包括未跟随的最后一次重定向）
+	Blocked      string       // 请求被WAF或过滤规则拦截的原因，未拦截时为空
+	Suppressed   string       // 因响应与通用错误页面几乎相同而忽略的证据
+	Exchange     string       // 完整的请求和响应（指定 -evidence-dir 时只在发现漏洞或敏感信息时保存）
+	Credentials  []Credential // 从元数据响应中提取的临时凭据
+	Secrets      []Secret     // 响应中匹配敏感信息规则的内容（与是否判定为漏洞无关）
+	PortState    string       // 端口扫描payload推断的端口状态（PortOpen/PortFiltered/PortClosed），无法判断时为空
+	PortReason   string       // 推断端口状态的依据，例如 连接被拒绝
+	Service      string       // 端口扫描识别出的内网服务和版本（例如 Redis 7.0.11），无法识别时为空
+	WebService   bool         // 端口扫描访问到内网Web服务，可以继续请求该服务的其他路径
+	Error        string       // 请求失败的原因，请求成功时为空
+	Canceled     bool         // 请求因扫描取消（超过最大扫描时间）而中止
+}
+
+// finding 构造判定为漏洞的检测结果
+func finding(kind, confidence, severity, evidence string) Result {
+	return Result{Vulnerable: true, Kind: kind, Confidence: confidence, Severity: severity, Evidence: evidence}
+}
+
+// keywordKind 命中的特征关键字对应的证据类别
+func keywordKind(keyword string) string {
+	if containsAny(keyword, credentialKeywords) {
+		return EvidenceCredential
+	}
+	return EvidenceKeyword
+}
+
+// keywordSeverity 根据payload类型和命中的关键字确定严重程度
+func keywordSeverity(payloadType, keyword string) string {
+	if containsAny(keyword, credentialKeywords) {
+		return SeverityCritical
+	}
+	switch payloadType {
+	case "文件读取", "Docker API": // 未授权的Docker API可直接创建特权容器控制宿主机
+		return SeverityCritical
+	case payloads.TypeElasticsearch: // 可以直接读取索引中的业务数据
+		return SeverityCritical
+	case payloads.TypeConsul, payloads.TypeEtcd: // KV存储中通常保存数据库密码等配置
+		if containsAny(keyword, payloads.KVListingKeywords) {
+			return SeverityCritical
+		}
+		return SeverityHigh
+	case payloads.TypeAdmin: // Actuator 环境变量和堆转储中包含应用的密码、密钥
+		if containsAny(keyword, payloads.AdminSecretKeywords) {
+			return SeverityCritical
+		}
+		return SeverityHigh
+	case "端口扫描", "内网探测", payloads.TypeInternalHost:
+		return SeverityMedium
+	default:
+		return SeverityHigh
+	}
+}
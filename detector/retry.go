@@ -0,0 +1,48 @@
+package detector
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// retryBackoff 第一次重试前的等待时间，之后每次重试递增
+var retryBackoff = time.Second
+
+// doRetry 发送请求，连接失败、超时或目标返回429时按 -retries 重发
+func (d *Detector) doRetry(req *http.Request) (*http.Response, error) {
+	if d.config.Retries <= 0 {
+		return d.doSession(req)
+	}
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := rewindRequest(req)
+		if err != nil {
+			return d.doSession(req)
+		}
+		resp, err := d.doSession(attemptReq)
+		if attempt > d.config.Retries || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		// 重发前清空上一次请求记录的重定向链
+		if chain, ok := ctx.Value(redirectChainKey{}).(*[]Redirect); ok {
+			*chain = (*chain)[:0]
+		}
+		select {
+		case <-time.After(time.Duration(attempt) * retryBackoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable 判断是否需要重发：请求出错（代理池已无可用代理时除外）或目标返回429限流
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, errNoProxy)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,59 @@
+package detector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+)
+
+func TestRetry(t *testing.T) {
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		retries  int
+		failures int // 服务端先返回429的次数
+		want     int // 期望的状态码
+		requests int // 期望服务端收到的请求数
+	}{
+		{"不重试", 0, 1, http.StatusTooManyRequests, 1},
+		{"重试后成功", 2, 2, http.StatusOK, 3},
+		{"重试次数用完", 1, 3, http.StatusTooManyRequests, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if body, _ := io.ReadAll(r.Body); string(body) != "url=x" {
+					t.Errorf("重发的请求体为 %q", body)
+				}
+				if requests <= tt.failures {
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}))
+			defer server.Close()
+
+			d := NewDetector(&config.Config{Timeout: 5, Retries: tt.retries})
+			status, _, err := d.Fetch(context.Background(), http.MethodPost, server.URL, "url=x")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.want || requests != tt.requests {
+				t.Errorf("得到状态码 %d、%d 次请求，期望 %d、%d 次请求", status, requests, tt.want, tt.requests)
+			}
+		})
+	}
+
+	// 连接失败时重试后仍返回错误
+	d := NewDetector(&config.Config{Timeout: 5, Retries: 2})
+	if _, _, err := d.Fetch(context.Background(), http.MethodGet, "http://"+closedAddr(t)+"/", ""); err == nil {
+		t.Error("连接失败时应返回错误")
+	}
+}
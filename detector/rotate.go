@@ -0,0 +1,23 @@
+package detector
+
+import (
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+
+	"gosssrf-client/config"
+)
+
+// rotateHeaders 为请求设置轮换的Header：-random-agent 随机User-Agent，-header-sets 按请求顺序轮流使用Header组
+// 两者都覆盖Header.txt中的同名Header，Header组中的User-Agent优先于随机User-Agent
+func (d *Detector) rotateHeaders(req *http.Request) {
+	if d.config.RandomAgent {
+		req.Header.Set("User-Agent", config.UserAgents[rand.Intn(len(config.UserAgents))])
+	}
+	if sets := d.config.HeaderSets; len(sets) > 0 {
+		n := atomic.AddUint64(&d.headerSeq, 1) - 1
+		for name, value := range sets[n%uint64(len(sets))] {
+			req.Header.Set(name, value)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestRotateHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("X-Team")))
+	}))
+	defer server.Close()
+
+	fetch := func(d *Detector) string {
+		_, body, err := d.Fetch(context.Background(), http.MethodGet, server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	// Header组按请求顺序轮换，并覆盖Header.txt中的同名Header
+	d := NewDetector(&config.Config{
+		Timeout:       5,
+		RandomAgent:   true,
+		CustomHeaders: map[string]string{"User-Agent": "GoSSRF", "X-Team": "default"},
+		HeaderSets:    []map[string]string{{"X-Team": "a"}, {"X-Team": "b", "User-Agent": "curl/8.0"}},
+	})
+	for i, want := range []string{"a", "b", "a"} {
+		body := fetch(d)
+		if got := body[len(body)-1:]; got != want {
+			t.Errorf("第 %d 次请求使用Header组 %s，期望 %s", i+1, got, want)
+		}
+		if i == 1 && body != "curl/8.0|b" {
+			t.Errorf("Header组中的User-Agent应优先于随机User-Agent，得到 %q", body)
+		}
+	}
+
+	// 随机User-Agent取自内置列表
+	known := make(map[string]bool)
+	for _, ua := range config.UserAgents {
+		known[ua+"|"] = true
+	}
+	d = NewDetector(&config.Config{Timeout: 5, RandomAgent: true})
+	for i := 0; i < 5; i++ {
+		if body := fetch(d); !known[body] {
+			t.Errorf("User-Agent %q 不在内置列表中", body)
+		}
+	}
+}
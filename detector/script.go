@@ -0,0 +1,232 @@
+package detector
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gosssrf-client/payloads"
+)
+
+// scriptHook 请求和响应钩子脚本（-script参数）
+// 脚本作为常驻子进程运行，每个钩子向标准输入写入一行JSON，脚本在标准输出回复一行JSON
+type scriptHook struct {
+	cmd    *exec.Cmd
+	mu     sync.Mutex // 同一时间只有一个钩子在等待回复
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// hookRequest 发送请求前传给脚本的内容，回复中出现的字段覆盖原请求（headers 逐个设置，值为空时删除）
+type hookRequest struct {
+	Hook    string            `json:"hook"` // request
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// hookRequestReply 脚本对请求钩子的回复
+type hookRequestReply struct {
+	Method  *string           `json:"method"`
+	URL     *string           `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    *string           `json:"body"`
+}
+
+// hookResponse 收到payload的响应后传给脚本的内容
+type hookResponse struct {
+	Hook        string            `json:"hook"` // response
+	URL         string            `json:"url"`
+	Payload     string            `json:"payload"`
+	PayloadType string            `json:"payload_type"`
+	Status      int               `json:"status"`
+	Headers     map[string]string `json:"headers"`
+	Body        string            `json:"body"`
+	Vulnerable  bool              `json:"vulnerable"` // 内置规则和插件的判定结果
+	Evidence    string            `json:"evidence"`
+}
+
+// hookVerdict 脚本对响应钩子的回复，vulnerable 为空时保持原判定
+type hookVerdict struct {
+	Vulnerable *bool  `json:"vulnerable"`
+	Severity   string `json:"severity"`
+	Confidence string `json:"confidence"`
+	Evidence   string `json:"evidence"`
+}
+
+// StartScript 启动 -script 指定的钩子脚本，扫描结束后调用 Close 结束脚本
+func (d *Detector) StartScript() error {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.Command(shell, flag, d.config.Script)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("启动钩子脚本失败: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("启动钩子脚本失败: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动钩子脚本失败: %v", err)
+	}
+	d.hooks = &scriptHook{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}
+	return nil
+}
+
+// Close 结束钩子脚本：关闭标准输入后等待脚本退出，2秒内未退出时强制结束
+func (d *Detector) Close() error {
+	if d.hooks == nil {
+		return nil
+	}
+	h := d.hooks
+	d.hooks = nil
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- h.cmd.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		h.cmd.Process.Kill()
+		<-done
+	}
+	return nil
+}
+
+// call 向脚本发送一条消息并读取回复
+func (h *scriptHook) call(msg, reply interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := h.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("钩子脚本已退出: %v", err)
+	}
+	line, err := h.stdout.ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("钩子脚本已退出")
+		}
+		return fmt.Errorf("读取钩子脚本输出失败: %v", err)
+	}
+	if err := json.Unmarshal(line, reply); err != nil {
+		return fmt.Errorf("钩子脚本输出不是有效的JSON: %s", strings.TrimSpace(string(line)))
+	}
+	return nil
+}
+
+// request 发送前交给脚本修改请求（例如计算签名、添加HMAC头）
+func (h *scriptHook) request(req *http.Request, body string) error {
+	msg := hookRequest{Hook: "request", Method: req.Method, URL: req.URL.String(), Headers: flattenHeader(req.Header), Body: body}
+	var reply hookRequestReply
+	if err := h.call(msg, &reply); err != nil {
+		return err
+	}
+
+	if reply.Method != nil && *reply.Method != "" {
+		req.Method = *reply.Method
+	}
+	if reply.URL != nil && *reply.URL != "" {
+		u, err := req.URL.Parse(*reply.URL)
+		if err != nil {
+			return fmt.Errorf("钩子脚本返回的地址无效: %v", err)
+		}
+		req.URL, req.Host = u, u.Host
+	}
+	for name, value := range reply.Headers {
+		if value == "" {
+			req.Header.Del(name)
+			continue
+		}
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if reply.Body != nil {
+		body := *reply.Body
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+	}
+	return nil
+}
+
+// evaluate 交给脚本判定payload的响应，脚本可以确认新的漏洞或推翻内置规则的判定
+func (h *scriptHook) evaluate(result Result, resp *http.Response, body, testURL string, payload payloads.Payload) (Result, error) {
+	msg := hookResponse{
+		Hook:        "response",
+		URL:         testURL,
+		Payload:     payload.Value,
+		PayloadType: payload.Type,
+		Status:      resp.StatusCode,
+		Headers:     flattenHeader(resp.Header),
+		Body:        body,
+		Vulnerable:  result.Vulnerable,
+		Evidence:    result.Evidence,
+	}
+	var verdict hookVerdict
+	if err := h.call(msg, &verdict); err != nil {
+		return result, err
+	}
+
+	switch {
+	case verdict.Vulnerable == nil:
+		return result, nil
+	case !*verdict.Vulnerable:
+		return Result{}, nil
+	}
+	// 未指定的字段沿用原判定，原判定未命中时使用 medium/probable
+	if !result.Vulnerable {
+		result = finding(EvidenceScript, ConfidenceProbable, SeverityMedium, "[脚本] 钩子脚本判定为漏洞")
+	}
+	if containsString(Severities, verdict.Severity) {
+		result.Severity = verdict.Severity
+	}
+	if containsString(Confidences, verdict.Confidence) {
+		result.Confidence = verdict.Confidence
+	}
+	if verdict.Evidence != "" {
+		result.Evidence = "[脚本] " + verdict.Evidence
+	}
+	return result, nil
+}
+
+// flattenHeader 将HTTP头转换为 名称 -> 值 的映射，同名头用逗号连接
+func flattenHeader(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
+
+// containsString 判断列表中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
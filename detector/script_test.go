@@ -0,0 +1,121 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+// sign 测试用的请求签名：HMAC-SHA256(方法 + 地址 + 请求体)
+func sign(method, url, body string) string {
+	mac := hmac.New(sha256.New, []byte("k"))
+	mac.Write([]byte(method + url + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestScriptHelper 作为钩子脚本运行（由 StartScript 启动的子进程），普通测试时直接返回
+func TestScriptHelper(t *testing.T) {
+	if os.Getenv("GOSSRF_SCRIPT_HELPER") != "1" {
+		t.Skip("只作为钩子脚本运行")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg map[string]interface{}
+		json.Unmarshal(scanner.Bytes(), &msg)
+		reply := map[string]interface{}{}
+		switch msg["hook"] {
+		case "request":
+			url, body := msg["url"].(string), msg["body"].(string)
+			reply["headers"] = map[string]string{"X-Signature": sign(msg["method"].(string), url, body), "X-Remove": ""}
+		case "response":
+			body := msg["body"].(string)
+			switch {
+			case strings.Contains(body, "INTERNAL"):
+				reply = map[string]interface{}{"vulnerable": true, "severity": "high", "evidence": "内部系统标识"}
+			case strings.Contains(msg["payload"].(string), "decoy"):
+				reply = map[string]interface{}{"vulnerable": false}
+			}
+		}
+		data, _ := json.Marshal(reply)
+		fmt.Println(string(data))
+	}
+	os.Exit(0)
+}
+
+func TestScriptHooks(t *testing.T) {
+	t.Setenv("GOSSRF_SCRIPT_HELPER", "1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := sign(r.Method, "http://"+r.Host+r.URL.RequestURI(), string(body))
+		if r.Header.Get("X-Signature") != want || r.Header.Get("X-Remove") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("url") {
+		case "http://erp.corp/":
+			w.Write([]byte("INTERNAL ERP"))
+		default:
+			w.Write([]byte("root:x:0:0:root:/root:/bin/bash"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Timeout:       5,
+		Script:        os.Args[0] + " -test.run=^TestScriptHelper$",
+		CustomHeaders: map[string]string{"X-Remove": "1"},
+	}
+	d := NewDetector(cfg)
+	if err := d.StartScript(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	tests := []struct {
+		name     string
+		value    string
+		want     string // 期望的证据，为空表示未命中
+		severity string
+	}{
+		{"脚本确认漏洞", "http://erp.corp/", "[脚本] 内部系统标识", SeverityHigh},
+		{"脚本未判定时保持内置规则", "file:///etc/passwd", "响应中包含特征关键字: root:", SeverityCritical},
+		{"脚本推翻内置规则", "file:///decoy", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := payloads.Payload{Value: tt.value, Type: "文件读取", Keywords: []string{"root:"}}
+			got := d.DetectWithMethod(context.Background(), http.MethodPost, server.URL+"/?url="+tt.value, "a=1", payload, nil)
+			if got.StatusCode != http.StatusOK {
+				t.Fatalf("请求签名未通过校验: 状态码 %d %s", got.StatusCode, got.Error)
+			}
+			if got.Evidence != tt.want || got.Vulnerable != (tt.want != "") || got.Severity != tt.severity {
+				t.Errorf("得到 %q (%s)，期望 %q (%s)", got.Evidence, got.Severity, tt.want, tt.severity)
+			}
+		})
+	}
+}
+
+func TestScriptExited(t *testing.T) {
+	d := NewDetector(&config.Config{Timeout: 5, Script: "exit 0"})
+	if err := d.StartScript(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if _, _, err := d.Fetch(context.Background(), http.MethodGet, "http://127.0.0.1:1/", ""); err == nil || !strings.Contains(err.Error(), "钩子脚本已退出") {
+		t.Errorf("脚本退出后请求应返回错误，得到 %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package detector
+
+import (
+	"strings"
+
+	"gosssrf-client/config"
+)
+
+// maxSecretsPerResponse 每个响应最多报告的敏感信息数量
+const maxSecretsPerResponse = 10
+
+// Secret 响应中匹配敏感信息规则的内容
+type Secret struct {
+	Rule     string // 规则名称
+	Severity string
+	Value    string // 匹配到的内容
+}
+
+// findSecrets 按敏感信息规则检查响应，基线响应和payload本身已包含的内容不报告
+func findSecrets(rules []config.SecretRule, body, payload string, baseline *Baseline) []Secret {
+	var secrets []Secret
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		for _, value := range rule.Pattern.FindAllString(body, maxSecretsPerResponse) {
+			if seen[value] || baseline.Contains(value) || strings.Contains(payload, value) {
+				continue
+			}
+			seen[value] = true
+			secrets = append(secrets, Secret{Rule: rule.Name, Severity: rule.Severity, Value: value})
+			if len(secrets) == maxSecretsPerResponse {
+				return secrets
+			}
+		}
+	}
+	return secrets
+}
+
+// MaskSecret 隐藏敏感信息的中间部分，只保留首尾各4个字符
+func MaskSecret(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 12 {
+		return string(runes[:len(runes)/3]) + "****"
+	}
+	return string(runes[:4]) + "****" + string(runes[len(runes)-4:])
+}
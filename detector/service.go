@@ -0,0 +1,107 @@
+package detector
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gosssrf-client/payloads"
+)
+
+// serviceRule 内网服务指纹：正则匹配端口扫描的响应体（server 为 true 时匹配 Server 头），第一个非空子匹配为版本号
+type serviceRule struct {
+	service string
+	web     bool // 内网Web应用，证据类别为 http-service；否则为数据库、缓存等服务，证据类别为 service
+	server  bool
+	pattern *regexp.Regexp
+}
+
+// serviceRules 按从具体到通用的顺序排列，Web服务器（nginx、Apache 等）最后匹配
+var serviceRules = []serviceRule{
+	{"Redis", false, false, regexp.MustCompile(`redis_version:(\d[\w.]*)|-NOAUTH Authentication required|-ERR wrong number of arguments for 'get' command`)},
+	{"Memcached", false, false, regexp.MustCompile(`STAT version (\d[\w.]*)`)},
+	{"Elasticsearch", false, false, regexp.MustCompile(`(?s)"number"\s*:\s*"(\d[\w.\-]*)".*"tagline"\s*:\s*"You Know, for Search"|"tagline"\s*:\s*"You Know, for Search"`)},
+	{"OpenSearch", false, false, regexp.MustCompile(`"tagline"\s*:\s*"The OpenSearch Project`)},
+	{"CouchDB", false, false, regexp.MustCompile(`"couchdb"\s*:\s*"Welcome"\s*,\s*"version"\s*:\s*"(\d[\w.]*)"|"couchdb"\s*:\s*"Welcome"`)},
+	{"MongoDB", false, false, regexp.MustCompile(`It looks like you are trying to access MongoDB over HTTP`)},
+	{"MySQL", false, false, regexp.MustCompile(`(\d+\.\d+\.\d+[\w.\-]*)\x00[\s\S]*mysql_native_password|mysql_native_password|caching_sha2_password`)},
+	{"PostgreSQL", false, false, regexp.MustCompile(`SFATAL|invalid length of startup packet`)},
+	{"Docker Engine API", false, false, regexp.MustCompile(`(?s)"Version"\s*:\s*"(\d[\w.\-]*)".*"ApiVersion"\s*:\s*"[\d.]+"|"ApiVersion"\s*:\s*"[\d.]+"`)},
+	{"Kubernetes API", false, false, regexp.MustCompile(`"gitVersion"\s*:\s*"(v[\w.\-+]+)"|"kind"\s*:\s*"Status"[\s\S]*system:anonymous`)},
+	{"etcd", false, false, regexp.MustCompile(`"etcdserver"\s*:\s*"(\d[\w.]*)"`)},
+	{"InfluxDB", false, false, regexp.MustCompile(`"name"\s*:\s*"influxdb"[\s\S]*?"version"\s*:\s*"v?(\d[\w.]*)"`)},
+	{"Jenkins", true, false, regexp.MustCompile(`Jenkins ver\. (\d[\w.]*)|<title>[^<]*Jenkins</title>|hudson\.model\.Hudson`)},
+	{"Grafana", true, false, regexp.MustCompile(`"buildInfo"\s*:\s*\{[^}]*?"version"\s*:\s*"(\d[\w.\-]*)"|<title>Grafana</title>|window\.grafanaBootData`)},
+	{"Kibana", true, false, regexp.MustCompile(`"name"\s*:\s*"kibana"|<title>Elastic</title>|kbn-injected-metadata`)},
+	{"Prometheus", true, false, regexp.MustCompile(`<title>Prometheus[^<]*</title>|"status"\s*:\s*"success"\s*,\s*"data"\s*:\s*\{\s*"version"\s*:\s*"(\d[\w.]*)"`)},
+	{"RabbitMQ Management", true, false, regexp.MustCompile(`<title>RabbitMQ Management</title>`)},
+	{"Consul", true, false, regexp.MustCompile(`<title>Consul by HashiCorp</title>|"Config"\s*:\s*\{[^}]*"Datacenter"`)},
+	{"Spring Boot", true, false, regexp.MustCompile(`Whitelabel Error Page|"_links"\s*:\s*\{\s*"self"\s*:\s*\{\s*"href"\s*:\s*"[^"]*/actuator"`)},
+	{"Apache Tomcat", true, false, regexp.MustCompile(`Apache Tomcat/(\d[\w.]*)|<title>Apache Tomcat</title>`)},
+	{"JBoss/WildFly", true, false, regexp.MustCompile(`<title>Welcome to (?:JBoss|WildFly)[^<]*</title>|JBoss Web/(\d[\w.]*)`)},
+	{"phpMyAdmin", true, false, regexp.MustCompile(`<title>phpMyAdmin</title>|pma_navigation`)},
+	{"GitLab", true, false, regexp.MustCompile(`<meta content="GitLab" property="og:site_name"|gon\.gitlab_url`)},
+	{"Apache Solr", true, false, regexp.MustCompile(`"solr-spec-version"\s*:\s*"(\d[\w.]*)"|<title>Solr Admin</title>`)},
+	{"Jetty", true, true, regexp.MustCompile(`Jetty\(([\w.\-]+)\)`)},
+	{"nginx", true, true, regexp.MustCompile(`^nginx(?:/(\d[\w.]*))?`)},
+	{"Apache httpd", true, true, regexp.MustCompile(`^Apache(?:/(\d[\w.]*))?`)},
+	{"Microsoft IIS", true, true, regexp.MustCompile(`^Microsoft-IIS(?:/(\d[\w.]*))?`)},
+	{"nginx", true, false, regexp.MustCompile(`<center>nginx(?:/(\d[\w.]*))?</center>`)},
+	{"Apache httpd", true, false, regexp.MustCompile(`<address>Apache(?:/(\d[\w.]*))?`)},
+}
+
+// identifyService 根据端口扫描的响应识别内网服务和版本（例如 Redis 7.0.11），无法识别时返回 false
+// 基线中已经出现的内容和与基线相同的 Server 头属于目标自身，不作为识别依据（没有基线时不使用 Server 头）；web 表示是否为内网Web应用
+func identifyService(resp *http.Response, body string, baseline *Baseline) (service string, web bool, ok bool) {
+	server := resp.Header.Get("Server")
+	if baseline == nil || baseline.Server == server {
+		server = ""
+	}
+	for _, rule := range serviceRules {
+		text := body
+		if rule.server {
+			text = server
+		}
+		if text == "" {
+			continue
+		}
+		m := rule.pattern.FindStringSubmatch(text)
+		if m == nil || !rule.server && baseline.Contains(m[0]) {
+			continue
+		}
+		service = rule.service
+		for _, version := range m[1:] {
+			if version != "" {
+				service += " " + version
+				break
+			}
+		}
+		return service, rule.web, true
+	}
+	return "", false, false
+}
+
+// serviceFinding 端口扫描识别出内网服务时的检测结果
+func serviceFinding(service string, web bool) Result {
+	result := finding(EvidenceService, ConfidenceProbable, SeverityHigh, "成功访问内网服务: "+service)
+	if web {
+		result = finding(EvidenceHTTPService, ConfidenceProbable, SeverityMedium, "成功访问内网HTTP服务: "+service)
+	}
+	result.Service, result.WebService = service, web
+	return result
+}
+
+// portScanType 判断payload是否按端口扫描识别服务和推断端口状态：端口扫描和 -hosts-wordlist 的内网主机名探测
+func portScanType(payloadType string) bool {
+	return payloadType == "端口扫描" || payloadType == payloads.TypeInternalHost
+}
+
+// webResponse 判断端口扫描的响应是否来自HTTP服务（出现基线中没有的HTTP响应特征）
+func webResponse(body string, baseline *Baseline) bool {
+	for _, feature := range []string{"HTTP/", "Server:", "<html"} {
+		if strings.Contains(body, feature) && !baseline.Contains(feature) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package detector
+
+import (
+	"net/http"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestIdentifyService(t *testing.T) {
+	baseline := newBaseline(200, "nginx/1.24.0", "<html><title>Apache Tomcat</title></html>")
+	tests := []struct {
+		name    string
+		server  string
+		body    string
+		want    string
+		web     bool
+		matched bool
+	}{
+		{"Redis INFO", "", "# Server\r\nredis_version:7.0.11\r\nredis_mode:standalone", "Redis 7.0.11", false, true},
+		{"Redis 未授权的错误回显", "", "-ERR wrong number of arguments for 'get' command\r\n", "Redis", false, true},
+		{"Elasticsearch", "", `{"name":"node-1","version":{"number":"8.11.1","lucene_version":"9.8.0"},"tagline":"You Know, for Search"}`, "Elasticsearch 8.11.1", false, true},
+		{"Jenkins", "", "<title>Dashboard [Jenkins]</title><footer>Jenkins ver. 2.401.3</footer>", "Jenkins 2.401.3", true, true},
+		{"Grafana 无版本", "", "<html><title>Grafana</title></html>", "Grafana", true, true},
+		{"Tomcat 版本", "", "<h3>Apache Tomcat/9.0.83</h3>", "Apache Tomcat 9.0.83", true, true},
+		{"基线中已有的Tomcat特征", "", "<title>Apache Tomcat</title>", "", false, false},
+		{"Docker", "", `{"Platform":{"Name":"Docker Engine"},"Version":"24.0.5","ApiVersion":"1.43"}`, "Docker Engine API 24.0.5", false, true},
+		{"与基线不同的Server头", "Microsoft-IIS/10.0", "ok", "Microsoft IIS 10.0", true, true},
+		{"与基线相同的Server头", "nginx/1.24.0", "ok", "", false, false},
+		{"无法识别", "", `{"status":"ok"}`, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.server != "" {
+				resp.Header.Set("Server", tt.server)
+			}
+			service, web, ok := identifyService(resp, tt.body, baseline)
+			if ok != tt.matched || service != tt.want || web != tt.web {
+				t.Errorf("identifyService() = %q, %v, %v, want %q, %v, %v", service, web, ok, tt.want, tt.web, tt.matched)
+			}
+		})
+	}
+}
+
+func TestAnalyzeResponseService(t *testing.T) {
+	d := &Detector{config: &config.Config{}}
+	baseline := newBaseline(200, "", "<html>portal</html>")
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+
+	got := d.analyzeResponse(resp, "<html><title>Grafana</title></html>", payloads.Payload{Type: "端口扫描"}, baseline)
+	if got.Service != "Grafana" || !got.WebService || got.Kind != EvidenceHTTPService || got.Evidence != "成功访问内网HTTP服务: Grafana" {
+		t.Errorf("端口扫描识别出的服务不正确: %+v", got)
+	}
+	got = d.analyzeResponse(resp, "redis_version:6.2.6", payloads.Payload{Type: "端口扫描"}, baseline)
+	if got.Service != "Redis 6.2.6" || got.WebService || got.Severity != SeverityHigh {
+		t.Errorf("端口扫描识别出的服务不正确: %+v", got)
+	}
+	got = d.analyzeResponse(resp, "<html><h1>It works!</h1></html>", payloads.Payload{Type: "端口扫描"}, newBaseline(200, "", "portal"))
+	if got.Service != "" || !got.WebService || got.Kind != EvidenceHTTPService {
+		t.Errorf("无法识别的内网Web服务应标记为 WebService: %+v", got)
+	}
+	got = d.analyzeResponse(resp, "<html><title>Dashboard - Jenkins</title></html>", payloads.Payload{Value: "http://jenkins.internal/", Type: payloads.TypeInternalHost}, baseline)
+	if got.Service != "Jenkins" || !got.WebService {
+		t.Errorf("内网主机名探测识别出的服务不正确: %+v", got)
+	}
+}
@@ -0,0 +1,194 @@
+package detector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// session 登录会话：-login 登录请求返回的Cookie保存在客户端的CookieJar中，-login-script 输出的Cookie添加到每个请求
+type session struct {
+	mu         sync.RWMutex
+	generation int            // 登录次数，并发请求同时检测到会话失效时只重新登录一次
+	cookies    []*http.Cookie // 登录脚本输出的Cookie
+}
+
+// Login 执行登录（-login/-login-script），扫描开始前调用
+func (d *Detector) Login(ctx context.Context) error {
+	d.session.mu.Lock()
+	defer d.session.mu.Unlock()
+	return d.login(ctx)
+}
+
+// login 发送登录请求或执行登录脚本，调用方需持有 session 写锁
+func (d *Detector) login(ctx context.Context) error {
+	switch {
+	case d.config.LoginRequest != nil:
+		if err := d.loginRequest(ctx); err != nil {
+			return err
+		}
+	case d.config.LoginScript != "":
+		cookies, err := runLoginScript(ctx, d.config.LoginScript)
+		if err != nil {
+			return err
+		}
+		d.session.cookies = cookies
+	default:
+		return nil
+	}
+	d.session.generation++
+	return nil
+}
+
+// loginRequest 发送 -login 指定的登录请求，响应中的 Set-Cookie 由CookieJar保存
+func (d *Detector) loginRequest(ctx context.Context) error {
+	login := d.config.LoginRequest
+	var body io.Reader
+	if login.Body != "" {
+		body = strings.NewReader(login.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, login.Method, login.URL, body)
+	if err != nil {
+		return fmt.Errorf("创建登录请求失败: %v", err)
+	}
+	for key, value := range login.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("登录请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("登录失败: 状态码 %d", resp.StatusCode)
+	}
+	if len(resp.Cookies()) == 0 {
+		slog.Warn(fmt.Sprintf("登录响应中没有Cookie（状态码 %d）", resp.StatusCode))
+	}
+	return nil
+}
+
+// runLoginScript 执行登录脚本，从标准输出中解析Cookie
+func runLoginScript(ctx context.Context, script string) ([]*http.Cookie, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	out, err := exec.CommandContext(ctx, shell, flag, script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("执行登录脚本失败: %v", err)
+	}
+	cookies := parseCookieLines(string(out))
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("登录脚本没有输出Cookie")
+	}
+	return cookies, nil
+}
+
+// parseCookieLines 解析登录脚本的输出：每行为 name=value、Cookie: a=1; b=2 或 Set-Cookie: a=1; Path=/
+func parseCookieLines(output string) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(lower, "set-cookie:"):
+			line, _, _ = strings.Cut(line[len("set-cookie:"):], ";")
+		case strings.HasPrefix(lower, "cookie:"):
+			line = line[len("cookie:"):]
+		}
+		for _, pair := range strings.Split(line, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || name == "" || strings.ContainsAny(name, " \t,") {
+				continue
+			}
+			cookies = append(cookies, &http.Cookie{Name: name, Value: strings.Trim(value, `"`)})
+		}
+	}
+	return cookies
+}
+
+// doSession 发送请求，响应体或 Location 头匹配 -logout-regex 时重新登录并重发一次
+func (d *Detector) doSession(req *http.Request) (*http.Response, error) {
+	d.session.mu.RLock()
+	generation := d.session.generation
+	for _, cookie := range d.session.cookies {
+		req.AddCookie(cookie)
+	}
+	d.session.mu.RUnlock()
+
+	if d.config.LogoutPattern == nil {
+		return d.client.Do(req)
+	}
+	// 发送前复制请求，CookieJar中的Cookie会被添加到原请求上
+	retry, err := rewindRequest(req)
+	if err != nil {
+		return d.client.Do(req)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := sessionExpired(resp, d.config.MaxBodySize, d.config.LogoutPattern.MatchString)
+	if err != nil {
+		return nil, err
+	}
+	if !expired {
+		return resp, nil
+	}
+
+	if err := d.relogin(req.Context(), generation); err != nil {
+		slog.Warn(fmt.Sprintf("检测到会话失效，重新登录失败: %v", err))
+		return resp, nil
+	}
+	resp.Body.Close()
+	// 重发前清空第一次请求记录的重定向链
+	if chain, ok := req.Context().Value(redirectChainKey{}).(*[]Redirect); ok {
+		*chain = (*chain)[:0]
+	}
+	d.session.mu.RLock()
+	for _, cookie := range d.session.cookies {
+		retry.AddCookie(cookie)
+	}
+	d.session.mu.RUnlock()
+	return d.client.Do(retry)
+}
+
+// relogin 会话失效时重新登录，其他请求已在 generation 之后重新登录时直接返回
+func (d *Detector) relogin(ctx context.Context, generation int) error {
+	d.session.mu.Lock()
+	defer d.session.mu.Unlock()
+	if d.session.generation != generation {
+		return nil
+	}
+	slog.Info("检测到会话失效，重新登录")
+	return d.login(ctx)
+}
+
+// sessionExpired 检查响应是否匹配会话失效特征，最多读取 limit 字节（-max-body），读取的内容放回 resp.Body 之前，后续读取时不会丢失
+func sessionExpired(resp *http.Response, limit int64, match func(string) bool) (bool, error) {
+	var r io.Reader = resp.Body
+	if limit > 0 {
+		r = io.LimitReader(resp.Body, limit)
+	}
+	body, err := io.ReadAll(r)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false, fmt.Errorf("读取响应失败: %v", err)
+	}
+	location := resp.Header.Get("Location")
+	return match(string(body)) || location != "" && match(location), nil
+}
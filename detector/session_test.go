@@ -0,0 +1,128 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"sync"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+// sessionServer 模拟登录后才能访问的接口，每个会话只能使用 limit 次
+type sessionServer struct {
+	mu     sync.Mutex
+	logins int
+	valid  string
+	uses   int
+	limit  int
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path == "/login" {
+		r.ParseForm()
+		if r.PostForm.Get("user") != "admin" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		s.logins++
+		s.valid, s.uses = fmt.Sprintf("sid-%d", s.logins), 0
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: s.valid})
+		w.Header().Set("Location", "/home")
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+	cookie, err := r.Cookie("session")
+	if err != nil || cookie.Value != s.valid || s.uses >= s.limit {
+		w.Header().Set("Location", "/login?expired=1")
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+	s.uses++
+	w.Write([]byte("ok " + r.URL.Query().Get("url")))
+}
+
+func TestSessionRelogin(t *testing.T) {
+	ss := &sessionServer{limit: 2}
+	server := httptest.NewServer(ss)
+	defer server.Close()
+
+	cfg := &config.Config{
+		Timeout: 5,
+		LoginRequest: &config.RawRequest{
+			Method:  http.MethodPost,
+			URL:     server.URL + "/login",
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:    "user=admin",
+		},
+		LogoutPattern: regexp.MustCompile(`/login\?expired`),
+	}
+	d := NewDetector(cfg)
+	if err := d.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		status, body, err := d.Fetch(context.Background(), http.MethodGet, fmt.Sprintf("%s/api?url=%d", server.URL, i), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != 200 || body != fmt.Sprintf("ok %d", i) {
+			t.Errorf("第 %d 次请求得到 %d %q，会话失效后应重新登录并重发", i+1, status, body)
+		}
+	}
+	if ss.logins != 3 {
+		t.Errorf("登录 %d 次，期望 3 次", ss.logins)
+	}
+
+	cfg.LoginRequest.Body = "user=guest"
+	if err := NewDetector(cfg).Login(context.Background()); err == nil {
+		t.Errorf("登录请求返回403时应返回错误")
+	}
+}
+
+func TestLoginScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("登录脚本测试使用 sh")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, _ := r.Cookie("session")
+		lang, _ := r.Cookie("lang")
+		if session == nil || lang == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(session.Value + " " + lang.Value))
+	}))
+	defer server.Close()
+
+	d := NewDetector(&config.Config{Timeout: 5, LoginScript: `echo "logging in"; echo "Cookie: session=abc; lang=zh"`})
+	if err := d.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, body, err := d.Fetch(context.Background(), http.MethodGet, server.URL, ""); err != nil || body != "abc zh" {
+		t.Errorf("得到 %q (%v)，期望携带登录脚本输出的Cookie", body, err)
+	}
+
+	if err := NewDetector(&config.Config{LoginScript: "echo nothing"}).Login(context.Background()); err == nil {
+		t.Errorf("登录脚本没有输出Cookie时应返回错误")
+	}
+}
+
+func TestParseCookieLines(t *testing.T) {
+	got := parseCookieLines("Set-Cookie: sid=1; Path=/; HttpOnly\r\ncookie: a=2; b=\"3\"\ntoken=x=y\n无效行\n")
+	want := []string{"sid=1", "a=2", "b=3", "token=x=y"}
+	if len(got) != len(want) {
+		t.Fatalf("得到 %v，期望 %v", got, want)
+	}
+	for i, cookie := range got {
+		if cookie.Name+"="+cookie.Value != want[i] {
+			t.Errorf("第 %d 个Cookie为 %s=%s，期望 %s", i, cookie.Name, cookie.Value, want[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package detector
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// genericSimilarity 与通用页面的相似度达到该值时视为同一页面（simhash 64位中最多6位不同）
+const genericSimilarity = 0.9
+
+// simhash 计算响应的模糊哈希：按词元加权合并64位哈希，内容相近的页面哈希只有少数位不同
+// 含数字的词元（时间、请求ID等）统一替换，避免每次请求都变化的内容影响结果
+func simhash(body string) uint64 {
+	var weights [64]int
+	for _, token := range tokenize(body) {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// tokenize 将响应拆分为小写词元，汉字等非拉丁文字每个字符作为一个词元
+func tokenize(body string) []string {
+	var tokens []string
+	var current strings.Builder
+	hasDigit := false
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		if hasDigit {
+			tokens = append(tokens, "#")
+		} else {
+			tokens = append(tokens, current.String())
+		}
+		current.Reset()
+		hasDigit = false
+	}
+
+	for _, r := range strings.ToLower(body) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			current.WriteRune(r)
+			hasDigit = hasDigit || unicode.IsDigit(r)
+		case unicode.IsLetter(r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// similarity 计算两个模糊哈希的相似度（0~1）
+func similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
@@ -0,0 +1,79 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/payloads"
+)
+
+func TestSimilarity(t *testing.T) {
+	page := func(id, detail string) string {
+		return "<html><head><title>出错了</title></head><body><h1>Service Unavailable</h1>" +
+			"<p>The server could not complete your request. Please try again later or contact support.</p>" +
+			"<p>Request ID: " + id + "</p><p>" + detail + "</p><footer>Powered by Example Gateway</footer></body></html>"
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"只有请求ID不同", page("a1b2c3", "fetch failed"), page("99ff00", "fetch failed"), true},
+		{"回显的地址不同", page("1", "fetch http://127.0.0.1:1/ failed"), page("2", "fetch http://127.0.0.1:6379/ failed"), true},
+		{"完全不同的页面", page("1", "fetch failed"), "root:x:0:0:root:/root:/bin/bash\ndaemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin\nbin:x:2:2:bin:/bin:/usr/sbin/nologin", false},
+		{"中文页面", "您访问的页面不存在，请检查地址后重试", "您访问的页面不存在，请检查地址后再试", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := similarity(simhash(tt.a), simhash(tt.b))
+			if got := sim >= genericSimilarity; got != tt.want {
+				t.Errorf("相似度 %.2f, want 相似=%v", sim, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericErrorPage(t *testing.T) {
+	// 目标把请求失败的地址和原因回显到固定的错误页面中，连接被拒绝时原因更长
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("url")
+		reason := "no such host"
+		if strings.Contains(target, "127.0.0.1") {
+			reason = "dial tcp " + target + ": connect: connection refused after 3 retries with exponential backoff"
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body><h1>Bad Gateway</h1><p>The upstream server could not be reached: " + reason + ".</p>" +
+			"<p>Please check the address and try again later or contact the administrator.</p></body></html>"))
+	}))
+	defer server.Close()
+
+	d := NewDetector(&config.Config{Timeout: 5})
+	request := func(value string) string { return server.URL + "/?url=" + url.QueryEscape(value) }
+	ctx := context.Background()
+	baseline, err := d.FetchBaseline(ctx, http.MethodGet, request("http://gossrf-baseline.invalid/"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := payloads.Payload{Value: "http://127.0.0.1:6379/", Type: "端口扫描"}
+
+	// 没有错误页面时，回显的 127.0.0.1 被当作敏感信息
+	if got := d.DetectWithMethod(ctx, http.MethodGet, request(payload.Value), "", payload, baseline); !got.Vulnerable {
+		t.Fatal("未记录错误页面时应按启发式规则判定")
+	}
+
+	page, err := d.FetchBaseline(ctx, http.MethodGet, request("http://127.0.0.1:1/"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline.AddErrorPage(page)
+	got := d.DetectWithMethod(ctx, http.MethodGet, request(payload.Value), "", payload, baseline)
+	if got.Vulnerable || got.Suppressed == "" {
+		t.Errorf("与错误页面相同的响应应被忽略: %+v", got)
+	}
+}
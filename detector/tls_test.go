@@ -0,0 +1,55 @@
+package detector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestTLSOptions(t *testing.T) {
+	var serverName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	// 使用服务器自身的证书作为客户端证书
+	clientCert := server.TLS.Certificates[0]
+	fetch := func(tlsConfig *tls.Config) error {
+		_, _, err := NewDetector(&config.Config{Timeout: 5, TLS: tlsConfig}).Fetch(context.Background(), http.MethodGet, server.URL, "")
+		return err
+	}
+
+	if err := fetch(nil); err == nil {
+		t.Errorf("未提供客户端证书时双向TLS认证应失败")
+	}
+	if err := fetch(&tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{clientCert}, ServerName: "internal.example"}); err != nil {
+		t.Fatalf("提供客户端证书后应成功: %v", err)
+	}
+	if serverName != "internal.example" {
+		t.Errorf("SNI为 %q，期望 internal.example", serverName)
+	}
+
+	// 指定CA后验证目标证书
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	if err := fetch(&tls.Config{RootCAs: pool, Certificates: []tls.Certificate{clientCert}}); err != nil {
+		t.Errorf("目标证书由指定的CA签发时应验证通过: %v", err)
+	}
+	if err := fetch(&tls.Config{RootCAs: x509.NewCertPool(), Certificates: []tls.Certificate{clientCert}}); err == nil {
+		t.Errorf("目标证书不由指定的CA签发时应验证失败")
+	}
+}
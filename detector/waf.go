@@ -0,0 +1,63 @@
+package detector
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// wafSignature WAF拦截页面的特征
+type wafSignature struct {
+	name    string
+	headers []string // 出现即说明经过该WAF的响应头（只在拦截状态码时作为依据）
+	body    []string // 拦截页面中的特征（小写）
+}
+
+// wafSignatures 常见WAF的拦截特征
+var wafSignatures = []wafSignature{
+	{name: "Cloudflare", headers: []string{"Cf-Ray"}, body: []string{"attention required! | cloudflare", "cf-error-details", "cloudflare ray id"}},
+	{name: "ModSecurity", body: []string{"mod_security", "modsecurity", "not acceptable!"}},
+	{name: "AWS WAF", headers: []string{"X-Amzn-Waf-Action"}, body: []string{"request blocked. we can't connect to the server"}},
+	{name: "Akamai", body: []string{"errors.edgesuite.net"}},
+	{name: "Imperva", headers: []string{"X-Iinfo"}, body: []string{"incapsula incident id", "_incapsula_resource"}},
+	{name: "Sucuri", headers: []string{"X-Sucuri-Block"}, body: []string{"sucuri website firewall"}},
+	{name: "F5 BIG-IP ASM", body: []string{"the requested url was rejected. please consult with your administrator"}},
+	{name: "阿里云WAF", body: []string{"errors.aliyun.com"}},
+	{name: "腾讯云WAF", body: []string{"waf.tencent-cloud.com"}},
+	{name: "360网站卫士", headers: []string{"X-Powered-By-360wzb"}, body: []string{"360wzws"}},
+	{name: "安全狗", body: []string{"safedog"}},
+	{name: "宝塔WAF", body: []string{"btwaf"}},
+	{name: "雷池WAF", body: []string{"safeline"}},
+	{name: "云锁", body: []string{"yunsuo"}},
+}
+
+// isBlockStatus 判断状态码是否为WAF和过滤规则常用的拦截状态码
+func isBlockStatus(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusNotAcceptable
+}
+
+// wafBlock 判断payload请求是否被WAF或过滤规则拦截，返回拦截原因，未拦截时返回空字符串
+// 响应体中出现WAF拦截页面特征（基线中没有），或与基线不同地返回403/406时视为拦截
+func wafBlock(resp *http.Response, body string, baseline *Baseline) string {
+	lower := strings.ToLower(body)
+	blockStatus := isBlockStatus(resp.StatusCode) && (baseline == nil || baseline.StatusCode != resp.StatusCode)
+	for _, sig := range wafSignatures {
+		for _, keyword := range sig.body {
+			if strings.Contains(lower, keyword) && !baseline.Contains(keyword) {
+				return fmt.Sprintf("%s，状态码 %d", sig.name, resp.StatusCode)
+			}
+		}
+		if !blockStatus {
+			continue
+		}
+		for _, header := range sig.headers {
+			if resp.Header.Get(header) != "" {
+				return fmt.Sprintf("%s，状态码 %d", sig.name, resp.StatusCode)
+			}
+		}
+	}
+	if blockStatus && baseline != nil {
+		return fmt.Sprintf("状态码 %d", resp.StatusCode)
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package detector
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWAFBlock(t *testing.T) {
+	baseline := &Baseline{StatusCode: 200, body: "<html>welcome</html>"}
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		body     string
+		baseline *Baseline
+		want     string
+	}{
+		{"拦截页面特征", 403, "", "<title>Attention Required! | Cloudflare</title>", baseline, "Cloudflare，状态码 403"},
+		{"返回200的拦截页面", 200, "", "网站防火墙 safedog", baseline, "安全狗，状态码 200"},
+		{"拦截状态码和响应头", 403, "X-Sucuri-Block", "blocked", baseline, "Sucuri，状态码 403"},
+		{"与基线不同的406", 406, "", "", baseline, "状态码 406"},
+		{"没有基线时不按状态码判定", 403, "", "forbidden", nil, ""},
+		{"基线也是403", 403, "", "forbidden", &Baseline{StatusCode: 403}, ""},
+		{"基线中已有的特征", 200, "", "powered by safedog", &Baseline{StatusCode: 200, body: "powered by safedog"}, ""},
+		{"正常响应", 200, "Cf-Ray", "ok", baseline, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set(tt.header, "1")
+			}
+			if got := wafBlock(resp, tt.body, tt.baseline); got != tt.want {
+				t.Errorf("wafBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
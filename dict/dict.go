@@ -0,0 +1,9 @@
+// Package dict 内置payload字典，编译时嵌入程序，运行时不依赖工作目录
+package dict
+
+import "embed"
+
+// Files 内置字典文件（dict/*.txt）
+//
+//go:embed *.txt
+var Files embed.FS
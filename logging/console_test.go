@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestConsoleOutput(t *testing.T) {
+	var stdout, stderr, file bytes.Buffer
+	c := NewConsole(&stdout, &stderr)
+	c.SetFile(&file)
+
+	c.Log(config.ColorNone, "[*] 开始扫描\n")
+	c.Result(config.ColorNone, "[critical/confirmed] 漏洞\n")
+
+	if stderr.String() != "[*] 开始扫描\n" {
+		t.Errorf("标准错误 = %q", stderr.String())
+	}
+	if stdout.String() != "[critical/confirmed] 漏洞\n" {
+		t.Errorf("标准输出 = %q", stdout.String())
+	}
+	if want := "[*] 开始扫描\n[critical/confirmed] 漏洞\n"; file.String() != want {
+		t.Errorf("输出文件 = %q, want %q", file.String(), want)
+	}
+}
+
+func TestConsoleStatus(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := NewConsole(&stdout, &stderr)
+
+	c.Status("[==>   ] 进度")
+	width := displayWidth("[==>   ] 进度")
+	stderr.Reset()
+
+	// 输出前先清除状态行
+	c.Result(config.ColorNone, "结果\n")
+	if want := "\r" + strings.Repeat(" ", width) + "\r"; stderr.String() != want {
+		t.Errorf("清除状态行输出 %q, want %q", stderr.String(), want)
+	}
+
+	// 没有显示状态行时不输出
+	stderr.Reset()
+	c.ClearStatus()
+	if stderr.Len() != 0 {
+		t.Errorf("重复清除输出 %q", stderr.String())
+	}
+}
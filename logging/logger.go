@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+
+	"gosssrf-client/config"
+)
+
+// LevelTrace 每个请求和响应的详细内容（-vv参数），低于 slog.LevelDebug
+const LevelTrace = slog.LevelDebug - 4
+
+// Options 日志选项
+type Options struct {
+	Verbosity int       // 命令行输出级别（config.LevelSilent/LevelDefault/LevelVerbose/LevelDebug）
+	File      io.Writer // 日志文件（-log-file参数），为nil时不写入
+	Format    string    // 日志文件格式（config.LogFormatText/LogFormatJSON）
+}
+
+// New 创建日志记录器：日志按级别输出到命令行，并以结构化格式写入日志文件
+// -silent 只影响命令行输出，日志文件至少记录 Info 级别
+func New(console *Console, opts Options) *slog.Logger {
+	handlers := []slog.Handler{&consoleHandler{console: console, level: Level(opts.Verbosity)}}
+	if opts.File != nil {
+		fileOpts := &slog.HandlerOptions{
+			Level:       Level(max(opts.Verbosity, config.LevelDefault)),
+			ReplaceAttr: replaceLevel,
+		}
+		if opts.Format == config.LogFormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(opts.File, fileOpts))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(opts.File, fileOpts))
+		}
+	}
+	if len(handlers) == 1 {
+		return slog.New(handlers[0])
+	}
+	return slog.New(multiHandler(handlers))
+}
+
+// Level 命令行输出级别对应的最低日志级别，-silent 时只输出警告和错误
+func Level(verbosity int) slog.Level {
+	switch verbosity {
+	case config.LevelSilent:
+		return slog.LevelWarn
+	case config.LevelVerbose:
+		return slog.LevelDebug
+	case config.LevelDebug:
+		return LevelTrace
+	}
+	return slog.LevelInfo
+}
+
+// replaceLevel 日志文件中 LevelTrace 显示为 TRACE
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level <= LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// consoleHandler 命令行日志：只输出带级别前缀的消息，属性只写入日志文件
+type consoleHandler struct {
+	console *Console
+	level   slog.Level
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	colorType, prefix := config.ColorNone, ""
+	switch {
+	case r.Level >= slog.LevelWarn:
+		colorType, prefix = config.ColorRed, "[!] "
+	case r.Level >= slog.LevelInfo:
+		colorType, prefix = config.ColorYellow, "[*] "
+	}
+	msg := prefix + r.Message
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	h.console.Log(colorType, msg)
+	return nil
+}
+
+func (h *consoleHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *consoleHandler) WithGroup(string) slog.Handler { return h }
+
+// multiHandler 将日志同时交给多个处理器
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
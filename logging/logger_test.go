@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		want      string
+	}{
+		{"静默模式只输出错误", config.LevelSilent, "[!] 请求失败\n"},
+		{"默认", config.LevelDefault, "[*] 开始扫描\n[!] 请求失败\n"},
+		{"-v", config.LevelVerbose, "[*] 开始扫描\n正在测试\n[!] 请求失败\n"},
+		{"-vv", config.LevelDebug, "[*] 开始扫描\n正在测试\n请求详情\n[!] 请求失败\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			logger := New(NewConsole(&stdout, &stderr), Options{Verbosity: tt.verbosity})
+			logger.Info("开始扫描", "target", "http://example.com")
+			logger.Debug("正在测试")
+			logger.Log(context.Background(), LevelTrace, "请求详情")
+			logger.Warn("请求失败")
+
+			if stderr.String() != tt.want {
+				t.Errorf("命令行输出 = %q, want %q", stderr.String(), tt.want)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("日志不应写入标准输出: %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestLoggerFile(t *testing.T) {
+	var console, file bytes.Buffer
+	logger := New(NewConsole(&console, &console), Options{Verbosity: config.LevelSilent, File: &file, Format: config.LogFormatJSON})
+	logger.Info("开始扫描", "target", "http://example.com")
+	logger.Log(context.Background(), LevelTrace, "请求详情")
+
+	// -silent 不影响日志文件，日志文件中也不记录低于 Info 的日志
+	if console.Len() != 0 {
+		t.Errorf("静默模式命令行输出 %q", console.String())
+	}
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("日志文件 %d 行, want 1: %q", len(lines), file.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("日志不是JSON: %v", err)
+	}
+	if entry["level"] != "INFO" || entry["msg"] != "开始扫描" || entry["target"] != "http://example.com" {
+		t.Errorf("日志内容 = %v", entry)
+	}
+}
+
+func TestReplaceLevel(t *testing.T) {
+	var file bytes.Buffer
+	logger := New(NewConsole(&bytes.Buffer{}, &bytes.Buffer{}), Options{Verbosity: config.LevelDebug, File: &file})
+	logger.Log(context.Background(), LevelTrace, "请求详情")
+	logger.Debug("正在测试")
+
+	out := file.String()
+	if !strings.Contains(out, "level=TRACE msg=请求详情") || !strings.Contains(out, "level=DEBUG msg=正在测试") {
+		t.Errorf("日志文件 = %q", out)
+	}
+}
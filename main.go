@@ -1,13 +1,35 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"gosssrf-client/cluster"
 	"gosssrf-client/config"
 	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/notify"
+	"gosssrf-client/oob"
+	"gosssrf-client/payloads"
+	"gosssrf-client/rebind"
+	"gosssrf-client/replay"
+	"gosssrf-client/report"
+	"gosssrf-client/s3"
 	"gosssrf-client/scanner"
+	"gosssrf-client/store"
 )
 
 func printBanner() {
@@ -15,55 +37,1029 @@ func printBanner() {
 }
 
 func main() {
-	// 解析命令行参数
-	cfg := config.ParseFlags()
-	flag.Parse()
+	// 解析命令行参数：scan/serve/payloads/report/oob/replay/lint/version 子命令，未指定子命令时按 scan 解析全部参数
+	cfg, err := config.Parse(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	// 命令行输出：日志写入标准错误，发现的漏洞写入标准输出
+	console := logging.NewConsole(os.Stdout, os.Stderr)
+	slog.SetDefault(logging.New(console, logging.Options{Verbosity: config.LevelDefault}))
+
+	// 输出版本信息（version）不需要验证扫描配置
+	if cfg.Command == config.CommandVersion {
+		runVersion(cfg, console)
+		return
+	}
 
-	printBanner()
+	// 扫描前检查配置和字典文件（lint），不需要扫描目标
+	if cfg.Command == config.CommandLint {
+		runLint(cfg, console)
+		return
+	}
+
+	// 验证配置（-silent 可能来自配置文件，验证后再决定是否输出Banner）
+	err = cfg.Validate()
+	if err == nil {
+		// 证据类别由检测模块定义，配置模块无法在 Validate 中检查
+		err = cfg.SeverityMap.CheckEvidence(detector.EvidenceKinds)
+	}
+	if err == nil && cfg.ReportTemplate != "" {
+		// 扫描开始前解析报告模板，避免扫描结束后才发现模板错误
+		_, err = report.ParseTemplate(cfg.ReportTemplate)
+	}
+	if !cfg.Silent {
+		printBanner()
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("配置错误: %v", err))
+		cfg.Usage()
+		os.Exit(1)
+	}
 
-	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		red := config.Colors(config.ColorRed)
-		red.Printf("[!] 配置错误: %v\n", err)
-		flag.Usage()
+	// 创建扫描工作区并保存配置快照（-workspace），日志文件和报告都写入其中
+	if err := cfg.CreateWorkspace(); err != nil {
+		slog.Error(err.Error())
 		os.Exit(1)
 	}
 
+	// 按输出级别重新设置日志，指定 -log-file 时同时写入日志文件
+	logOpts := logging.Options{Verbosity: cfg.Verbosity(), Format: cfg.LogFormat}
+	if cfg.LogFile != "" {
+		logFile, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			slog.Error(fmt.Sprintf("打开日志文件失败: %v", err))
+			os.Exit(1)
+		}
+		defer logFile.Close()
+		logOpts.File = logFile
+	}
+	slog.SetDefault(logging.New(console, logOpts))
+
 	// 打印配置信息
 	cfg.Print()
+	if cfg.WorkspaceDir != "" {
+		slog.Info(fmt.Sprintf("扫描工作区: %s", cfg.WorkspaceDir))
+	}
+	if len(cfg.Requests) > 0 {
+		slog.Info(fmt.Sprintf("从Burp导出文件 %s 导入 %d 个请求", cfg.BurpFile, len(cfg.Requests)))
+	}
+
+	// 比较两次扫描的报告（report -diff old.json new.json）
+	if cfg.Diff {
+		runDiff(cfg, console)
+		return
+	}
+
+	// 将保存的漏洞渲染为其他格式的报告（report scan.json -o report.html）
+	if cfg.Command == config.CommandReport {
+		runReport(cfg, console)
+		return
+	}
+
+	// 生成单独使用的payload（payloads gen）
+	if cfg.Generate != "" {
+		runGenerate(cfg, console)
+		return
+	}
+
+	// 列出扫描会发送的payload（payloads list）
+	if cfg.Command == config.CommandPayloads {
+		runPayloads(cfg, console)
+		return
+	}
+
+	// 重放已保存的漏洞（replay -finding）
+	if cfg.Replay {
+		runReplay(cfg, console)
+		return
+	}
+
+	// 复测已保存的漏洞（-verify）
+	if cfg.VerifyFile != "" {
+		runVerify(cfg, console)
+		return
+	}
+
+	// 通过已确认的SSRF参数执行利用模块（-exploit）
+	if cfg.Exploit != "" {
+		runExploit(cfg, console)
+		return
+	}
+
+	// 代理节点：扫描参数和目标由控制节点下发（-agent）
+	if cfg.AgentOf != "" {
+		runAgent(cfg, console)
+		return
+	}
+
+	// 创建回连标识登记表（OOB测试时为每个请求分配唯一标识）
+	var oobRegistry *oob.Registry
+	if cfg.ShouldScanOOB() {
+		var err error
+		oobRegistry, err = oob.NewRegistry(cfg.OOBMapFile)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer oobRegistry.Close()
+	}
+
+	// 启动内置OOB回连服务
+	var oobServer *oob.Server
+	if cfg.OOBListen != "" {
+		var err error
+		oobServer, err = oob.NewServer(cfg.OOBListen, cfg.OOBServer, oobRegistry)
+		if err == nil {
+			err = oobServer.Start()
+		}
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer oobServer.Close()
+
+		slog.Info(fmt.Sprintf("内置OOB服务已启动，监听 %s，回连地址 %s", cfg.OOBListen, oobServer.BaseURL()))
+	}
+
+	// 启动内置DNS重绑定服务
+	var dnsServer *rebind.Server
+	if cfg.DNSListen != "" {
+		dnsServer = rebind.NewServer(cfg.DNSListen, cfg.RebindDomain)
+		if err := dnsServer.Start(); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer dnsServer.Close()
+
+		slog.Info(fmt.Sprintf("内置DNS重绑定服务已启动，监听 %s，域名 %s", cfg.DNSListen, cfg.RebindDomain))
+	}
+
+	// oob 子命令或未指定目标时只运行回连服务，直到Ctrl+C退出
+	if cfg.IsOOBServeOnly() {
+		serveOOB(console, oobServer, dnsServer)
+		return
+	}
+
+	// 持续监控：按 -watch 的间隔重复扫描，只通知新出现的漏洞
+	if cfg.Watch > 0 {
+		runWatch(cfg, console, oobServer, oobRegistry, dnsServer)
+		return
+	}
 
 	// 初始化检测器
 	det := detector.NewDetector(cfg)
 
-	// 如果指定了输出文件，创建输出文件
-	var outputFile *os.File
-	if cfg.OutputFile != "" {
+	// 如果指定了输出文件，创建输出文件（文本输出文件同步保存命令行输出）；HTML、Markdown、JSON和DefectDojo报告在扫描结束后统一写入，JSON行文件在确认漏洞时逐行写入
+	outputFile := openOutput(cfg, console)
+	if outputFile != nil {
+		defer outputFile.Close()
+	}
+	bucket := newBucket(cfg)
+
+	// 初始化扫描器
+	scanManager := scanner.NewScanManager(cfg, det, console, oobServer, oobRegistry, dnsServer)
+
+	// 结果数据库：记录每个测试请求、响应和发现的漏洞
+	var db *store.DB
+	if cfg.DBFile != "" {
 		var err error
-		outputFile, err = os.Create(cfg.OutputFile)
+		db, err = store.Open(cfg.DBFile)
 		if err != nil {
-			red := config.Colors(config.ColorRed)
-			red.Printf("创建输出文件失败: %v\n", err)
+			slog.Error(err.Error())
 			os.Exit(1)
 		}
-		defer outputFile.Close()
+		scanManager.OnResult(db.Record)
+	}
+
+	// JSON行输出：每确认一个漏洞立即写入一行，不在扫描结束后生成报告
+	var jsonl *report.JSONL
+	if outputFile != nil && cfg.OutputFormat == config.FormatJSONL {
+		jsonl = report.NewJSONL(outputFile)
+		scanManager.OnResult(jsonl.Record)
+	}
+
+	// webhook和聊天通知：发现漏洞和扫描结束时发送
+	notifiers, err := newNotifiers(cfg)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	for _, n := range notifiers {
+		scanManager.OnResult(n.Finding)
+	}
+
+	// 从标准输入边读取边扫描目标
+	if cfg.StdinTargets() {
+		scanManager.ReadTargets(os.Stdin)
 	}
 
-	// 初始化扫描器（传入输出文件）
-	scanManager := scanner.NewScanManager(cfg, det, outputFile)
+	// 断点续扫
+	if cfg.ResumeFile != "" {
+		if err := scanManager.LoadState(cfg.ResumeFile); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
 
 	// 执行扫描
-	fmt.Println()
-	if outputFile != nil {
-		outputFile.WriteString("\n")
+	if !cfg.Silent {
+		console.Log(config.ColorNone, "\n")
 	}
 
-	vulnerableCount := scanManager.RunScan()
+	// 控制节点：拆分任务分配给代理节点，本机不发送扫描请求（-controller）
+	var controller *cluster.Controller
+	if cfg.Controller != "" {
+		controller, err = cluster.NewController(cfg, scanManager.Merge)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// Ctrl+C 或 SIGTERM：停止下发新的payload，等待进行中的请求完成后输出已有结果；再次中断时立即退出
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		slog.Warn("收到中断信号，等待进行中的请求完成（再次按 Ctrl+C 立即退出）...")
+		scanManager.Stop()
+		if controller != nil {
+			controller.Stop()
+		}
+		<-sigCh
+		os.Exit(130)
+	}()
+
+	// 指定 -max-scan-time 时限制整个扫描的时长
+	ctx := context.Background()
+	if cfg.MaxScanTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.MaxScanTime)
+		defer cancel()
+	}
+
+	// 启动请求和响应钩子脚本（-script），分布式扫描时由代理节点各自启动
+	if cfg.Script != "" && controller == nil {
+		if err := det.StartScript(); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer det.Close()
+	}
+
+	// 扫描前登录（-login/-login-script），会话失效时检测器自动重新登录；分布式扫描时由代理节点各自登录
+	if (cfg.LoginRequest != nil || cfg.LoginScript != "") && controller == nil {
+		if err := det.Login(ctx); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info("登录成功")
+	}
+
+	// 爬取目标站点，发现的链接和表单加入扫描目标（-crawl）
+	startTime := time.Now()
+	if cfg.Crawl {
+		slog.Info(fmt.Sprintf("开始爬取目标站点，最多 %d 层链接", cfg.CrawlDepth))
+		added := scanManager.Crawl(ctx)
+		slog.Info(fmt.Sprintf("爬取完成，新增 %d 个扫描目标，共 %d 个目标", added, len(cfg.Targets)))
+	}
+	if db != nil {
+		db.BeginScan(cfg.Targets, startTime)
+	}
+	var vulnerableCount int
+	if controller != nil {
+		if err := controller.Run(ctx); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		vulnerableCount = len(scanManager.Results())
+	} else {
+		vulnerableCount = scanManager.RunScan(ctx)
+	}
+	endTime := time.Now()
+	signal.Stop(sigCh)
 
-	// 打印摘要
+	// 打印摘要（-silent 时只输出漏洞，不输出摘要）
 	summaryMsg := fmt.Sprintf("\n扫描完成，存在 %d 个SSRF测试点\n", vulnerableCount)
-	fmt.Print(summaryMsg)
+	if scanManager.Stopped() || ctx.Err() != nil {
+		summaryMsg = fmt.Sprintf("\n扫描已中断，已发现 %d 个SSRF测试点\n", vulnerableCount)
+		if ctx.Err() == context.DeadlineExceeded {
+			summaryMsg = fmt.Sprintf("\n已达到最长扫描时间 %s，已发现 %d 个SSRF测试点\n", cfg.MaxScanTime, vulnerableCount)
+		}
+		if cfg.WorkspaceDir != "" && cfg.ResumeFile != "" {
+			summaryMsg += fmt.Sprintf("进度已保存到 %s，将 -workspace 改为 %s 即可继续扫描\n", cfg.ResumeFile, cfg.WorkspaceDir)
+		} else if cfg.ResumeFile != "" {
+			summaryMsg += fmt.Sprintf("进度已保存到 %s，使用相同命令即可继续扫描\n", cfg.ResumeFile)
+		} else {
+			summaryMsg += "提示: 使用 -resume 参数可在中断后继续扫描\n"
+		}
+	}
+	if !cfg.Silent {
+		console.Log(config.ColorNone, summaryMsg)
+	}
+
+	// 按严重程度和置信度统计本次发现的漏洞
+	results := scanManager.Results()
+	if len(results) > 0 && !cfg.Silent {
+		severities := make(map[string]int)
+		confidences := make(map[string]int)
+		for _, result := range results {
+			severities[result.Severity]++
+			confidences[result.Confidence]++
+		}
+		levelMsg := fmt.Sprintf("  严重程度: %s\n  置信度: %s\n",
+			formatCounts(detector.Severities, severities), formatCounts(detector.Confidences, confidences))
+		console.Log(config.ColorNone, levelMsg)
+	}
+
+	// 多目标时输出每个目标的漏洞数量
+	targets, counts := scanManager.TargetVulnCounts()
+	if len(targets) > 1 && !cfg.Silent {
+		for _, target := range targets {
+			targetMsg := fmt.Sprintf("  %s: %d 个SSRF测试点\n", target, counts[target])
+			console.Log(config.ColorNone, targetMsg)
+		}
+	}
+
+	// 保存结果数据库
+	if db != nil {
+		db.EndScan(endTime, scanManager.Stopped() || ctx.Err() != nil, vulnerableCount)
+		if err := db.Close(); err != nil {
+			slog.Error(err.Error())
+		} else {
+			slog.Info(fmt.Sprintf("结果已保存到数据库 %s", cfg.DBFile))
+		}
+	}
+
+	// 发送扫描结束事件，等待所有通知发送完成
+	if len(notifiers) > 0 {
+		summary := notify.NewSummary(targets, startTime, endTime, scanManager.Stopped() || ctx.Err() != nil, results)
+		for _, n := range notifiers {
+			n.Complete(summary)
+		}
+		for _, n := range notifiers {
+			if err := n.Close(); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+	}
+
+	// 生成HTML、Markdown或JSON报告
+	r := &report.Report{
+		Targets:     targets,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Interrupted: scanManager.Stopped() || ctx.Err() != nil,
+		Results:     results,
+		Headers:     cfg.CustomHeaders,
+		ContentType: cfg.BodyContentType(),
+	}
+	if jsonl != nil {
+		if err := jsonl.Err(); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info(fmt.Sprintf("漏洞已逐行保存到 %s", cfg.OutputFile))
+	} else if outputFile != nil {
+		writeReport(cfg, outputFile, r)
+	}
+
+	// 工作区中的报告不是JSON格式时另存一份JSON报告，之后可以通过 report 子命令重新渲染或比较
+	if path := cfg.WorkspacePath(config.WorkspaceReport); path != "" && path != cfg.OutputFile && cfg.OutputFormat != config.FormatJSON && cfg.OutputFormat != config.FormatJSONL {
+		writeWorkspaceReport(path, r)
+	}
+
+	// 发送邮件报告（-email-report）
+	if len(cfg.EmailTo) > 0 {
+		sendEmailReport(cfg, r)
+	}
+
+	// 上传报告和证据文件（-s3）
+	if bucket != nil {
+		if err := uploadArtifacts(cfg, bucket, startTime, results); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// openOutput 创建 -o 指定的输出文件，未指定时返回nil；文本格式时命令行输出同步写入该文件
+func openOutput(cfg *config.Config, console *logging.Console) *os.File {
+	if cfg.OutputFile == "" {
+		return nil
+	}
+	file, err := os.Create(cfg.OutputFile)
+	if err != nil {
+		slog.Error(fmt.Sprintf("创建输出文件失败: %v", err))
+		os.Exit(1)
+	}
+	if cfg.OutputFormat == config.FormatText {
+		console.SetFile(file)
+	}
+	return file
+}
+
+// writeWorkspaceReport 将JSON报告写入工作区
+func writeWorkspaceReport(path string, r *report.Report) {
+	file, err := os.Create(path)
+	if err != nil {
+		slog.Error(fmt.Sprintf("创建工作区报告失败: %v", err))
+		return
+	}
+	defer file.Close()
+	if err := report.WriteJSON(file, r); err != nil {
+		slog.Error(fmt.Sprintf("生成JSON报告失败: %v", err))
+		return
+	}
+	slog.Info(fmt.Sprintf("JSON报告已保存到 %s", path))
+}
+
+// writeReport 按 -format 将HTML、Markdown、JSON、DefectDojo报告、JSON行文件或 -report-template 渲染的报告写入输出文件，文本格式已在扫描过程中写入
+func writeReport(cfg *config.Config, file *os.File, r *report.Report) {
+	var write func(io.Writer, *report.Report) error
+	var name string
+	switch cfg.OutputFormat {
+	case config.FormatHTML:
+		write, name = report.WriteHTML, "HTML"
+	case config.FormatMarkdown:
+		write, name = report.WriteMarkdown, "Markdown"
+	case config.FormatJSON:
+		write, name = report.WriteJSON, "JSON"
+	case config.FormatJSONL:
+		write, name = report.WriteJSONL, "JSONL"
+	case config.FormatDefectDojo:
+		write, name = report.WriteDefectDojo, "DefectDojo"
+	case config.FormatTemplate:
+		tmpl, err := report.ParseTemplate(cfg.ReportTemplate)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		write, name = tmpl.Write, "自定义"
+	default:
+		return
+	}
+	if err := write(file, r); err != nil {
+		slog.Error(fmt.Sprintf("生成%s报告失败: %v", name, err))
+		os.Exit(1)
+	}
+	slog.Info(fmt.Sprintf("%s报告已保存到 %s", name, cfg.OutputFile))
+}
+
+// sendEmailReport 将扫描汇总和报告通过邮件发送给 -email-report 的收件人：-o 为HTML、JSON、Markdown、DefectDojo或自定义模板报告时附带该文件，
+// 否则附带生成的HTML报告；发送失败只输出错误
+func sendEmailReport(cfg *config.Config, r *report.Report) {
+	var attachment *report.Attachment
+	switch cfg.OutputFormat {
+	case config.FormatHTML, config.FormatJSON, config.FormatMarkdown, config.FormatDefectDojo, config.FormatTemplate:
+		if data, err := os.ReadFile(cfg.OutputFile); err == nil {
+			attachment = &report.Attachment{Name: filepath.Base(cfg.OutputFile), Data: data}
+		}
+	}
+	opts := report.EmailOptions{
+		Server:   cfg.SMTPServer,
+		From:     cfg.SMTPFrom,
+		To:       cfg.EmailTo,
+		Username: cfg.SMTPUser,
+		Password: cfg.SMTPPassword,
+	}
+	if err := report.SendEmail(opts, r, attachment); err != nil {
+		slog.Error(err.Error())
+		return
+	}
+	slog.Info(fmt.Sprintf("邮件报告已发送到 %s", strings.Join(cfg.EmailTo, ", ")))
+}
+
+// newBucket 创建 -s3 指定的存储桶，未指定时返回nil；在扫描开始前调用，缺少访问密钥时立即退出
+func newBucket(cfg *config.Config) *s3.Bucket {
+	if cfg.S3 == "" {
+		return nil
+	}
+	bucket, err := s3.New(cfg.S3, cfg.S3Endpoint, cfg.S3Region, time.Duration(cfg.Timeout)*time.Second)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	return bucket
+}
 
+// uploadArtifacts 将输出文件和结果引用的证据文件上传到存储桶：输出文件保存为 前缀/文件名，证据文件保存为 前缀/evidence/文件名
+// 前缀中的 {date} 和 {time} 按 startTime 展开；部分文件上传失败时继续上传其他文件，返回失败的情况
+func uploadArtifacts(cfg *config.Config, bucket *s3.Bucket, startTime time.Time, results []scanner.ScanResult) error {
+	files := make(map[string]string) // 对象键 -> 本地文件
+	if cfg.OutputFile != "" {
+		files[bucket.Key(startTime, filepath.Base(cfg.OutputFile))] = cfg.OutputFile
+	}
+	for _, r := range results {
+		if r.EvidenceFile != "" {
+			files[bucket.Key(startTime, "evidence/"+filepath.Base(r.EvidenceFile))] = r.EvidenceFile
+		}
+	}
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var uploaded, failed int
+	var lastErr error
+	for _, key := range keys {
+		data, err := os.ReadFile(files[key])
+		if os.IsNotExist(err) && files[key] != cfg.OutputFile {
+			// 分布式扫描时证据文件保存在代理节点上
+			slog.Debug(fmt.Sprintf("证据文件 %s 不存在，跳过上传", files[key]))
+			continue
+		}
+		if err == nil {
+			err = bucket.Put(context.Background(), key, data, mime.TypeByExtension(filepath.Ext(key)))
+		}
+		if err != nil {
+			failed++
+			lastErr = err
+			slog.Debug(err.Error())
+			continue
+		}
+		uploaded++
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件上传到S3失败: %v", failed, lastErr)
+	}
+	slog.Info(fmt.Sprintf("已上传 %d 个文件到 s3://%s/%s", uploaded, bucket.Name, bucket.Key(startTime, "")))
+	return nil
+}
+
+// newNotifiers 根据配置创建webhook和Slack/Discord/Telegram通知
+func newNotifiers(cfg *config.Config) ([]notify.Notifier, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	var notifiers []notify.Notifier
+	if cfg.Webhook != "" {
+		notifiers = append(notifiers, notify.NewWebhook(cfg.Webhook, timeout))
+	}
+	if cfg.Syslog != "" {
+		s, err := notify.NewSyslog(cfg.Syslog, timeout)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, s)
+	}
+
+	opts := notify.ChatOptions{MinSeverity: cfg.NotifySeverity, Timeout: timeout}
+	if cfg.NotifyTemplate != "" {
+		data, err := os.ReadFile(cfg.NotifyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("读取消息模板失败: %v", err)
+		}
+		opts.Template = string(data)
+	}
+
+	chats := []struct {
+		enabled bool
+		create  func() (*notify.Chat, error)
+	}{
+		{cfg.Slack != "", func() (*notify.Chat, error) { return notify.NewSlack(cfg.Slack, opts) }},
+		{cfg.Discord != "", func() (*notify.Chat, error) { return notify.NewDiscord(cfg.Discord, opts) }},
+		{cfg.TelegramToken != "", func() (*notify.Chat, error) { return notify.NewTelegram(cfg.TelegramToken, cfg.TelegramChat, opts) }},
+	}
+	for _, chat := range chats {
+		if !chat.enabled {
+			continue
+		}
+		n, err := chat.create()
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// formatCounts 按给定顺序输出非零计数，例如 critical 1 | high 2
+func formatCounts(order []string, counts map[string]int) string {
+	var parts []string
+	for _, name := range order {
+		if counts[name] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", name, counts[name]))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// runAgent 以代理节点模式运行，直到控制节点没有剩余任务；Ctrl+C 时回传当前任务的已有结果后退出
+func runAgent(cfg *config.Config, console *logging.Console) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := cluster.NewAgent(cfg, console).Run(ctx); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// runReplay 重新发送漏洞的原始请求，输出完整的请求和响应报文
+func runReplay(cfg *config.Config, console *logging.Console) {
+	finding, err := replay.Load(cfg.Finding, cfg.DBFile)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	replay.SetBodyType(cfg, finding.RequestBody)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	det := startDetector(ctx, cfg)
+	defer det.Close()
+
+	slog.Info(fmt.Sprintf("重放漏洞: [%s/%s] [%s] %s %s=%s", finding.Severity, finding.Confidence, finding.PayloadType, finding.Target, finding.Parameter, finding.Payload))
+	if finding.Evidence != "" {
+		slog.Info("原始证据: " + finding.Evidence)
+	}
+	exchange, err := det.Replay(ctx, finding.Method, finding.URL, finding.RequestBody)
+	if err != nil {
+		slog.Error(fmt.Sprintf("重放请求失败: %v", err))
+		os.Exit(1)
+	}
+	console.Result(config.ColorNone, exchange+"\n")
+}
+
+// runVerify 复测 -verify 报告中的漏洞，输出每个漏洞的复测结果；指定 -o 时报告中包含复测结果
+func runVerify(cfg *config.Config, console *logging.Console) {
+	findings, err := report.ReadFindings(cfg.VerifyFile)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	outputFile := openOutput(cfg, console)
 	if outputFile != nil {
-		outputFile.WriteString(summaryMsg)
+		defer outputFile.Close()
+	}
+	bucket := newBucket(cfg)
+
+	// Ctrl+C 停止复测，剩余的漏洞标记为 unverified；-max-scan-time 同样限制复测时长
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if cfg.MaxScanTime > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.MaxScanTime)
+		defer cancel()
+	}
+	det := startDetector(ctx, cfg)
+	defer det.Close()
+
+	slog.Info(fmt.Sprintf("开始复测 %s 中的 %d 个漏洞", cfg.VerifyFile, len(findings)))
+	startTime := time.Now()
+	results := scanner.NewScanManager(cfg, det, console, nil, nil, nil).VerifyAll(ctx, findings)
+	endTime := time.Now()
+
+	var targets []string
+	statuses := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, r := range results {
+		statuses[r.Status]++
+		if !seen[r.Target] {
+			seen[r.Target] = true
+			targets = append(targets, r.Target)
+		}
+	}
+	if !cfg.Silent {
+		console.Log(config.ColorNone, fmt.Sprintf("\n复测完成，共 %d 个漏洞: %s\n", len(results),
+			formatCounts([]string{scanner.StatusVulnerable, scanner.StatusFixed, scanner.StatusUnverified}, statuses)))
+	}
+
+	r := &report.Report{
+		Targets:     targets,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Interrupted: ctx.Err() != nil,
+		Results:     results,
+		Headers:     cfg.CustomHeaders,
+		ContentType: cfg.BodyContentType(),
+	}
+	if outputFile != nil {
+		writeReport(cfg, outputFile, r)
+	}
+	if len(cfg.EmailTo) > 0 {
+		sendEmailReport(cfg, r)
+	}
+	if bucket != nil {
+		if err := uploadArtifacts(cfg, bucket, startTime, results); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// runDiff 比较旧报告和新报告，输出新增、已修复和仍存在的漏洞；指定 -o 时保存差异
+func runDiff(cfg *config.Config, console *logging.Console) {
+	old, err := report.ReadFindings(cfg.ReportFiles[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	current, err := report.ReadFindings(cfg.ReportFiles[1])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	outputFile := openOutput(cfg, console)
+	if outputFile != nil {
+		defer outputFile.Close()
+	}
+
+	d := report.Compare(old, current)
+	groups := []struct {
+		label   string
+		color   config.ColorType
+		results []scanner.ScanResult
+	}{
+		{"新增", config.ColorRed, d.New},
+		{"已修复", config.ColorGreen, d.Fixed},
+		{"仍存在", config.ColorYellow, d.Persistent},
+	}
+	for _, g := range groups {
+		for _, r := range g.results {
+			console.Result(g.color, fmt.Sprintf("[%s] [%s/%s] [%s] %s %s=%s\n", g.label, r.Severity, r.Confidence, r.PayloadType, r.Target, r.Parameter, r.Payload))
+		}
+	}
+	if !cfg.Silent {
+		console.Log(config.ColorNone, fmt.Sprintf("\n%s -> %s: 新增 %d 个，已修复 %d 个，仍存在 %d 个漏洞\n",
+			cfg.ReportFiles[0], cfg.ReportFiles[1], len(d.New), len(d.Fixed), len(d.Persistent)))
+	}
+
+	if outputFile != nil && cfg.OutputFormat == config.FormatJSON {
+		if err := report.WriteDiffJSON(outputFile, d); err != nil {
+			slog.Error(fmt.Sprintf("生成JSON报告失败: %v", err))
+			os.Exit(1)
+		}
+		slog.Info(fmt.Sprintf("差异报告已保存到 %s", cfg.OutputFile))
+	}
+}
+
+// runReport 将 report 子命令读取的漏洞按 -o/-format 生成报告；报告的开始和结束时间为原报告文件的修改时间
+func runReport(cfg *config.Config, console *logging.Console) {
+	path := cfg.ReportFiles[0]
+	findings, err := report.ReadFindings(path)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, r := range findings {
+		if !seen[r.Target] {
+			seen[r.Target] = true
+			targets = append(targets, r.Target)
+		}
+	}
+	outputFile := openOutput(cfg, console)
+	defer outputFile.Close()
+	slog.Info(fmt.Sprintf("从 %s 读取 %d 个漏洞", path, len(findings)))
+	writeReport(cfg, outputFile, &report.Report{
+		Targets:   targets,
+		StartTime: modTime,
+		EndTime:   modTime,
+		Results:   findings,
+	})
+}
+
+// runLint 输出 lint 发现的每个问题（标准输出，文件:行号: 问题），有问题时以状态码1退出，便于在扫描脚本中提前失败
+func runLint(cfg *config.Config, console *logging.Console) {
+	files, issues := cfg.Lint(detector.EvidenceKinds)
+	for _, issue := range issues {
+		console.Result(config.ColorRed, issue.String()+"\n")
+	}
+	if len(issues) > 0 {
+		if !cfg.Silent {
+			console.Log(config.ColorNone, fmt.Sprintf("检查了 %d 个文件，发现 %d 个问题\n", len(files), len(issues)))
+		}
+		os.Exit(1)
+	}
+	if !cfg.Silent {
+		console.Log(config.ColorGreen, fmt.Sprintf("检查了 %d 个文件，未发现问题\n", len(files)))
+	}
+}
+
+// runVersion 输出版本号（version），-v 时同时输出编译信息、内置字典的来源和SHA-256以及各扫描模块的内置payload数量，
+// 便于在报告中注明扫描使用的检测项
+func runVersion(cfg *config.Config, console *logging.Console) {
+	build := config.ReadBuildInfo()
+	line := "GoSSRF " + config.Version
+	if build.Commit != "" {
+		line += " (" + shortCommit(build.Commit) + ")"
+	}
+	console.Result(config.ColorNone, line+"\n")
+	if !cfg.Verbose {
+		return
+	}
+
+	commit := "未知 (不是从git仓库编译)"
+	if build.Commit != "" {
+		commit = build.Commit
+		if build.CommitTime != "" {
+			commit += " " + build.CommitTime
+		}
+		if build.Modified {
+			commit += " (编译时有未提交的修改)"
+		}
+	}
+	console.Result(config.ColorNone, fmt.Sprintf("提交: %s\nGo: %s %s/%s\n", commit, build.GoVersion, runtime.GOOS, runtime.GOARCH))
+
+	files, err := payloads.DictFiles()
+	if err != nil {
+		slog.Error(fmt.Sprintf("读取内置字典失败: %v", err))
+		os.Exit(1)
+	}
+	console.Result(config.ColorNone, "字典:\n")
+	for _, file := range files {
+		source := "内置"
+		if file.Path != "" {
+			source = "本地文件 " + file.Path
+		}
+		console.Result(config.ColorNone, fmt.Sprintf("  %-22s %4d 个payload  sha256:%s  %s\n", file.Name, file.Payloads, file.SHA256, source))
+	}
+
+	// 各扫描阶段的内置payload数量（默认端口和本机地址、全部云厂商，不含 -w、-plugin 和编码变种）
+	modules := []string{"ports", "high_risk", "cloud", "k8s", "docker", "infra", "redirect", "dict"}
+	counts := map[string]int{
+		"ports":     payloads.PortScanPayloadCount(nil, nil),
+		"high_risk": len(payloads.GetHighRiskPayloads()),
+		"cloud":     len(payloads.GetCloudMetadataPayloads(nil)),
+		"k8s":       len(payloads.GetKubernetesPayloads()),
+		"docker":    len(payloads.GetDockerPayloads()),
+		"infra":     len(payloads.GetInfraPayloads()),
+		"redirect":  len(payloads.GetOpenRedirectPayloads()),
+		"dict":      len(payloads.GetAllDictPayloads()),
+	}
+	console.Result(config.ColorNone, "内置payload: "+formatCounts(modules, counts)+"\n")
+}
+
+// shortCommit 返回提交哈希的前7位
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// runPayloads 按扫描顺序输出每个参数会发送的payload及其扫描阶段、类型和检测关键字（payloads list），-silent 时只输出payload
+func runPayloads(cfg *config.Config, console *logging.Console) {
+	target := ""
+	if len(cfg.Targets) > 0 {
+		target = cfg.Targets[0]
+	}
+	var types []string
+	counts := make(map[string]int)
+	err := scanner.NewScanManager(cfg, nil, console, nil, nil, nil).EachPayload(target, func(phase string, p payloads.Payload) bool {
+		if counts[p.Type] == 0 {
+			types = append(types, p.Type)
+		}
+		counts[p.Type]++
+		if cfg.Silent {
+			console.Result(config.ColorNone, p.Value+"\n")
+			return true
+		}
+		line := fmt.Sprintf("[%s] [%s] %s", phase, p.Type, p.Value)
+		if len(p.Keywords) > 0 {
+			line += " 关键字: " + strings.Join(p.Keywords, ", ")
+		}
+		console.Result(config.ColorNone, line+"\n")
+		return true
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if !cfg.Silent {
+		console.Log(config.ColorNone, fmt.Sprintf("\n共 %d 个payload: %s\n", total, formatCounts(types, counts)))
+	}
+}
+
+// runGenerate 生成手动测试（例如在Burp Repeater中）使用的payload（payloads gen），指定 -encoders 时在每个payload之后输出编码变种
+// payload 输出到标准输出，说明输出到标准错误（-silent 时不输出）
+func runGenerate(cfg *config.Config, console *logging.Console) {
+	var groups []struct {
+		note  string
+		items []payloads.Payload
+	}
+	add := func(note string, items ...payloads.Payload) {
+		groups = append(groups, struct {
+			note  string
+			items []payloads.Payload
+		}{note, items})
+	}
+
+	switch cfg.Generate {
+	case config.GenerateRedis:
+		w := payloads.NewRedisWrite(cfg.RedisMode, cfg.RedisHost, cfg.RedisPort, cfg.RedisPath, cfg.RedisContent)
+		add("读取当前的 dir 和 dbfilename，清理时恢复", payloads.Payload{Value: payloads.RedisConfigPayload(cfg.RedisHost, cfg.RedisPort)})
+		add(fmt.Sprintf("写入 %s", w.Path), payloads.Payload{Value: w.Payload()})
+		add(fmt.Sprintf("清理: 删除写入的键，dir 和 dbfilename 恢复为 %s/%s（原配置不同时请修改），写入的文件需要手动删除", payloads.RedisDefaultDir, payloads.RedisDefaultFile),
+			payloads.Payload{Value: w.CleanupPayload(payloads.RedisDefaultDir, payloads.RedisDefaultFile)})
+	case config.GenerateIP:
+		ips := []string{"127.0.0.1"}
+		if cfg.InternalIPs != nil && cfg.InternalIPs.Len() > 0 {
+			ips = nil
+			cfg.InternalIPs.Each(func(ip string) bool {
+				ips = append(ips, ip)
+				return true
+			})
+		}
+		ports := []string{""}
+		if len(cfg.PortList) > 0 {
+			ports = nil
+			for _, port := range cfg.PortList {
+				ports = append(ports, fmt.Sprintf(":%d", port))
+			}
+		}
+		for _, ip := range ips {
+			var items []payloads.Payload
+			for _, host := range payloads.IPVariants(ip) {
+				for _, port := range ports {
+					items = append(items, payloads.Payload{Value: "http://" + host + port + "/"})
+				}
+			}
+			add(ip+" 的等价写法", items...)
+		}
+	case config.GenerateCloud:
+		for _, r := range payloads.GetCloudRequests(cfg.CloudList) {
+			note := fmt.Sprintf("%s: %s http://%s%s", r.Provider, r.Method, r.Host, r.Path)
+			for _, h := range r.Headers {
+				note += fmt.Sprintf(" [%s: %s]", h[0], h[1])
+			}
+			add(note, r.Payload())
+		}
+	}
+
+	for _, g := range groups {
+		if !cfg.Silent {
+			console.Log(config.ColorNone, "# "+g.note+"\n")
+		}
+		for _, p := range g.items {
+			payloads.EachVariant(p, cfg.EncoderList, func(variant payloads.Payload) bool {
+				console.Result(config.ColorNone, variant.Value+"\n")
+				return true
+			})
+		}
+	}
+}
+
+// startDetector 创建重放和复测使用的检测器：启动钩子脚本（-script）并登录（-login/-login-script），返回后需要调用 Close
+func startDetector(ctx context.Context, cfg *config.Config) *detector.Detector {
+	det := detector.NewDetector(cfg)
+	if cfg.Script != "" {
+		if err := det.StartScript(); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+	if cfg.LoginRequest != nil || cfg.LoginScript != "" {
+		if err := det.Login(ctx); err != nil {
+			det.Close()
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+	return det
+}
+
+// serveOOB 以独立模式运行OOB回连服务和DNS重绑定服务，每一次回连和DNS查询输出到标准输出
+func serveOOB(console *logging.Console, oobServer *oob.Server, dnsServer *rebind.Server) {
+	if oobServer != nil {
+		oobServer.OnHit(func(hit oob.Hit) {
+			console.Result(config.ColorGreen, fmt.Sprintf("[OOB] %s %s %s%s 来源: %s UA: %s\n",
+				hit.Time.Format("2006-01-02 15:04:05"), hit.Method, hit.Host, hit.Path, hit.RemoteIP, hit.UserAgent))
+		})
+	}
+	var queries atomic.Int64
+	if dnsServer != nil {
+		dnsServer.OnQuery(func(q rebind.Query) {
+			queries.Add(1)
+			console.Result(config.ColorGreen, fmt.Sprintf("[DNS] %s %s 第 %d 次查询，返回 %s 来源: %s\n",
+				q.Time.Format("2006-01-02 15:04:05"), q.Name, q.Count, q.Answer, q.RemoteIP))
+		})
+	}
+
+	slog.Info("等待回连中，按 Ctrl+C 退出")
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	<-sigCh
+
+	if oobServer != nil {
+		slog.Info(fmt.Sprintf("共收到 %d 次回连", len(oobServer.Hits())))
+	}
+	if dnsServer != nil {
+		slog.Info(fmt.Sprintf("共收到 %d 次DNS查询", queries.Load()))
 	}
 }
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// 聊天通知平台
+const (
+	PlatformSlack    = "slack"
+	PlatformDiscord  = "discord"
+	PlatformTelegram = "telegram"
+)
+
+// telegramAPI Telegram Bot API地址
+var telegramAPI = "https://api.telegram.org"
+
+// 各平台单条消息的最大长度
+var messageLimits = map[string]int{
+	PlatformSlack:    3000,
+	PlatformDiscord:  2000,
+	PlatformTelegram: 4096,
+}
+
+// DefaultFindingTemplate 默认的漏洞消息模板，模板数据为 Finding
+const DefaultFindingTemplate = `[GoSSRF] 发现 {{.Severity}} 级SSRF漏洞（{{.Confidence}}）
+目标: {{.Target}}
+参数: {{.Parameter}}
+Payload: {{.Payload}}
+类型: {{.PayloadType}}
+证据: {{.Evidence}}`
+
+// summaryTemplate 扫描结束消息模板，模板数据为 Summary
+const summaryTemplate = `[GoSSRF] {{if .Interrupted}}扫描已中断{{else}}扫描完成{{end}}，{{len .Targets}} 个目标，发现 {{.VulnCount}} 个SSRF测试点
+{{- range $s := severities}}{{with index $.Severities $s}}
+{{$s}}: {{.}}{{end}}{{end}}
+耗时: {{duration .StartTime .EndTime}}`
+
+// ChatOptions 聊天通知选项
+type ChatOptions struct {
+	MinSeverity string        // 只通知不低于该严重程度的漏洞，为空时通知所有漏洞
+	Template    string        // 漏洞消息模板（text/template，数据为 Finding），为空时使用默认模板
+	Timeout     time.Duration // 请求超时时间
+}
+
+// Chat 向Slack/Discord/Telegram发送漏洞消息（-slack/-discord/-telegram-token参数）
+// 达到最低严重程度的漏洞在发现时立即发送，扫描结束时发送汇总
+type Chat struct {
+	platform string
+	url      string
+	chatID   string // Telegram会话ID
+	secret   string // 错误信息中需要隐藏的内容（Telegram Bot Token）
+	minRank  int
+	finding  *template.Template
+	summary  *template.Template
+	client   *http.Client
+	queue    *queue
+}
+
+// NewSlack 创建Slack通知（Incoming Webhook地址）
+func NewSlack(webhookURL string, opts ChatOptions) (*Chat, error) {
+	return newChat(PlatformSlack, webhookURL, "", "", opts)
+}
+
+// NewDiscord 创建Discord通知（频道Webhook地址）
+func NewDiscord(webhookURL string, opts ChatOptions) (*Chat, error) {
+	return newChat(PlatformDiscord, webhookURL, "", "", opts)
+}
+
+// NewTelegram 创建Telegram通知（Bot Token 和会话ID）
+func NewTelegram(botToken, chatID string, opts ChatOptions) (*Chat, error) {
+	return newChat(PlatformTelegram, telegramAPI+"/bot"+botToken+"/sendMessage", chatID, botToken, opts)
+}
+
+// newChat 解析模板并启动后台发送
+func newChat(platform, url, chatID, secret string, opts ChatOptions) (*Chat, error) {
+	minRank := len(detector.Severities) - 1
+	if opts.MinSeverity != "" {
+		minRank = severityRank(opts.MinSeverity)
+		if minRank < 0 {
+			return nil, fmt.Errorf("不支持的严重程度: %s (支持 %s)", opts.MinSeverity, strings.Join(detector.Severities, "/"))
+		}
+	}
+
+	text := opts.Template
+	if text == "" {
+		text = DefaultFindingTemplate
+	}
+	finding, err := template.New("finding").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板失败: %v", err)
+	}
+	summary := template.Must(template.New("summary").Funcs(template.FuncMap{
+		"severities": func() []string { return detector.Severities },
+		"duration":   func(start, end time.Time) string { return end.Sub(start).Round(time.Second).String() },
+	}).Parse(summaryTemplate))
+
+	c := &Chat{
+		platform: platform,
+		url:      url,
+		chatID:   chatID,
+		secret:   secret,
+		minRank:  minRank,
+		finding:  finding,
+		summary:  summary,
+		client:   &http.Client{Timeout: opts.Timeout},
+	}
+	c.queue = newQueue(platform, c.post)
+	return c, nil
+}
+
+// Finding 发送漏洞消息，未发现漏洞或低于最低严重程度的结果忽略，可作为 ScanManager.OnResult 的处理函数
+func (c *Chat) Finding(result scanner.ScanResult) {
+	if !result.Vulnerable || severityRank(result.Severity) > c.minRank {
+		return
+	}
+	finding := NewFinding(result)
+	c.queue.push(Event{Event: EventFinding, Time: time.Now(), Finding: &finding})
+}
+
+// Complete 发送扫描结束汇总
+func (c *Chat) Complete(summary Summary) {
+	c.queue.push(Event{Event: EventScanComplete, Time: time.Now(), Summary: &summary})
+}
+
+// Close 等待已产生的消息发送完成，返回发送失败的情况
+func (c *Chat) Close() error {
+	return c.queue.close()
+}
+
+// post 渲染消息并按平台格式发送
+func (c *Chat) post(event Event) error {
+	var builder strings.Builder
+	var err error
+	if event.Finding != nil {
+		err = c.finding.Execute(&builder, event.Finding)
+	} else {
+		err = c.summary.Execute(&builder, event.Summary)
+	}
+	if err != nil {
+		return fmt.Errorf("渲染消息失败: %v", err)
+	}
+	text := truncateMessage(builder.String(), messageLimits[c.platform])
+
+	var body interface{}
+	switch c.platform {
+	case PlatformSlack:
+		body = map[string]interface{}{"text": escapeSlack(text)}
+	case PlatformDiscord:
+		// 不解析消息中的 @everyone 等提及（payload和证据来自目标响应）
+		body = map[string]interface{}{"content": text, "allowed_mentions": map[string]interface{}{"parse": []string{}}}
+	case PlatformTelegram:
+		body = map[string]interface{}{"chat_id": c.chatID, "text": text, "disable_web_page_preview": true}
+	}
+
+	if err := postJSON(c.client, c.url, body); err != nil {
+		if c.secret != "" {
+			return errors.New(strings.ReplaceAll(err.Error(), c.secret, "***"))
+		}
+		return err
+	}
+	return nil
+}
+
+// severityRank 严重程度的排序位置（0为最高），不支持的严重程度返回-1
+func severityRank(severity string) int {
+	for i, s := range detector.Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// truncateMessage 截断超过平台长度限制的消息（按字符计算）
+func truncateMessage(text string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-3]) + "..."
+}
+
+// escapeSlack 转义Slack消息中的控制字符 & < >
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
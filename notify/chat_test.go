@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+// chatServer 记录收到的请求路径和JSON内容
+func chatServer(t *testing.T, status int) (*httptest.Server, func() []map[string]interface{}, func() []string) {
+	var mux sync.Mutex
+	var bodies []map[string]interface{}
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析消息失败: %v", err)
+		}
+		bodies = append(bodies, body)
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []map[string]interface{} { return bodies }, func() []string { return paths }
+}
+
+var (
+	criticalFinding = scanner.ScanResult{Target: "http://a.example/", Parameter: "url", Payload: "http://169.254.169.254/<x>&",
+		PayloadType: "云元数据", Severity: "critical", Confidence: "confirmed", Evidence: "AccessKeyId", Vulnerable: true}
+	lowFinding = scanner.ScanResult{Target: "http://a.example/", Parameter: "url", Payload: "http://127.0.0.1:8080/",
+		PayloadType: "端口扫描", Severity: "low", Confidence: "tentative", Vulnerable: true}
+)
+
+func TestChatPlatforms(t *testing.T) {
+	server, bodies, paths := chatServer(t, http.StatusOK)
+	telegramAPI = server.URL
+	opts := ChatOptions{MinSeverity: "high", Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name  string
+		chat  func() (*Chat, error)
+		field string
+		path  string
+		check func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			name:  "Slack",
+			chat:  func() (*Chat, error) { return NewSlack(server.URL+"/slack", opts) },
+			field: "text",
+			path:  "/slack",
+			check: func(t *testing.T, body map[string]interface{}) {
+				if !strings.Contains(body["text"].(string), "http://169.254.169.254/&lt;x&gt;&amp;") {
+					t.Errorf("Slack消息未转义: %v", body["text"])
+				}
+			},
+		},
+		{
+			name:  "Discord",
+			chat:  func() (*Chat, error) { return NewDiscord(server.URL+"/discord", opts) },
+			field: "content",
+			path:  "/discord",
+			check: func(t *testing.T, body map[string]interface{}) {
+				if _, ok := body["allowed_mentions"]; !ok {
+					t.Error("Discord消息缺少 allowed_mentions")
+				}
+			},
+		},
+		{
+			name:  "Telegram",
+			chat:  func() (*Chat, error) { return NewTelegram("123:abc", "-100", opts) },
+			field: "text",
+			path:  "/bot123:abc/sendMessage",
+			check: func(t *testing.T, body map[string]interface{}) {
+				if body["chat_id"] != "-100" {
+					t.Errorf("chat_id = %v", body["chat_id"])
+				}
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.chat()
+			if err != nil {
+				t.Fatalf("创建通知失败: %v", err)
+			}
+			c.Finding(lowFinding) // 低于最低严重程度，不发送
+			c.Finding(criticalFinding)
+			start := time.Unix(1700000000, 0)
+			c.Complete(NewSummary([]string{"http://a.example/"}, start, start.Add(90*time.Second), false,
+				[]scanner.ScanResult{criticalFinding, lowFinding}))
+			if err := c.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := bodies()[i*2:]
+			if len(got) != 2 {
+				t.Fatalf("收到 %d 条消息, want 2", len(got))
+			}
+			if p := paths()[i*2]; p != tt.path {
+				t.Errorf("请求路径 = %s, want %s", p, tt.path)
+			}
+			finding, _ := got[0][tt.field].(string)
+			if !strings.Contains(finding, "发现 critical 级SSRF漏洞") || !strings.Contains(finding, "AccessKeyId") {
+				t.Errorf("漏洞消息 = %q", finding)
+			}
+			summary, _ := got[1][tt.field].(string)
+			for _, want := range []string{"扫描完成，1 个目标，发现 2 个SSRF测试点", "critical: 1", "low: 1", "耗时: 1m30s"} {
+				if !strings.Contains(summary, want) {
+					t.Errorf("汇总消息缺少 %q: %q", want, summary)
+				}
+			}
+			tt.check(t, got[0])
+		})
+	}
+}
+
+func TestChatTemplate(t *testing.T) {
+	server, bodies, _ := chatServer(t, http.StatusOK)
+	c, err := NewDiscord(server.URL, ChatOptions{Template: "{{.Severity}} {{.Target}} {{.Payload}}", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Finding(lowFinding) // 未指定最低严重程度时通知所有漏洞
+	c.Close()
+
+	if got := bodies()[0]["content"]; got != "low http://a.example/ http://127.0.0.1:8080/" {
+		t.Errorf("content = %v", got)
+	}
+}
+
+func TestChatErrors(t *testing.T) {
+	if _, err := NewSlack("http://x", ChatOptions{MinSeverity: "urgent"}); err == nil {
+		t.Error("不支持的严重程度应返回错误")
+	}
+	if _, err := NewSlack("http://x", ChatOptions{Template: "{{.Target"}); err == nil {
+		t.Error("无效模板应返回错误")
+	}
+
+	// 发送失败时错误信息中不包含Bot Token
+	server, _, _ := chatServer(t, http.StatusForbidden)
+	telegramAPI = server.URL
+	c, err := NewTelegram("123:secret-token", "1", ChatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Finding(criticalFinding)
+	err = c.Close()
+	if err == nil {
+		t.Fatal("Close() 应返回发送失败")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("错误信息泄露Token: %v", err)
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	if got := truncateMessage("漏洞漏洞漏洞", 5); got != "漏洞..." {
+		t.Errorf("truncateMessage() = %q", got)
+	}
+	if got := truncateMessage("abc", 5); got != "abc" {
+		t.Errorf("truncateMessage() = %q", got)
+	}
+}
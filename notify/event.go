@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+// 事件类型
+const (
+	EventFinding      = "finding"       // 发现漏洞
+	EventScanComplete = "scan_complete" // 扫描结束（包括被中断）
+)
+
+// Event 通知事件
+type Event struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Finding *Finding  `json:"finding,omitempty"`
+	Summary *Summary  `json:"summary,omitempty"`
+}
+
+// Finding 漏洞信息
+type Finding struct {
+	Target       string `json:"target"`
+	Method       string `json:"method,omitempty"`
+	URL          string `json:"url,omitempty"`
+	RequestBody  string `json:"request_body,omitempty"`
+	Parameter    string `json:"parameter"`
+	Payload      string `json:"payload"`
+	PayloadType  string `json:"payload_type"`
+	StatusCode   int    `json:"status_code,omitempty"`
+	Severity     string `json:"severity"`
+	Confidence   string `json:"confidence"`
+	Evidence     string `json:"evidence"`
+	Kind         string `json:"kind,omitempty"`    // 证据类别
+	Service      string `json:"service,omitempty"` // 端口扫描识别出的内网服务和版本
+	Fingerprint  string `json:"fingerprint"`       // 漏洞指纹，多次扫描间保持不变
+	EvidenceFile string `json:"evidence_file,omitempty"`
+	Status       string `json:"status,omitempty"` // 复测结果（-verify参数）
+}
+
+// Summary 扫描结果汇总
+type Summary struct {
+	Targets     []string       `json:"targets"`
+	StartTime   time.Time      `json:"started_at"`
+	EndTime     time.Time      `json:"finished_at"`
+	Interrupted bool           `json:"interrupted"`
+	VulnCount   int            `json:"vuln_count"`
+	Severities  map[string]int `json:"severities"`
+	Confidences map[string]int `json:"confidences"`
+	TargetVulns map[string]int `json:"target_vulns"`
+}
+
+// NewFinding 将扫描结果转换为漏洞信息
+func NewFinding(r scanner.ScanResult) Finding {
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = scanner.Fingerprint(r)
+	}
+	return Finding{
+		Target:       r.Target,
+		Method:       r.Method,
+		URL:          r.URL,
+		RequestBody:  r.RequestBody,
+		Parameter:    r.Parameter,
+		Payload:      r.Payload,
+		PayloadType:  r.PayloadType,
+		StatusCode:   r.StatusCode,
+		Severity:     r.Severity,
+		Confidence:   r.Confidence,
+		Evidence:     r.Evidence,
+		Kind:         r.Kind,
+		Service:      r.Service,
+		Fingerprint:  fingerprint,
+		EvidenceFile: r.EvidenceFile,
+		Status:       r.Status,
+	}
+}
+
+// ScanResult 将漏洞信息转换回扫描结果（重放和复测已保存的漏洞）
+func (f Finding) ScanResult() scanner.ScanResult {
+	return scanner.ScanResult{
+		Target:       f.Target,
+		Method:       f.Method,
+		URL:          f.URL,
+		RequestBody:  f.RequestBody,
+		Parameter:    f.Parameter,
+		Payload:      f.Payload,
+		PayloadType:  f.PayloadType,
+		StatusCode:   f.StatusCode,
+		Vulnerable:   true,
+		Severity:     f.Severity,
+		Confidence:   f.Confidence,
+		Evidence:     f.Evidence,
+		Kind:         f.Kind,
+		Service:      f.Service,
+		Fingerprint:  f.Fingerprint,
+		EvidenceFile: f.EvidenceFile,
+		Status:       f.Status,
+	}
+}
+
+// ParseFinding 解析漏洞JSON，支持完整的漏洞事件和单独的 finding 对象
+func ParseFinding(data []byte) (Finding, error) {
+	var event struct {
+		Finding
+		Nested *Finding `json:"finding"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return Finding{}, fmt.Errorf("解析漏洞JSON失败: %v", err)
+	}
+	if event.Nested != nil {
+		return *event.Nested, nil
+	}
+	return event.Finding, nil
+}
+
+// NewSummary 汇总扫描结果
+func NewSummary(targets []string, start, end time.Time, interrupted bool, results []scanner.ScanResult) Summary {
+	s := Summary{
+		Targets:     targets,
+		StartTime:   start,
+		EndTime:     end,
+		Interrupted: interrupted,
+		VulnCount:   len(results),
+		Severities:  make(map[string]int),
+		Confidences: make(map[string]int),
+		TargetVulns: make(map[string]int),
+	}
+	for _, r := range results {
+		s.Severities[r.Severity]++
+		s.Confidences[r.Confidence]++
+		s.TargetVulns[r.Target]++
+	}
+	return s
+}
+
+// Notifier 通知方式（webhook、Slack、Discord、Telegram）
+type Notifier interface {
+	Finding(result scanner.ScanResult) // 每个扫描结果调用一次，只处理发现漏洞的结果
+	Complete(summary Summary)          // 扫描结束时调用
+	Close() error                      // 等待所有通知发送完成
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+)
+
+// queue 在后台按顺序投递事件，不阻塞扫描
+type queue struct {
+	name    string // 通知名称，用于错误信息
+	deliver func(Event) error
+	ch      chan Event
+	done    chan struct{}
+	sendMux sync.Mutex // 保护 closed，避免向已关闭的队列发送
+	closed  bool
+	mux     sync.Mutex
+	failed  int   // 投递失败的事件数量
+	err     error // 最近一次投递失败的原因
+}
+
+// newQueue 创建事件队列并启动后台投递
+func newQueue(name string, deliver func(Event) error) *queue {
+	q := &queue{
+		name:    name,
+		deliver: deliver,
+		ch:      make(chan Event, 100),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// push 将事件加入队列，close 之后产生的事件（例如迟到的OOB回连）丢弃
+func (q *queue) push(event Event) {
+	q.sendMux.Lock()
+	defer q.sendMux.Unlock()
+	if q.closed {
+		return
+	}
+	q.ch <- event
+}
+
+// close 等待已加入的事件投递完成，返回投递失败的情况
+func (q *queue) close() error {
+	q.sendMux.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.sendMux.Unlock()
+
+	<-q.done
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.failed > 0 {
+		return fmt.Errorf("%d 个%s事件发送失败: %v", q.failed, q.name, q.err)
+	}
+	return nil
+}
+
+// run 依次投递队列中的事件
+func (q *queue) run() {
+	defer close(q.done)
+	for event := range q.ch {
+		if err := q.deliver(event); err != nil {
+			q.mux.Lock()
+			q.failed++
+			q.err = err
+			q.mux.Unlock()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// syslogFacility 发送的syslog消息使用的facility（local0）
+const syslogFacility = 16
+
+// syslogSDID 结构化数据的SD-ID，32473 为RFC 5612保留给文档和示例的企业编号
+const syslogSDID = "gossrf@32473"
+
+// syslogSeverities GoSSRF严重程度对应的syslog严重程度，扫描结束事件使用 informational
+var syslogSeverities = map[string]int{
+	detector.SeverityCritical: 2, // critical
+	detector.SeverityHigh:     3, // error
+	detector.SeverityMedium:   4, // warning
+	detector.SeverityLow:      5, // notice
+	detector.SeverityInfo:     6, // informational
+}
+
+// Syslog 将漏洞和扫描结束事件按RFC 5424格式发送到syslog服务（-syslog参数）
+// 支持 udp://（每个数据报一条消息）、tcp:// 和 tls://（RFC 5425的八位组计数分帧），消息内容为与webhook相同的JSON事件
+type Syslog struct {
+	network   string // udp 或 tcp
+	addr      string
+	tlsConfig *tls.Config // tls:// 时使用，按系统根证书验证服务端
+	timeout   time.Duration
+	hostname  string
+	conn      net.Conn
+	queue     *queue
+}
+
+// NewSyslog 解析syslog地址并建立连接，启动后台发送；未指定端口时UDP和TCP使用514，TLS使用6514
+func NewSyslog(rawURL string, timeout time.Duration) (*Syslog, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("无效的syslog地址: %s (需要 udp://、tcp:// 或 tls:// 开头)", rawURL)
+	}
+	s := &Syslog{timeout: timeout, hostname: "-"}
+	port := "514"
+	switch strings.ToLower(u.Scheme) {
+	case "udp":
+		s.network = "udp"
+	case "tcp":
+		s.network = "tcp"
+	case "tls":
+		s.network, port = "tcp", "6514"
+		s.tlsConfig = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, fmt.Errorf("无效的syslog地址: %s (需要 udp://、tcp:// 或 tls:// 开头)", rawURL)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	s.addr = net.JoinHostPort(u.Hostname(), port)
+	if name, err := os.Hostname(); err == nil && name != "" {
+		s.hostname = name
+	}
+
+	// 启动时建立连接，地址错误或服务不可达时立即报错
+	if err := s.connect(); err != nil {
+		return nil, fmt.Errorf("连接syslog服务 %s 失败: %v", rawURL, err)
+	}
+	s.queue = newQueue("syslog", s.send)
+	return s, nil
+}
+
+// Finding 发送漏洞事件，未发现漏洞的结果忽略，可作为 ScanManager.OnResult 的处理函数
+func (s *Syslog) Finding(result scanner.ScanResult) {
+	if !result.Vulnerable {
+		return
+	}
+	finding := NewFinding(result)
+	s.queue.push(Event{Event: EventFinding, Time: time.Now(), Finding: &finding})
+}
+
+// Complete 发送扫描结束事件
+func (s *Syslog) Complete(summary Summary) {
+	s.queue.push(Event{Event: EventScanComplete, Time: time.Now(), Summary: &summary})
+}
+
+// Close 等待已产生的事件发送完成并关闭连接，返回发送失败的情况
+func (s *Syslog) Close() error {
+	err := s.queue.close()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return err
+}
+
+// connect 建立到syslog服务的连接
+func (s *Syslog) connect() error {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	var err error
+	if s.tlsConfig != nil {
+		s.conn, err = tls.DialWithDialer(dialer, s.network, s.addr, s.tlsConfig)
+	} else {
+		s.conn, err = dialer.Dial(s.network, s.addr)
+	}
+	return err
+}
+
+// send 发送单个事件，TCP连接断开时重新连接并重试一次
+func (s *Syslog) send(event Event) error {
+	msg, err := s.format(event)
+	if err != nil {
+		return err
+	}
+	if s.network == "tcp" {
+		// RFC 5425/6587 八位组计数：消息长度 空格 消息
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	for attempt := 1; ; attempt++ {
+		if s.conn == nil {
+			err = s.connect()
+		}
+		if err == nil {
+			s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+			if _, err = s.conn.Write(msg); err == nil {
+				return nil
+			}
+			s.conn.Close()
+			s.conn = nil
+		}
+		if attempt >= 2 {
+			return err
+		}
+	}
+}
+
+// format 按RFC 5424生成一条syslog消息：<PRI>1 时间 主机名 gossrf 进程号 事件类型 [结构化数据] JSON事件
+func (s *Syslog) format(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	severity := 6
+	sd := "-"
+	if f := event.Finding; f != nil {
+		if level, ok := syslogSeverities[f.Severity]; ok {
+			severity = level
+		}
+		sd = syslogStructuredData(
+			"target", f.Target, "parameter", f.Parameter, "payload", f.Payload, "type", f.PayloadType,
+			"severity", f.Severity, "confidence", f.Confidence, "kind", f.Kind, "fingerprint", f.Fingerprint)
+	} else if sum := event.Summary; sum != nil {
+		sd = syslogStructuredData("vuln_count", strconv.Itoa(sum.VulnCount), "interrupted", strconv.FormatBool(sum.Interrupted))
+	}
+	header := fmt.Sprintf("<%d>1 %s %s gossrf %d %s %s ", syslogFacility*8+severity,
+		event.Time.Format("2006-01-02T15:04:05.000000Z07:00"), s.hostname, os.Getpid(), event.Event, sd)
+	return append([]byte(header), data...), nil
+}
+
+// syslogStructuredData 生成结构化数据元素，参数为交替的名称和值，值为空的参数省略
+// 值中的 "、\ 和 ] 按RFC 5424转义
+func syslogStructuredData(params ...string) string {
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(params[i+1])
+		fmt.Fprintf(&b, ` %s="%s"`, params[i], value)
+	}
+	b.WriteString("]")
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestSyslog(t *testing.T) {
+	finding := scanner.ScanResult{Target: "http://a.example/", Parameter: "url", Payload: `file:///etc/"passwd"]`,
+		PayloadType: "文件读取", Kind: "keyword", Severity: "critical", Confidence: "confirmed", Vulnerable: true}
+	start := time.Now()
+	summary := NewSummary([]string{"http://a.example/"}, start, start.Add(time.Minute), false, []scanner.ScanResult{finding})
+
+	tests := []struct {
+		name    string
+		network string
+	}{
+		{"UDP", "udp"},
+		{"TCP八位组计数", "tcp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, addr := syslogServer(t, tt.network)
+			s, err := NewSyslog(tt.network+"://"+addr, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Finding(scanner.ScanResult{Target: "http://a.example/", Payload: "http://127.0.0.1/"}) // 未发现漏洞，忽略
+			s.Finding(finding)
+			s.Complete(summary)
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			var got []string
+			for i := 0; i < 2; i++ {
+				select {
+				case msg := <-messages:
+					got = append(got, msg)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("只收到 %d 条消息: %q", len(got), got)
+				}
+			}
+
+			// critical 对应 local0.crit：16*8+2
+			if !strings.HasPrefix(got[0], "<130>1 ") || !strings.Contains(got[0], " gossrf ") || !strings.Contains(got[0], " finding [gossrf@32473 ") {
+				t.Errorf("漏洞消息头 = %q", got[0])
+			}
+			if !strings.Contains(got[0], `payload="file:///etc/\"passwd\"\]"`) || !strings.Contains(got[0], `kind="keyword"`) {
+				t.Errorf("结构化数据没有转义或缺少字段: %q", got[0])
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(got[0][strings.Index(got[0], "] {")+2:]), &event); err != nil || event.Finding == nil || event.Finding.Fingerprint == "" {
+				t.Errorf("消息内容不是漏洞事件: %v %q", err, got[0])
+			}
+			if !strings.HasPrefix(got[1], "<134>1 ") || !strings.Contains(got[1], ` scan_complete [gossrf@32473 vuln_count="1" interrupted="false"] {`) {
+				t.Errorf("扫描结束消息 = %q", got[1])
+			}
+		})
+	}
+}
+
+func TestNewSyslogInvalid(t *testing.T) {
+	for _, addr := range []string{"http://10.0.0.9:514", "udp://", "10.0.0.9:514"} {
+		if _, err := NewSyslog(addr, time.Second); err == nil {
+			t.Errorf("NewSyslog(%q) 应该返回错误", addr)
+		}
+	}
+}
+
+// syslogServer 启动本地syslog服务，返回收到的消息（TCP按八位组计数分帧）和监听地址
+func syslogServer(t *testing.T, network string) (<-chan string, string) {
+	t.Helper()
+	messages := make(chan string, 10)
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		go func() {
+			buf := make([]byte, 64*1024)
+			for {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				messages <- string(buf[:n])
+			}
+		}()
+		return messages, conn.LocalAddr().String()
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+			if err != nil {
+				t.Errorf("消息长度前缀 %q 无效", prefix)
+				return
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			messages <- string(buf)
+		}
+	}()
+	return messages, ln.Addr().String()
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+// webhookRetries 发送失败（网络错误或5xx）时的最多尝试次数
+const webhookRetries = 3
+
+// Webhook 将漏洞和扫描结束事件以JSON格式POST到指定地址（-webhook参数）
+// 事件在后台按发生顺序依次发送，不阻塞扫描
+type Webhook struct {
+	url    string
+	client *http.Client
+	queue  *queue
+}
+
+// NewWebhook 创建webhook通知并启动后台发送
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	w := &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+	w.queue = newQueue("webhook", w.post)
+	return w
+}
+
+// Finding 发送漏洞事件，未发现漏洞的结果忽略，可作为 ScanManager.OnResult 的处理函数
+func (w *Webhook) Finding(result scanner.ScanResult) {
+	if !result.Vulnerable {
+		return
+	}
+	finding := NewFinding(result)
+	w.queue.push(Event{Event: EventFinding, Time: time.Now(), Finding: &finding})
+}
+
+// Complete 发送扫描结束事件
+func (w *Webhook) Complete(summary Summary) {
+	w.queue.push(Event{Event: EventScanComplete, Time: time.Now(), Summary: &summary})
+}
+
+// Close 等待已产生的事件发送完成，返回发送失败的情况
+func (w *Webhook) Close() error {
+	return w.queue.close()
+}
+
+// post 发送单个事件
+func (w *Webhook) post(event Event) error {
+	return postJSON(w.client, w.url, event)
+}
+
+// postJSON 以JSON格式POST数据，网络错误和5xx响应时重试
+func postJSON(client *http.Client, url string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := postOnce(client, url, data)
+		if err == nil || !retry || attempt >= webhookRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// postOnce 发送一次请求，retry 表示失败原因是否值得重试
+func postOnce(client *http.Client, url string, data []byte) (retry bool, err error) {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, fmt.Errorf("返回状态码 %d", resp.StatusCode)
+	}
+	return false, nil
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestWebhook(t *testing.T) {
+	var mux sync.Mutex
+	var events []Event
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+		requests++
+		// 第一次请求返回500，验证重试
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("解析事件失败: %v", err)
+		}
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	finding := scanner.ScanResult{Target: "http://a.example/", Parameter: "url", Payload: "file:///etc/passwd",
+		PayloadType: "文件读取", Severity: "critical", Confidence: "confirmed", Vulnerable: true}
+	w := NewWebhook(server.URL, 5*time.Second)
+	w.Finding(scanner.ScanResult{Target: "http://a.example/", Payload: "http://127.0.0.1/"}) // 未发现漏洞，忽略
+	w.Finding(finding)
+	start := time.Now()
+	w.Complete(NewSummary([]string{"http://a.example/"}, start, start.Add(time.Minute), true, []scanner.ScanResult{finding}))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	w.Finding(finding) // Close 之后的事件丢弃
+
+	if len(events) != 2 {
+		t.Fatalf("收到 %d 个事件, want 2", len(events))
+	}
+	if events[0].Event != EventFinding || events[0].Finding == nil || events[0].Finding.Payload != finding.Payload {
+		t.Errorf("漏洞事件 = %+v", events[0])
+	}
+	summary := events[1].Summary
+	if events[1].Event != EventScanComplete || summary == nil {
+		t.Fatalf("扫描结束事件 = %+v", events[1])
+	}
+	if summary.VulnCount != 1 || summary.Severities["critical"] != 1 || !summary.Interrupted {
+		t.Errorf("汇总 = %+v", summary)
+	}
+}
+
+func TestWebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	w := NewWebhook(server.URL, 5*time.Second)
+	w.Complete(Summary{})
+	if err := w.Close(); err == nil {
+		t.Error("Close() 应返回发送失败")
+	}
+}
@@ -0,0 +1,114 @@
+package oob
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 回连标识嵌入方式
+const (
+	ModePath      = "path"      // http://oob.com/<token>/callback
+	ModeSubdomain = "subdomain" // http://<token>.oob.com/callback（适用于DNS回连平台）
+)
+
+// tokenPattern 回连URL中的唯一标识（16位十六进制）
+var tokenPattern = regexp.MustCompile(`[0-9a-f]{16}`)
+
+// Callback 已下发的回连地址及其对应的测试请求
+type Callback struct {
+	Token   string    `json:"token"`
+	Target  string    `json:"target"`
+	Param   string    `json:"param"`
+	Payload string    `json:"payload"`
+	Created time.Time `json:"created"`
+}
+
+// Registry 回连标识登记表，记录每个标识对应的 目标+参数+payload
+type Registry struct {
+	callbacks map[string]*Callback
+	mapFile   *os.File
+	mux       sync.Mutex
+}
+
+// NewRegistry 创建回连标识登记表
+// mapFile 不为空时将每条记录以JSON行追加写入文件，便于与外部OOB平台的日志关联
+func NewRegistry(mapFile string) (*Registry, error) {
+	r := &Registry{callbacks: make(map[string]*Callback)}
+	if mapFile != "" {
+		file, err := os.OpenFile(mapFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("创建OOB关联文件失败: %v", err)
+		}
+		r.mapFile = file
+	}
+	return r, nil
+}
+
+// Register 登记一次测试请求的回连标识（标识由 NewToken 生成）
+func (r *Registry) Register(cb Callback) {
+	if cb.Created.IsZero() {
+		cb.Created = time.Now()
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.callbacks[cb.Token] = &cb
+	if r.mapFile != nil {
+		if line, err := json.Marshal(cb); err == nil {
+			r.mapFile.Write(append(line, '\n'))
+		}
+	}
+}
+
+// Lookup 在字符串（Host、路径等）中查找已登记的标识
+func (r *Registry) Lookup(s string) *Callback {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, token := range tokenPattern.FindAllString(strings.ToLower(s), -1) {
+		if cb, ok := r.callbacks[token]; ok {
+			return cb
+		}
+	}
+	return nil
+}
+
+// Close 关闭关联文件
+func (r *Registry) Close() error {
+	if r.mapFile == nil {
+		return nil
+	}
+	return r.mapFile.Close()
+}
+
+// EmbedToken 将标识嵌入回连URL的路径或子域名
+func EmbedToken(callbackURL, token, mode string) string {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || parsed.Host == "" {
+		return callbackURL + "-" + token
+	}
+
+	if mode == ModeSubdomain {
+		parsed.Host = token + "." + parsed.Host
+	} else {
+		parsed.Path = "/" + token + parsed.Path
+	}
+	return parsed.String()
+}
+
+// NewToken 生成16位十六进制唯一标识
+func NewToken() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		// 随机数生成失败时退化为时间戳
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
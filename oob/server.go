@@ -0,0 +1,149 @@
+package oob
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hit 收到的一次回连
+type Hit struct {
+	Time      time.Time
+	RemoteIP  string
+	Method    string
+	Host      string
+	Path      string
+	UserAgent string
+	Callback  *Callback // 关联到的payload，未能关联时为nil
+}
+
+// Server 内置OOB回连服务
+type Server struct {
+	listenAddr string
+	baseURL    string
+	httpServer *http.Server
+	registry   *Registry
+	hits       []Hit
+	onHit      func(Hit)
+	mux        sync.Mutex
+}
+
+// NewServer 创建OOB回连服务
+// listenAddr: 监听地址，例如 :8088
+// publicURL: payload中使用的回连地址，为空时根据监听地址和本机IP推断
+// registry: 回连标识登记表，收到回连时据此关联到具体的测试请求
+func NewServer(listenAddr, publicURL string, registry *Registry) (*Server, error) {
+	if publicURL == "" {
+		host, port, err := net.SplitHostPort(listenAddr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的监听地址: %v", err)
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = localIP()
+		}
+		publicURL = "http://" + net.JoinHostPort(host, port)
+	}
+
+	return &Server{
+		listenAddr: listenAddr,
+		baseURL:    strings.TrimRight(publicURL, "/"),
+		registry:   registry,
+	}, nil
+}
+
+// Start 启动HTTP监听（非阻塞）
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("OOB服务监听失败: %v", err)
+	}
+
+	s.httpServer = &http.Server{
+		Handler:           http.HandlerFunc(s.handle),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go s.httpServer.Serve(listener)
+
+	return nil
+}
+
+// Close 关闭HTTP监听
+func (s *Server) Close() error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Close()
+}
+
+// BaseURL 返回payload中使用的回连地址
+func (s *Server) BaseURL() string {
+	return s.baseURL
+}
+
+// OnHit 设置收到回连时的处理函数
+func (s *Server) OnHit(fn func(Hit)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.onHit = fn
+}
+
+// Hits 返回所有已收到的回连
+func (s *Server) Hits() []Hit {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	hits := make([]Hit, len(s.hits))
+	copy(hits, s.hits)
+	return hits
+}
+
+// handle 记录每一次回连请求
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	hit := Hit{
+		Time:      time.Now(),
+		RemoteIP:  remoteIP,
+		Method:    r.Method,
+		Host:      r.Host,
+		Path:      r.URL.RequestURI(),
+		UserAgent: r.UserAgent(),
+	}
+
+	// 从Host和路径中查找已下发的标识
+	if s.registry != nil {
+		hit.Callback = s.registry.Lookup(r.Host + r.URL.RequestURI())
+	}
+
+	s.mux.Lock()
+	s.hits = append(s.hits, hit)
+	onHit := s.onHit
+	s.mux.Unlock()
+
+	if onHit != nil {
+		onHit(hit)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// localIP 获取本机第一个非回环IPv4地址
+func localIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "127.0.0.1"
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
@@ -0,0 +1,37 @@
+package payloads
+
+// TypeAdmin 内网Web服务敏感管理接口payload的类型
+const TypeAdmin = "管理接口"
+
+// AdminSecretKeywords 环境变量、配置属性和堆转储中的特征，命中时说明可以读取应用的密码、密钥等配置
+var AdminSecretKeywords = []string{"propertySources", "systemProperties", "JAVA PROFILE"}
+
+// adminPaths Spring Boot Actuator、Web服务器状态页和Java中间件控制台等敏感路径及对应的响应特征
+var adminPaths = []struct {
+	path     string
+	keywords []string
+}{
+	{"/actuator", []string{"_links"}},
+	{"/actuator/env", []string{"propertySources", "activeProfiles"}},
+	{"/actuator/heapdump", []string{"JAVA PROFILE"}},
+	{"/actuator/mappings", []string{"dispatcherServlets"}},
+	{"/env", []string{"systemProperties", "systemEnvironment"}}, // Spring Boot 1.x
+	{"/server-status", []string{"Apache Server Status", "Server uptime"}},
+	{"/nginx_status", []string{"Active connections"}},
+	{"/jmx-console/", []string{"JMX Agent View", "jboss.system"}},
+	{"/manager/html", []string{"Tomcat Web Application Manager"}},
+	{"/druid/index.html", []string{"Druid Stat Index"}},
+}
+
+// GetAdminPayloads 获取内网Web服务 base（例如 http://10.0.0.5:8080）上的敏感管理接口payload
+func GetAdminPayloads(base string) []Payload {
+	result := make([]Payload, 0, len(adminPaths))
+	for _, p := range adminPaths {
+		result = append(result, Payload{
+			Value:    base + p.path,
+			Type:     TypeAdmin,
+			Keywords: p.keywords,
+		})
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package payloads
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+
+	"gosssrf-client/dict"
+)
+
+// DictFile 扫描使用的字典文件及其来源、摘要和payload数量（version -v）
+type DictFile struct {
+	Name     string
+	Path     string // 覆盖内置字典的本地文件，使用内置字典时为空
+	SHA256   string
+	Payloads int
+}
+
+// DictFiles 返回全部内置字典文件的实际内容信息：工作目录或程序所在目录的 dict/ 下有同名文件时按本地文件计算
+func DictFiles() ([]DictFile, error) {
+	entries, err := fs.ReadDir(dict.Files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []DictFile
+	for _, entry := range entries {
+		file, err := openDictFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		files = append(files, DictFile{
+			Name:     entry.Name(),
+			Path:     localDictFile(entry.Name()),
+			SHA256:   hex.EncodeToString(sum[:]),
+			Payloads: countDictLines(data),
+		})
+	}
+	return files, nil
+}
+
+// countDictLines 统计字典中的payload行数，与 loadDictFile 相同跳过空行和注释
+func countDictLines(data []byte) int {
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) > 0 && line[0] != '#' {
+			count++
+		}
+	}
+	return count
+}
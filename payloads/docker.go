@@ -0,0 +1,35 @@
+package payloads
+
+// dockerEndpoints Docker Engine API 接口及对应的响应特征
+var dockerEndpoints = []struct {
+	path     string
+	keywords []string
+}{
+	{"/version", []string{"ApiVersion", "KernelVersion"}},
+	{"/containers/json?all=1", []string{"Names", "ImageID"}},
+	{"/images/json", []string{"RepoTags", "RepoDigests"}},
+}
+
+// dockerBases Docker API 地址：2375（HTTP）、2376（HTTPS）、
+// 本机反向代理到 docker.sock 的地址，以及部分HTTP库支持的 unix socket 写法（http://unix:<socket>:<path>）
+var dockerBases = []string{
+	"http://127.0.0.1:2375",
+	"https://127.0.0.1:2376",
+	"http://localhost",
+	"http://unix:/var/run/docker.sock:",
+}
+
+// GetDockerPayloads 获取Docker Engine API 未授权访问payload（默认扫描）
+func GetDockerPayloads() []Payload {
+	var result []Payload
+	for _, base := range dockerBases {
+		for _, endpoint := range dockerEndpoints {
+			result = append(result, Payload{
+				Value:    base + endpoint.path,
+				Type:     "Docker API",
+				Keywords: endpoint.keywords,
+			})
+		}
+	}
+	return result
+}
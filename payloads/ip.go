@@ -0,0 +1,31 @@
+package payloads
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPVariants 返回IP地址的等价写法，用于绕过按字符串匹配 127.0.0.1、169.254.169.254 等地址的过滤（payloads gen ip）
+// IPv4地址依次为：原始写法、十进制整数、十六进制整数、八进制整数、点分十六进制、点分八进制、
+// 合并后几段（127.0.1、127.1）、IPv4映射的IPv6地址；IPv6地址和主机名只返回原始写法
+func IPVariants(ip string) []string {
+	v4 := net.ParseIP(ip).To4()
+	if v4 == nil || strings.Contains(ip, ":") {
+		return []string{URLHost(ip)}
+	}
+	n := uint32(v4[0])<<24 | uint32(v4[1])<<16 | uint32(v4[2])<<8 | uint32(v4[3])
+	return []string{
+		v4.String(),
+		fmt.Sprintf("%d", n),
+		fmt.Sprintf("0x%x", n),
+		fmt.Sprintf("0%o", n),
+		fmt.Sprintf("0x%x.0x%x.0x%x.0x%x", v4[0], v4[1], v4[2], v4[3]),
+		fmt.Sprintf("0%o.0%o.0%o.0%o", v4[0], v4[1], v4[2], v4[3]),
+		// a.b.c 的最后一段按16位解析，a.b 的最后一段按24位解析
+		fmt.Sprintf("%d.%d.%d", v4[0], v4[1], n&0xffff),
+		fmt.Sprintf("%d.%d", v4[0], n&0xffffff),
+		fmt.Sprintf("[::ffff:%s]", v4),
+		fmt.Sprintf("[::ffff:%x:%x]", n>>16, n&0xffff),
+	}
+}
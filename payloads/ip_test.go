@@ -0,0 +1,25 @@
+package payloads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIPVariants(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{"127.0.0.1", []string{"127.0.0.1", "2130706433", "0x7f000001", "017700000001", "0x7f.0x0.0x0.0x1", "0177.00.00.01",
+			"127.0.1", "127.1", "[::ffff:127.0.0.1]", "[::ffff:7f00:1]"}},
+		{"169.254.169.254", []string{"169.254.169.254", "2852039166", "0xa9fea9fe", "025177524776", "0xa9.0xfe.0xa9.0xfe", "0251.0376.0251.0376",
+			"169.254.43518", "169.16689662", "[::ffff:169.254.169.254]", "[::ffff:a9fe:a9fe]"}},
+		{"::1", []string{"[::1]"}},
+		{"metadata.google.internal", []string{"metadata.google.internal"}},
+	}
+	for _, tt := range tests {
+		if got := IPVariants(tt.ip); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IPVariants(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
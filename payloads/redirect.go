@@ -0,0 +1,26 @@
+package payloads
+
+// OpenRedirectHost 开放重定向测试使用的外部域名（IANA保留的示例域名）
+const OpenRedirectHost = "www.example.com"
+
+// TypeOpenRedirect 开放重定向payload的类型
+const TypeOpenRedirect = "开放重定向"
+
+// openRedirectValues 开放重定向payload：完整URL、协议相对地址，以及浏览器按 // 处理的反斜杠写法
+var openRedirectValues = []string{
+	"https://" + OpenRedirectHost + "/",
+	"//" + OpenRedirectHost + "/",
+	"/\\" + OpenRedirectHost + "/",
+	"\\\\" + OpenRedirectHost + "/",
+	"https://" + OpenRedirectHost + "/%2f..",
+}
+
+// GetOpenRedirectPayloads 获取开放重定向payload（-open-redirect参数）
+// 目标将payload写入 Location 头或 meta refresh 时判定为开放重定向，不依赖目标发起请求
+func GetOpenRedirectPayloads() []Payload {
+	result := make([]Payload, len(openRedirectValues))
+	for i, value := range openRedirectValues {
+		result[i] = Payload{Value: value, Type: TypeOpenRedirect}
+	}
+	return result
+}
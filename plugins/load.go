@@ -0,0 +1,38 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// Load 加载 go build -buildmode=plugin 编译的插件
+// 插件需导出 Plugin 变量（实现 Plugin 接口）或 func NewPlugin() Plugin，且与扫描器使用相同版本的Go和依赖编译
+func Load(path string) (Plugin, error) {
+	lib, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("加载插件 %s 失败: %v", path, err)
+	}
+
+	if sym, err := lib.Lookup("NewPlugin"); err == nil {
+		if newPlugin, ok := sym.(func() Plugin); ok {
+			return newPlugin(), nil
+		}
+		return nil, fmt.Errorf("插件 %s 的 NewPlugin 应为 func() plugins.Plugin", path)
+	}
+	sym, err := lib.Lookup("Plugin")
+	if err != nil {
+		return nil, fmt.Errorf("插件 %s 未导出 Plugin 或 NewPlugin", path)
+	}
+	switch p := sym.(type) {
+	case *Plugin: // var Plugin plugins.Plugin = ...
+		if *p == nil {
+			return nil, fmt.Errorf("插件 %s 的 Plugin 变量为nil", path)
+		}
+		return *p, nil
+	case Plugin: // var Plugin myPlugin（指针实现接口）
+		return p, nil
+	}
+	return nil, fmt.Errorf("插件 %s 的 Plugin 未实现 plugins.Plugin 接口", path)
+}
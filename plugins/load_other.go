@@ -0,0 +1,10 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package plugins
+
+import "fmt"
+
+// Load Go plugin 只支持启用cgo编译的 Linux/macOS/FreeBSD
+func Load(path string) (Plugin, error) {
+	return nil, fmt.Errorf("加载插件 %s 失败: 当前平台或未启用cgo的编译不支持插件", path)
+}
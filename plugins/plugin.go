@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"gosssrf-client/payloads"
+)
+
+// Plugin 自定义检测插件（-plugin参数）：提供额外的payload，并在内置规则未命中时分析每个payload的响应
+//
+// 插件以 Go plugin 形式编译（go build -buildmode=plugin），导出名为 Plugin 的变量或返回 Plugin 的 NewPlugin 函数
+type Plugin interface {
+	// Name 插件名称，用于扫描阶段和漏洞证据
+	Name() string
+	// ProvidePayloads 返回对目标额外测试的payload，payload模板变量与内置payload相同
+	ProvidePayloads(target string) []payloads.Payload
+	// AnalyzeResponse 分析payload的响应，未命中时返回nil
+	AnalyzeResponse(resp *Response) *Finding
+}
+
+// Response 交给插件分析的响应
+type Response struct {
+	URL        string           // 测试请求地址
+	Payload    payloads.Payload // 发送的payload（包括内置payload）
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// Finding 插件判定的漏洞
+type Finding struct {
+	Severity   string // 严重程度：critical/high/medium/low/info，为空时为 medium
+	Confidence string // 置信度：confirmed/probable/tentative，为空时为 probable
+	Evidence   string
+}
+
+// Analyze 依次调用插件分析响应，返回第一个命中的插件和结果
+// 插件 panic 时记录警告并视为未命中，避免第三方代码中断扫描
+func Analyze(list []Plugin, resp *Response) (Plugin, *Finding) {
+	for _, p := range list {
+		if finding := analyze(p, resp); finding != nil {
+			return p, finding
+		}
+	}
+	return nil, nil
+}
+
+// analyze 调用单个插件分析响应
+func analyze(p Plugin, resp *Response) (finding *Finding) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Warn(fmt.Sprintf("插件 %s 分析响应时出错: %v", p.Name(), r))
+			finding = nil
+		}
+	}()
+	return p.AnalyzeResponse(resp)
+}
+
+// AnalyzeOnly 返回只分析响应、不提供payload的插件（分布式扫描中插件payload只在一个任务中发送）
+func AnalyzeOnly(p Plugin) Plugin {
+	return analyzeOnly{p}
+}
+
+type analyzeOnly struct {
+	Plugin
+}
+
+func (analyzeOnly) ProvidePayloads(string) []payloads.Payload {
+	return nil
+}
@@ -0,0 +1,74 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+
+	"gosssrf-client/payloads"
+)
+
+// testPlugin 测试用插件：响应体包含 marker 时命中
+type testPlugin struct {
+	name   string
+	marker string
+}
+
+func (p testPlugin) Name() string { return p.name }
+
+func (p testPlugin) ProvidePayloads(target string) []payloads.Payload {
+	return []payloads.Payload{{Value: "http://internal.corp/" + p.name, Type: "自定义"}}
+}
+
+func (p testPlugin) AnalyzeResponse(resp *Response) *Finding {
+	if p.marker == "" {
+		panic("未设置特征")
+	}
+	if strings.Contains(resp.Body, p.marker) {
+		return &Finding{Evidence: "命中 " + p.marker}
+	}
+	return nil
+}
+
+func TestAnalyze(t *testing.T) {
+	list := []Plugin{testPlugin{name: "broken"}, testPlugin{name: "a", marker: "AAA"}, testPlugin{name: "b", marker: "BBB"}}
+
+	tests := []struct {
+		name string
+		body string
+		want string // 命中的插件，为空表示未命中
+	}{
+		{"第一个命中的插件", "AAA BBB", "a"},
+		{"后面的插件", "BBB", "b"},
+		{"未命中", "nothing", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, finding := Analyze(list, &Response{Body: tt.body})
+			if tt.want == "" {
+				if p != nil || finding != nil {
+					t.Errorf("期望未命中，得到 %v %+v", p, finding)
+				}
+				return
+			}
+			if p == nil || p.Name() != tt.want || finding == nil {
+				t.Fatalf("期望插件 %s 命中，得到 %v %+v", tt.want, p, finding)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOnly(t *testing.T) {
+	p := AnalyzeOnly(testPlugin{name: "a", marker: "AAA"})
+	if got := p.ProvidePayloads("http://target/"); len(got) != 0 {
+		t.Errorf("只分析响应的插件不应提供payload: %v", got)
+	}
+	if p.Name() != "a" || p.AnalyzeResponse(&Response{Body: "AAA"}) == nil {
+		t.Error("只分析响应的插件应保留名称和响应分析")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, err := Load("/nonexistent/plugin.so"); err == nil {
+		t.Error("加载不存在的插件应返回错误")
+	}
+}
@@ -0,0 +1,52 @@
+package rebind
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Hostname 生成DNS重绑定域名: [nonce.]<首次解析IP>.<后续解析IP>.<domain>
+// IP以8位十六进制表示，与 rbndr.us 等公共重绑定服务的格式一致
+// nonce 不为空时作为前缀，保证每个payload使用未被缓存的新域名（仅内置DNS服务支持）
+func Hostname(first, second net.IP, domain, nonce string) (string, error) {
+	a, b := first.To4(), second.To4()
+	if a == nil || b == nil {
+		return "", fmt.Errorf("DNS重绑定仅支持IPv4地址: %s, %s", first, second)
+	}
+
+	name := hex.EncodeToString(a) + "." + hex.EncodeToString(b) + "." + strings.Trim(domain, ".")
+	if nonce != "" {
+		name = nonce + "." + name
+	}
+	return name, nil
+}
+
+// ParseHostname 从重绑定域名中解析出两个IP，域名不属于 domain 或格式不正确时返回 false
+func ParseHostname(name, domain string) (net.IP, net.IP, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	if !strings.HasSuffix(name, suffix) {
+		return nil, nil, false
+	}
+
+	labels := strings.Split(strings.TrimSuffix(name, suffix), ".")
+	if len(labels) < 2 {
+		return nil, nil, false
+	}
+	first, err1 := hex.DecodeString(labels[len(labels)-2])
+	second, err2 := hex.DecodeString(labels[len(labels)-1])
+	if err1 != nil || err2 != nil || len(first) != 4 || len(second) != 4 {
+		return nil, nil, false
+	}
+	return net.IP(first), net.IP(second), true
+}
+
+// NewNonce 生成重绑定域名前缀（8位十六进制）
+func NewNonce() string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
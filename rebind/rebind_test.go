@@ -0,0 +1,107 @@
+package rebind
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostname(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   string
+		second  string
+		nonce   string
+		want    string
+		wantErr bool
+	}{
+		{"公网到本机", "1.2.3.4", "127.0.0.1", "", "01020304.7f000001.rebind.test", false},
+		{"带随机前缀", "1.2.3.4", "169.254.169.254", "abcd", "abcd.01020304.a9fea9fe.rebind.test", false},
+		{"不支持IPv6", "::1", "127.0.0.1", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Hostname(net.ParseIP(tt.first), net.ParseIP(tt.second), "rebind.test.", tt.nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("得到 %s (%v)，期望 %s", got, err, tt.want)
+			}
+
+			first, second, ok := ParseHostname(strings.ToUpper(got), "rebind.test")
+			if !ok || first.String() != tt.first || second.String() != tt.second {
+				t.Errorf("解析得到 %s %s %v", first, second, ok)
+			}
+		})
+	}
+
+	if _, _, ok := ParseHostname("01020304.7f000001.other.test", "rebind.test"); ok {
+		t.Errorf("不属于重绑定域名的名称不应解析成功")
+	}
+}
+
+// queryA 发送A记录查询，返回响应码和应答地址
+func queryA(t *testing.T, addr, name string) (int, net.IP) {
+	t.Helper()
+	packet := []byte{0x12, 0x34, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, label := range strings.Split(name, ".") {
+		packet = append(packet, byte(len(label)))
+		packet = append(packet, label...)
+	}
+	packet = append(packet, 0, 0, dnsTypeA, 0, dnsClassIN)
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+	resp := make([]byte, 512)
+	n, err := conn.Read(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = resp[:n]
+	if binary.BigEndian.Uint16(resp[6:8]) == 0 {
+		return int(resp[3] & 0x0F), nil
+	}
+	return int(resp[3] & 0x0F), net.IP(resp[n-4:])
+}
+
+func TestServerRebinds(t *testing.T) {
+	server := NewServer("127.0.0.1:0", "rebind.test")
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	addr := server.conn.LocalAddr().String()
+
+	var queries []Query
+	server.OnQuery(func(q Query) { queries = append(queries, q) })
+
+	name := "abcd.01020304.7f000001.rebind.test"
+	want := []string{"1.2.3.4", "127.0.0.1", "127.0.0.1"}
+	for i, ip := range want {
+		rcode, answer := queryA(t, addr, name)
+		if rcode != 0 || answer.String() != ip {
+			t.Errorf("第%d次查询得到 rcode=%d %s，期望 %s", i+1, rcode, answer, ip)
+		}
+	}
+	if len(queries) != 3 || queries[1].Count != 2 {
+		t.Errorf("查询记录错误: %+v", queries)
+	}
+
+	if rcode, answer := queryA(t, addr, "x.other.test"); rcode != rcodeNXDomain || answer != nil {
+		t.Errorf("非本域名应返回NXDOMAIN，得到 rcode=%d %s", rcode, answer)
+	}
+}
@@ -0,0 +1,174 @@
+package rebind
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNS报文常量
+const (
+	dnsTypeA      = 1
+	dnsClassIN    = 1
+	dnsHeaderLen  = 12
+	rcodeNXDomain = 3
+)
+
+// Query 内置DNS服务收到的一次重绑定域名查询
+type Query struct {
+	Time     time.Time
+	RemoteIP string
+	Name     string
+	Count    int    // 该域名第几次被查询
+	Answer   net.IP // 本次返回的地址
+}
+
+// Server 内置DNS重绑定服务（重绑定域名的权威DNS）
+// 同一域名第一次查询返回首个IP（通常为可通过白名单校验的公网IP），之后的查询返回第二个IP（内网地址），TTL为0
+type Server struct {
+	listenAddr string
+	domain     string
+	conn       net.PacketConn
+	counts     map[string]int
+	onQuery    func(Query)
+	mux        sync.Mutex
+}
+
+// NewServer 创建DNS重绑定服务
+// listenAddr: UDP监听地址，例如 :53
+// domain: 委派到本服务的域名，例如 rebind.example.com
+func NewServer(listenAddr, domain string) *Server {
+	return &Server{
+		listenAddr: listenAddr,
+		domain:     strings.ToLower(strings.Trim(domain, ".")),
+		counts:     make(map[string]int),
+	}
+}
+
+// Start 启动UDP监听（非阻塞）
+func (s *Server) Start() error {
+	conn, err := net.ListenPacket("udp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("DNS服务监听失败: %v", err)
+	}
+	s.conn = conn
+	go s.serve()
+	return nil
+}
+
+// Close 关闭UDP监听
+func (s *Server) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// OnQuery 设置收到重绑定域名查询时的处理函数
+func (s *Server) OnQuery(fn func(Query)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.onQuery = fn
+}
+
+// serve 循环处理DNS查询
+func (s *Server) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.handle(buf[:n], addr); resp != nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// handle 解析查询报文并构造响应，无法解析的报文返回nil
+func (s *Server) handle(packet []byte, addr net.Addr) []byte {
+	if len(packet) < dnsHeaderLen || binary.BigEndian.Uint16(packet[4:6]) != 1 {
+		return nil
+	}
+
+	// 解析问题部分的域名
+	name, offset, ok := readName(packet, dnsHeaderLen)
+	if !ok || offset+4 > len(packet) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(packet[offset : offset+2])
+	question := packet[dnsHeaderLen : offset+4]
+
+	// 响应头：QR=1 AA=1，保留客户端的RD标志
+	resp := make([]byte, dnsHeaderLen, dnsHeaderLen+len(question)+16)
+	copy(resp[0:2], packet[0:2])
+	resp[2] = 0x84 | (packet[2] & 0x01)
+	binary.BigEndian.PutUint16(resp[4:6], 1)
+	resp = append(resp, question...)
+
+	first, second, ok := ParseHostname(name, s.domain)
+	if !ok {
+		if name != s.domain && !strings.HasSuffix(name, "."+s.domain) {
+			resp[3] = rcodeNXDomain
+		}
+		return resp
+	}
+	if qtype != dnsTypeA {
+		// 只应答A记录，其余类型返回空结果
+		return resp
+	}
+
+	s.mux.Lock()
+	s.counts[name]++
+	count := s.counts[name]
+	onQuery := s.onQuery
+	s.mux.Unlock()
+
+	answer := first
+	if count > 1 {
+		answer = second
+	}
+
+	// 应答记录：名称指针 0xC00C，A记录，TTL 0
+	binary.BigEndian.PutUint16(resp[6:8], 1)
+	resp = append(resp, 0xC0, dnsHeaderLen)
+	resp = binary.BigEndian.AppendUint16(resp, dnsTypeA)
+	resp = binary.BigEndian.AppendUint16(resp, dnsClassIN)
+	resp = binary.BigEndian.AppendUint32(resp, 0)
+	resp = binary.BigEndian.AppendUint16(resp, 4)
+	resp = append(resp, answer.To4()...)
+
+	if onQuery != nil {
+		remoteIP, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			remoteIP = addr.String()
+		}
+		onQuery(Query{Time: time.Now(), RemoteIP: remoteIP, Name: name, Count: count, Answer: answer})
+	}
+
+	return resp
+}
+
+// readName 从报文指定位置读取域名（查询报文中不使用压缩指针）
+func readName(packet []byte, offset int) (string, int, bool) {
+	var labels []string
+	for {
+		if offset >= len(packet) {
+			return "", 0, false
+		}
+		length := int(packet[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		if length&0xC0 != 0 || offset+length > len(packet) {
+			return "", 0, false
+		}
+		labels = append(labels, string(packet[offset:offset+length]))
+		offset += length
+	}
+	return strings.ToLower(strings.Join(labels, ".")), offset, true
+}
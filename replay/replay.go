@@ -0,0 +1,74 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gosssrf-client/config"
+	"gosssrf-client/notify"
+	"gosssrf-client/scanner"
+	"gosssrf-client/store"
+)
+
+// Load 读取要重放的漏洞（-finding参数）
+// 纯数字为结果数据库（-db）中的漏洞ID，以 { 开头为JSON，否则为保存JSON的文件；
+// JSON 为 webhook 的漏洞事件或其中的 finding 对象
+func Load(ref, dbFile string) (scanner.ScanResult, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		if dbFile == "" {
+			return scanner.ScanResult{}, errors.New("按漏洞ID重放需要通过 -db 指定结果数据库")
+		}
+		return checkRequest(store.LoadFinding(dbFile, id))
+	}
+
+	data := []byte(ref)
+	if !strings.HasPrefix(ref, "{") {
+		var err error
+		if data, err = os.ReadFile(ref); err != nil {
+			return scanner.ScanResult{}, fmt.Errorf("读取漏洞文件失败: %v", err)
+		}
+	}
+	finding, err := notify.ParseFinding(data)
+	if err != nil {
+		return scanner.ScanResult{}, err
+	}
+	return checkRequest(finding.ScanResult(), nil)
+}
+
+// checkRequest 检查漏洞是否记录了可以重放的请求，未记录请求方式时使用GET
+func checkRequest(finding scanner.ScanResult, err error) (scanner.ScanResult, error) {
+	if err != nil {
+		return finding, err
+	}
+	if finding.URL == "" {
+		return finding, errors.New("漏洞没有记录请求地址（OOB回连等漏洞无法重放）")
+	}
+	if finding.Method == "" {
+		finding.Method = "GET"
+	}
+	return finding, nil
+}
+
+// SetBodyType 数据库和漏洞事件中没有保存请求的Content-Type，未通过 -body-type 或Header指定时根据请求体判断
+func SetBodyType(cfg *config.Config, body string) {
+	if cfg.BodyType != config.BodyTypeForm {
+		return
+	}
+	for name := range cfg.CustomHeaders {
+		if strings.EqualFold(name, "Content-Type") {
+			return
+		}
+	}
+	body = strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(body, "<"):
+		cfg.BodyType = config.BodyTypeXML
+	case json.Valid([]byte(body)) && (strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[")):
+		cfg.BodyType = config.BodyTypeJSON
+	}
+}
@@ -0,0 +1,72 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "finding.json")
+	event := `{"event":"finding","time":"2024-01-01T00:00:00Z","finding":{"target":"http://a.example/?url=x","url":"http://a.example/?url=file:///etc/passwd","parameter":"url","payload":"file:///etc/passwd","severity":"critical"}}`
+	if err := os.WriteFile(file, []byte(event), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		db      string
+		url     string
+		method  string
+		wantErr bool
+	}{
+		{name: "漏洞事件文件", ref: file, url: "http://a.example/?url=file:///etc/passwd", method: "GET"},
+		{name: "finding对象", ref: `{"url":"http://a.example/","method":"POST","request_body":"url=x"}`, url: "http://a.example/", method: "POST"},
+		{name: "漏洞ID需要数据库", ref: "3", wantErr: true},
+		{name: "没有请求地址", ref: `{"payload_type":"OOB检测"}`, wantErr: true},
+		{name: "无效的JSON", ref: `{"url":`, wantErr: true},
+		{name: "文件不存在", ref: filepath.Join(t.TempDir(), "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.ref, tt.db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.URL != tt.url || got.Method != tt.method || !got.Vulnerable {
+				t.Errorf("Load() = %s %s (vulnerable=%v)，期望 %s %s", got.Method, got.URL, got.Vulnerable, tt.method, tt.url)
+			}
+		})
+	}
+}
+
+func TestSetBodyType(t *testing.T) {
+	tests := []struct {
+		name     string
+		bodyType string
+		headers  map[string]string
+		body     string
+		want     string
+	}{
+		{"表单", config.BodyTypeForm, nil, "url=x", config.BodyTypeForm},
+		{"JSON", config.BodyTypeForm, nil, `{"url":"x"}`, config.BodyTypeJSON},
+		{"XML", config.BodyTypeForm, nil, "<a>x</a>", config.BodyTypeXML},
+		{"Header中指定了Content-Type", config.BodyTypeForm, map[string]string{"content-type": "text/plain"}, `{"url":"x"}`, config.BodyTypeForm},
+		{"指定了 -body-type", config.BodyTypeXML, nil, `{"url":"x"}`, config.BodyTypeXML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{BodyType: tt.bodyType, CustomHeaders: tt.headers}
+			SetBodyType(cfg, tt.body)
+			if cfg.BodyType != tt.want {
+				t.Errorf("SetBodyType() = %s, want %s", cfg.BodyType, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// ssrfCWE SSRF对应的CWE编号（CWE-918）
+const ssrfCWE = 918
+
+// defectDojoMitigation 导入DefectDojo的修复建议
+const defectDojoMitigation = "不要直接请求用户提供的地址：服务端校验协议和目标主机（使用白名单），解析域名后拒绝内网、回环和云元数据地址，" +
+	"禁止跟随重定向或对重定向后的地址重新校验，关闭不需要的协议（file、gopher、dict 等），并在网络层限制服务器访问内网和元数据服务。"
+
+// defectDojoReferences 导入DefectDojo的参考链接
+const defectDojoReferences = "https://owasp.org/www-community/attacks/Server_Side_Request_Forgery\nhttps://cwe.mitre.org/data/definitions/918.html"
+
+// defectDojoSeverities GoSSRF严重程度对应的DefectDojo严重程度
+var defectDojoSeverities = map[string]string{
+	detector.SeverityCritical: "Critical",
+	detector.SeverityHigh:     "High",
+	detector.SeverityMedium:   "Medium",
+	detector.SeverityLow:      "Low",
+	detector.SeverityInfo:     "Info",
+}
+
+// defectDojoReport DefectDojo Generic Findings Import 的JSON格式
+type defectDojoReport struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+// defectDojoFinding DefectDojo导入的一个漏洞，字段名与DefectDojo的Finding模型一致
+type defectDojoFinding struct {
+	Title            string               `json:"title"`
+	Severity         string               `json:"severity"`
+	Date             string               `json:"date"`
+	CWE              int                  `json:"cwe"`
+	Description      string               `json:"description"`
+	Mitigation       string               `json:"mitigation"`
+	StepsToReproduce string               `json:"steps_to_reproduce,omitempty"`
+	References       string               `json:"references"`
+	Param            string               `json:"param,omitempty"`
+	Payload          string               `json:"payload,omitempty"`
+	Active           bool                 `json:"active"`
+	Verified         bool                 `json:"verified"`
+	DynamicFinding   bool                 `json:"dynamic_finding"`
+	UniqueIDFromTool string               `json:"unique_id_from_tool"`
+	VulnIDFromTool   string               `json:"vuln_id_from_tool,omitempty"`
+	Endpoints        []defectDojoEndpoint `json:"endpoints,omitempty"`
+}
+
+// defectDojoEndpoint 漏洞所在的端点
+type defectDojoEndpoint struct {
+	Protocol string `json:"protocol,omitempty"`
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+// WriteDefectDojo 输出DefectDojo Generic Findings Import 格式的JSON（-format defectdojo），
+// 可以直接在DefectDojo中以 Generic Findings Import 扫描类型导入；漏洞指纹作为 unique_id_from_tool 用于去重
+func WriteDefectDojo(w io.Writer, r *Report) error {
+	out := defectDojoReport{Findings: make([]defectDojoFinding, 0, len(r.Results))}
+	for _, result := range sortedResults(r.Results) {
+		fingerprint := result.Fingerprint
+		if fingerprint == "" {
+			fingerprint = scanner.Fingerprint(result)
+		}
+		severity, ok := defectDojoSeverities[result.Severity]
+		if !ok {
+			severity = "Medium"
+		}
+		f := defectDojoFinding{
+			Title:            defectDojoTitle(result),
+			Severity:         severity,
+			Date:             r.StartTime.Format("2006-01-02"),
+			CWE:              ssrfCWE,
+			Description:      defectDojoDescription(result),
+			Mitigation:       defectDojoMitigation,
+			References:       defectDojoReferences,
+			Param:            result.Parameter,
+			Payload:          result.Payload,
+			Active:           result.Status != scanner.StatusFixed,
+			Verified:         result.Status == scanner.StatusVulnerable,
+			DynamicFinding:   true,
+			UniqueIDFromTool: fingerprint,
+			VulnIDFromTool:   result.Kind,
+		}
+		if result.URL != "" {
+			f.StepsToReproduce = "```bash\n" + curlCommand(result, r.Headers, r.ContentType) + "\n```"
+		}
+		if endpoint, ok := defectDojoEndpointOf(result.Target); ok {
+			f.Endpoints = []defectDojoEndpoint{endpoint}
+		}
+		out.Findings = append(out.Findings, f)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// defectDojoTitle 漏洞标题：类型和参数，DefectDojo按标题展示和聚合漏洞
+func defectDojoTitle(result scanner.ScanResult) string {
+	title := "SSRF"
+	if result.PayloadType != "" {
+		title += " - " + result.PayloadType
+	}
+	if result.Parameter != "" {
+		title += fmt.Sprintf(" (参数 %s)", result.Parameter)
+	}
+	return title
+}
+
+// defectDojoDescription 漏洞描述（Markdown）：请求、payload、证据和响应片段
+func defectDojoDescription(result scanner.ScanResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GoSSRF 通过参数 %s 发现服务端请求伪造漏洞。\n\n", markdownCode(result.Parameter))
+	fmt.Fprintf(&b, "- 目标: %s\n", markdownCode(result.Target))
+	if result.Method != "" {
+		fmt.Fprintf(&b, "- 请求方法: %s\n", result.Method)
+	}
+	fmt.Fprintf(&b, "- Payload: %s\n", markdownCode(result.Payload))
+	fmt.Fprintf(&b, "- 类型: %s\n", markdownCell(result.PayloadType))
+	fmt.Fprintf(&b, "- 置信度: %s\n", result.Confidence)
+	fmt.Fprintf(&b, "- 证据: %s\n", markdownCell(result.Evidence))
+	if result.StatusCode != 0 {
+		fmt.Fprintf(&b, "- 响应: 状态码 %d，长度 %d，耗时 %dms\n", result.StatusCode, result.ResponseLen, result.ResponseTime)
+	}
+	if result.Status != "" {
+		fmt.Fprintf(&b, "- 复测结果: %s\n", statusText(result.Status))
+	}
+	if result.Response != "" {
+		b.WriteString("\n响应片段:\n\n")
+		writeMarkdownBlock(&b, "", result.Response)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// defectDojoEndpointOf 将扫描目标转换为DefectDojo的端点，路径不以 / 开头
+func defectDojoEndpointOf(target string) (defectDojoEndpoint, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.Hostname() == "" {
+		return defectDojoEndpoint{}, false
+	}
+	endpoint := defectDojoEndpoint{
+		Protocol: u.Scheme,
+		Host:     u.Hostname(),
+		Path:     strings.TrimPrefix(u.Path, "/"),
+		Query:    u.RawQuery,
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		endpoint.Port = port
+	}
+	return endpoint, true
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestWriteDefectDojo(t *testing.T) {
+	r := &Report{
+		StartTime: time.Date(2024, 6, 1, 3, 4, 5, 0, time.UTC),
+		Results: []scanner.ScanResult{
+			{Target: "http://a.example:8080/fetch?url=x", Method: "GET", URL: "http://a.example:8080/fetch?url=http://10.0.0.1/", Parameter: "url",
+				Payload: "http://10.0.0.1/", PayloadType: "内网探测", Kind: "http-service", Severity: "low", Confidence: "probable", Vulnerable: true},
+			{Target: "https://b.example/api", Method: "POST", URL: "https://b.example/api", Parameter: "url", Payload: "file:///etc/passwd",
+				PayloadType: "文件读取", Kind: "keyword", Severity: "critical", Confidence: "confirmed", Evidence: "root:x:0:0", Vulnerable: true, Status: scanner.StatusFixed},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteDefectDojo(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Findings []struct {
+			Title            string `json:"title"`
+			Severity         string `json:"severity"`
+			Date             string `json:"date"`
+			CWE              int    `json:"cwe"`
+			Description      string `json:"description"`
+			StepsToReproduce string `json:"steps_to_reproduce"`
+			Active           bool   `json:"active"`
+			UniqueIDFromTool string `json:"unique_id_from_tool"`
+			Endpoints        []struct {
+				Protocol string `json:"protocol"`
+				Host     string `json:"host"`
+				Port     int    `json:"port"`
+				Path     string `json:"path"`
+				Query    string `json:"query"`
+			} `json:"endpoints"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("输出不是有效的JSON: %v", err)
+	}
+	if len(out.Findings) != 2 {
+		t.Fatalf("导出 %d 个漏洞，期望 2 个", len(out.Findings))
+	}
+
+	// 按严重程度排列，critical 在前
+	critical, low := out.Findings[0], out.Findings[1]
+	if critical.Title != "SSRF - 文件读取 (参数 url)" || critical.Severity != "Critical" || critical.CWE != 918 || critical.Date != "2024-06-01" {
+		t.Errorf("漏洞 = %+v", critical)
+	}
+	if critical.Active || !low.Active {
+		t.Errorf("已修复的漏洞 active = %v，未复测的漏洞 active = %v", critical.Active, low.Active)
+	}
+	if !strings.Contains(critical.Description, "root:x:0:0") || !strings.Contains(critical.StepsToReproduce, "curl") {
+		t.Errorf("描述或复现步骤缺少证据和curl命令: %q %q", critical.Description, critical.StepsToReproduce)
+	}
+	if want := scanner.Fingerprint(r.Results[1]); critical.UniqueIDFromTool != want {
+		t.Errorf("unique_id_from_tool = %q, want 漏洞指纹 %q", critical.UniqueIDFromTool, want)
+	}
+	if len(low.Endpoints) != 1 {
+		t.Fatalf("端点 = %+v", low.Endpoints)
+	}
+	if e := low.Endpoints[0]; e.Protocol != "http" || e.Host != "a.example" || e.Port != 8080 || e.Path != "fetch" || e.Query != "url=x" {
+		t.Errorf("端点 = %+v", e)
+	}
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"gosssrf-client/notify"
+	"gosssrf-client/scanner"
+)
+
+// Diff 同一目标两次扫描结果的差异（diff子命令）
+type Diff struct {
+	New        []scanner.ScanResult // 只在新报告中出现的漏洞
+	Fixed      []scanner.ScanResult // 只在旧报告中出现的漏洞
+	Persistent []scanner.ScanResult // 两次扫描都发现的漏洞，使用新报告中的记录
+}
+
+// Compare 比较两次扫描的漏洞，每组结果按严重程度排列
+func Compare(old, new []scanner.ScanResult) Diff {
+	oldKeys := make(map[string]bool, len(old))
+	for _, r := range old {
+		oldKeys[findingKey(r)] = true
+	}
+
+	var d Diff
+	newKeys := make(map[string]bool, len(new))
+	for _, r := range new {
+		key := findingKey(r)
+		if newKeys[key] {
+			continue
+		}
+		newKeys[key] = true
+		if oldKeys[key] {
+			d.Persistent = append(d.Persistent, r)
+		} else {
+			d.New = append(d.New, r)
+		}
+	}
+	for _, r := range old {
+		key := findingKey(r)
+		if !newKeys[key] {
+			newKeys[key] = true // 旧报告中重复的漏洞只列一次
+			d.Fixed = append(d.Fixed, r)
+		}
+	}
+
+	d.New, d.Fixed, d.Persistent = sortedResults(d.New), sortedResults(d.Fixed), sortedResults(d.Persistent)
+	return d
+}
+
+// findingKey 判断两次扫描是否为同一漏洞：目标、请求方式、参数、类型和payload相同
+// OOB回连的payload中包含每次扫描随机生成的标识，只比较目标、参数和类型
+func findingKey(r scanner.ScanResult) string {
+	payload := r.Payload
+	if r.PayloadType == "OOB检测" {
+		payload = ""
+	}
+	return r.Target + "\x00" + r.Method + "\x00" + r.Parameter + "\x00" + r.PayloadType + "\x00" + payload
+}
+
+// jsonDiff JSON格式的差异报告，漏洞字段与JSON报告一致
+type jsonDiff struct {
+	Summary struct {
+		New        int `json:"new"`
+		Fixed      int `json:"fixed"`
+		Persistent int `json:"persistent"`
+	} `json:"summary"`
+	New        []notify.Finding `json:"new"`
+	Fixed      []notify.Finding `json:"fixed"`
+	Persistent []notify.Finding `json:"persistent"`
+}
+
+// WriteDiffJSON 输出JSON格式的差异报告，便于持续扫描流程判断是否出现新漏洞
+func WriteDiffJSON(w io.Writer, d Diff) error {
+	var out jsonDiff
+	out.Summary.New, out.Summary.Fixed, out.Summary.Persistent = len(d.New), len(d.Fixed), len(d.Persistent)
+	out.New, out.Fixed, out.Persistent = toFindings(d.New), toFindings(d.Fixed), toFindings(d.Persistent)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// toFindings 转换为漏洞信息，没有漏洞时输出空数组
+func toFindings(results []scanner.ScanResult) []notify.Finding {
+	findings := make([]notify.Finding, 0, len(results))
+	for _, r := range results {
+		findings = append(findings, notify.NewFinding(r))
+	}
+	return findings
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gosssrf-client/scanner"
+)
+
+func TestCompare(t *testing.T) {
+	target := "http://a.example/?url=x"
+	finding := func(payloadType, payload, severity string) scanner.ScanResult {
+		return scanner.ScanResult{Target: target, Method: "GET", Parameter: "url", PayloadType: payloadType, Payload: payload, Severity: severity, Vulnerable: true}
+	}
+	old := []scanner.ScanResult{
+		finding("内网探测", "http://10.0.0.1/", "medium"),
+		finding("文件读取", "file:///etc/passwd", "critical"),
+		finding("OOB检测", "http://oob.example/1111111111111111", "high"),
+		finding("内网探测", "http://10.0.0.1/", "medium"), // 重复的漏洞只列一次
+	}
+	current := []scanner.ScanResult{
+		finding("文件读取", "file:///etc/passwd", "critical"),
+		finding("OOB检测", "http://oob.example/2222222222222222", "high"), // 回连标识不同仍为同一漏洞
+		finding("云元数据", "http://169.254.169.254/latest/meta-data/", "low"),
+		finding("内网探测", "http://127.0.0.1:6379/", "critical"),
+	}
+
+	tests := []struct {
+		name    string
+		results []scanner.ScanResult
+		want    []string
+	}{
+		{"新增按严重程度排列", Compare(old, current).New, []string{"http://127.0.0.1:6379/", "http://169.254.169.254/latest/meta-data/"}},
+		{"已修复", Compare(old, current).Fixed, []string{"http://10.0.0.1/"}},
+		{"仍存在使用新报告的记录", Compare(old, current).Persistent, []string{"file:///etc/passwd", "http://oob.example/2222222222222222"}},
+		{"旧报告为空", Compare(nil, current).Persistent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range tt.results {
+				got = append(got, r.Payload)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("得到 %v，期望 %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("第 %d 个漏洞为 %s，期望 %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDiffJSON(t *testing.T) {
+	d := Diff{New: []scanner.ScanResult{{Target: "http://a.example/", Payload: "http://10.0.0.1/", Severity: "high"}}}
+	var buf bytes.Buffer
+	if err := WriteDiffJSON(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"summary":    `{"new":1,"fixed":0,"persistent":0}`,
+		"fixed":      `[]`,
+		"persistent": `[]`,
+	}
+	for key, value := range want {
+		var compact bytes.Buffer
+		json.Compact(&compact, out[key])
+		if compact.String() != value {
+			t.Errorf("%s 为 %s，期望 %s", key, compact.String(), value)
+		}
+	}
+}
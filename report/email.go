@@ -0,0 +1,228 @@
+package report
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// emailTimeout 连接SMTP服务器并发送邮件的最长时间
+const emailTimeout = time.Minute
+
+// emailMaxFindings 邮件正文中列出的漏洞数量上限，完整结果见附件
+const emailMaxFindings = 20
+
+// EmailOptions 邮件报告的SMTP设置（-email-report参数）
+type EmailOptions struct {
+	Server   string // SMTP服务器 host:port，465端口使用TLS连接，其他端口在服务器支持时使用STARTTLS
+	From     string
+	To       []string
+	Username string // 为空时不认证
+	Password string
+}
+
+// Attachment 邮件附件
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// SendEmail 发送扫描报告邮件：正文为扫描汇总和漏洞列表，attachment 为空时附带生成的HTML报告
+func SendEmail(opts EmailOptions, r *Report, attachment *Attachment) error {
+	if attachment == nil {
+		var buf bytes.Buffer
+		if err := WriteHTML(&buf, r); err != nil {
+			return fmt.Errorf("生成HTML报告失败: %v", err)
+		}
+		attachment = &Attachment{Name: "gossrf-report-" + r.StartTime.Format("20060102-150405") + ".html", Data: buf.Bytes()}
+	}
+	msg := emailMessage(opts, r, attachment, time.Now())
+	if err := sendMail(opts, msg); err != nil {
+		return fmt.Errorf("发送邮件报告失败: %v", err)
+	}
+	return nil
+}
+
+// emailSubject 邮件主题：发现的漏洞数量和最高严重程度
+func emailSubject(s summary) string {
+	if len(s.Findings) == 0 {
+		return "GoSSRF 扫描报告: 未发现SSRF漏洞"
+	}
+	subject := fmt.Sprintf("GoSSRF 扫描报告: 发现 %d 个SSRF测试点（最高 %s）", len(s.Findings), s.Findings[0].Severity)
+	if s.Interrupted {
+		subject += "，扫描被中断"
+	}
+	return subject
+}
+
+// emailBody 邮件正文（纯文本）
+func emailBody(s summary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "开始时间: %s\n", s.StartTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "耗时: %s\n", s.Duration)
+	fmt.Fprintf(&b, "目标: %d 个\n", len(s.Targets))
+	if s.Interrupted {
+		b.WriteString("扫描被中断，结果不完整\n")
+	}
+	fmt.Fprintf(&b, "\n共发现 %d 个SSRF测试点\n", len(s.Findings))
+	for _, group := range []struct {
+		title  string
+		counts []Count
+	}{{"严重程度", s.Severities}, {"置信度", s.Confidences}} {
+		if len(group.counts) == 0 {
+			continue
+		}
+		parts := make([]string, 0, len(group.counts))
+		for _, c := range group.counts {
+			parts = append(parts, fmt.Sprintf("%s %d", c.Name, c.Count))
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", group.title, strings.Join(parts, ", "))
+	}
+
+	if len(s.Findings) > 0 {
+		b.WriteString("\n漏洞:\n")
+	}
+	for i, r := range s.Findings {
+		if i == emailMaxFindings {
+			fmt.Fprintf(&b, "  ... 其余 %d 个漏洞见附件\n", len(s.Findings)-emailMaxFindings)
+			break
+		}
+		fmt.Fprintf(&b, "  [%s/%s] [%s] %s %s=%s\n", r.Severity, r.Confidence, r.PayloadType, r.Target, r.Parameter, r.Payload)
+	}
+	return b.String()
+}
+
+// emailMessage 生成 multipart/mixed 格式的邮件：纯文本正文和一个附件，均使用base64编码
+func emailMessage(opts EmailOptions, r *Report, attachment *Attachment, now time.Time) []byte {
+	s := newSummary(r)
+	boundary := randomBoundary()
+
+	var b bytes.Buffer
+	to := make([]string, 0, len(opts.To))
+	for _, addr := range opts.To {
+		to = append(to, headerAddress(addr))
+	}
+	fmt.Fprintf(&b, "From: %s\r\n", headerAddress(opts.From))
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", emailSubject(s)))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64Lines(&b, []byte(emailBody(s)))
+
+	contentType := mime.TypeByExtension(filepath.Ext(attachment.Name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	name := mime.QEncoding.Encode("UTF-8", attachment.Name)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	fmt.Fprintf(&b, "Content-Type: %s; name=%q\r\n", contentType, name)
+	fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n", name)
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64Lines(&b, attachment.Data)
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
+
+// writeBase64Lines 以每行76个字符输出base64编码的内容
+func writeBase64Lines(b *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+}
+
+// randomBoundary 生成multipart分隔符
+func randomBoundary() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "gossrf-" + hex.EncodeToString(buf)
+}
+
+// headerAddress 返回邮件头中的地址，名称中的非ASCII字符按RFC 2047编码
+func headerAddress(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		return a.String()
+	}
+	return addr
+}
+
+// envelopeAddress 返回 "名称 <地址>" 格式中的地址，用于SMTP的 MAIL FROM 和 RCPT TO
+func envelopeAddress(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		return a.Address
+	}
+	return addr
+}
+
+// sendMail 通过SMTP发送邮件；465端口使用TLS连接，其他端口在服务器支持时升级为STARTTLS，指定用户名时使用PLAIN认证
+func sendMail(opts EmailOptions, msg []byte) error {
+	host, port, err := net.SplitHostPort(opts.Server)
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{Timeout: emailTimeout}
+	tlsConfig := &tls.Config{ServerName: host}
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", opts.Server, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", opts.Server)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && port != "465" {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if opts.Username != "" {
+		// net/smtp 只允许在TLS连接或本机服务器上发送明文密码
+		if err := c.Auth(smtp.PlainAuth("", opts.Username, opts.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(envelopeAddress(opts.From)); err != nil {
+		return err
+	}
+	for _, to := range opts.To {
+		if err := c.Rcpt(envelopeAddress(to)); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
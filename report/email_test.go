@@ -0,0 +1,151 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestSendEmail(t *testing.T) {
+	messages, addr := smtpServer(t)
+	start := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	r := &Report{
+		Targets:   []string{"http://a.example/?url=x"},
+		StartTime: start,
+		EndTime:   start.Add(time.Minute),
+		Results: []scanner.ScanResult{
+			{Target: "http://a.example/?url=x", Parameter: "url", Payload: "http://10.0.0.1/", PayloadType: "内网探测", Severity: "low", Confidence: "probable", Vulnerable: true},
+			{Target: "http://a.example/?url=x", Parameter: "url", Payload: "file:///etc/passwd", PayloadType: "文件读取", Severity: "critical", Confidence: "confirmed", Vulnerable: true},
+		},
+	}
+	opts := EmailOptions{Server: addr, From: "扫描器 <gossrf@example.com>", To: []string{"sec@example.com", "ops@example.com"}}
+	if err := SendEmail(opts, r, &Attachment{Name: "report.json", Data: []byte(`{"findings":[]}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got smtpMessage
+	select {
+	case got = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到邮件")
+	}
+	if got.from != "gossrf@example.com" || strings.Join(got.to, ",") != "sec@example.com,ops@example.com" {
+		t.Errorf("MAIL FROM = %q, RCPT TO = %q", got.from, got.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "GoSSRF 扫描报告: 发现 2 个SSRF测试点（最高 critical）" {
+		t.Errorf("Subject = %q", subject)
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || from[0].Name != "扫描器" {
+		t.Errorf("From = %v, %v", from, err)
+	}
+
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	var filename string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		bodies = append(bodies, string(data))
+		if part.FileName() != "" {
+			filename = part.FileName()
+		}
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("邮件包含 %d 个部分，期望正文和附件", len(bodies))
+	}
+	if !strings.Contains(bodies[0], "共发现 2 个SSRF测试点") || !strings.Contains(bodies[0], "[critical/confirmed] [文件读取] http://a.example/?url=x url=file:///etc/passwd") {
+		t.Errorf("正文 = %q", bodies[0])
+	}
+	if filename != "report.json" || bodies[1] != `{"findings":[]}` {
+		t.Errorf("附件 = %q %q", filename, bodies[1])
+	}
+}
+
+// smtpMessage 测试SMTP服务收到的邮件
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer 启动只接收一封邮件、不支持认证和STARTTLS的本地SMTP服务
+func smtpServer(t *testing.T) (<-chan smtpMessage, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ESMTP")
+		var msg smtpMessage
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimSpace(line)
+			switch upper := strings.ToUpper(cmd); {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				msg.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data bytes.Buffer
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				msg.data = data.String()
+				messages <- msg
+				reply("250 OK")
+			case upper == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+	return messages, ln.Addr().String()
+}
@@ -0,0 +1,27 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"gosssrf-client/detector"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+// WriteHTML 输出独立的HTML报告（样式内联，不依赖外部资源）
+func WriteHTML(w io.Writer, r *Report) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"inc":        func(i int) int { return i + 1 },
+		"redirects":  detector.FormatRedirects,
+		"expiration": expiration,
+		"status":     statusText,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, newSummary(r))
+}
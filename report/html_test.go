@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestWriteHTML(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &Report{
+		Targets:   []string{"http://a.example/?url=x", "http://b.example/"},
+		StartTime: start,
+		EndTime:   start.Add(90 * time.Second),
+		Results: []scanner.ScanResult{
+			{
+				Target: "http://a.example/?url=x", Method: "GET", URL: "http://a.example/?url=file:///etc/passwd",
+				Parameter: "url", Payload: "file:///etc/passwd", PayloadType: "文件读取", StatusCode: 200,
+				Evidence: "发现关键字: root:x:0:0", Severity: "critical", Confidence: "confirmed",
+				Response: "root:x:0:0:root:/root:/bin/bash",
+			},
+			{
+				Target: "http://a.example/?url=x", Method: "POST", URL: "http://a.example/",
+				RequestBody: "url=<script>alert(1)</script>", Parameter: "url", Payload: "<script>alert(1)</script>",
+				PayloadType: "内网探测", StatusCode: 200, Severity: "low", Confidence: "tentative",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, r); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	out := buf.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"标题", "<title>GoSSRF 扫描报告</title>"},
+		{"耗时", "1m30s"},
+		{"漏洞数量", "共发现 2 个SSRF测试点"},
+		{"严重程度徽章", `<span class="badge critical">critical</span>`},
+		{"置信度徽章", `<span class="badge tentative">tentative</span>`},
+		{"类型图表", `<span class="name">文件读取</span><div style="width: 100%`},
+		{"请求", "GET http://a.example/?url=file:///etc/passwd"},
+		{"响应片段", "root:x:0:0:root:/root:/bin/bash"},
+		{"转义payload", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"目标统计", "<td>http://b.example/</td><td>0</td>"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%s: 报告中缺少 %q", tt.name, tt.want)
+		}
+	}
+	if strings.Contains(out, "<script>alert(1)</script>") {
+		t.Error("payload未转义")
+	}
+	// 按严重程度排序：critical 在 low 之前
+	if strings.Index(out, "sev-critical") > strings.Index(out, "sev-low") {
+		t.Error("漏洞详情未按严重程度排序")
+	}
+	// 没有出现的严重程度不列出
+	if strings.Contains(out, `<span class="badge high">`) {
+		t.Error("严重程度统计包含零值")
+	}
+}
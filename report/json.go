@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gosssrf-client/notify"
+	"gosssrf-client/scanner"
+)
+
+// jsonReport JSON报告的结构，汇总和漏洞字段与webhook事件一致
+type jsonReport struct {
+	Summary  notify.Summary   `json:"summary"`
+	Findings []notify.Finding `json:"findings"`
+}
+
+// WriteJSON 输出JSON报告，便于其他工具处理，也可作为 -verify 的输入复测
+func WriteJSON(w io.Writer, r *Report) error {
+	out := jsonReport{
+		Summary:  notify.NewSummary(r.Targets, r.StartTime, r.EndTime, r.Interrupted, r.Results),
+		Findings: toFindings(sortedResults(r.Results)),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// ReadFindings 读取已保存的漏洞（-verify参数）：JSON报告（-format json），
+// 或每行一个webhook事件或漏洞的JSON行文件（-format jsonl，只读取漏洞事件）
+func ReadFindings(path string) ([]scanner.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取漏洞报告失败: %v", err)
+	}
+
+	var report struct {
+		Findings []notify.Finding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &report); err == nil && report.Findings != nil {
+		results := make([]scanner.ScanResult, 0, len(report.Findings))
+		for _, f := range report.Findings {
+			results = append(results, f.ScanResult())
+		}
+		return results, nil
+	}
+
+	var results []scanner.ScanResult
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	lines.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; lines.Scan(); n++ {
+		line := bytes.TrimSpace(lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var event notify.Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, fmt.Errorf("%s 第 %d 行不是有效的JSON: %v", path, n, err)
+		}
+		if event.Event != "" && event.Event != notify.EventFinding {
+			continue
+		}
+		f, err := notify.ParseFinding(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s 第 %d 行: %v", path, n, err)
+		}
+		results = append(results, f.ScanResult())
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("读取漏洞报告失败: %v", err)
+	}
+	if len(results) == 0 {
+		return nil, errors.New("漏洞报告中没有漏洞 (需要 -format json/jsonl 生成的报告或webhook漏洞事件)")
+	}
+	return results, nil
+}
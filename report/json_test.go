@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestReadFindings(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &Report{
+		Targets:   []string{"http://a.example/?url=x"},
+		StartTime: start,
+		EndTime:   start.Add(time.Minute),
+		Results: []scanner.ScanResult{
+			{Target: "http://a.example/?url=x", Method: "GET", URL: "http://a.example/?url=http://10.0.0.1/", Parameter: "url", Payload: "http://10.0.0.1/", Severity: "medium", Vulnerable: true},
+			{Target: "http://a.example/?url=x", Method: "GET", URL: "http://a.example/?url=file:///etc/passwd", Parameter: "url", Payload: "file:///etc/passwd", Severity: "critical", Vulnerable: true, Status: scanner.StatusFixed},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	events := `{"event":"finding","time":"2024-01-02T03:04:05Z","finding":{"target":"http://a.example/?url=x","url":"http://a.example/?url=file:///etc/passwd","payload":"file:///etc/passwd"}}
+
+{"event":"scan_complete","time":"2024-01-02T03:05:05Z","summary":{"vuln_count":1}}
+`
+	tests := []struct {
+		name     string
+		content  string
+		payloads []string
+		status   string // 第一个漏洞的复测结果
+		wantErr  bool
+	}{
+		{name: "JSON报告按严重程度排列", content: buf.String(), payloads: []string{"file:///etc/passwd", "http://10.0.0.1/"}, status: scanner.StatusFixed},
+		{name: "webhook事件", content: events, payloads: []string{"file:///etc/passwd"}},
+		{name: "没有漏洞", content: `{"event":"scan_complete"}`, wantErr: true},
+		{name: "无效的JSON行", content: "not json\n", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "report"+string(rune('a'+i))+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadFindings(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFindings() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.payloads) {
+				t.Fatalf("读取到 %d 个漏洞，期望 %d", len(got), len(tt.payloads))
+			}
+			for j, payload := range tt.payloads {
+				if got[j].Payload != payload || !got[j].Vulnerable || got[j].URL == "" {
+					t.Errorf("第 %d 个漏洞 = %+v", j+1, got[j])
+				}
+			}
+			if got[0].Status != tt.status {
+				t.Errorf("复测结果 = %q，期望 %q", got[0].Status, tt.status)
+			}
+		})
+	}
+
+	// 没有记录指纹的扫描结果在输出时生成指纹，读取后保持不变
+	path := filepath.Join(dir, "fingerprint.json")
+	os.WriteFile(path, buf.Bytes(), 0o644)
+	got, err := ReadFindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := scanner.Fingerprint(r.Results[1]); got[0].Fingerprint != want {
+		t.Errorf("指纹 = %q，期望 %q", got[0].Fingerprint, want)
+	}
+}
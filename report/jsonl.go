@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"gosssrf-client/notify"
+	"gosssrf-client/scanner"
+)
+
+// JSONL 逐行写入漏洞的JSON行输出（-format jsonl）：每确认一个漏洞立即写入一行，字段与JSON报告的 findings 一致，
+// 扫描过程中可以 tail -f 或通过管道交给 jq 处理，也可作为 -verify 的输入
+type JSONL struct {
+	mux  sync.Mutex
+	w    io.Writer
+	seen map[string]bool // 已写入的漏洞指纹，-watch 的每一轮只追加新出现的漏洞
+	err  error           // 第一次写入失败的错误
+}
+
+// NewJSONL 创建JSON行输出，w 通常为 -o 指定的文件
+func NewJSONL(w io.Writer) *JSONL {
+	return &JSONL{w: w, seen: make(map[string]bool)}
+}
+
+// Record 写入发现漏洞的扫描结果，可作为 ScanManager.OnResult 的处理函数；写入失败后不再写入，错误由 Err 返回
+func (j *JSONL) Record(result scanner.ScanResult) {
+	if !result.Vulnerable {
+		return
+	}
+	finding := notify.NewFinding(result)
+	line, err := json.Marshal(finding)
+
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.err != nil || j.seen[finding.Fingerprint] {
+		return
+	}
+	if err != nil {
+		j.err = err
+		return
+	}
+	j.seen[finding.Fingerprint] = true
+	// 整行一次写入文件（不经过缓冲），读取方不会读到半行
+	_, j.err = j.w.Write(append(line, '\n'))
+}
+
+// Err 返回第一次写入失败的错误
+func (j *JSONL) Err() error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.err != nil {
+		return fmt.Errorf("写入JSONL输出失败: %v", j.err)
+	}
+	return nil
+}
+
+// WriteJSONL 将报告中的所有漏洞写入JSON行文件，用于扫描结束后才得到结果的复测（-verify -format jsonl）
+func WriteJSONL(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	for _, f := range toFindings(sortedResults(r.Results)) {
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
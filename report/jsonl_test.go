@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gosssrf-client/notify"
+	"gosssrf-client/scanner"
+)
+
+func TestJSONLRecord(t *testing.T) {
+	results := []scanner.ScanResult{
+		{Target: "http://a.example/?url=x", Parameter: "url", Payload: "http://10.0.0.1/", PayloadType: "内网探测", Kind: "http-service", Severity: "medium", Vulnerable: true},
+		{Target: "http://a.example/?url=x", Parameter: "url", Payload: "http://10.0.0.2/"},
+		{Target: "http://a.example/?url=x", Parameter: "url", Payload: "file:///etc/passwd", PayloadType: "文件读取", Kind: "file-read", Severity: "critical", Vulnerable: true},
+		// -watch 下一轮再次发现的相同漏洞不重复写入
+		{Target: "http://a.example/?url=x", Parameter: "url", Payload: "file:///etc/passwd", PayloadType: "文件读取", Kind: "file-read", Severity: "critical", Vulnerable: true},
+	}
+
+	var buf bytes.Buffer
+	j := NewJSONL(&buf)
+	var wg sync.WaitGroup
+	for _, r := range results {
+		wg.Add(1)
+		go func(r scanner.ScanResult) {
+			defer wg.Done()
+			j.Record(r)
+		}(r)
+	}
+	wg.Wait()
+	if err := j.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("写入 %d 行，期望 2 行:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var f notify.Finding
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("%q 不是有效的JSON: %v", line, err)
+		}
+		if f.Fingerprint == "" || f.Target != "http://a.example/?url=x" {
+			t.Errorf("漏洞 = %+v，缺少指纹或目标", f)
+		}
+	}
+
+	// 输出的JSON行文件可以作为 -verify 的输入
+	path := filepath.Join(t.TempDir(), "findings.jsonl")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := ReadFindings(path)
+	if err != nil || len(findings) != 2 {
+		t.Fatalf("ReadFindings() = %d 个漏洞, %v，期望 2 个", len(findings), err)
+	}
+}
+
+// failingWriter 总是写入失败
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("磁盘已满") }
+
+func TestJSONLWriteError(t *testing.T) {
+	j := NewJSONL(failingWriter{})
+	j.Record(scanner.ScanResult{Target: "http://a.example/", Payload: "http://10.0.0.1/", Vulnerable: true})
+	if err := j.Err(); err == nil || !strings.Contains(err.Error(), "磁盘已满") {
+		t.Errorf("Err() = %v，期望返回写入失败的错误", err)
+	}
+}
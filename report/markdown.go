@@ -0,0 +1,177 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// WriteMarkdown 输出Markdown报告（每个漏洞一节，附curl复现命令），便于粘贴到渗透测试报告或Wiki
+func WriteMarkdown(w io.Writer, r *Report) error {
+	s := newSummary(r)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# GoSSRF 扫描报告\n\n")
+	fmt.Fprintf(bw, "- 开始时间: %s\n", s.StartTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(bw, "- 耗时: %s\n", s.Duration)
+	fmt.Fprintf(bw, "- 目标: %s\n", strings.Join(markdownCodes(s.Targets), ", "))
+	if s.Interrupted {
+		fmt.Fprintf(bw, "\n> 扫描被中断，结果不完整\n")
+	}
+
+	fmt.Fprintf(bw, "\n## 概览\n\n共发现 %d 个SSRF测试点\n", len(s.Results))
+	writeMarkdownCounts(bw, "严重程度", s.Severities)
+	writeMarkdownCounts(bw, "置信度", s.Confidences)
+	writeMarkdownCounts(bw, "类型", s.Types)
+	writeMarkdownCounts(bw, "目标", s.TargetStats)
+
+	if len(s.Loot) > 0 {
+		fmt.Fprintf(bw, "\n## 获取的凭据\n\n| 厂商 | 来源 | AccessKeyId | 密钥 | 令牌 | 过期时间 |\n| --- | --- | --- | --- | --- | --- |\n")
+		for _, l := range s.Loot {
+			fmt.Fprintf(bw, "| %s | %s | %s | %s | %s | %s |\n", markdownCell(l.Provider), markdownCode(l.Target+" "+l.Parameter+"="+l.Payload),
+				markdownCode(l.AccessKeyID), markdownCode(l.SecretKey), markdownCode(l.Token), expiration(l))
+		}
+	}
+
+	fmt.Fprintf(bw, "\n## 漏洞详情\n")
+	if len(s.Findings) == 0 {
+		fmt.Fprintf(bw, "\n未发现SSRF漏洞\n")
+	}
+	for i, result := range s.Findings {
+		fmt.Fprintf(bw, "\n### %d. [%s] %s\n\n", i+1, result.Severity, markdownCell(result.Parameter+"="+result.Payload))
+		fmt.Fprintf(bw, "| 项目 | 内容 |\n| --- | --- |\n")
+		fmt.Fprintf(bw, "| 目标 | %s |\n", markdownCode(result.Target))
+		fmt.Fprintf(bw, "| 参数 | %s |\n", markdownCode(result.Parameter))
+		fmt.Fprintf(bw, "| Payload | %s |\n", markdownCode(result.Payload))
+		fmt.Fprintf(bw, "| 类型 | %s |\n", markdownCell(result.PayloadType))
+		fmt.Fprintf(bw, "| 严重程度 | %s |\n", result.Severity)
+		fmt.Fprintf(bw, "| 置信度 | %s |\n", result.Confidence)
+		fmt.Fprintf(bw, "| 证据 | %s |\n", markdownCell(result.Evidence))
+		if result.Service != "" {
+			fmt.Fprintf(bw, "| 内网服务 | %s |\n", markdownCell(result.Service))
+		}
+		if result.Fingerprint != "" {
+			fmt.Fprintf(bw, "| 指纹 | %s |\n", markdownCode(result.Fingerprint))
+		}
+		if result.Status != "" {
+			fmt.Fprintf(bw, "| 复测结果 | %s |\n", statusText(result.Status))
+		}
+		if result.Bypass != "" {
+			fmt.Fprintf(bw, "| 绕过WAF | %s |\n", markdownCell(result.Bypass))
+		}
+		if result.EvidenceFile != "" {
+			fmt.Fprintf(bw, "| 证据文件 | %s |\n", markdownCode(result.EvidenceFile))
+		}
+		if len(result.Redirects) > 0 {
+			fmt.Fprintf(bw, "| 重定向 | %s |\n", markdownCell(detector.FormatRedirects(result.Redirects)))
+		}
+		if result.StatusCode != 0 {
+			fmt.Fprintf(bw, "| 响应 | 状态码 %d，长度 %d，耗时 %dms |\n", result.StatusCode, result.ResponseLen, result.ResponseTime)
+		}
+
+		if result.URL != "" {
+			fmt.Fprintf(bw, "\n复现命令:\n\n")
+			writeMarkdownBlock(bw, "bash", curlCommand(result, r.Headers, r.ContentType))
+		}
+		if result.Response != "" {
+			fmt.Fprintf(bw, "\n响应片段:\n\n")
+			writeMarkdownBlock(bw, "", result.Response)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownCounts 输出统计表，没有数据时不输出
+func writeMarkdownCounts(w io.Writer, title string, counts []Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n| %s | 数量 |\n| --- | --- |\n", title)
+	for _, c := range counts {
+		fmt.Fprintf(w, "| %s | %d |\n", markdownCell(c.Name), c.Count)
+	}
+}
+
+// writeMarkdownBlock 输出代码块，内容中包含 ``` 时使用更长的围栏
+func writeMarkdownBlock(w io.Writer, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// markdownCell 转义表格单元格中的 | 和换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// markdownCode 以行内代码输出，内容中包含反引号时使用更长的分隔符
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(markdownCell(s), "<br>", " ")
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// markdownCodes 依次以行内代码输出
+func markdownCodes(list []string) []string {
+	codes := make([]string, len(list))
+	for i, s := range list {
+		codes[i] = markdownCode(s)
+	}
+	return codes
+}
+
+// curlCommand 生成复现请求的curl命令
+// -g 关闭URL中 [] {} 的展开（IPv6地址、模板变量），--path-as-is 保留路径中的 ../
+func curlCommand(result scanner.ScanResult, headers map[string]string, contentType string) string {
+	args := []string{"curl", "-i", "-s", "-k", "-g", "--path-as-is"}
+	if result.Method != "" && result.Method != http.MethodGet {
+		args = append(args, "-X", result.Method)
+	}
+	args = append(args, shellQuote(result.URL))
+
+	names := make([]string, 0, len(headers))
+	hasContentType := false
+	for name := range headers {
+		names = append(names, name)
+		if strings.EqualFold(name, "Content-Type") {
+			hasContentType = true
+		}
+	}
+	sort.Strings(names)
+	// 与扫描时一致：有请求体时设置Content-Type，自定义Header中的Content-Type优先
+	if result.RequestBody != "" && contentType != "" && !hasContentType {
+		args = append(args, "-H", shellQuote("Content-Type: "+contentType))
+	}
+	for _, name := range names {
+		args = append(args, "-H", shellQuote(name+": "+headers[name]))
+	}
+
+	if result.RequestBody != "" {
+		args = append(args, "--data-binary", shellQuote(result.RequestBody))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote 使用单引号包裹参数，内容中的单引号先结束引号再以 \' 转义
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &Report{
+		Targets:     []string{"http://a.example/"},
+		StartTime:   start,
+		EndTime:     start.Add(5 * time.Second),
+		Interrupted: true,
+		Headers:     map[string]string{"Cookie": "sid=1"},
+		ContentType: "application/json",
+		Results: []scanner.ScanResult{
+			{
+				Target: "http://a.example/", Method: "POST", URL: "http://a.example/api",
+				RequestBody: `{"url":"http://127.0.0.1/it's"}`, Parameter: "url", Payload: "http://127.0.0.1/a|b",
+				PayloadType: "内网探测", StatusCode: 200, Severity: "high", Confidence: "probable",
+				Response: "line1\n```\nline2",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, r); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"> 扫描被中断，结果不完整",
+		"共发现 1 个SSRF测试点",
+		"| high | 1 |",
+		"### 1. [high] url=http://127.0.0.1/a\\|b",
+		"| Payload | `http://127.0.0.1/a\\|b` |",
+		`curl -i -s -k -g --path-as-is -X POST 'http://a.example/api' -H 'Content-Type: application/json' -H 'Cookie: sid=1' --data-binary '{"url":"http://127.0.0.1/it'\''s"}'`,
+		"````\nline1\n```\nline2\n````",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("报告中缺少 %q\n%s", want, out)
+		}
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      scanner.ScanResult
+		headers     map[string]string
+		contentType string
+		want        string
+	}{
+		{
+			name:   "GET请求",
+			result: scanner.ScanResult{Method: "GET", URL: "http://a.example/?url=http://[::1]/"},
+			want:   "curl -i -s -k -g --path-as-is 'http://a.example/?url=http://[::1]/'",
+		},
+		{
+			name:        "自定义Content-Type优先",
+			result:      scanner.ScanResult{Method: "POST", URL: "http://a.example/", RequestBody: "url=x"},
+			headers:     map[string]string{"content-type": "text/plain"},
+			contentType: "application/x-www-form-urlencoded",
+			want:        "curl -i -s -k -g --path-as-is -X POST 'http://a.example/' -H 'content-type: text/plain' --data-binary 'url=x'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := curlCommand(tt.result, tt.headers, tt.contentType); got != tt.want {
+				t.Errorf("curlCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
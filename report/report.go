@@ -0,0 +1,160 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// Report 一次扫描的报告数据
+type Report struct {
+	Targets     []string
+	StartTime   time.Time
+	EndTime     time.Time
+	Interrupted bool // 扫描被中断或达到最长扫描时间
+	Results     []scanner.ScanResult
+	Headers     map[string]string // 扫描时发送的自定义请求头（用于生成复现命令）
+	ContentType string            // 请求体的Content-Type（用于生成复现命令）
+}
+
+// summary 报告模板使用的数据（在 Report 基础上增加统计结果）
+type summary struct {
+	*Report
+	Duration    string
+	Findings    []scanner.ScanResult // 按严重程度排序的结果
+	Severities  []Count
+	Confidences []Count
+	Types       []Count
+	TargetStats []Count
+	Loot        []Loot // 从元数据响应中提取的凭据
+}
+
+// newSummary 统计报告数据
+func newSummary(r *Report) summary {
+	return summary{
+		Report:      r,
+		Duration:    r.EndTime.Sub(r.StartTime).Round(time.Second).String(),
+		Findings:    sortedResults(r.Results),
+		Severities:  countBy(r.Results, detector.Severities, false, func(s scanner.ScanResult) string { return s.Severity }),
+		Confidences: countBy(r.Results, detector.Confidences, false, func(s scanner.ScanResult) string { return s.Confidence }),
+		Types:       countBy(r.Results, nil, false, func(s scanner.ScanResult) string { return s.PayloadType }),
+		TargetStats: countBy(r.Results, r.Targets, true, func(s scanner.ScanResult) string { return s.Target }),
+		Loot:        collectLoot(r.Results, r.EndTime),
+	}
+}
+
+// Loot 报告中的凭据条目
+type Loot struct {
+	detector.Credential
+	Target    string
+	Parameter string
+	Payload   string
+	Expired   bool // 生成报告时已过期
+}
+
+// collectLoot 汇总所有漏洞中提取的凭据，相同的凭据只保留第一次获取的记录
+func collectLoot(results []scanner.ScanResult, now time.Time) []Loot {
+	var loot []Loot
+	seen := make(map[string]bool)
+	for _, r := range results {
+		for _, cred := range r.Credentials {
+			key := cred.Provider + "\x00" + cred.AccessKeyID + "\x00" + cred.Token
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			loot = append(loot, Loot{Credential: cred, Target: r.Target, Parameter: r.Parameter, Payload: r.Payload, Expired: cred.Expired(now)})
+		}
+	}
+	return loot
+}
+
+// expiration 格式化凭据过期时间，未知时返回“未知”
+func expiration(l Loot) string {
+	if l.Expiration.IsZero() {
+		return "未知"
+	}
+	text := l.Expiration.Local().Format("2006-01-02 15:04:05")
+	if l.Expired {
+		text += "（已过期）"
+	}
+	return text
+}
+
+// statusText 复测结果的说明（-verify参数）
+func statusText(status string) string {
+	switch status {
+	case scanner.StatusVulnerable:
+		return "仍存在漏洞 (still-vulnerable)"
+	case scanner.StatusFixed:
+		return "已修复 (fixed)"
+	case scanner.StatusUnverified:
+		return "无法复测 (unverified)"
+	}
+	return status
+}
+
+// Count 名称和数量（用于汇总表和图表）
+type Count struct {
+	Name    string
+	Count   int
+	Percent int // 占最大数量的百分比，用于图表宽度
+}
+
+// countBy 按 key 统计结果数量；order 不为空时按其顺序输出，否则按数量从多到少排列
+// keepZero 为 false 时省略数量为0的项
+func countBy(results []scanner.ScanResult, order []string, keepZero bool, key func(scanner.ScanResult) string) []Count {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[key(r)]++
+	}
+
+	names := order
+	if len(names) == 0 {
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+	}
+
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+
+	var result []Count
+	for _, name := range names {
+		if counts[name] == 0 && !keepZero {
+			continue
+		}
+		percent := 0
+		if max > 0 {
+			percent = counts[name] * 100 / max
+		}
+		result = append(result, Count{Name: name, Count: counts[name], Percent: percent})
+	}
+	return result
+}
+
+// sortedResults 按严重程度从高到低排列结果，同级保持发现顺序
+func sortedResults(results []scanner.ScanResult) []scanner.ScanResult {
+	rank := make(map[string]int)
+	for i, s := range detector.Severities {
+		rank[s] = i
+	}
+	sorted := make([]scanner.ScanResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank[sorted[i].Severity] < rank[sorted[j].Severity]
+	})
+	return sorted
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+func TestLoot(t *testing.T) {
+	end := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	aws := detector.Credential{Provider: "AWS", AccessKeyID: "ASIAEXAMPLE", SecretKey: "secret", Token: "token", Expiration: end.Add(-time.Hour)}
+	gcp := detector.Credential{Provider: "GCP", Token: "ya29.example"}
+	r := &Report{
+		Targets:   []string{"http://a.example/"},
+		StartTime: end.Add(-time.Minute),
+		EndTime:   end,
+		Results: []scanner.ScanResult{
+			{Target: "http://a.example/", Parameter: "url", Payload: "http://169.254.169.254/latest/meta-data/iam/security-credentials/role", Severity: "critical", Credentials: []detector.Credential{aws}},
+			{Target: "http://a.example/", Parameter: "img", Payload: "http://169.254.169.254/latest/meta-data/iam/security-credentials/role", Severity: "critical", Credentials: []detector.Credential{aws}},
+			{Target: "http://a.example/", Parameter: "url", Payload: "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", Severity: "critical", Credentials: []detector.Credential{gcp}},
+		},
+	}
+
+	// 相同凭据只保留第一次获取的记录
+	loot := newSummary(r).Loot
+	if len(loot) != 2 || loot[0].Parameter != "url" || !loot[0].Expired || loot[1].Expired {
+		t.Fatalf("凭据 = %+v", loot)
+	}
+
+	var md, html bytes.Buffer
+	if err := WriteMarkdown(&md, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteHTML(&html, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## 获取的凭据", "| AWS |", "`ASIAEXAMPLE`", "`ya29.example`", "（已过期）", "| 未知 |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown报告中缺少 %q\n%s", want, md.String())
+		}
+	}
+	for _, want := range []string{"获取的凭据（2）", "<td>ASIAEXAMPLE</td>", "ya29.example", "（已过期）"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML报告中缺少 %q", want)
+		}
+	}
+}
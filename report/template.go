@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/scanner"
+)
+
+// Template 自定义报告模板（-report-template参数）
+// 模板数据与内置HTML报告相同：.Targets .StartTime .EndTime .Duration .Interrupted .Findings（按严重程度排序的 scanner.ScanResult）
+// .Severities .Confidences .Types .TargetStats（统计）和 .Loot（提取的凭据）
+type Template struct {
+	path string
+	text string
+	html bool // .html/.htm 模板使用 html/template 自动转义
+}
+
+// ParseTemplate 读取并解析报告模板，在扫描开始前调用以尽早发现模板错误
+func ParseTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取报告模板失败: %v", err)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	t := &Template{path: path, text: string(data), html: ext == ".html" || ext == ".htm"}
+	if _, err := t.parse(&Report{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Write 使用模板渲染报告
+func (t *Template) Write(w io.Writer, r *Report) error {
+	tmpl, err := t.parse(r)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, newSummary(r)); err != nil {
+		return fmt.Errorf("渲染报告模板失败: %v", err)
+	}
+	return nil
+}
+
+// executor text/template 和 html/template 共同的渲染方法
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// parse 解析模板，curl 函数需要报告中的自定义请求头，每次渲染时重新解析
+func (t *Template) parse(r *Report) (executor, error) {
+	funcs := templateFuncs(r)
+	var tmpl executor
+	var err error
+	if t.html {
+		tmpl, err = htmltemplate.New(filepath.Base(t.path)).Funcs(funcs).Parse(t.text)
+	} else {
+		tmpl, err = template.New(filepath.Base(t.path)).Funcs(funcs).Parse(t.text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析报告模板失败: %v", err)
+	}
+	return tmpl, nil
+}
+
+// templateFuncs 报告模板可用的函数
+func templateFuncs(r *Report) map[string]interface{} {
+	return map[string]interface{}{
+		"inc":        func(i int) int { return i + 1 },
+		"redirects":  detector.FormatRedirects,
+		"expiration": expiration,
+		"status":     statusText,
+		"curl": func(result scanner.ScanResult) string {
+			return curlCommand(result, r.Headers, r.ContentType)
+		},
+		"fingerprint": func(result scanner.ScanResult) string {
+			if result.Fingerprint != "" {
+				return result.Fingerprint
+			}
+			return scanner.Fingerprint(result)
+		},
+		"date":  func(layout string, t time.Time) string { return t.Format(layout) },
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
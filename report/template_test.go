@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestTemplate(t *testing.T) {
+	start := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	r := &Report{
+		Targets:   []string{"http://a.example/?url=x"},
+		StartTime: start,
+		EndTime:   start.Add(90 * time.Second),
+		Results: []scanner.ScanResult{
+			{Target: "http://a.example/?url=x", Method: "GET", URL: "http://a.example/?url=http://10.0.0.1/", Parameter: "url", Payload: "http://10.0.0.1/", PayloadType: "内网探测", Severity: "low", Vulnerable: true},
+			{Target: "http://a.example/?url=x", Parameter: "url", Payload: "<script>", PayloadType: "文件读取", Severity: "critical", Vulnerable: true},
+		},
+		Headers: map[string]string{"Cookie": "sid=1"},
+	}
+
+	tests := []struct {
+		name string
+		file string
+		text string
+		want string
+	}{
+		{
+			name: "文本模板",
+			file: "report.md",
+			text: `{{date "2006-01-02" .StartTime}} {{.Duration}} {{len .Findings}}{{range $i, $f := .Findings}}
+{{inc $i}}. {{upper $f.Severity}} {{$f.Payload}} {{fingerprint $f}}{{end}}
+{{curl (index .Findings 1)}}`,
+			want: "2024-06-01 1m30s 2\n1. CRITICAL <script> " + scanner.Fingerprint(r.Results[1]) + "\n2. LOW http://10.0.0.1/ " + scanner.Fingerprint(r.Results[0]) +
+				"\ncurl -i -s -k -g --path-as-is 'http://a.example/?url=http://10.0.0.1/' -H 'Cookie: sid=1'",
+		},
+		{
+			name: "HTML模板自动转义",
+			file: "report.html",
+			text: `<ul>{{range .Findings}}<li>{{.Payload}}</li>{{end}}</ul>`,
+			want: "<ul><li>&lt;script&gt;</li><li>http://10.0.0.1/</li></ul>",
+		},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			tmpl, err := ParseTemplate(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Write(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("渲染结果:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplateError(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"syntax.tmpl":  "{{range .Findings}}",
+		"unknown.tmpl": "{{nosuchfunc .Targets}}",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseTemplate(path); err == nil || !strings.Contains(err.Error(), "解析报告模板失败") {
+			t.Errorf("ParseTemplate(%s) err = %v", name, err)
+		}
+	}
+	if _, err := ParseTemplate(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("模板文件不存在时应该返回错误")
+	}
+}
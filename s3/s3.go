@@ -0,0 +1,184 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultRegion 未指定 -s3-region 和 AWS_REGION 时使用的区域，MinIO 默认也使用该区域
+const defaultRegion = "us-east-1"
+
+// Bucket S3兼容存储（AWS S3、MinIO 等）中上传扫描报告的存储桶（-s3参数）
+// 使用AWS签名V4认证，访问密钥从环境变量 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 和 AWS_SESSION_TOKEN 读取
+type Bucket struct {
+	Name      string
+	prefix    string   // 对象键前缀，可以包含 {date}、{time}、{host} 占位符
+	endpoint  *url.URL // 自定义服务地址（MinIO 等）时使用路径形式 endpoint/bucket/key
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+}
+
+// New 解析 s3://bucket/prefix 地址创建存储桶；endpoint 为空时使用AWS S3的虚拟主机形式地址，region 为空时读取 AWS_REGION
+func New(rawURL, endpoint, region string, timeout time.Duration) (*Bucket, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("无效的S3地址: %s (格式: s3://bucket/前缀)", rawURL)
+	}
+	b := &Bucket{
+		Name:      u.Host,
+		prefix:    strings.TrimPrefix(u.Path, "/"),
+		region:    region,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		client:    &http.Client{Timeout: timeout},
+	}
+	if b.accessKey == "" || b.secretKey == "" {
+		return nil, errors.New("上传到S3需要设置环境变量 AWS_ACCESS_KEY_ID 和 AWS_SECRET_ACCESS_KEY")
+	}
+	if b.region == "" {
+		b.region = os.Getenv("AWS_REGION")
+	}
+	if b.region == "" {
+		b.region = defaultRegion
+	}
+	if endpoint != "" {
+		if b.endpoint, err = url.Parse(strings.TrimRight(endpoint, "/")); err != nil {
+			return nil, fmt.Errorf("无效的S3服务地址: %s", endpoint)
+		}
+	}
+	return b, nil
+}
+
+// Key 生成对象键：展开前缀中的占位符后拼接 name，{date} 和 {time} 使用 t（通常为扫描开始时间），{host} 为本机主机名
+func (b *Bucket) Key(t time.Time, name string) string {
+	host, _ := os.Hostname()
+	prefix := strings.NewReplacer(
+		"{date}", t.Format("20060102"),
+		"{time}", t.Format("20060102-150405"),
+		"{host}", host,
+	).Replace(b.prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix + name
+}
+
+// Put 上传一个对象
+func (b *Bucket) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, b.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if b.token != "" {
+		req.Header.Set("X-Amz-Security-Token", b.token)
+	}
+	b.sign(req, body, time.Now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("上传 s3://%s/%s 失败: %v", b.Name, key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("上传 s3://%s/%s 失败: 返回状态码 %d %s", b.Name, key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// objectURL 对象地址：AWS S3 使用 https://bucket.s3.region.amazonaws.com/key，自定义服务地址使用 endpoint/bucket/key
+func (b *Bucket) objectURL(key string) string {
+	if b.endpoint != nil {
+		return b.endpoint.String() + "/" + uriEncode(b.Name) + "/" + uriEncode(key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.Name, b.region, uriEncode(key))
+}
+
+// sign 按AWS签名V4为请求添加 Authorization 头，签名 Host 和请求中已设置的所有请求头
+func (b *Bucket) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + b.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), now.Format("20060102"))
+	for _, part := range []string{b.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, signedHeaders, signature))
+}
+
+// uriEncode 按S3的规则编码对象键：保留 / 和非保留字符，其他字节编码为 %XX
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex 返回数据SHA-256哈希的十六进制
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
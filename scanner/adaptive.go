@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"gosssrf-client/detector"
+)
+
+// 自适应并发（-adaptive参数）的调整规则
+const (
+	adaptiveWindow  = 20   // 每收到多少个响应评估一次目标状态
+	adaptiveBackoff = 0.2  // 异常比例达到该值时并发减半
+	adaptiveHealthy = 0.05 // 异常比例不超过该值时并发加一
+)
+
+// adaptiveLimit 单个主机的自适应并发上限：异常增多时减半，恢复正常后逐步增加到 max
+type adaptiveLimit struct {
+	host     string
+	limit    int // 当前的并发上限
+	max      int // 并发上限的最大值（-t 或 -threads-per-host）
+	inflight int // 正在发送的请求数
+	sent     int // 本轮收到的响应数
+	failed   int // 其中超时、连接失败或返回5xx的数量
+	mu       sync.Mutex
+	cond     *sync.Cond
+}
+
+// newAdaptiveLimit 创建自适应并发上限，初始为 max
+func newAdaptiveLimit(host string, max int) *adaptiveLimit {
+	if max < 1 {
+		max = 1
+	}
+	a := &adaptiveLimit{host: host, limit: max, max: max}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// acquire 占用一个并发，正在发送的请求数达到当前上限时阻塞
+func (a *adaptiveLimit) acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.inflight >= a.limit {
+		a.cond.Wait()
+	}
+	a.inflight++
+}
+
+// release 释放 acquire 占用的并发
+func (a *adaptiveLimit) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight--
+	a.cond.Signal()
+}
+
+// record 记录一个响应是否异常，每收到 adaptiveWindow 个响应按异常比例调整并发上限
+func (a *adaptiveLimit) record(failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sent++
+	if failed {
+		a.failed++
+	}
+	if a.sent < adaptiveWindow {
+		return
+	}
+
+	rate := float64(a.failed) / float64(a.sent)
+	a.sent, a.failed = 0, 0
+	switch {
+	case rate >= adaptiveBackoff && a.limit > 1:
+		a.limit = max(1, a.limit/2)
+		slog.Warn(fmt.Sprintf("[%s] %.0f%% 的请求超时、连接失败或返回5xx，并发降至 %d", a.host, rate*100, a.limit), "target", a.host)
+	case rate <= adaptiveHealthy && a.limit < a.max:
+		a.limit++
+		slog.Debug(fmt.Sprintf("[%s] 目标响应恢复正常，并发增加到 %d", a.host, a.limit), "target", a.host)
+		a.cond.Broadcast()
+	}
+}
+
+// adaptiveLimits 每个主机的自适应并发上限（-adaptive参数）
+type adaptiveLimits struct {
+	max   int
+	hosts map[string]*adaptiveLimit
+	mu    sync.Mutex
+}
+
+// newAdaptiveLimits 创建自适应并发上限，未指定 -adaptive 时返回nil
+// 指定 -threads-per-host 时每个主机最多增加到该值，否则最多增加到 -t
+func newAdaptiveLimits(enabled bool, threads, perHost int) *adaptiveLimits {
+	if !enabled {
+		return nil
+	}
+	if perHost > 0 {
+		threads = perHost
+	}
+	return &adaptiveLimits{max: threads, hosts: make(map[string]*adaptiveLimit)}
+}
+
+// host 返回目标所在主机的自适应并发上限，未启用 -adaptive 时返回nil
+func (l *adaptiveLimits) host(target string) *adaptiveLimit {
+	if l == nil {
+		return nil
+	}
+	host := targetHost(target)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a, ok := l.hosts[host]
+	if !ok {
+		a = newAdaptiveLimit(host, l.max)
+		l.hosts[host] = a
+	}
+	return a
+}
+
+// recordHealth 按请求结果调整目标主机的自适应并发（-adaptive参数）
+// 没有收到响应（超时、连接失败）、返回429或返回5xx时视为异常，基线响应本身就是5xx时不计5xx
+func (sm *ScanManager) recordHealth(target string, result detector.Result, baseline *detector.Baseline) {
+	a := sm.adaptive.host(target)
+	if a == nil || result.Canceled {
+		return
+	}
+	failed := (result.Error != "" && result.StatusCode == 0) || result.StatusCode == 429
+	if result.StatusCode >= 500 && (baseline == nil || baseline.StatusCode < 500) {
+		failed = true
+	}
+	a.record(failed)
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+)
+
+func TestAdaptiveLimitRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		failed []int // 每轮 adaptiveWindow 个响应中的异常数量
+		want   int
+	}{
+		{"异常比例过高时减半", 8, []int{4}, 4},
+		{"连续异常时降到1", 8, []int{10, 10, 10, 10, 10}, 1},
+		{"少量异常时保持不变", 4, []int{2}, 4},
+		{"恢复正常后逐步增加", 2, []int{0, 0}, 4},
+		{"不超过最大值", 8, []int{0, 0}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAdaptiveLimit("example.com", 8)
+			a.limit = tt.limit
+			for _, failed := range tt.failed {
+				for i := 0; i < adaptiveWindow; i++ {
+					a.record(i < failed)
+				}
+			}
+			if a.limit != tt.want {
+				t.Errorf("limit = %d, want %d", a.limit, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimitAcquire(t *testing.T) {
+	a := newAdaptiveLimit("example.com", 2)
+	a.limit = 1
+	a.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		a.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("达到并发上限时 acquire 应该阻塞")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("release 后 acquire 应该继续")
+	}
+}
+
+func TestRecordHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   detector.Result
+		baseline *detector.Baseline
+		failed   bool
+	}{
+		{"正常响应", detector.Result{StatusCode: 200}, nil, false},
+		{"请求超时", detector.Result{Error: "请求失败: timeout"}, nil, true},
+		{"返回429", detector.Result{StatusCode: 429}, nil, true},
+		{"返回502", detector.Result{StatusCode: 502}, &detector.Baseline{StatusCode: 200}, true},
+		{"基线就是500", detector.Result{StatusCode: 500}, &detector.Baseline{StatusCode: 500}, false},
+		{"有响应但检测出错", detector.Result{StatusCode: 400, Error: "GraphQL校验失败"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Threads: 4, Adaptive: true}
+			sm := &ScanManager{config: cfg, adaptive: newAdaptiveLimits(cfg.Adaptive, cfg.Threads, cfg.ThreadsPerHost)}
+			sm.recordHealth("http://example.com/?url=1", tt.result, tt.baseline)
+			a := sm.adaptive.host("http://EXAMPLE.com/other")
+			if got := a.failed == 1; got != tt.failed || a.sent != 1 {
+				t.Errorf("failed = %v (sent %d), want %v", got, a.sent, tt.failed)
+			}
+		})
+	}
+}
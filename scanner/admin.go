@@ -0,0 +1,26 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/payloads"
+	"log/slog"
+)
+
+// scanAdminPaths 对端口扫描访问到的每个内网Web服务，通过SSRF请求 Spring Boot Actuator、server-status、jmx-console 等敏感路径
+// 内网服务通常不对这些接口做认证，命中的路径作为漏洞逐条报告
+func (sm *ScanManager) scanAdminPaths(ctx context.Context, target string, params map[string]string) {
+	for _, base := range sm.webHosts(target) {
+		slog.Info(fmt.Sprintf("[%s] 访问到内网Web服务 %s，尝试请求敏感管理接口", target, base), "target", target)
+		for param := range params {
+			for _, payload := range payloads.GetAdminPayloads(base) {
+				if sm.excluded(payload) {
+					continue
+				}
+				if !sm.testPayload(ctx, target, param, payload) {
+					return
+				}
+			}
+		}
+	}
+}
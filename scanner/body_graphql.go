@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// graphqlVariables GraphQL请求体中变量的JSON路径前缀，-p 和自动发现的参数名为变量名（嵌套变量使用点路径，例如 input.url）
+const graphqlVariables = "variables."
+
+// buildGraphQLBody 将payload注入到GraphQL请求的变量中，变量不存在时自动创建
+func buildGraphQLBody(bodyTemplate, param, payload string) (string, error) {
+	return buildJSONBody(bodyTemplate, graphqlVariables+param, payload)
+}
+
+// hasGraphQLVariable 判断GraphQL请求中是否存在指定变量
+func hasGraphQLVariable(bodyTemplate, param string) bool {
+	return hasJSONPath(bodyTemplate, graphqlVariables+param)
+}
+
+// flattenGraphQL 列出GraphQL请求中所有字符串变量的路径及其值（用于参数自动发现），不包括查询文档本身
+func flattenGraphQL(bodyTemplate string) map[string]string {
+	result := make(map[string]string)
+	for path, value := range flattenJSON(bodyTemplate) {
+		if strings.HasPrefix(path, graphqlVariables) {
+			result[strings.TrimPrefix(path, graphqlVariables)] = value
+		}
+	}
+	return result
+}
+
+// inGraphQLQuery 判断请求体中 pos 位置是否位于 query 字段的值（查询文档）中
+func inGraphQLQuery(bodyTemplate string, pos int) bool {
+	decoder := json.NewDecoder(strings.NewReader(bodyTemplate))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		start := decoder.InputOffset()
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return false
+		}
+		if key == "query" {
+			return int64(pos) >= start && int64(pos) < decoder.InputOffset()
+		}
+	}
+	return false
+}
+
+// encodeGraphQLString 将payload转义为GraphQL字符串字面量的内容（转义规则与JSON字符串相同）
+func encodeGraphQLString(payload string) string {
+	encoded, _ := json.Marshal(payload)
+	return string(encoded[1 : len(encoded)-1])
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+const graphqlTemplate = `{"query":"mutation($url: String!) { importAvatar(url: $url) { id } }","variables":{"url":"a","id":1}}`
+
+func TestBuildGraphQLBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		param    string
+		want     string
+	}{
+		{
+			name:     "已有变量",
+			template: graphqlTemplate,
+			param:    "url",
+			want:     `{"query":"mutation($url: String!) { importAvatar(url: $url) { id } }","variables":{"id":1,"url":"http://127.0.0.1/"}}`,
+		},
+		{
+			name:     "嵌套变量",
+			template: `{"query":"q","variables":{"input":{"webhook":"a"}}}`,
+			param:    "input.webhook",
+			want:     `{"query":"q","variables":{"input":{"webhook":"http://127.0.0.1/"}}}`,
+		},
+		{
+			name:     "variables为null时创建变量",
+			template: `{"query":"q","variables":null}`,
+			param:    "url",
+			want:     `{"query":"q","variables":{"url":"http://127.0.0.1/"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildGraphQLBody(tt.template, tt.param, "http://127.0.0.1/")
+			if err != nil {
+				t.Fatalf("buildGraphQLBody() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("buildGraphQLBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLParams(t *testing.T) {
+	if !hasBodyParam(config.BodyTypeGraphQL, graphqlTemplate, "url") {
+		t.Error("hasBodyParam() 应识别GraphQL变量")
+	}
+	if hasBodyParam(config.BodyTypeGraphQL, graphqlTemplate, "query") {
+		t.Error("hasBodyParam() 不应把查询文档当作变量")
+	}
+
+	want := map[string]string{"url": "a"}
+	if got := flattenGraphQL(graphqlTemplate); !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenGraphQL() = %v, want %v", got, want)
+	}
+}
+
+func TestGraphQLMarker(t *testing.T) {
+	template := `{"query":"{ fetch(url: \"{{PAYLOAD}}\") }","variables":{"u":"{{PAYLOAD}}"}}`
+	tests := []struct {
+		name  string
+		index int
+		want  string
+	}{
+		{"查询文档中的标记双重转义", 1, `{"query":"{ fetch(url: \"a\\\"b\") }","variables":{"u":""}}`},
+		{"变量中的标记按JSON转义", 2, `{"query":"{ fetch(url: \"\") }","variables":{"u":"a\"b"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body, err := buildMarkerRequest("http://example.com/graphql", config.BodyTypeGraphQL, template, tt.index, `a"b`)
+			if err != nil {
+				t.Fatalf("buildMarkerRequest() error = %v", err)
+			}
+			if body != tt.want {
+				t.Errorf("buildMarkerRequest() body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
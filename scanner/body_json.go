@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON 解析JSON，数字保留为 json.Number，避免大整数和高精度数字被改写
+func decodeJSON(data string, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// buildJSONBody 将payload注入到JSON请求体中由点路径指定的键（例如 data.avatar.url）
+// bodyTemplate 为空时从空对象开始构造，路径中不存在的对象会自动创建
+func buildJSONBody(bodyTemplate, path, payload string) (string, error) {
+	var root interface{} = map[string]interface{}{}
+	if strings.TrimSpace(bodyTemplate) != "" {
+		if err := decodeJSON(bodyTemplate, &root); err != nil {
+			return "", fmt.Errorf("无效的JSON请求体: %v", err)
+		}
+	}
+
+	root, err := setJSONPath(root, strings.Split(path, "."), payload)
+	if err != nil {
+		return "", err
+	}
+
+	// 不转义 & < > 等字符，保持payload原样
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(root); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// setJSONPath 按路径设置值，数字路径段用于访问数组下标
+func setJSONPath(node interface{}, keys []string, value string) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+
+	key := keys[0]
+	switch current := node.(type) {
+	case map[string]interface{}:
+		child, err := setJSONPath(current[key], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[key] = child
+		return current, nil
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(current) {
+			return nil, fmt.Errorf("无效的JSON数组下标: %s", key)
+		}
+		child, err := setJSONPath(current[index], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[index] = child
+		return current, nil
+	default:
+		// 路径不存在或为普通值时创建新对象
+		child, err := setJSONPath(nil, keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key: child}, nil
+	}
+}
+
+// hasJSONPath 判断JSON请求体中是否存在指定路径
+func hasJSONPath(bodyTemplate, path string) bool {
+	var root interface{}
+	if err := decodeJSON(bodyTemplate, &root); err != nil {
+		return false
+	}
+
+	node := root
+	for _, key := range strings.Split(path, ".") {
+		switch current := node.(type) {
+		case map[string]interface{}:
+			child, ok := current[key]
+			if !ok {
+				return false
+			}
+			node = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return false
+			}
+			node = current[index]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// flattenJSON 列出JSON请求体中所有字符串值的点路径及其值（用于参数自动发现）
+func flattenJSON(bodyTemplate string) map[string]string {
+	result := make(map[string]string)
+
+	var root interface{}
+	if err := decodeJSON(bodyTemplate, &root); err != nil {
+		return result
+	}
+
+	var walk func(prefix string, node interface{})
+	walk = func(prefix string, node interface{}) {
+		join := func(key string) string {
+			if prefix == "" {
+				return key
+			}
+			return prefix + "." + key
+		}
+
+		switch current := node.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(current))
+			for key := range current {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(join(key), current[key])
+			}
+		case []interface{}:
+			for i, child := range current {
+				walk(join(strconv.Itoa(i)), child)
+			}
+		case string:
+			result[prefix] = current
+		}
+	}
+	walk("", root)
+
+	return result
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import "testing"
+
+func TestBuildJSONBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		payload  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "顶层键",
+			template: `{"url":"a","id":1}`,
+			path:     "url",
+			payload:  "http://127.0.0.1/",
+			want:     `{"id":1,"url":"http://127.0.0.1/"}`,
+		},
+		{
+			name:     "嵌套对象",
+			template: `{"data":{"avatar":{"url":"a"}}}`,
+			path:     "data.avatar.url",
+			payload:  "http://x/?a=1&b=<2>",
+			want:     `{"data":{"avatar":{"url":"http://x/?a=1&b=<2>"}}}`,
+		},
+		{
+			name:     "数组下标",
+			template: `{"items":[{"src":"a"},{"src":"b"}]}`,
+			path:     "items.1.src",
+			payload:  "p",
+			want:     `{"items":[{"src":"a"},{"src":"p"}]}`,
+		},
+		{
+			name:     "大整数保持不变",
+			template: `{"id":12345678901234567890,"price":0.1000,"url":"a"}`,
+			path:     "url",
+			payload:  "p",
+			want:     `{"id":12345678901234567890,"price":0.1000,"url":"p"}`,
+		},
+		{
+			name:     "自动创建不存在的路径",
+			template: "",
+			path:     "a.b",
+			payload:  "p",
+			want:     `{"a":{"b":"p"}}`,
+		},
+		{
+			name:     "数组下标越界",
+			template: `{"items":["a"]}`,
+			path:     "items.3",
+			payload:  "p",
+			wantErr:  true,
+		},
+		{
+			name:     "数组下标不是数字",
+			template: `{"items":["a"]}`,
+			path:     "items.x",
+			payload:  "p",
+			wantErr:  true,
+		},
+		{
+			name:     "无效JSON",
+			template: `{"url":`,
+			path:     "url",
+			payload:  "p",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildJSONBody(tt.template, tt.path, tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("构造失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("得到 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenJSON(t *testing.T) {
+	got := flattenJSON(`{"a":{"url":"x"},"list":["y",2],"n":1}`)
+	want := map[string]string{"a.url": "x", "list.0": "y"}
+	if len(got) != len(want) {
+		t.Fatalf("得到 %v，期望 %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q，期望 %q", key, got[key], value)
+		}
+	}
+}
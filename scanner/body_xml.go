@@ -0,0 +1,280 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlTarget XML注入位置：元素路径（按本地名匹配路径后缀），可选属性名
+// 参数格式: url | GetImage/url | image@src
+type xmlTarget struct {
+	path []string
+	attr string
+}
+
+// parseXMLTarget 解析XML注入位置
+func parseXMLTarget(param string) xmlTarget {
+	target := xmlTarget{}
+	if idx := strings.LastIndex(param, "@"); idx != -1 {
+		target.attr = param[idx+1:]
+		param = param[:idx]
+	}
+	for _, part := range strings.Split(param, "/") {
+		if part != "" {
+			target.path = append(target.path, part)
+		}
+	}
+	return target
+}
+
+// matches 判断当前元素栈是否匹配注入路径（路径为空表示任意元素）
+func (t xmlTarget) matches(stack []string) bool {
+	if len(t.path) > len(stack) {
+		return false
+	}
+	offset := len(stack) - len(t.path)
+	for i, name := range t.path {
+		if stack[offset+i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// xmlLocation 注入位置在原始XML中的字节范围
+type xmlLocation struct {
+	tagStart     int // 开始标签起始位置
+	contentStart int // 元素内容起始位置（开始标签结束位置）
+	contentEnd   int // 元素内容结束位置（结束标签起始位置）
+	selfClosing  bool
+	name         string // 开始标签中的原始元素名（含前缀）
+}
+
+// locateXML 定位第一个匹配的元素
+func locateXML(bodyTemplate string, target xmlTarget) (*xmlLocation, error) {
+	decoder := xml.NewDecoder(strings.NewReader(bodyTemplate))
+	decoder.Strict = false
+
+	var stack []string
+	var found *xmlLocation
+	foundDepth := 0
+
+	for {
+		offset := int(decoder.InputOffset())
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("无效的XML请求体: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if found == nil && target.matches(stack) && (target.attr == "" || hasXMLAttr(t, target.attr)) {
+				end := int(decoder.InputOffset())
+				found = &xmlLocation{
+					tagStart:     offset,
+					contentStart: end,
+					selfClosing:  strings.HasSuffix(bodyTemplate[offset:end], "/>"),
+					name:         rawTagName(t.Name),
+				}
+				foundDepth = len(stack)
+			}
+		case xml.EndElement:
+			if found != nil && found.contentEnd == 0 && len(stack) == foundDepth {
+				found.contentEnd = offset
+				if found.selfClosing {
+					found.contentEnd = found.contentStart
+				}
+				return found, nil
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if found != nil {
+		return nil, fmt.Errorf("XML元素未闭合: %s", found.name)
+	}
+	return nil, fmt.Errorf("XML请求体中不存在注入位置")
+}
+
+// hasXMLAttr 判断元素是否包含指定属性（按本地名匹配）
+func hasXMLAttr(elem xml.StartElement, attr string) bool {
+	for _, a := range elem.Attr {
+		if a.Name.Local == attr || rawTagName(a.Name) == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// rawTagName 返回带前缀的原始名称（RawToken不解析命名空间，Space即为前缀）
+func rawTagName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// escapeXML 对payload进行XML转义
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// buildXMLBody 将payload注入到XML请求体中的元素值或属性值，其余内容保持原样
+func buildXMLBody(bodyTemplate, param, payload string) (string, error) {
+	target := parseXMLTarget(param)
+	loc, err := locateXML(bodyTemplate, target)
+	if err != nil {
+		return "", err
+	}
+
+	escaped := escapeXML(payload)
+
+	// 属性注入：替换开始标签中的属性值
+	if target.attr != "" {
+		tag := bodyTemplate[loc.tagStart:loc.contentStart]
+		valueStart, valueEnd, ok := findXMLAttrValue(tag, target.attr)
+		if !ok {
+			return "", fmt.Errorf("XML请求体中不存在属性: %s", target.attr)
+		}
+		valueStart += loc.tagStart
+		valueEnd += loc.tagStart
+		return bodyTemplate[:valueStart] + escaped + bodyTemplate[valueEnd:], nil
+	}
+
+	// 自闭合元素：展开为 <name>payload</name>
+	if loc.selfClosing {
+		tag := bodyTemplate[loc.tagStart:loc.contentStart]
+		opening := strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(tag, "/>")), "/") + ">"
+		return bodyTemplate[:loc.tagStart] + opening + escaped + "</" + loc.name + ">" + bodyTemplate[loc.contentStart:], nil
+	}
+
+	// 元素注入：替换元素内容
+	return bodyTemplate[:loc.contentStart] + escaped + bodyTemplate[loc.contentEnd:], nil
+}
+
+// findXMLAttrValue 在原始开始标签中查找属性值（不含引号）的字节范围
+// 逐个解析属性，跳过引号内的内容，避免匹配到其他属性值中的同名文本
+func findXMLAttrValue(tag, attr string) (int, int, bool) {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+	// 跳过 < 和元素名
+	i := 1
+	for i < len(tag) && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+
+	for i < len(tag) {
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] == '>' || tag[i] == '/' {
+			break
+		}
+
+		// 属性名
+		nameStart := i
+		for i < len(tag) && !isSpace(tag[i]) && tag[i] != '=' && tag[i] != '>' && tag[i] != '/' {
+			i++
+		}
+		name := tag[nameStart:i]
+
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] != '=' {
+			continue // 没有值的属性
+		}
+		i++
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || (tag[i] != '"' && tag[i] != '\'') {
+			return 0, 0, false
+		}
+
+		// 属性值（引号内）
+		quote := tag[i]
+		valueStart := i + 1
+		valueEnd := strings.IndexByte(tag[valueStart:], quote)
+		if valueEnd == -1 {
+			return 0, 0, false
+		}
+		valueEnd += valueStart
+		i = valueEnd + 1
+
+		local := name
+		if idx := strings.LastIndex(name, ":"); idx != -1 {
+			local = name[idx+1:]
+		}
+		if name == attr || local == attr {
+			return valueStart, valueEnd, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// hasXMLParam 判断XML请求体中是否存在注入位置
+func hasXMLParam(bodyTemplate, param string) bool {
+	_, err := locateXML(bodyTemplate, parseXMLTarget(param))
+	return err == nil
+}
+
+// flattenXML 列出XML请求体中所有文本叶子元素和属性（用于参数自动发现）
+// 元素以本地名表示，属性以 元素@属性 表示
+func flattenXML(bodyTemplate string) map[string]string {
+	result := make(map[string]string)
+	decoder := xml.NewDecoder(strings.NewReader(bodyTemplate))
+	decoder.Strict = false
+
+	var stack []string
+	var text strings.Builder
+	hasChild := false
+	for {
+		tok, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			text.Reset()
+			hasChild = false
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				result[t.Name.Local+"@"+a.Name.Local] = a.Value
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				break
+			}
+			name := stack[len(stack)-1]
+			if !hasChild {
+				if _, ok := result[name]; !ok {
+					result[name] = strings.TrimSpace(text.String())
+				}
+			}
+			stack = stack[:len(stack)-1]
+			text.Reset()
+			hasChild = true
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import "testing"
+
+func TestBuildXMLBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		param    string
+		payload  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "元素值",
+			template: `<req><url>a</url><id>1</id></req>`,
+			param:    "url",
+			payload:  "http://x/?a=1&b=2",
+			want:     `<req><url>http://x/?a=1&amp;b=2</url><id>1</id></req>`,
+		},
+		{
+			name:     "元素路径",
+			template: `<r><a><url>1</url></a><b><url>2</url></b></r>`,
+			param:    "b/url",
+			payload:  "p",
+			want:     `<r><a><url>1</url></a><b><url>p</url></b></r>`,
+		},
+		{
+			name:     "属性值跳过其他属性中的同名文本",
+			template: `<r><img alt="src='x'" src="a.png"/></r>`,
+			param:    "img@src",
+			payload:  "p",
+			want:     `<r><img alt="src='x'" src="p"/></r>`,
+		},
+		{
+			name:     "单引号属性",
+			template: `<r><img src='a'></img></r>`,
+			param:    "img@src",
+			payload:  "p",
+			want:     `<r><img src='p'></img></r>`,
+		},
+		{
+			name:     "自闭合元素展开",
+			template: `<r><url/><id>1</id></r>`,
+			param:    "url",
+			payload:  "p",
+			want:     `<r><url>p</url><id>1</id></r>`,
+		},
+		{
+			name:     "带命名空间前缀",
+			template: `<soap:Envelope xmlns:soap="urn:s"><soap:Body><m:Fetch xmlns:m="urn:m"><m:url>a</m:url></m:Fetch></soap:Body></soap:Envelope>`,
+			param:    "Fetch/url",
+			payload:  "p",
+			want:     `<soap:Envelope xmlns:soap="urn:s"><soap:Body><m:Fetch xmlns:m="urn:m"><m:url>p</m:url></m:Fetch></soap:Body></soap:Envelope>`,
+		},
+		{
+			name:     "元素不存在",
+			template: `<r><id>1</id></r>`,
+			param:    "url",
+			payload:  "p",
+			wantErr:  true,
+		},
+		{
+			name:     "属性不存在",
+			template: `<r><img alt="src"/></r>`,
+			param:    "img@src",
+			payload:  "p",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildXMLBody(tt.template, tt.param, tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("构造失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("得到 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gosssrf-client/config"
+)
+
+// maxCrawlPages 每个起始目标最多爬取的页面数量
+const maxCrawlPages = 300
+
+var (
+	linkPattern  = regexp.MustCompile(`(?is)<(?:a|area|iframe|frame)\b[^>]*?\s(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	formPattern  = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	fieldPattern = regexp.MustCompile(`(?is)<(input|textarea|select)\b([^>]*)>`)
+	attrPattern  = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// crawlPoint 爬取发现的注入点
+type crawlPoint struct {
+	Method string
+	URL    string
+	Body   string // POST表单的请求体
+}
+
+// Crawl 从每个目标开始爬取同一站点的页面（-crawl参数），将带有疑似SSRF参数的链接和表单加入扫描目标，返回新增的目标数量
+// 链接和GET表单作为新的目标URL，POST表单按表单字段生成请求体，扫描时使用各自的请求方式和请求体
+func (sm *ScanManager) Crawl(ctx context.Context) int {
+	seen := make(map[string]bool)
+	for _, target := range sm.config.Targets {
+		seen[pointKey("GET", target, "")] = true
+	}
+
+	var points []crawlPoint
+	for _, start := range sm.config.Targets {
+		if sm.stopped(ctx) {
+			break
+		}
+		pages, found := sm.crawlSite(ctx, start)
+		added := 0
+		for _, p := range found {
+			key := pointKey(p.Method, p.URL, p.Body)
+			if seen[key] || !sm.injectable(p) {
+				continue
+			}
+			seen[key] = true
+			points = append(points, p)
+			added++
+		}
+		slog.Info(fmt.Sprintf("[%s] 爬取 %d 个页面，发现 %d 个注入点", start, pages, added), "target", start)
+	}
+
+	added := 0
+	for _, p := range points {
+		if _, ok := sm.config.Requests[p.URL]; ok || containsString(sm.config.Targets, p.URL) {
+			continue
+		}
+		if p.Method != "GET" {
+			if sm.config.Requests == nil {
+				sm.config.Requests = make(map[string]*config.RawRequest)
+			}
+			sm.config.Requests[p.URL] = &config.RawRequest{
+				Method:   p.Method,
+				URL:      p.URL,
+				Headers:  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:     p.Body,
+				BodyType: config.BodyTypeForm,
+			}
+		}
+		sm.config.Targets = append(sm.config.Targets, p.URL)
+		added++
+		msg := fmt.Sprintf("[crawl] 新增扫描目标: %s %s", p.Method, p.URL)
+		if p.Body != "" {
+			msg += " 请求体: " + p.Body
+		}
+		slog.Info(msg, "target", p.URL)
+	}
+	return added
+}
+
+// crawlSite 按层爬取与起始目标同一站点的页面，最多 -depth 层，返回爬取的页面数量和同一站点的注入点
+func (sm *ScanManager) crawlSite(ctx context.Context, start string) (int, []crawlPoint) {
+	base, err := url.Parse(start)
+	if err != nil || base.Host == "" {
+		return 0, nil
+	}
+
+	var (
+		mu     sync.Mutex
+		points []crawlPoint
+	)
+	visited := map[string]bool{pageKey(base): true}
+	level := []string{start}
+	pages := 0
+	for depth := 0; depth <= sm.config.CrawlDepth && len(level) > 0 && !sm.stopped(ctx); depth++ {
+		var next []string
+		var wg sync.WaitGroup
+		semaphore := sm.semaphore(start)
+		for _, page := range level {
+			if pages >= maxCrawlPages || sm.stopped(ctx) {
+				break
+			}
+			pages++
+			wg.Add(1)
+			semaphore.acquire()
+			go func(page string) {
+				defer func() {
+					semaphore.release()
+					wg.Done()
+				}()
+				_, body, err := sm.detector.Fetch(ctx, "GET", page, "")
+				if err != nil {
+					slog.Debug(fmt.Sprintf("[crawl] 请求 %s 失败: %v", page, err), "target", start)
+					return
+				}
+				links, found := parsePage(page, body)
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, p := range found {
+					if u, err := url.Parse(p.URL); err == nil && u.Host == base.Host {
+						points = append(points, p)
+					}
+				}
+				for _, link := range links {
+					u, err := url.Parse(link)
+					if err != nil || u.Host != base.Host || config.IsStaticResource(link) || visited[pageKey(u)] {
+						continue
+					}
+					visited[pageKey(u)] = true
+					next = append(next, link)
+				}
+			}(page)
+		}
+		wg.Wait()
+		sort.Strings(next) // 并发请求完成的顺序不固定，保证每次爬取的顺序相同
+		level = next
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].URL < points[j].URL })
+	return pages, points
+}
+
+// injectable 判断注入点是否包含要测试的参数：指定 -p 时包含该参数，否则至少有一个疑似SSRF参数
+func (sm *ScanManager) injectable(p crawlPoint) bool {
+	for _, c := range discoverParams(p.URL, config.BodyTypeForm, p.Body) {
+		if c.Name == sm.config.ParamName || (sm.config.ParamName == "" && c.Score >= minParamScore) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePage 解析页面中的同站链接和表单，返回要继续爬取的页面地址和其中的注入点
+// 带查询参数的链接和GET表单作为GET注入点，POST表单作为POST注入点
+func parsePage(pageURL, body string) ([]string, []crawlPoint) {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	var links []string
+	var points []crawlPoint
+	for _, m := range linkPattern.FindAllStringSubmatch(body, -1) {
+		u, ok := resolveLink(page, m[1]+m[2]+m[3])
+		if !ok {
+			continue
+		}
+		links = append(links, u.String())
+		if u.RawQuery != "" {
+			points = append(points, crawlPoint{Method: "GET", URL: u.String()})
+		}
+	}
+
+	for _, m := range formPattern.FindAllStringSubmatch(body, -1) {
+		attrs := parseAttrs(m[1])
+		action, ok := resolveLink(page, attrs["action"])
+		if !ok {
+			continue
+		}
+		values := url.Values{}
+		for _, field := range fieldPattern.FindAllStringSubmatch(m[2], -1) {
+			fieldAttrs := parseAttrs(field[2])
+			name := fieldAttrs["name"]
+			switch strings.ToLower(fieldAttrs["type"]) {
+			case "submit", "button", "reset", "image", "file":
+				continue
+			}
+			if name != "" {
+				values.Set(name, fieldAttrs["value"])
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if strings.EqualFold(attrs["method"], "post") {
+			points = append(points, crawlPoint{Method: "POST", URL: action.String(), Body: values.Encode()})
+			continue
+		}
+		query := action.Query()
+		for name := range values {
+			query.Set(name, values.Get(name))
+		}
+		action.RawQuery = query.Encode()
+		points = append(points, crawlPoint{Method: "GET", URL: action.String()})
+	}
+	return links, points
+}
+
+// resolveLink 将页面中的链接解析为绝对地址，只保留 http/https 链接并去掉锚点
+func resolveLink(page *url.URL, link string) (*url.URL, bool) {
+	link = strings.TrimSpace(html.UnescapeString(link))
+	if strings.HasPrefix(link, "#") {
+		return nil, false
+	}
+	u, err := page.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+	u.Fragment = ""
+	return u, true
+}
+
+// parseAttrs 解析标签属性（属性名小写，值已反转义）
+func parseAttrs(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := attrs[name]; !ok {
+			attrs[name] = html.UnescapeString(m[2] + m[3] + m[4])
+		}
+	}
+	return attrs
+}
+
+// pageKey 页面去重的键：同一路径只爬取一次，不区分查询参数的值
+func pageKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path + "?" + paramNames(u.Query())
+}
+
+// pointKey 注入点去重的键：请求方式、地址路径和参数名相同时视为同一注入点
+func pointKey(method, rawURL, body string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method + " " + rawURL
+	}
+	form, _ := url.ParseQuery(body)
+	return method + " " + pageKey(u) + " " + paramNames(form)
+}
+
+// paramNames 排序后的参数名，逗号分隔
+func paramNames(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+)
+
+func TestParsePage(t *testing.T) {
+	body := `<a href="/fetch?url=http://a.example/&amp;x=1#top">a</a>
+<a href='https://other.example/p?src=1'>b</a>
+<A HREF=/about>c</A> <a href="javascript:void(0)">d</a> <a href="#x">e</a>
+<form action="/search"><input type="text" name="q" value="x"><input type="submit" name="go"></form>
+<form method="POST" action="avatar"><input name="image_url" value="http://img.example/a.png"><textarea name="note"></textarea></form>`
+	links, points := parsePage("http://t.example/app/index.html", body)
+
+	wantLinks := []string{"http://t.example/fetch?url=http://a.example/&x=1", "https://other.example/p?src=1", "http://t.example/about"}
+	if len(links) != len(wantLinks) {
+		t.Fatalf("解析到链接 %v，期望 %v", links, wantLinks)
+	}
+	for i := range links {
+		if links[i] != wantLinks[i] {
+			t.Errorf("第 %d 个链接为 %s，期望 %s", i, links[i], wantLinks[i])
+		}
+	}
+
+	wantPoints := []crawlPoint{
+		{Method: "GET", URL: "http://t.example/fetch?url=http://a.example/&x=1"},
+		{Method: "GET", URL: "https://other.example/p?src=1"},
+		{Method: "GET", URL: "http://t.example/search?q=x"},
+		{Method: "POST", URL: "http://t.example/app/avatar", Body: "image_url=http%3A%2F%2Fimg.example%2Fa.png&note="},
+	}
+	if len(points) != len(wantPoints) {
+		t.Fatalf("解析到注入点 %v，期望 %v", points, wantPoints)
+	}
+	for i := range points {
+		if points[i] != wantPoints[i] {
+			t.Errorf("第 %d 个注入点为 %+v，期望 %+v", i, points[i], wantPoints[i])
+		}
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="/docs">docs</a> <a href="/static/app.js">js</a> <a href="/view?id=1">view</a> <a href="http://other.example/?url=x">other</a>`))
+	})
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="/proxy?url=http://a.example/">p</a> <a href="/deep">deep</a>
+<form method="post" action="/webhook"><input name="callback" value="http://hook.example/"></form>`))
+	})
+	mux.HandleFunc("/deep", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="/deeper?dest=x">x</a>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		depth int
+		param string
+		want  []string // 新增的目标
+	}{
+		{name: "只解析目标页面", depth: 0, want: nil},
+		{name: "爬取一层链接", depth: 1, want: []string{server.URL + "/proxy?url=http://a.example/", server.URL + "/webhook"}},
+		{name: "爬取两层链接", depth: 2, want: []string{server.URL + "/deeper?dest=x", server.URL + "/proxy?url=http://a.example/", server.URL + "/webhook"}},
+		{name: "指定参数时只保留包含该参数的注入点", depth: 2, param: "id", want: []string{server.URL + "/view?id=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Method: http.MethodGet, Timeout: 5, Threads: 2, CrawlDepth: tt.depth, ParamName: tt.param, Targets: []string{server.URL + "/"}}
+			var out bytes.Buffer
+			sm := NewScanManager(cfg, detector.NewDetector(cfg), logging.NewConsole(&out, &out), nil, nil, nil)
+			if added := sm.Crawl(context.Background()); added != len(tt.want) {
+				t.Fatalf("新增 %d 个目标 %v，期望 %v", added, cfg.Targets[1:], tt.want)
+			}
+			for i, want := range tt.want {
+				if cfg.Targets[i+1] != want {
+					t.Errorf("第 %d 个新增目标为 %s，期望 %s", i, cfg.Targets[i+1], want)
+				}
+			}
+			if tt.param == "" && tt.depth > 0 {
+				if method, _, body := cfg.TargetRequest(server.URL + "/webhook"); method != "POST" || body != "callback=http%3A%2F%2Fhook.example%2F" {
+					t.Errorf("POST表单应使用表单的请求方式和请求体，得到 %s %s", method, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/payloads"
+	"log/slog"
+)
+
+// scanElasticsearch 端口扫描发现 9200 端口开放后，通过SSRF读取 Elasticsearch 的集群状态和索引列表
+// 索引名称和文档数量作为数据泄露的证据，比仅判断端口开放更能说明漏洞的影响
+func (sm *ScanManager) scanElasticsearch(ctx context.Context, target string, params map[string]string) {
+	for _, host := range sm.openHosts(target, payloads.ElasticsearchPort) {
+		slog.Info(fmt.Sprintf("[%s] %s:%d 端口开放，尝试读取 Elasticsearch 集群状态和索引列表", target, host, payloads.ElasticsearchPort), "target", target)
+		for param := range params {
+			for _, payload := range payloads.GetElasticsearchPayloads(host) {
+				if sm.excluded(payload) {
+					continue
+				}
+				if !sm.testPayload(ctx, target, param, payload) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// unsafeFileChars 证据文件名中替换为下划线的字符
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// evidenceFileName 生成证据文件名：时间_序号_目标主机_参数.txt，多次扫描写入同一目录时不会覆盖
+func evidenceFileName(now time.Time, seq int64, target, param string) string {
+	host := target
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	name := fmt.Sprintf("%s_%03d_%s_%s", now.Format("20060102-150405"), seq, host, param)
+	return strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_") + ".txt"
+}
+
+// saveEvidence 将漏洞的完整请求和响应写入 -evidence-dir 目录，返回文件路径
+func (sm *ScanManager) saveEvidence(result ScanResult, exchange string) (string, error) {
+	if err := os.MkdirAll(sm.config.EvidenceDir, 0755); err != nil {
+		return "", fmt.Errorf("创建证据目录失败: %v", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(sm.config.EvidenceDir, evidenceFileName(now, atomic.AddInt64(&sm.evidenceSeq, 1), result.Target, result.Parameter))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "目标: %s\n", result.Target)
+	fmt.Fprintf(&b, "参数: %s\n", result.Parameter)
+	fmt.Fprintf(&b, "Payload: %s\n", result.Payload)
+	fmt.Fprintf(&b, "类型: %s\n", result.PayloadType)
+	fmt.Fprintf(&b, "严重程度: %s\n", result.Severity)
+	fmt.Fprintf(&b, "置信度: %s\n", result.Confidence)
+	fmt.Fprintf(&b, "证据: %s\n", result.Evidence)
+	if result.Bypass != "" {
+		fmt.Fprintf(&b, "绕过WAF: %s\n", result.Bypass)
+	}
+	fmt.Fprintf(&b, "时间: %s\n\n", now.Format("2006-01-02 15:04:05"))
+	b.WriteString(exchange)
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("保存证据文件失败: %v", err)
+	}
+	return path, nil
+}
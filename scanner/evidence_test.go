@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+)
+
+func TestEvidenceFileName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		target string
+		param  string
+		want   string
+	}{
+		{"http://example.com:8080/api?url=x", "url", "20240501-123000_001_example.com_8080_url.txt"},
+		{"https://[::1]/", "data.avatar.url", "20240501-123000_001_1_data.avatar.url.txt"},
+		{"http://example.com/", "image@src", "20240501-123000_001_example.com_image_src.txt"},
+	}
+	for _, tt := range tests {
+		if got := evidenceFileName(now, 1, tt.target, tt.param); got != tt.want {
+			t.Errorf("evidenceFileName(%q, %q) = %q, want %q", tt.target, tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestSaveEvidence(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewScanManager(&config.Config{EvidenceDir: dir}, nil, nil, nil, nil, nil)
+	result := ScanResult{Target: "http://example.com/", Parameter: "url", Payload: "file:///etc/passwd", Evidence: "响应中包含特征关键字: root:"}
+
+	first, err := sm.saveEvidence(result, "===== 请求 =====\nGET / HTTP/1.1\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sm.saveEvidence(result, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("同一漏洞的证据文件不应覆盖: %s", first)
+	}
+
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Payload: file:///etc/passwd", "证据: 响应中包含特征关键字: root:", "GET / HTTP/1.1"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("证据文件缺少 %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package scanner
+
+import "context"
+
+// Send 将payload注入目标的参数后发送一次请求，返回状态码和响应体，用于利用模块（-exploit参数）
+// 不经过 -exclude-payload/-payload-allow 等过滤，也不判定漏洞
+func (sm *ScanManager) Send(ctx context.Context, target, param, value string) (int, string, error) {
+	method, testURL, body, err := sm.buildRequest(target, param, value)
+	if err != nil {
+		return 0, "", err
+	}
+	return sm.detector.Fetch(ctx, method, testURL, body)
+}
@@ -0,0 +1,15 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint 漏洞指纹：目标、参数、payload类型和证据类别的哈希（16位十六进制），同一漏洞在多次扫描间保持不变
+// 不包含payload本身（OOB标识、模板变量展开后的地址每次扫描可能不同），同一参数被同类payload以同类证据命中的多个结果指纹相同，
+// 便于下游按指纹屏蔽已确认的漏洞、对比扫描结果和合并工单
+func Fingerprint(r ScanResult) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{r.Target, r.Parameter, r.PayloadType, r.Kind}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
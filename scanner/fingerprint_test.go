@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"testing"
+
+	"gosssrf-client/detector"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := ScanResult{
+		Target:      "http://example.com/api?url=x",
+		Parameter:   "url",
+		Payload:     "http://127.0.0.1:6379/",
+		PayloadType: "端口扫描",
+		Kind:        detector.EvidenceService,
+		Evidence:    "检测到内网服务特征",
+		Severity:    detector.SeverityHigh,
+	}
+	fp := Fingerprint(base)
+	if len(fp) != 16 {
+		t.Fatalf("Fingerprint = %q, want 16位十六进制", fp)
+	}
+
+	same := base
+	same.Payload, same.Evidence, same.Severity, same.StatusCode = "http://127.0.0.1:3306/", "检测到内网服务特征（mysql）", detector.SeverityLow, 500
+	if Fingerprint(same) != fp {
+		t.Error("payload、证据文本和严重程度不同时指纹应保持不变")
+	}
+
+	for name, change := range map[string]func(*ScanResult){
+		"目标":        func(r *ScanResult) { r.Target = "http://example.com/other?url=x" },
+		"参数":        func(r *ScanResult) { r.Parameter = "image" },
+		"payload类型": func(r *ScanResult) { r.PayloadType = "内网探测" },
+		"证据类别":      func(r *ScanResult) { r.Kind = detector.EvidenceHTTPService },
+	} {
+		other := base
+		change(&other)
+		if Fingerprint(other) == fp {
+			t.Errorf("%s不同时指纹应不同", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// semaphore 并发请求配额，依次占用自适应并发、主机配额和全局配额，等待慢主机时不会占住其他主机可用的并发
+type semaphore struct {
+	adaptive *adaptiveLimit // 目标主机的自适应并发上限（-adaptive），未指定时为nil
+	host     chan struct{}  // 目标主机的配额（-threads-per-host），未指定时为nil
+	global   chan struct{}
+}
+
+// acquire 占用一个并发配额，配额用完时阻塞
+func (s *semaphore) acquire() {
+	if s.adaptive != nil {
+		s.adaptive.acquire()
+	}
+	if s.host != nil {
+		s.host <- struct{}{}
+	}
+	s.global <- struct{}{}
+}
+
+// release 释放 acquire 占用的配额
+func (s *semaphore) release() {
+	<-s.global
+	if s.host != nil {
+		<-s.host
+	}
+	if s.adaptive != nil {
+		s.adaptive.release()
+	}
+}
+
+// hostLimits 所有目标共用的并发配额（-threads-per-host）
+type hostLimits struct {
+	global  chan struct{}            // 所有目标共用 -t 个并发
+	perHost int                      // 每个主机的并发上限
+	hosts   map[string]chan struct{} // 每个主机的配额
+	mu      sync.Mutex
+}
+
+// newHostLimits 创建按主机限制的并发配额，未指定 -threads-per-host 时返回nil
+func newHostLimits(threads, perHost int) *hostLimits {
+	if perHost <= 0 {
+		return nil
+	}
+	return &hostLimits{global: make(chan struct{}, threads), perHost: perHost, hosts: make(map[string]chan struct{})}
+}
+
+// semaphore 返回扫描目标使用的并发配额：未指定 -threads-per-host 时每次调用各自使用 -t 个并发，
+// 指定时同一主机（域名和端口）的目标共用主机配额，所有目标共用 -t 个并发
+func (sm *ScanManager) semaphore(target string) *semaphore {
+	adaptive := sm.adaptive.host(target)
+	if sm.limits == nil {
+		return &semaphore{adaptive: adaptive, global: make(chan struct{}, sm.config.Threads)}
+	}
+	host := targetHost(target)
+
+	sm.limits.mu.Lock()
+	defer sm.limits.mu.Unlock()
+	slots, ok := sm.limits.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, sm.limits.perHost)
+		sm.limits.hosts[host] = slots
+	}
+	return &semaphore{adaptive: adaptive, host: slots, global: sm.limits.global}
+}
+
+// targetHost 返回目标的主机（小写的域名和端口），无法解析时返回目标本身
+func targetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return strings.ToLower(u.Host)
+	}
+	return target
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+)
+
+func TestSemaphoreSharesHost(t *testing.T) {
+	cfg := &config.Config{Threads: 4, ThreadsPerHost: 2}
+	sm := &ScanManager{config: cfg, limits: newHostLimits(cfg.Threads, cfg.ThreadsPerHost)}
+
+	a := sm.semaphore("http://Example.com:8080/a?url=1")
+	b := sm.semaphore("http://example.com:8080/b?u=2")
+	c := sm.semaphore("http://example.com:9090/a?url=1")
+	if a.host != b.host {
+		t.Error("同一主机的不同路径应该共用主机配额")
+	}
+	if a.host == c.host {
+		t.Error("不同端口应该使用各自的主机配额")
+	}
+	if a.global != c.global {
+		t.Error("所有主机应该共用全局配额")
+	}
+
+	sm.limits = nil
+	if s := sm.semaphore("http://example.com/"); s.host != nil || cap(s.global) != cfg.Threads {
+		t.Errorf("未指定 -threads-per-host 时应该只使用 %d 个全局配额", cfg.Threads)
+	}
+}
+
+func TestSemaphoreLimits(t *testing.T) {
+	cfg := &config.Config{Threads: 3, ThreadsPerHost: 2}
+	sm := &ScanManager{config: cfg, limits: newHostLimits(cfg.Threads, cfg.ThreadsPerHost)}
+
+	var mu sync.Mutex
+	active := make(map[string]int)
+	peak := make(map[string]int)
+	var total, peakTotal int
+	var wg sync.WaitGroup
+	for _, host := range []string{"a.example", "b.example"} {
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				s := sm.semaphore("http://" + host + "/")
+				s.acquire()
+				defer s.release()
+
+				mu.Lock()
+				active[host]++
+				total++
+				peak[host] = max(peak[host], active[host])
+				peakTotal = max(peakTotal, total)
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				active[host]--
+				total--
+				mu.Unlock()
+			}(host)
+		}
+	}
+	wg.Wait()
+
+	for host, n := range peak {
+		if n > cfg.ThreadsPerHost {
+			t.Errorf("%s 的并发数 = %d, 超过每个主机的上限 %d", host, n, cfg.ThreadsPerHost)
+		}
+	}
+	if peakTotal > cfg.Threads {
+		t.Errorf("总并发数 = %d, 超过全局上限 %d", peakTotal, cfg.Threads)
+	}
+}
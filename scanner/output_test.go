@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"bytes"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/logging"
+)
+
+func TestPrintFinding(t *testing.T) {
+	finding := ScanResult{
+		Target:      "http://example.com/api",
+		Parameter:   "url",
+		Payload:     "file:///etc/passwd",
+		PayloadType: "文件读取",
+		Severity:    "critical",
+		Confidence:  "confirmed",
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"静默模式只输出一行", config.Config{Silent: true},
+			"[critical/confirmed] [文件读取] http://example.com/api url=file:///etc/passwd\n"},
+		{"默认输出完整信息", config.Config{}, "漏洞详情\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cfg := tt.cfg
+			sm := &ScanManager{config: &cfg, console: logging.NewConsole(&stdout, &stderr)}
+			sm.printFinding(finding, "漏洞详情\n")
+
+			if stdout.String() != tt.want {
+				t.Errorf("输出 = %q, want %q", stdout.String(), tt.want)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("漏洞不应写入标准错误: %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestFormatDetail(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ScanResult
+		want   string
+	}{
+		{"响应片段逐行缩进", ScanResult{
+			Method: "POST", URL: "http://example.com/api", RequestBody: "url=http://127.0.0.1", Payload: "http://127.0.0.1",
+			StatusCode: 200, ResponseLen: 12, ResponseTime: 35, Response: "line1\r\nline2\n",
+		}, "[POST] 已测试 http://127.0.0.1\n    请求: POST http://example.com/api\n    请求体: url=http://127.0.0.1\n" +
+			"    响应: 状态码 200，长度 12，耗时 35ms\n    响应片段:\n      line1\n      line2\n"},
+		{"请求错误不输出响应", ScanResult{
+			Method: "GET", URL: "http://example.com/?url=x", Payload: "x", Error: "请求超时",
+		}, "[GET] 已测试 x\n    请求: GET http://example.com/?url=x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDetail(tt.result); got != tt.want {
+				t.Errorf("formatDetail = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
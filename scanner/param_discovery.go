@@ -0,0 +1,152 @@
+package scanner
+
+import (
+	"gosssrf-client/config"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minParamScore 参数被自动选为测试点的最低分数
+const minParamScore = 2
+
+// ssrfParamNames 常见的SSRF参数名（完全匹配）
+var ssrfParamNames = []string{
+	"url", "uri", "link", "dest", "destination", "redirect", "redirect_uri", "redirect_url",
+	"callback", "callback_url", "image", "image_url", "img", "img_url", "proxy", "target",
+	"src", "source", "feed", "host", "site", "domain", "fetch", "load", "webhook", "endpoint",
+	"return_url", "href", "preview", "avatar",
+}
+
+// weakParamNames 导航/文件类参数名（完全匹配），本身不足以选中，需要参数值也像URL或路径
+var weakParamNames = []string{
+	"next", "return", "continue", "file", "path",
+}
+
+// ssrfParamKeywords 参数名中包含即加分的关键字
+var ssrfParamKeywords = []string{
+	"url", "uri", "link", "dest", "redirect", "callback", "image", "img", "proxy",
+	"src", "feed", "host", "site", "domain", "fetch", "hook", "endpoint", "avatar", "remote",
+}
+
+// domainPattern 形如域名的参数值（顶级域必须包含字母，排除 9.99、1.2.3 等数字）
+var domainPattern = regexp.MustCompile(`^(www\.)?([a-zA-Z0-9-]+\.)+[a-zA-Z0-9-]*[a-zA-Z][a-zA-Z0-9-]*(:\d+)?(/.*)?$`)
+
+// paramCandidate 自动发现的候选参数
+type paramCandidate struct {
+	Name  string
+	Value string
+	Score int
+}
+
+// discoverParams 解析目标URL查询参数和请求体模板中的参数，按SSRF可能性打分排序
+// JSON请求体中的参数以点路径表示（例如 data.avatar.url），XML请求体中的属性以 元素@属性 表示
+func discoverParams(targetURL, bodyType, bodyTemplate string) []paramCandidate {
+	found := make(map[string]string)
+	var order []string
+
+	collect := func(values url.Values) {
+		for name, vals := range values {
+			if _, ok := found[name]; ok || name == "" {
+				continue
+			}
+			value := ""
+			if len(vals) > 0 {
+				value = vals[0]
+			}
+			found[name] = value
+			order = append(order, name)
+		}
+	}
+
+	if parsedURL, err := url.Parse(targetURL); err == nil {
+		collect(parsedURL.Query())
+	}
+	if bodyTemplate != "" && bodyType == config.BodyTypeJSON {
+		values := url.Values{}
+		for path, value := range flattenJSON(bodyTemplate) {
+			values.Set(path, value)
+		}
+		collect(values)
+	} else if bodyTemplate != "" && bodyType == config.BodyTypeGraphQL {
+		values := url.Values{}
+		for path, value := range flattenGraphQL(bodyTemplate) {
+			values.Set(path, value)
+		}
+		collect(values)
+	} else if bodyTemplate != "" && bodyType == config.BodyTypeXML {
+		values := url.Values{}
+		for path, value := range flattenXML(bodyTemplate) {
+			values.Set(path, value)
+		}
+		collect(values)
+	} else if bodyTemplate != "" {
+		if values, err := url.ParseQuery(bodyTemplate); err == nil {
+			collect(values)
+		}
+	}
+
+	var candidates []paramCandidate
+	for _, name := range order {
+		candidates = append(candidates, paramCandidate{
+			Name:  name,
+			Value: found[name],
+			Score: scoreParam(name, found[name]),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// scoreParam 根据参数名和参数值形态打分
+func scoreParam(name, value string) int {
+	score := 0
+	lowerName := strings.ToLower(name)
+
+	// 参数名启发式（点路径、XML属性只取最后一段）
+	if idx := strings.LastIndexAny(lowerName, ".@"); idx != -1 {
+		lowerName = lowerName[idx+1:]
+	}
+	matched := false
+	for _, n := range ssrfParamNames {
+		if lowerName == n {
+			score += 3
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		for _, n := range weakParamNames {
+			if lowerName == n {
+				score++
+				matched = true
+				break
+			}
+		}
+	}
+	if !matched {
+		for _, kw := range ssrfParamKeywords {
+			if strings.Contains(lowerName, kw) {
+				score += 2
+				break
+			}
+		}
+	}
+
+	// 参数值形态启发式
+	lowerValue := strings.ToLower(value)
+	switch {
+	case strings.Contains(lowerValue, "://"):
+		score += 3
+	case strings.HasPrefix(lowerValue, "//"), domainPattern.MatchString(lowerValue):
+		score += 2
+	case strings.HasPrefix(lowerValue, "/"):
+		score++
+	}
+
+	return score
+}
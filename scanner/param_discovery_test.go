@@ -0,0 +1,42 @@
+package scanner
+
+import "testing"
+
+func TestScoreParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		param string
+		value string
+		want  int
+	}{
+		{"常见参数名加URL值", "url", "http://a.com/", 6},
+		{"关键字参数名", "avatarUrl", "", 2},
+		{"点路径取最后一段", "data.image", "", 3},
+		{"XML属性取属性名", "img@src", "", 3},
+		{"域名值", "q", "example.com", 2},
+		{"数字值不视为域名", "price", "9.99", 0},
+		{"版本号不视为域名", "v", "1.2.3", 0},
+		{"导航参数名需要值配合", "next", "2", 1},
+		{"导航参数名加路径值", "next", "/home", 2},
+		{"协议相对地址", "x", "//a.com", 2},
+		{"无关参数", "id", "1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreParam(tt.param, tt.value); got != tt.want {
+				t.Errorf("scoreParam(%q, %q) = %d，期望 %d", tt.param, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverParamsOrder(t *testing.T) {
+	candidates := discoverParams("http://x/?page=2&id=1&callback=http://a/", "", "next=/home")
+	if len(candidates) != 4 {
+		t.Fatalf("得到 %d 个候选参数，期望 4", len(candidates))
+	}
+	if candidates[0].Name != "callback" || candidates[1].Name != "next" {
+		t.Errorf("排序错误: %+v", candidates)
+	}
+}
@@ -0,0 +1,217 @@
+package scanner
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+)
+
+// portRank 合并多个参数的结果时端口状态的优先级：任一参数判断为开放即为开放
+var portRank = map[string]int{detector.PortClosed: 1, detector.PortFiltered: 2, detector.PortOpen: 3}
+
+// portSummary 端口扫描（包括内网主机名探测）推断的端口状态：目标 -> IP或主机名 -> 端口 -> 状态
+// web 为访问到内网Web服务的地址：目标 -> IP -> 端口 -> 地址（例如 http://10.0.0.5:8080）
+type portSummary struct {
+	targets map[string]map[string]map[int]string
+	web     map[string]map[string]map[int]string
+	mu      sync.Mutex
+}
+
+// recordPort 记录端口扫描payload推断的端口状态，目标请求超时视为端口被过滤（目标一直在等待连接）
+func (sm *ScanManager) recordPort(target string, payload payloads.Payload, result detector.Result) {
+	if payload.Type != "端口扫描" && payload.Type != payloads.TypeInternalHost {
+		return
+	}
+	state, reason := result.PortState, result.PortReason
+	if state == "" && result.Error == "请求超时" {
+		state, reason = detector.PortFiltered, "目标请求超时"
+	}
+	if state == "" {
+		return
+	}
+	// 编码变种等无法解析出IP和端口的payload不记录，内网主机名未指定端口时使用协议的默认端口
+	u, err := url.Parse(payload.Value)
+	if err != nil || u.Hostname() == "" {
+		return
+	}
+	portText := u.Port()
+	if portText == "" && payload.Type == payloads.TypeInternalHost {
+		portText = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return
+	}
+
+	slog.Debug(fmt.Sprintf("[%s] %s 端口状态 %s (%s)", target, u.Host, state, reason), "target", target)
+
+	s := &sm.ports
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.targets == nil {
+		s.targets = make(map[string]map[string]map[int]string)
+	}
+	hosts := s.targets[target]
+	if hosts == nil {
+		hosts = make(map[string]map[int]string)
+		s.targets[target] = hosts
+	}
+	ports := hosts[u.Hostname()]
+	if ports == nil {
+		ports = make(map[int]string)
+		hosts[u.Hostname()] = ports
+	}
+	if portRank[state] > portRank[ports[port]] {
+		ports[port] = state
+	}
+
+	if result.WebService {
+		if s.web == nil {
+			s.web = make(map[string]map[string]map[int]string)
+		}
+		if s.web[target] == nil {
+			s.web[target] = make(map[string]map[int]string)
+		}
+		if s.web[target][u.Hostname()] == nil {
+			s.web[target][u.Hostname()] = make(map[int]string)
+		}
+		s.web[target][u.Hostname()][port] = u.Scheme + "://" + u.Host
+	}
+}
+
+// printPortSummary 端口扫描完成后输出目标每个IP的开放、过滤和关闭端口，只有关闭端口的IP只计入汇总行
+func (sm *ScanManager) printPortSummary(target string) {
+	if sm.config.Verbosity() == config.LevelSilent {
+		return
+	}
+	sm.ports.mu.Lock()
+	hosts := sm.ports.targets[target]
+	sm.ports.mu.Unlock()
+	if table := formatPortSummary(target, hosts); table != "" {
+		sm.console.Result(config.ColorNone, table)
+	} else {
+		slog.Info(fmt.Sprintf("[%s] 端口扫描的响应与基线相同，无法推断端口状态", target), "target", target)
+	}
+}
+
+// openHosts 返回端口扫描推断 port 端口开放的IP，按地址排序
+func (sm *ScanManager) openHosts(target string, port int) []string {
+	sm.ports.mu.Lock()
+	defer sm.ports.mu.Unlock()
+	var ips []string
+	for ip, ports := range sm.ports.targets[target] {
+		if ports[port] == detector.PortOpen {
+			ips = append(ips, ip)
+		}
+	}
+	sortIPs(ips)
+	return ips
+}
+
+// webHosts 返回端口扫描访问到的内网Web服务地址，按IP和端口排序
+func (sm *ScanManager) webHosts(target string) []string {
+	sm.ports.mu.Lock()
+	defer sm.ports.mu.Unlock()
+	hosts := sm.ports.web[target]
+	ips := make([]string, 0, len(hosts))
+	for ip := range hosts {
+		ips = append(ips, ip)
+	}
+	sortIPs(ips)
+
+	var bases []string
+	for _, ip := range ips {
+		ports := make([]int, 0, len(hosts[ip]))
+		for port := range hosts[ip] {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		for _, port := range ports {
+			bases = append(bases, hosts[ip][port])
+		}
+	}
+	return bases
+}
+
+// formatPortSummary 将端口状态格式化为表格，没有推断出任何端口状态时返回空字符串
+func formatPortSummary(target string, hosts map[string]map[int]string) string {
+	if len(hosts) == 0 {
+		return ""
+	}
+	ips := make([]string, 0, len(hosts))
+	for ip := range hosts {
+		ips = append(ips, ip)
+	}
+	sortIPs(ips)
+
+	var rows strings.Builder
+	closedOnly, closedPorts := 0, 0
+	w := tabwriter.NewWriter(&rows, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IP\topen\tfiltered\tclosed")
+	for _, ip := range ips {
+		byState := make(map[string][]int)
+		for port, state := range hosts[ip] {
+			byState[state] = append(byState[state], port)
+		}
+		if len(byState[detector.PortOpen]) == 0 && len(byState[detector.PortFiltered]) == 0 {
+			closedOnly++
+			closedPorts += len(byState[detector.PortClosed])
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", ip, portList(byState[detector.PortOpen]), portList(byState[detector.PortFiltered]), len(byState[detector.PortClosed]))
+	}
+	w.Flush()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[%s] 端口扫描结果 (根据响应内容和错误信息推断):\n", target))
+	switch {
+	case closedOnly == len(ips):
+		b.WriteString(fmt.Sprintf("%d 个IP的 %d 个端口均为关闭\n", closedOnly, closedPorts))
+	case closedOnly > 0:
+		b.WriteString(rows.String())
+		b.WriteString(fmt.Sprintf("另有 %d 个IP的 %d 个端口均为关闭\n", closedOnly, closedPorts))
+	default:
+		b.WriteString(rows.String())
+	}
+	return b.String()
+}
+
+// portList 按升序输出端口列表，为空时输出 -
+func portList(ports []int) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	sort.Ints(ports)
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortIPs 按地址排序，IPv4在前，无法解析的主机名按字母顺序排在最后
+func sortIPs(ips []string) {
+	sort.Slice(ips, func(i, j int) bool {
+		a, b := net.ParseIP(ips[i]), net.ParseIP(ips[j])
+		switch {
+		case a == nil || b == nil:
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return ips[i] < ips[j]
+		case (a.To4() == nil) != (b.To4() == nil):
+			return a.To4() != nil
+		}
+		return string(a.To16()) < string(b.To16())
+	})
+}
@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+)
+
+func TestRecordPort(t *testing.T) {
+	sm := &ScanManager{config: &config.Config{}}
+	target := "http://example.com/?url=1"
+	record := func(value, state, errMsg string) {
+		sm.recordPort(target, payloads.Payload{Value: value, Type: "端口扫描"}, detector.Result{PortState: state, Error: errMsg})
+	}
+	record("http://10.0.0.1:22", detector.PortClosed, "")
+	record("http://10.0.0.1:22", detector.PortOpen, "") // 另一个参数判断为开放
+	record("http://10.0.0.1:22", detector.PortClosed, "")
+	record("http://10.0.0.1:3306", "", "请求超时")
+	record("http://[::1]:6379", detector.PortClosed, "")
+	record("http://10.0.0.1:80", "", "连接被拒绝") // 目标本身无法连接，不能推断
+	sm.recordPort(target, payloads.Payload{Value: "http://10.0.0.1:8080", Type: "协议探测"}, detector.Result{PortState: detector.PortOpen})
+
+	hosts := sm.ports.targets[target]
+	want := map[string]map[int]string{
+		"10.0.0.1": {22: detector.PortOpen, 3306: detector.PortFiltered},
+		"::1":      {6379: detector.PortClosed},
+	}
+	if len(hosts) != len(want) {
+		t.Fatalf("hosts = %v, want %v", hosts, want)
+	}
+	for ip, ports := range want {
+		for port, state := range ports {
+			if hosts[ip][port] != state {
+				t.Errorf("%s:%d = %q, want %q", ip, port, hosts[ip][port], state)
+			}
+		}
+		if len(hosts[ip]) != len(ports) {
+			t.Errorf("%s 的端口 = %v, want %v", ip, hosts[ip], ports)
+		}
+	}
+}
+
+func TestFormatPortSummary(t *testing.T) {
+	hosts := map[string]map[int]string{
+		"10.0.0.10": {22: detector.PortOpen, 80: detector.PortOpen, 3306: detector.PortFiltered, 6379: detector.PortClosed},
+		"10.0.0.2":  {8080: detector.PortOpen},
+		"10.0.0.3":  {22: detector.PortClosed, 80: detector.PortClosed},
+		"localhost": {6379: detector.PortFiltered},
+	}
+	got := formatPortSummary("http://example.com/", hosts)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	want := []string{
+		"[http://example.com/] 端口扫描结果 (根据响应内容和错误信息推断):",
+		"IP         open   filtered  closed",
+		"10.0.0.2   8080   -         0",
+		"10.0.0.10  22,80  3306      1",
+		"localhost  -      6379      0",
+		"另有 1 个IP的 2 个端口均为关闭",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("formatPortSummary =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	if got := formatPortSummary("http://example.com/", map[string]map[int]string{"10.0.0.3": {22: detector.PortClosed}}); !strings.Contains(got, "1 个IP的 1 个端口均为关闭") || strings.Contains(got, "IP ") {
+		t.Errorf("只有关闭端口时只输出汇总行, got\n%s", got)
+	}
+	if got := formatPortSummary("http://example.com/", nil); got != "" {
+		t.Errorf("没有推断出端口状态时应该返回空字符串, got %q", got)
+	}
+}
+
+func TestOpenHosts(t *testing.T) {
+	sm := &ScanManager{config: &config.Config{}}
+	target := "http://example.com/?url=1"
+	for _, r := range []struct {
+		value, state string
+	}{
+		{"http://10.0.0.12:9200", detector.PortOpen},
+		{"http://10.0.0.2:9200", detector.PortOpen},
+		{"http://10.0.0.3:9200", detector.PortClosed},
+		{"http://10.0.0.4:9201", detector.PortOpen},
+	} {
+		sm.recordPort(target, payloads.Payload{Value: r.value, Type: "端口扫描"}, detector.Result{PortState: r.state})
+	}
+
+	if got := strings.Join(sm.openHosts(target, 9200), ","); got != "10.0.0.2,10.0.0.12" {
+		t.Errorf("openHosts(9200) = %q, want %q", got, "10.0.0.2,10.0.0.12")
+	}
+	if got := sm.openHosts("http://other.example/", 9200); len(got) != 0 {
+		t.Errorf("未扫描的目标 openHosts = %v, want 空", got)
+	}
+}
+
+func TestWebHosts(t *testing.T) {
+	sm := &ScanManager{config: &config.Config{}}
+	target := "http://example.com/?url=1"
+	for _, r := range []struct {
+		value string
+		web   bool
+	}{
+		{"http://10.0.0.12:80", true},
+		{"http://10.0.0.2:8080", true},
+		{"http://10.0.0.2:80", true},
+		{"http://10.0.0.3:6379", false},
+		{"http://[::1]:8080", true},
+	} {
+		sm.recordPort(target, payloads.Payload{Value: r.value, Type: "端口扫描"}, detector.Result{PortState: detector.PortOpen, WebService: r.web})
+	}
+	// -hosts-wordlist 的内网主机名排在IP之后，未指定端口时使用协议的默认端口
+	sm.recordPort(target, payloads.Payload{Value: "http://jenkins.internal/", Type: payloads.TypeInternalHost}, detector.Result{PortState: detector.PortOpen, WebService: true})
+
+	want := "http://10.0.0.2:80,http://10.0.0.2:8080,http://10.0.0.12:80,http://[::1]:8080,http://jenkins.internal"
+	if got := strings.Join(sm.webHosts(target), ","); got != want {
+		t.Errorf("webHosts = %q, want %q", got, want)
+	}
+	if state := sm.ports.targets[target]["jenkins.internal"][80]; state != detector.PortOpen {
+		t.Errorf("jenkins.internal:80 = %q, want %q", state, detector.PortOpen)
+	}
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gosssrf-client/logging"
+)
+
+// 进度条刷新间隔和宽度
+const (
+	progressInterval = 500 * time.Millisecond
+	progressBarWidth = 30
+)
+
+// progress 扫描进度：已完成payload数量、百分比、速率和预计剩余时间
+// 进度条显示在标准错误的最后一行，只在标准错误为终端时显示
+type progress struct {
+	console *logging.Console // 与扫描输出共用，其它输出前会先清除进度条
+	total   int64
+	done    int64 // 已完成的payload数量（包括断点续扫跳过的）
+	skipped int64 // 断点续扫跳过的数量，不计入速率
+	start   time.Time
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newProgress 创建进度条
+func newProgress(console *logging.Console) *progress {
+	return &progress{console: console}
+}
+
+// isTerminal 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// begin 设置payload总数并开始定时刷新
+func (p *progress) begin(total int64) {
+	atomic.StoreInt64(&p.total, total)
+	p.start = time.Now()
+	p.stopCh = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.console.Status(p.render(time.Now()))
+			case <-p.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// finish 停止刷新并清除进度条
+func (p *progress) finish() {
+	if p.stopCh == nil {
+		return
+	}
+	close(p.stopCh)
+	p.wg.Wait()
+	p.stopCh = nil
+	p.console.ClearStatus()
+}
+
+// grow 增加payload总数（从标准输入逐个读取目标时）
+func (p *progress) grow(n int64) {
+	atomic.AddInt64(&p.total, n)
+}
+
+// add 记录一个已完成的payload
+func (p *progress) add() {
+	atomic.AddInt64(&p.done, 1)
+}
+
+// skip 记录一个断点续扫跳过的payload
+func (p *progress) skip() {
+	atomic.AddInt64(&p.done, 1)
+	atomic.AddInt64(&p.skipped, 1)
+}
+
+// render 生成进度条文本，例如 [=========>          ]  30.0% 300/1000 50/s 剩余 14s
+func (p *progress) render(now time.Time) string {
+	done := atomic.LoadInt64(&p.done)
+	total := atomic.LoadInt64(&p.total)
+	if total < done {
+		total = done
+	}
+
+	ratio := 1.0
+	if total > 0 {
+		ratio = float64(done) / float64(total)
+	}
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	line := fmt.Sprintf("[%s] %5.1f%% %d/%d", bar, ratio*100, done, total)
+	elapsed := now.Sub(p.start).Seconds()
+	sent := done - atomic.LoadInt64(&p.skipped)
+	if elapsed >= 1 && sent > 0 {
+		rate := float64(sent) / elapsed
+		remaining := time.Duration(float64(total-done) / rate * float64(time.Second))
+		line += fmt.Sprintf(" %.0f/s 剩余 %s", rate, remaining.Round(time.Second))
+	}
+	return line
+}
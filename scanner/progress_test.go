@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressRender(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		total   int64
+		done    int
+		skipped int
+		elapsed time.Duration
+		want    string
+	}{
+		{"刚开始", 100, 0, 0, 0, "[>                             ]   0.0% 0/100"},
+		{"速率和剩余时间", 1000, 300, 0, 6 * time.Second, "[=========>                    ]  30.0% 300/1000 50/s 剩余 14s"},
+		{"续扫跳过的不计入速率", 1000, 600, 300, 6 * time.Second, "[==================>           ]  60.0% 600/1000 50/s 剩余 8s"},
+		{"完成", 10, 10, 0, 2 * time.Second, "[==============================] 100.0% 10/10 5/s 剩余 0s"},
+		{"超过预计总数", 10, 12, 0, 0, "[==============================] 100.0% 12/12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProgress(nil)
+			p.total = tt.total
+			p.start = start
+			for i := 0; i < tt.skipped; i++ {
+				p.skip()
+			}
+			for i := tt.skipped; i < tt.done; i++ {
+				p.add()
+			}
+			if got := p.render(start.Add(tt.elapsed)); got != tt.want {
+				t.Errorf("render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,18 @@ package scanner
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"gosssrf-client/config"
 	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/oob"
 	"gosssrf-client/payloads"
+	"gosssrf-client/rebind"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -14,7 +22,10 @@ import (
 
 // ScanResult 扫描结果
 type ScanResult struct {
+	Target       string
+	Method       string // 请求方式
 	URL          string
+	RequestBody  string // 请求体（GET请求为空）
 	Parameter    string
 	Payload      string
 	PayloadType  string
@@ -23,302 +34,1081 @@ type ScanResult struct {
 	ResponseTime int64
 	Vulnerable   bool
 	Evidence     string
-	Severity     string
+	Kind         string                // 证据类别（detector.EvidenceKeyword 等）
+	Fingerprint  string                // 漏洞指纹（Fingerprint），记录漏洞时生成
+	Severity     string                // 严重程度：critical/high/medium/low/info
+	Confidence   string                // 置信度：confirmed/probable/tentative
+	Response     string                // 响应内容片段
+	Redirects    []detector.Redirect   // 重定向链
+	Bypass       string                // 检测到WAF拦截后命中的绕过技术，为空表示常规payload
+	Blocked      string                // 请求被WAF或过滤规则拦截的原因
+	Suppressed   string                // 因响应与通用错误页面几乎相同而忽略的证据
+	EvidenceFile string                // 保存完整请求和响应的证据文件（-evidence-dir参数）
+	Credentials  []detector.Credential // 从元数据响应中提取的临时凭据
+	Service      string                // 端口扫描识别出的内网服务和版本
+	Error        string                // 请求失败的原因
+	Status       string                // 复测结果（-verify参数）：still-vulnerable/fixed/unverified
 }
 
 // ScanManager 扫描管理器
 type ScanManager struct {
-	config       *config.Config
-	detector     *detector.Detector
-	outputMux    sync.Mutex
-	outputFile   *os.File
-	vulnCount    int
-	vulnCountMux sync.Mutex
+	config         *config.Config
+	detector       *detector.Detector
+	console        *logging.Console // 命令行输出，发现的漏洞写入标准输出
+	vulnCount      int
+	targetVulns    map[string]int // 每个目标发现的漏洞数量
+	vulnCountMux   sync.Mutex
+	oobServer      *oob.Server                   // 内置OOB回连服务，未启用时为nil
+	oobRegistry    *oob.Registry                 // 回连标识登记表，未启用OOB测试时为nil
+	oobConfirmed   map[string]bool               // 已确认回连的标识
+	secretsSeen    map[string]bool               // 已报告的 目标+敏感信息（受 vulnCountMux 保护）
+	state          *ScanState                    // 扫描进度，未启用断点续扫时为nil
+	dnsServer      *rebind.Server                // 内置DNS重绑定服务，未启用时为nil
+	results        []ScanResult                  // 本次扫描发现的漏洞（受 vulnCountMux 保护）
+	baselines      map[string]*detector.Baseline // 每个 目标+参数 的基线响应
+	baselineMux    sync.RWMutex
+	secondPages    map[string]*detector.Baseline // 每个 目标+参数 对应的二阶SSRF验证地址基线响应（受 baselineMux 保护）
+	secondMux      sync.Mutex                    // 保证二阶SSRF的注入请求和验证请求成对发送
+	blocks         map[string]*blockStats        // 每个 目标+参数 的WAF拦截统计
+	blockMux       sync.Mutex
+	scopeWarned    sync.Map // 已提示过超出 -payload-allow/-payload-deny 范围的主机
+	scopeOnce      sync.Once
+	stopCh         chan struct{} // 关闭后停止下发新的payload
+	stopOnce       sync.Once
+	onResult       []func(ScanResult) // 每个请求完成和每次发现漏洞时的处理函数
+	onResultMux    sync.RWMutex
+	progress       *progress          // 扫描进度条，标准错误不是终端时为nil
+	customPayloads []payloads.Payload // -w 指定的自定义字典
+	input          io.Reader          // 逐行读取扫描目标的输入（ReadTargets），为nil时只扫描 config.Targets
+	limits         *hostLimits        // 按主机限制的并发配额（-threads-per-host），未指定时为nil
+	adaptive       *adaptiveLimits    // 每个主机的自适应并发上限（-adaptive），未指定时为nil
+	evidenceSeq    int64              // 已保存的证据文件数量，用于生成文件名
+	ports          portSummary        // 端口扫描推断的每个目标的端口状态
 }
 
+// baselineURL 获取基线响应使用的无害地址（.invalid 顶级域保证无法解析）
+const baselineURL = "http://gossrf-baseline.invalid/"
+
+// closedPortProbe 本机关闭端口，其响应用于推断端口扫描的端口状态
+const closedPortProbe = "http://127.0.0.1:1/"
+
+// errorPageProbes 获取目标通用错误页面使用的payload：格式无效的地址和本机关闭端口
+var errorPageProbes = []string{"gossrf-invalid-url", closedPortProbe}
+
 // NewScanManager 创建扫描管理器
-func NewScanManager(cfg *config.Config, det *detector.Detector, outputFile *os.File) *ScanManager {
-	return &ScanManager{
-		config:     cfg,
-		detector:   det,
-		outputFile: outputFile,
-		vulnCount:  0,
+// console 为命令行输出（漏洞写入标准输出，日志通过 slog 输出），oobServer 为内置OOB回连服务，oobRegistry 为回连标识登记表，dnsServer 为内置DNS重绑定服务，未启用时传nil
+func NewScanManager(cfg *config.Config, det *detector.Detector, console *logging.Console, oobServer *oob.Server, oobRegistry *oob.Registry, dnsServer *rebind.Server) *ScanManager {
+	sm := &ScanManager{
+		config:       cfg,
+		detector:     det,
+		console:      console,
+		vulnCount:    0,
+		targetVulns:  make(map[string]int),
+		oobServer:    oobServer,
+		oobRegistry:  oobRegistry,
+		oobConfirmed: make(map[string]bool),
+		secretsSeen:  make(map[string]bool),
+		dnsServer:    dnsServer,
+		baselines:    make(map[string]*detector.Baseline),
+		secondPages:  make(map[string]*detector.Baseline),
+		limits:       newHostLimits(cfg.Threads, cfg.ThreadsPerHost),
+		adaptive:     newAdaptiveLimits(cfg.Adaptive, cfg.Threads, cfg.ThreadsPerHost),
+		blocks:       make(map[string]*blockStats),
+		stopCh:       make(chan struct{}),
+	}
+
+	if isTerminal(os.Stderr) && cfg.Verbosity() > config.LevelSilent {
+		sm.progress = newProgress(console)
 	}
+
+	if oobServer != nil {
+		oobServer.OnHit(sm.handleOOBHit)
+	}
+	if dnsServer != nil {
+		dnsServer.OnQuery(sm.handleRebindQuery)
+	}
+
+	return sm
 }
 
-// RunScan 执行扫描，返回发现的漏洞数量
-func (sm *ScanManager) RunScan() int {
-	// 获取要测试的参数
-	params := sm.config.GetParams()
+// LoadState 加载扫描进度状态文件，之后的扫描会跳过已发送的payload并持续保存进度
+func (sm *ScanManager) LoadState(path string) error {
+	state, err := loadScanState(path)
+	if err != nil {
+		return err
+	}
 
-	// 如果指定了字典文件，只使用字典文件扫描
+	// 恢复之前已发现的漏洞数量
+	sm.vulnCountMux.Lock()
+	for target, count := range state.Vulns {
+		sm.targetVulns[target] = count
+		sm.vulnCount += count
+	}
+	sm.vulnCountMux.Unlock()
+
+	if completed := state.Completed(); completed > 0 {
+		slog.Info(fmt.Sprintf("从状态文件恢复扫描，跳过 %d 个已发送的payload", completed))
+	}
+
+	sm.state = state
+	return nil
+}
+
+// OnResult 添加处理函数，每个测试请求完成和每次发现漏洞（包括OOB回连和时间盲注）时调用，可多次调用
+// 发现漏洞时 result.Vulnerable 为 true；处理函数可能被并发调用
+func (sm *ScanManager) OnResult(fn func(ScanResult)) {
+	sm.onResultMux.Lock()
+	defer sm.onResultMux.Unlock()
+	sm.onResult = append(sm.onResult, fn)
+}
+
+// notify 调用所有结果处理函数
+func (sm *ScanManager) notify(result ScanResult) {
+	sm.onResultMux.RLock()
+	handlers := sm.onResult
+	sm.onResultMux.RUnlock()
+
+	for _, fn := range handlers {
+		fn(result)
+	}
+}
+
+// Stop 停止扫描：不再下发新的payload，已发出的请求完成后 RunScan 返回（可重复调用）
+func (sm *ScanManager) Stop() {
+	sm.stopOnce.Do(func() { close(sm.stopCh) })
+}
+
+// Stopped 判断扫描是否已被停止
+func (sm *ScanManager) Stopped() bool {
+	select {
+	case <-sm.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopped 判断是否应停止下发新的payload（手动停止或 ctx 已取消）
+func (sm *ScanManager) stopped(ctx context.Context) bool {
+	return sm.Stopped() || ctx.Err() != nil
+}
+
+// RunScan 执行扫描，返回发现的漏洞数量
+// ctx 取消（例如超过最大扫描时间）时中止进行中的请求并尽快返回，已完成的进度仍会保存
+func (sm *ScanManager) RunScan(ctx context.Context) int {
+	// 如果指定了字典文件，先加载字典
 	if sm.config.PayloadFile != "" {
-		sm.scanWithCustomDict(params)
-		return sm.vulnCount
+		customPayloads, err := sm.loadCustomPayloads()
+		if err != nil {
+			slog.Error(fmt.Sprintf("加载字典文件失败: %v", err))
+			return 0
+		}
+		sm.customPayloads = customPayloads
+	}
+
+	// 统计payload总数，显示进度条
+	if sm.progress != nil {
+		sm.progress.begin(sm.countPayloads())
 	}
 
-	// 否则使用默认扫描
-	// 1. 端口扫描（总是启用）
-	sm.scanPorts(params)
+	// 指定 -threads-per-host 时同时扫描多个目标（最多 -t 个），请求并发由共用的配额限制
+	var wg sync.WaitGroup
+	parallel := make(chan struct{}, 1)
+	if sm.limits != nil {
+		parallel = make(chan struct{}, sm.config.Threads)
+	}
+	scanned := 0
+	for target := range sm.targets(ctx) {
+		if sm.stopped(ctx) {
+			break
+		}
+		// 从输入读取的目标追加到目标列表，并累加到进度条的总数中
+		if scanned++; scanned > len(sm.config.Targets) {
+			sm.config.Targets = append(sm.config.Targets, target)
+			if sm.progress != nil {
+				sm.progress.grow(int64(len(sm.targetParams(target, false))) * sm.paramPayloads(target))
+			}
+		}
 
-	// 2. 高危协议和文件读取测试（默认启用）
-	sm.scanHighRisk(params)
+		// 多目标时打印当前目标，便于区分输出
+		if len(sm.config.Targets) > 1 || sm.input != nil {
+			slog.Info(fmt.Sprintf("开始扫描目标: %s", target), "target", target)
+		}
 
-	// 3. 云元数据测试（默认启用）
-	sm.scanCloudMetadata(params)
+		sm.vulnCountMux.Lock()
+		if _, ok := sm.targetVulns[target]; !ok {
+			sm.targetVulns[target] = 0
+		}
+		sm.vulnCountMux.Unlock()
 
-	// 4. 如果指定了-all参数，扫描所有内置字典文件（绕过技术等）
-	if sm.config.ScanAll {
-		sm.scanAllDictPayloads(params)
+		wg.Add(1)
+		parallel <- struct{}{}
+		go func(target string) {
+			defer func() {
+				<-parallel
+				wg.Done()
+			}()
+			sm.scanTarget(ctx, target)
+		}(target)
 	}
+	wg.Wait()
 
-	// 5. OOB测试（指定-oob参数后启用）
-	if sm.config.ShouldScanOOB() {
-		sm.scanOOB(params)
+	if sm.progress != nil {
+		sm.progress.finish()
 	}
 
+	// 等待延迟到达的OOB回连（扫描被停止时不再等待）
+	if sm.oobServer != nil && sm.config.OOBWait > 0 && !sm.stopped(ctx) {
+		slog.Info(fmt.Sprintf("等待 %d 秒接收OOB回连...", sm.config.OOBWait))
+		select {
+		case <-time.After(time.Duration(sm.config.OOBWait) * time.Second):
+		case <-sm.stopCh:
+		case <-ctx.Done():
+		}
+	}
+
+	// 保存最终进度
+	if sm.state != nil {
+		if err := sm.state.Save(); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+
+	sm.vulnCountMux.Lock()
+	defer sm.vulnCountMux.Unlock()
 	return sm.vulnCount
 }
 
-// scanPorts 扫描端口
-func (sm *ScanManager) scanPorts(params map[string]string) {
-	var wg sync.WaitGroup
+// countPayloads 统计所有目标需要发送的payload总数（参数×payload，不包括多步检测和时间盲注的请求）
+func (sm *ScanManager) countPayloads() int64 {
+	if len(sm.config.Targets) == 0 {
+		return 0
+	}
 
-	// 如果指定了字典文件，则不使用默认payload
+	// 每个参数发送的payload数量与目标无关
+	perParam := sm.paramPayloads(sm.config.Targets[0])
+	var total int64
+	for _, target := range sm.config.Targets {
+		total += int64(len(sm.targetParams(target, false))) * perParam
+	}
+	return total
+}
+
+// paramPayloads 统计目标的每个参数需要发送的payload数量；排除规则、payload主机范围、安全模式和编码变种会改变数量，此时逐个统计
+func (sm *ScanManager) paramPayloads(target string) int64 {
+	filtered := sm.config.ExcludePattern != nil || len(sm.config.ExcludeTypes) > 0 || len(sm.config.EncoderList) > 0 || sm.config.HasPayloadScope() || sm.config.Safe
+	var perParam int64
+	for _, phase := range sm.scanPhases(target) {
+		if phase.each == nil {
+			continue
+		}
+		if phase.size != nil && !filtered {
+			perParam += int64(phase.size())
+			continue
+		}
+		sm.filterStream(phase.each)(func(payloads.Payload) bool {
+			perParam++
+			return true
+		})
+	}
+	return perParam
+}
+
+// EachPayload 按扫描顺序生成目标的每个参数会发送的payload（payloads子命令），phase 为所属的扫描阶段
+// 与扫描使用相同的扫描模块、排除规则、payload主机范围、安全模式和编码变种，多步检测和时间盲注的请求不包括在内
+func (sm *ScanManager) EachPayload(target string, fn func(phase string, payload payloads.Payload) bool) error {
 	if sm.config.PayloadFile != "" {
+		customPayloads, err := sm.loadCustomPayloads()
+		if err != nil {
+			return fmt.Errorf("加载字典文件失败: %v", err)
+		}
+		sm.customPayloads = customPayloads
+	}
+	for _, phase := range sm.scanPhases(target) {
+		if phase.each == nil {
+			continue
+		}
+		stop := false
+		sm.filterStream(phase.each)(func(payload payloads.Payload) bool {
+			payload.Value = strings.ReplaceAll(payload.Value, payloads.VarOOB, sm.oobBaseURL())
+			stop = !fn(phase.name, payload)
+			return !stop
+		})
+		if stop {
+			break
+		}
+	}
+	return nil
+}
+
+// TargetVulnCounts 返回每个目标发现的漏洞数量（按扫描顺序）
+func (sm *ScanManager) TargetVulnCounts() ([]string, map[string]int) {
+	sm.vulnCountMux.Lock()
+	defer sm.vulnCountMux.Unlock()
+
+	counts := make(map[string]int, len(sm.targetVulns))
+	for target, count := range sm.targetVulns {
+		counts[target] = count
+	}
+	return sm.config.Targets, counts
+}
+
+// scanTarget 对单个目标执行全部扫描流程
+func (sm *ScanManager) scanTarget(ctx context.Context, target string) {
+	params := sm.targetParams(target, true)
+	if len(params) == 0 {
 		return
 	}
 
-	// 获取端口扫描payload（传入内网IP列表、自定义端口列表）
-	portPayloads := payloads.GetPortScanPayloads(sm.config.InternalIPs, sm.config.PortList)
-	semaphore := make(chan struct{}, sm.config.Threads)
+	// -burp 导入的目标使用导入请求中的Header
+	ctx = detector.WithHeaders(ctx, sm.config.TargetHeaders(target))
 
-	for paramName := range params {
-		for _, payload := range portPayloads {
-			wg.Add(1)
-			semaphore <- struct{}{}
+	// 获取每个注入点的基线响应，之后的payload响应与其对比
+	if !sm.config.NoBaseline {
+		sm.fetchBaselines(ctx, target, params)
+	}
 
-			go func(param string, pl payloads.Payload) {
-				defer func() {
-					<-semaphore
-					wg.Done()
-				}()
+	// 依次执行启用的扫描阶段
+	for _, phase := range sm.scanPhases(target) {
+		if phase.run != nil {
+			phase.run(ctx, target, params)
+			continue
+		}
+		sm.runPayloadStream(ctx, target, phase.name, params, phase.each)
+	}
+}
 
-				sm.testPayload(param, pl)
-			}(paramName, payload)
+// targetParams 获取目标要测试的参数，report 为 false 时不输出提示（用于统计进度）
+// 指定 -p 时优先；否则URL或请求体中有注入标记时测试标记位置，没有标记时自动发现
+func (sm *ScanManager) targetParams(target string, report bool) map[string]string {
+	params := sm.config.GetParams()
+	_, _, bodyTemplate := sm.config.TargetRequest(target)
+	if count := countMarkers(target, bodyTemplate); len(params) == 0 && count > 0 {
+		params = markerParams(target, bodyTemplate)
+		if report {
+			slog.Info(fmt.Sprintf("[%s] 发现 %d 个注入标记，payload将注入到标记位置", target, count), "target", target)
+		}
+	} else if len(params) == 0 {
+		params = sm.autoSelectParams(target, report)
+		if len(params) == 0 {
+			return nil
 		}
 	}
 
-	wg.Wait()
+	// 发包前验证每个注入位置，避免所有payload因同一个错误静默失败
+	return sm.checkInjectionPoints(target, params, report)
 }
 
-// scanHighRisk 高危协议和文件读取测试
-func (sm *ScanManager) scanHighRisk(params map[string]string) {
-	var wg sync.WaitGroup
+// scanPhase 扫描阶段
+type scanPhase struct {
+	name string                                                             // 阶段名称，用于记录断点续扫进度
+	each func(fn func(payloads.Payload) bool)                               // 按需生成payload（每个参数相同），为nil时不计入进度
+	size func() int                                                         // 不为nil时直接返回 each 生成的payload数量（大网段无需逐个生成）
+	run  func(ctx context.Context, target string, params map[string]string) // 不为nil时代替并发发送 each 生成的payload
+}
 
-	// 获取高危payload
-	highRiskPayloads := payloads.GetHighRiskPayloads()
-	semaphore := make(chan struct{}, sm.config.Threads)
+// scanPhases 返回目标启用的扫描阶段（按执行顺序）
+func (sm *ScanManager) scanPhases(target string) []scanPhase {
+	// 如果指定了字典文件，只使用字典文件扫描
+	if sm.config.PayloadFile != "" {
+		return append([]scanPhase{sm.listPhase(target, "custom", sm.customPayloads)}, sm.wafPhase()...)
+	}
 
-	for paramName := range params {
-		for _, payload := range highRiskPayloads {
-			wg.Add(1)
-			semaphore <- struct{}{}
+	// 否则按 -tags 启用的模块扫描
+	var phases []scanPhase
+
+	// 1. 端口扫描：按需生成payload（传入内网IP列表、自定义端口列表），大网段不会一次性展开
+	// 指定 -hosts-wordlist 时先请求字典中的内网主机名，与端口扫描的结果一起汇总
+	// 全部端口发送完成后输出每个IP的端口状态汇总，9200 端口开放的IP继续读取 Elasticsearch 索引列表，
+	// 访问到的内网Web服务继续测试 Actuator 等敏感管理接口
+	scanPorts := sm.config.HasTag(config.TagPorts)
+	if len(sm.config.HostNames) > 0 {
+		phase := sm.listPhase(target, "hosts", payloads.GetInternalHostPayloads(sm.config.HostNames))
+		if !scanPorts {
+			each := phase.each
+			phase.run = func(ctx context.Context, target string, params map[string]string) {
+				sm.runPayloadStream(ctx, target, "hosts", params, each)
+				sm.printPortSummary(target)
+			}
+		}
+		phases = append(phases, phase)
+	}
+	if scanPorts {
+		each := func(fn func(payloads.Payload) bool) {
+			payloads.EachPortScanPayload(sm.config.InternalIPs, sm.config.PortList, fn)
+		}
+		phases = append(phases, scanPhase{
+			name: "ports",
+			each: each,
+			size: func() int {
+				return payloads.PortScanPayloadCount(sm.config.InternalIPs, sm.config.PortList)
+			},
+			run: func(ctx context.Context, target string, params map[string]string) {
+				sm.runPayloadStream(ctx, target, "ports", params, each)
+				sm.printPortSummary(target)
+			},
+		})
+	}
+	if scanPorts || len(sm.config.HostNames) > 0 {
+		phases = append(phases, scanPhase{name: "elasticsearch", run: sm.scanElasticsearch})
+		phases = append(phases, scanPhase{name: "admin", run: sm.scanAdminPaths})
+	}
 
-			go func(param string, pl payloads.Payload) {
-				defer func() {
-					<-semaphore
-					wg.Done()
-				}()
+	// 2. 高危协议和文件读取测试
+	if sm.config.HasTag(config.TagFiles) || sm.config.HasTag(config.TagProtocol) {
+		phases = append(phases, sm.listPhase(target, "high_risk", sm.highRiskPayloads()))
+	}
 
-				sm.testPayload(param, pl)
-			}(paramName, payload)
+	// 3. 云元数据测试，AWS IMDSv2 需要先申请令牌，单独按多步流程检测
+	if sm.config.HasTag(config.TagCloud) {
+		phases = append(phases, sm.listPhase(target, "cloud", payloads.GetCloudMetadataPayloads(sm.config.CloudList)))
+		if sm.cloudSelected(payloads.CloudAWS) {
+			phases = append(phases, scanPhase{name: "imdsv2", run: sm.scanIMDSv2})
+		}
+		// 指定 -exploit-metadata 时读取实例角色列表，再请求角色凭据
+		if sm.config.ExploitMetadata {
+			phases = append(phases, scanPhase{name: "credentials", run: sm.scanMetadataCredentials})
 		}
 	}
 
-	wg.Wait()
+	// 4. Kubernetes集群内部接口测试（kube-apiserver、kubelet、服务账号令牌）
+	if sm.config.HasTag(config.TagK8s) {
+		phases = append(phases, sm.listPhase(target, "k8s", payloads.GetKubernetesPayloads()))
+	}
+
+	// 5. Docker Engine API 未授权访问测试
+	if sm.config.HasTag(config.TagDocker) {
+		phases = append(phases, sm.listPhase(target, "docker", payloads.GetDockerPayloads()))
+	}
+
+	// 6. Consul、etcd、Vault 内部API测试（KV存储、集群成员和Vault解封状态）
+	if sm.config.HasTag(config.TagInfra) {
+		phases = append(phases, sm.listPhase(target, "infra", payloads.GetInfraPayloads()))
+	}
+
+	// 7. DNS重绑定测试（指定-rebind-domain参数后启用，绕过先解析校验、再发起请求的白名单）
+	if sm.config.RebindDomain != "" {
+		rebindPayloads := payloads.GetRebindPayloads(sm.config.RebindDomain, sm.config.RebindIP, sm.dnsServer != nil)
+		phases = append(phases, sm.listPhase(target, "rebind", rebindPayloads))
+	}
+
+	// 8. 时间盲注检测（指定-timing参数后启用）
+	if sm.config.Timing {
+		phases = append(phases, scanPhase{name: "timing", run: sm.scanTiming})
+	}
+
+	// 9. 开放重定向检测（指定-open-redirect参数后启用）
+	if sm.config.OpenRedirect {
+		phases = append(phases, sm.listPhase(target, "redirect", payloads.GetOpenRedirectPayloads()))
+	}
+
+	// 10. 扫描所有内置字典文件（绕过技术等，指定-all参数或 -tags bypass 后启用）
+	if sm.config.HasTag(config.TagBypass) {
+		phases = append(phases, sm.dictPhase(target))
+	}
+
+	// 11. 自定义插件提供的payload（-plugin参数）
+	for _, p := range sm.config.Plugins {
+		phases = append(phases, sm.listPhase(target, "plugin:"+p.Name(), p.ProvidePayloads(target)))
+	}
+
+	// 12. payload被WAF稳定拦截的参数自动尝试绕过字典和编码变种（-no-waf-bypass 关闭）
+	phases = append(phases, sm.wafPhase()...)
+
+	// 13. OOB测试（指定-oob参数后启用）
+	if sm.config.ShouldScanOOB() && sm.config.HasTag(config.TagOOB) {
+		phase := sm.listPhase(target, "oob", payloads.GetOOBPayloads(sm.oobBaseURL()))
+		phase.run = sm.scanOOB
+		phases = append(phases, phase)
+	}
+
+	return phases
 }
 
-// scanCloudMetadata 云服务元数据测试
-func (sm *ScanManager) scanCloudMetadata(params map[string]string) {
-	var wg sync.WaitGroup
+// wafPhase 返回WAF绕过升级阶段，指定 -no-waf-bypass 时不启用
+func (sm *ScanManager) wafPhase() []scanPhase {
+	if sm.config.NoWAFBypass {
+		return nil
+	}
+	return []scanPhase{{name: "waf", run: sm.scanWAFBypass}}
+}
 
-	// 获取云元数据payload
-	cloudPayloads := payloads.GetCloudMetadataPayloads()
-	semaphore := make(chan struct{}, sm.config.Threads)
+// autoSelectParams 自动发现目标中的候选SSRF参数
+// report 为 false 时不输出提示
+func (sm *ScanManager) autoSelectParams(target string, report bool) map[string]string {
+	_, bodyType, bodyTemplate := sm.config.TargetRequest(target)
+	candidates := discoverParams(target, bodyType, bodyTemplate)
+	if len(candidates) == 0 {
+		if !report {
+			return nil
+		}
+		slog.Warn(fmt.Sprintf("[%s] 未发现任何参数，请使用 -p 指定要测试的参数", target), "target", target)
+		return nil
+	}
 
-	for paramName := range params {
-		for _, payload := range cloudPayloads {
-			wg.Add(1)
-			semaphore <- struct{}{}
+	params := make(map[string]string)
+	var selected, skipped []string
+	for _, c := range candidates {
+		if c.Score >= minParamScore {
+			params[c.Name] = c.Value
+			selected = append(selected, fmt.Sprintf("%s(%d)", c.Name, c.Score))
+		} else {
+			skipped = append(skipped, c.Name)
+		}
+	}
 
-			go func(param string, pl payloads.Payload) {
-				defer func() {
-					<-semaphore
-					wg.Done()
-				}()
+	if len(params) == 0 {
+		if !report {
+			return nil
+		}
+		slog.Warn(fmt.Sprintf("[%s] 未发现疑似SSRF参数（已发现: %s），请使用 -p 指定", target, strings.Join(skipped, ", ")), "target", target)
+		return nil
+	}
 
-				sm.testPayload(param, pl)
-			}(paramName, payload)
+	if report {
+		slog.Info(fmt.Sprintf("[%s] 自动选择候选参数: %s", target, strings.Join(selected, ", ")), "target", target)
+	}
+	return params
+}
+
+// buildRequest 按目标的请求方式和请求体模板构造测试请求，返回请求方式、请求地址和请求体
+func (sm *ScanManager) buildRequest(target, param, value string) (string, string, string, error) {
+	method, bodyType, bodyTemplate := sm.config.TargetRequest(target)
+	testURL, body, err := buildTestRequest(method, target, bodyType, bodyTemplate, param, value)
+	return method, testURL, body, err
+}
+
+// checkInjectionPoints 使用示例payload构造一次请求，移除无法注入的参数，report 为 true 时输出原因
+func (sm *ScanManager) checkInjectionPoints(target string, params map[string]string, report bool) map[string]string {
+	valid := make(map[string]string)
+	for param, value := range params {
+		_, _, _, err := sm.buildRequest(target, param, "http://127.0.0.1/")
+		if err != nil {
+			if report {
+				slog.Warn(fmt.Sprintf("[%s] 参数 %s 无法注入: %v", target, param, err), "target", target, "param", param)
+			}
+			continue
 		}
+		valid[param] = value
 	}
+	return valid
+}
 
-	wg.Wait()
+// fetchBaselines 将参数设置为无害地址发送请求，记录基线响应
+func (sm *ScanManager) fetchBaselines(ctx context.Context, target string, params map[string]string) {
+	for param := range params {
+		method, testURL, body, err := sm.buildRequest(target, param, baselineURL)
+		if err != nil {
+			continue
+		}
+
+		baseline, err := sm.detector.FetchBaseline(ctx, method, testURL, body)
+		if err != nil {
+			slog.Info(fmt.Sprintf("[%s] 参数 %s 获取基线响应失败，使用默认规则判定: %v", target, param, err), "target", target, "param", param)
+			continue
+		}
+		secondBaseline := sm.fetchSecondBaseline(ctx)
+
+		// 无效地址和关闭端口通常返回目标的通用错误页面，与其几乎相同的响应不按启发式规则判定
+		for _, probe := range errorPageProbes {
+			_, probeURL, probeBody, err := sm.buildRequest(target, param, probe)
+			if err != nil {
+				continue
+			}
+			if page, err := sm.detector.FetchBaseline(ctx, method, probeURL, probeBody); err == nil && probe == closedPortProbe {
+				baseline.SetClosedPort(page)
+			} else if err == nil {
+				baseline.AddErrorPage(page)
+			}
+			if page := sm.fetchSecondBaseline(ctx); page != nil && secondBaseline != nil {
+				secondBaseline.AddErrorPage(page)
+			}
+		}
+		sm.calibrate(ctx, target, param, method, baseline)
+
+		sm.baselineMux.Lock()
+		sm.baselines[target+"\x00"+param] = baseline
+		if secondBaseline != nil {
+			sm.secondPages[target+"\x00"+param] = secondBaseline
+		}
+		sm.baselineMux.Unlock()
+		slog.Info(fmt.Sprintf("[%s] 参数 %s 基线响应: 状态码 %d，长度 %d", target, param, baseline.StatusCode, baseline.Length), "target", target, "param", param)
+	}
 }
 
-// scanOOB OOB测试
-func (sm *ScanManager) scanOOB(params map[string]string) {
-	var wg sync.WaitGroup
+// calibrate 将参数设置为可访问的外部地址（-calibrate-url），学习目标成功获取地址时的响应，并输出每种情况的响应特征
+func (sm *ScanManager) calibrate(ctx context.Context, target, param, method string, baseline *detector.Baseline) {
+	if sm.config.CalibrateURL == "" {
+		slog.Debug(fmt.Sprintf("[%s] 参数 %s 校准: %s", target, param, baseline.Calibration()), "target", target, "param", param)
+		return
+	}
+	_, probeURL, probeBody, err := sm.buildRequest(target, param, sm.config.CalibrateURL)
+	if err != nil {
+		return
+	}
+	page, err := sm.detector.FetchBaseline(ctx, method, probeURL, probeBody)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("[%s] 参数 %s 请求校准地址失败: %v", target, param, err), "target", target, "param", param)
+		return
+	}
+	baseline.SetReachable(page)
+	slog.Info(fmt.Sprintf("[%s] 参数 %s 校准: %s", target, param, baseline.Calibration()), "target", target, "param", param)
+	if baseline.Blind() {
+		slog.Warn(fmt.Sprintf("[%s] 参数 %s 访问校准地址与请求失败时的响应相同，目标可能不回显获取的内容，建议使用 -oob 或 -timing", target, param), "target", target, "param", param)
+	}
+}
 
-	// 获取OOB payload
-	oobPayloads := payloads.GetOOBPayloads(sm.config.OOBServer)
-	semaphore := make(chan struct{}, sm.config.Threads)
+// baseline 返回注入点的基线响应，未获取时返回nil
+func (sm *ScanManager) baseline(target, param string) *detector.Baseline {
+	sm.baselineMux.RLock()
+	defer sm.baselineMux.RUnlock()
+	return sm.baselines[target+"\x00"+param]
+}
+
+// runPayloads 并发测试一组payload
+// phase 为扫描阶段名称，用于记录断点续扫进度
+func (sm *ScanManager) runPayloads(ctx context.Context, target, phase string, params map[string]string, payloadList []payloads.Payload) {
+	sm.runPayloadStream(ctx, target, phase, params, sm.listPhase(target, phase, payloadList).each)
+}
+
+// listPhase 由payload列表生成扫描阶段：展开模板变量，未配置OOB地址时跳过需要回连地址的payload
+func (sm *ScanManager) listPhase(target, name string, payloadList []payloads.Payload) scanPhase {
+	vars := sm.templateVars(target)
+	oobEnabled := sm.oobBaseURL() != ""
+	return scanPhase{
+		name: name,
+		each: func(fn func(payloads.Payload) bool) {
+			payloads.ExpandTemplates(payloadList, vars, func(payload payloads.Payload) bool {
+				if !oobEnabled && strings.Contains(payload.Value, payloads.VarOOB) {
+					return true
+				}
+				return fn(payload)
+			})
+		},
+	}
+}
+
+// templateVars 返回展开目标payload模板变量使用的值
+func (sm *ScanManager) templateVars(target string) payloads.TemplateVars {
+	vars := payloads.TemplateVars{
+		IPs:   sm.config.InternalIPs,
+		Ports: sm.config.PortList,
+	}
+	if parsed, err := url.Parse(target); err == nil {
+		vars.TargetHost = payloads.URLHost(parsed.Hostname())
+	}
+	return vars
+}
+
+// oobBaseURL 返回OOB回连地址，内置OOB服务优先，未配置时返回空
+func (sm *ScanManager) oobBaseURL() string {
+	if sm.oobServer != nil {
+		return sm.oobServer.BaseURL()
+	}
+	return sm.config.OOBServer
+}
+
+// expandOOB 将payload中的 {{OOB}} 替换为回连地址
+// 启用回连标识时为每个请求分配唯一标识，回连时关联到具体的目标和参数
+func (sm *ScanManager) expandOOB(target, param, value string) string {
+	callbackURL := sm.oobBaseURL()
+	if sm.oobRegistry == nil {
+		return strings.ReplaceAll(value, payloads.VarOOB, callbackURL)
+	}
+
+	token := oob.NewToken()
+	value = strings.ReplaceAll(value, payloads.VarOOB, oob.EmbedToken(callbackURL, token, sm.config.OOBMode))
+	sm.oobRegistry.Register(oob.Callback{
+		Token:   token,
+		Target:  target,
+		Param:   param,
+		Payload: value,
+	})
+	return value
+}
+
+// runPayloadStream 并发测试按需生成的payload（each 按固定顺序依次回调每个payload）
+// 断点续扫时按payload在流中的序号跳过已连续完成的部分
+func (sm *ScanManager) runPayloadStream(ctx context.Context, target, phase string, params map[string]string, each func(fn func(payloads.Payload) bool)) {
+	sm.sendStream(ctx, target, phase, params, sm.filterStream(each))
+}
+
+// sendStream 并发发送 each 生成的payload，不再应用排除规则和编码变种
+func (sm *ScanManager) sendStream(ctx context.Context, target, phase string, params map[string]string, each func(fn func(payloads.Payload) bool)) {
+	var wg sync.WaitGroup
+	semaphore := sm.semaphore(target)
 
 	for paramName := range params {
-		for _, payload := range oobPayloads {
+		if sm.stopped(ctx) {
+			break
+		}
+		paramName := paramName
+		key := stateKey(target, paramName, phase)
+		resumeIndex := 0
+		if sm.state != nil {
+			resumeIndex = sm.state.ResumeIndex(key)
+		}
+
+		index := 0
+		each(func(payload payloads.Payload) bool {
+			current := index
+			index++
+
+			// 跳过状态文件中已发送的payload
+			if current < resumeIndex {
+				if sm.progress != nil {
+					sm.progress.skip()
+				}
+				return true
+			}
+
+			// 扫描被停止时不再下发新的payload
+			if sm.stopped(ctx) {
+				return false
+			}
+
 			wg.Add(1)
-			semaphore <- struct{}{}
+			semaphore.acquire()
 
 			go func(param string, pl payloads.Payload) {
 				defer func() {
-					<-semaphore
+					semaphore.release()
 					wg.Done()
 				}()
 
-				sm.testPayload(param, pl)
+				// 因扫描取消而中止的请求不记录进度，续扫时重新发送
+				if !sm.testPayload(ctx, target, param, pl) {
+					return
+				}
+				if sm.progress != nil {
+					sm.progress.add()
+				}
+				if sm.state != nil {
+					if err := sm.state.MarkDone(key, current); err != nil {
+						slog.Warn(err.Error())
+					}
+				}
 			}(paramName, payload)
-		}
+			return true
+		})
 	}
 
 	wg.Wait()
 }
 
-// testPayload 测试单个payload
-func (sm *ScanManager) testPayload(param string, payload payloads.Payload) {
-	// 如果设置了延迟时间，则延迟发包
-	if sm.config.DelayTime > 0 {
-		time.Sleep(time.Duration(sm.config.DelayTime) * time.Second)
+// filterStream 跳过被 -exclude-payload/-exclude-type 排除的payload，指定 -encoders 时在每个payload之后生成其编码变种
+func (sm *ScanManager) filterStream(each func(fn func(payloads.Payload) bool)) func(fn func(payloads.Payload) bool) {
+	return func(fn func(payloads.Payload) bool) {
+		each(func(payload payloads.Payload) bool {
+			if sm.excluded(payload) {
+				return true
+			}
+			return payloads.EachVariant(payload, sm.config.EncoderList, fn)
+		})
 	}
+}
 
-	// 构造测试请求
-	testURL, body, err := buildTestRequest(sm.config.Method, sm.config.TargetURL, param, payload.Value)
-	if err != nil {
-		return
-	}
+// excluded 判断payload是否不发送：被 -exclude-payload/-exclude-type 排除，引用的主机超出 -payload-allow/-payload-deny 的范围，
+// 或指定 -safe 时可能修改内网服务的状态
+func (sm *ScanManager) excluded(payload payloads.Payload) bool {
+	return sm.config.Excluded(payload.Value, payload.Type) || sm.outOfScope(payload.Value) || sm.unsafe(payload.Value)
+}
 
-	// 打印测试信息（使用互斥锁保护输出顺序）
-	sm.outputMux.Lock()
-	testMsg := fmt.Sprintf("[%s] 正在测试 %s\n", sm.config.Method, payload.Value)
-	fmt.Print(testMsg)
-	if sm.outputFile != nil {
-		sm.outputFile.WriteString(testMsg)
+// unsafe 指定 -safe 时判断payload是否包含非只读的命令，-v 时输出跳过的payload
+func (sm *ScanManager) unsafe(value string) bool {
+	if !sm.config.Safe {
+		return false
+	}
+	command, destructive := payloads.Destructive(value)
+	if destructive {
+		slog.Debug(fmt.Sprintf("安全模式不发送包含非只读命令 %s 的payload: %s", command, value))
 	}
-	sm.outputMux.Unlock()
+	return destructive
+}
 
-	// 发送请求并检测
-	vulnerable, _, _, _, _, errMsg := sm.detector.DetectWithMethod(
-		sm.config.Method, testURL, body, payload)
+// outOfScope 判断payload引用的主机是否超出 -payload-allow/-payload-deny 的范围，每个主机只输出一次
+// 指定 -force 时仍然发送，返回 false
+func (sm *ScanManager) outOfScope(value string) bool {
+	host, ok := sm.config.PayloadInScope(value)
+	if ok {
+		return false
+	}
+	if _, warned := sm.scopeWarned.LoadOrStore(host, true); !warned {
+		msg := fmt.Sprintf("payload引用的主机 %s 超出 -payload-allow/-payload-deny 的范围，不发送", host)
+		if sm.config.Force {
+			msg = fmt.Sprintf("payload引用的主机 %s 超出 -payload-allow/-payload-deny 的范围，已指定 -force，仍然发送", host)
+		}
+		// 只有第一个主机输出警告，其余主机 -v 时输出
+		first := false
+		sm.scopeOnce.Do(func() { first = true })
+		if first && !sm.config.Force {
+			slog.Warn(msg + "（其余超出范围的主机 -v 时输出，-force 强制发送）")
+		} else if first {
+			slog.Warn(msg)
+		} else {
+			slog.Debug(msg)
+		}
+	}
+	return !sm.config.Force
+}
 
-	// 输出结果（使用互斥锁保护输出顺序）
-	sm.outputMux.Lock()
-	if errMsg != "" {
-		// 红色输出错误（文件中保存纯文本）
-		red := config.Colors(config.ColorRed)
-		red.Printf("[%s] %s Error: %s\n", sm.config.Method, testURL, errMsg)
-		if sm.outputFile != nil {
-			errOutput := fmt.Sprintf("[%s] %s Error: %s\n", sm.config.Method, testURL, errMsg)
-			sm.outputFile.WriteString(errOutput)
+// highRiskPayloads 获取高危payload，按 -tags 只保留文件读取（files）或协议探测（protocol）
+func (sm *ScanManager) highRiskPayloads() []payloads.Payload {
+	var highRiskPayloads []payloads.Payload
+	for _, payload := range payloads.GetHighRiskPayloads() {
+		tag := config.TagProtocol
+		if payload.Type == "文件读取" {
+			tag = config.TagFiles
+		}
+		if sm.config.HasTag(tag) {
+			highRiskPayloads = append(highRiskPayloads, payload)
 		}
 	}
+	return highRiskPayloads
+}
 
-	if vulnerable {
-		// 绿色输出漏洞（文件中保存纯文本）
-		green := config.Colors(config.ColorGreen)
-		green.Printf("[%s] %s payload: %s=%s\n", sm.config.Method, testURL, param, payload.Value)
-		if sm.outputFile != nil {
-			vulnOutput := fmt.Sprintf("[%s] %s payload: %s=%s\n", sm.config.Method, testURL, param, payload.Value)
-			sm.outputFile.WriteString(vulnOutput)
+// cloudSelected 判断是否测试指定云厂商（未指定 -cloud 时测试全部）
+func (sm *ScanManager) cloudSelected(provider string) bool {
+	if len(sm.config.CloudList) == 0 {
+		return true
+	}
+	for _, p := range sm.config.CloudList {
+		if p == provider {
+			return true
 		}
+	}
+	return false
+}
 
-		// 增加漏洞计数
-		sm.vulnCountMux.Lock()
-		sm.vulnCount++
-		sm.vulnCountMux.Unlock()
+// handleRebindQuery 处理内置DNS服务收到的重绑定域名查询
+// 同一域名被再次解析说明目标在校验后重新解析，重绑定生效
+func (sm *ScanManager) handleRebindQuery(query rebind.Query) {
+	if query.Count == 2 {
+		slog.Info(fmt.Sprintf("[DNS] %s 被再次解析，已返回 %s 来源: %s 时间: %s",
+			query.Name, query.Answer, query.RemoteIP, query.Time.Format("2006-01-02 15:04:05")), "domain", query.Name)
 	}
-	sm.outputMux.Unlock()
 }
 
-// scanAllDictPayloads 扫描所有内置字典文件（绕过技术、编码变种等）
-func (sm *ScanManager) scanAllDictPayloads(params map[string]string) {
-	var wg sync.WaitGroup
+// scanOOB OOB测试
+func (sm *ScanManager) scanOOB(ctx context.Context, target string, params map[string]string) {
+	// 获取OOB payload
+	oobServer := sm.oobBaseURL()
 
-	// 加载所有内置字典文件
-	dictPayloads := payloads.GetAllDictPayloads()
+	if sm.oobRegistry == nil {
+		sm.runPayloads(ctx, target, "oob", params, payloads.GetOOBPayloads(oobServer))
+		return
+	}
 
-	if len(dictPayloads) == 0 {
-		red := config.Colors(config.ColorRed)
-		red.Printf("[!] 未能加载任何内置字典文件\n")
+	// 为每个 目标+参数+payload 分配唯一标识并嵌入回连URL，回连时据此关联到具体请求
+	for paramName, value := range params {
+		oobPayloads := payloads.GetOOBPayloads(oobServer)
+		for i := range oobPayloads {
+			token := oob.NewToken()
+			oobPayloads[i].Value = oob.EmbedToken(oobPayloads[i].Value, token, sm.config.OOBMode)
+			sm.oobRegistry.Register(oob.Callback{
+				Token:   token,
+				Target:  target,
+				Param:   paramName,
+				Payload: oobPayloads[i].Value,
+			})
+		}
+		sm.runPayloads(ctx, target, "oob", map[string]string{paramName: value}, oobPayloads)
+	}
+}
+
+// handleOOBHit 处理内置OOB服务收到的回连
+func (sm *ScanManager) handleOOBHit(hit oob.Hit) {
+	if hit.Callback == nil {
+		slog.Info(fmt.Sprintf("[OOB] 收到未关联的回连: %s %s%s 来源: %s",
+			hit.Method, hit.Host, hit.Path, hit.RemoteIP), "remote_ip", hit.RemoteIP)
 		return
 	}
 
-	green := config.Colors(config.ColorGreen)
-	green.Printf("[+] 已加载 %d 个内置字典 payload（绕过技术、编码变种等）\n", len(dictPayloads))
+	// 同一个payload的多次回连只计数一次
+	sm.vulnCountMux.Lock()
+	first := !sm.oobConfirmed[hit.Callback.Token]
+	sm.oobConfirmed[hit.Callback.Token] = true
+	sm.vulnCountMux.Unlock()
+	if !first {
+		return
+	}
 
-	semaphore := make(chan struct{}, sm.config.Threads)
+	result := ScanResult{
+		Target:      hit.Callback.Target,
+		Parameter:   hit.Callback.Param,
+		Payload:     hit.Callback.Payload,
+		PayloadType: "OOB检测",
+		Evidence:    deferredEvidence(fmt.Sprintf("收到来自 %s 的回连", hit.RemoteIP), hit.Callback.Created, hit.Time),
+		Kind:        detector.EvidenceOOB,
+		Severity:    sm.config.SeverityMap.Severity("OOB检测", detector.EvidenceOOB, detector.SeverityHigh),
+		Confidence:  detector.ConfidenceConfirmed,
+	}
+	sm.printFinding(result, fmt.Sprintf("[OOB] [%s] 收到回连 %s 来源: %s 时间: %s payload: %s=%s [%s/%s]\n",
+		hit.Callback.Target, hit.Method, hit.RemoteIP, hit.Time.Format("2006-01-02 15:04:05"), hit.Callback.Param, hit.Callback.Payload,
+		result.Severity, result.Confidence))
+	sm.recordVuln(result)
+}
 
-	for paramName := range params {
-		for _, payload := range dictPayloads {
-			wg.Add(1)
-			semaphore <- struct{}{}
+// recordVuln 记录新发现的漏洞
+func (sm *ScanManager) recordVuln(result ScanResult) {
+	result.Vulnerable = true
+	if result.Fingerprint == "" {
+		result.Fingerprint = Fingerprint(result)
+	}
 
-			go func(param string, pl payloads.Payload) {
-				defer func() {
-					<-semaphore
-					wg.Done()
-				}()
+	sm.vulnCountMux.Lock()
+	sm.vulnCount++
+	sm.targetVulns[result.Target]++
+	sm.results = append(sm.results, result)
+	sm.vulnCountMux.Unlock()
 
-				sm.testPayload(param, pl)
-			}(paramName, payload)
-		}
+	if sm.state != nil {
+		sm.state.AddVuln(result.Target)
 	}
 
-	wg.Wait()
+	sm.notify(result)
 }
 
-// scanWithCustomDict 使用自定义字典扫描
-func (sm *ScanManager) scanWithCustomDict(params map[string]string) {
-	var wg sync.WaitGroup
+// Merge 合并其他节点扫描目标 target 发现的漏洞（分布式扫描），输出并记录每个漏洞
+func (sm *ScanManager) Merge(target string, results []ScanResult) {
+	sm.vulnCountMux.Lock()
+	if _, ok := sm.targetVulns[target]; !ok {
+		sm.targetVulns[target] = 0
+	}
+	sm.vulnCountMux.Unlock()
+
+	for _, result := range results {
+		sm.printFinding(result, findingLine(result))
+		sm.recordVuln(result)
+	}
+}
 
-	// 从文件加载payload
-	customPayloads, err := sm.loadCustomPayloads()
+// Results 返回本次扫描发现的漏洞（按发现顺序）
+func (sm *ScanManager) Results() []ScanResult {
+	sm.vulnCountMux.Lock()
+	defer sm.vulnCountMux.Unlock()
+
+	results := make([]ScanResult, len(sm.results))
+	copy(results, sm.results)
+	return results
+}
+
+// testPayload 测试单个payload，请求因 ctx 取消而未完成时返回 false
+func (sm *ScanManager) testPayload(ctx context.Context, target, param string, payload payloads.Payload) bool {
+	// 如果设置了延迟时间，则延迟发包（-jitter 在固定间隔上叠加随机间隔）
+	delay := time.Duration(sm.config.DelayTime) * time.Second
+	if sm.config.JitterMax > 0 {
+		delay += time.Duration(sm.config.JitterMin+rand.Intn(sm.config.JitterMax-sm.config.JitterMin+1)) * time.Millisecond
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// 替换回连地址模板变量
+	original := payload
+	if strings.Contains(payload.Value, payloads.VarOOB) {
+		payload.Value = sm.expandOOB(target, param, payload.Value)
+	}
+
+	// 构造测试请求
+	method, testURL, body, err := sm.buildRequest(target, param, payload.Value)
 	if err != nil {
-		red := config.Colors(config.ColorRed)
-		red.Printf("[!] 加载字典文件失败: %v\n", err)
-		return
+		slog.Warn(fmt.Sprintf("[%s] 构造请求失败 %s=%s: %v", target, param, payload.Value, err), "target", target, "param", param)
+		return true
 	}
 
-	semaphore := make(chan struct{}, sm.config.Threads)
+	// -vv 时在收到响应后与请求详情一起输出，避免并发时测试信息与详情错开
+	if sm.config.Verbosity() == config.LevelVerbose {
+		slog.Debug(fmt.Sprintf("[%s] 正在测试 %s", method, payload.Value), "target", target, "param", param)
+	}
 
-	for paramName := range params {
-		for _, payload := range customPayloads {
-			wg.Add(1)
-			semaphore <- struct{}{}
+	// 发送请求并检测
+	result := sm.detect(ctx, method, testURL, body, payload, sm.baseline(target, param), sm.secondBaseline(target, param))
+	if result.Canceled {
+		return false
+	}
 
-			go func(param string, pl payloads.Payload) {
-				defer func() {
-					<-semaphore
-					wg.Done()
-				}()
+	sm.recordBlock(target, param, original, result)
+	sm.recordHealth(target, result, sm.baseline(target, param))
+	sm.recordPort(target, original, result)
 
-				sm.testPayload(param, pl)
-			}(paramName, payload)
+	// 红色输出错误（文件中保存纯文本）
+	if result.Error != "" {
+		slog.Debug(fmt.Sprintf("[%s] %s Error: %s", method, testURL, result.Error), "target", target, "param", param)
+	}
+
+	scanResult := ScanResult{
+		Target:       target,
+		Method:       method,
+		URL:          testURL,
+		RequestBody:  body,
+		Parameter:    param,
+		Payload:      payload.Value,
+		PayloadType:  payload.Type,
+		StatusCode:   result.StatusCode,
+		ResponseLen:  result.ResponseLen,
+		ResponseTime: result.ResponseTime,
+		Evidence:     result.Evidence,
+		Kind:         result.Kind,
+		Severity:     result.Severity,
+		Confidence:   result.Confidence,
+		Response:     result.Response,
+		Redirects:    result.Redirects,
+		Bypass:       payload.Bypass,
+		Blocked:      result.Blocked,
+		Suppressed:   result.Suppressed,
+		Credentials:  result.Credentials,
+		Service:      result.Service,
+		Error:        result.Error,
+	}
+
+	if sm.config.Verbosity() >= config.LevelDebug {
+		slog.Log(ctx, logging.LevelTrace, formatDetail(scanResult), "target", target, "param", param,
+			"status", result.StatusCode, "length", result.ResponseLen, "time_ms", result.ResponseTime)
+	}
+
+	if result.Vulnerable {
+		if result.Exchange != "" {
+			if path, err := sm.saveEvidence(scanResult, result.Exchange); err != nil {
+				slog.Warn(err.Error(), "target", target, "param", param)
+			} else {
+				scanResult.EvidenceFile = path
+			}
 		}
+
+		// 绿色输出漏洞（文件中保存纯文本），并标注所属目标、严重程度和置信度
+		sm.printFinding(scanResult, findingLine(scanResult))
+
+		sm.recordVuln(scanResult)
+	} else {
+		sm.notify(scanResult)
 	}
+	sm.reportSecrets(scanResult, result.Secrets, result.Exchange)
 
-	wg.Wait()
+	return true
 }
 
-// loadCustomPayloads 从文件加载自定义payload
+// dictPhase 扫描所有内置字典文件（绕过技术、编码变种等）
+func (sm *ScanManager) dictPhase(target string) scanPhase {
+	dictPayloads := payloads.GetAllDictPayloads()
+	phase := sm.listPhase(target, "dict", dictPayloads)
+	each := phase.each
+	phase.run = func(ctx context.Context, target string, params map[string]string) {
+		if len(dictPayloads) == 0 {
+			slog.Warn("未能加载任何内置字典文件")
+			return
+		}
+		slog.Info(fmt.Sprintf("已加载 %d 个内置字典 payload（绕过技术、编码变种等）", len(dictPayloads)))
+		sm.runPayloadStream(ctx, target, "dict", params, each)
+	}
+	return phase
+}
+
+// loadCustomPayloads 从文件加载自定义payload，每行格式为 payload | 关键字1,关键字2 | 类型（关键字和类型可以省略）
 func (sm *ScanManager) loadCustomPayloads() ([]payloads.Payload, error) {
 	file, err := os.Open(sm.config.PayloadFile)
 	if err != nil {
@@ -339,12 +1129,7 @@ func (sm *ScanManager) loadCustomPayloads() ([]payloads.Payload, error) {
 			continue
 		}
 
-		// 创建payload
-		result = append(result, payloads.Payload{
-			Value:    line,
-			Type:     "自定义字典",
-			Keywords: []string{},
-		})
+		result = append(result, payloads.ParseDictLine(line, "自定义字典"))
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+)
+
+// deferredCallback 注入请求发出后超过该时间才收到的回连视为延迟处理触发（二阶SSRF）
+const deferredCallback = 10 * time.Second
+
+// detect 发送注入请求并检测，指定 -second-order 时随后请求验证地址，注入请求的响应未命中时按验证地址的响应判定
+// 验证地址展示的是最近一次注入的内容，注入请求和验证请求成对串行发送，保证验证响应对应本次payload
+func (sm *ScanManager) detect(ctx context.Context, method, testURL, body string, payload payloads.Payload, baseline, secondBaseline *detector.Baseline) detector.Result {
+	if sm.config.SecondOrder == "" {
+		return sm.detector.DetectWithMethod(ctx, method, testURL, body, payload, baseline)
+	}
+
+	sm.secondMux.Lock()
+	defer sm.secondMux.Unlock()
+	result := sm.detector.DetectWithMethod(ctx, method, testURL, body, payload, baseline)
+	if result.Canceled || result.Vulnerable || result.Blocked != "" {
+		return result
+	}
+
+	if !sm.secondOrderPause(ctx) {
+		return detector.Result{Canceled: true, Error: "扫描已取消"}
+	}
+	second := sm.detector.DetectWithMethod(ctx, "GET", sm.config.SecondOrder, "", payload, secondBaseline)
+	if second.Canceled {
+		return second
+	}
+	result.Secrets = append(result.Secrets, second.Secrets...)
+	if !second.Vulnerable {
+		if second.Error != "" {
+			slog.Debug(fmt.Sprintf("[GET] %s Error: %s", sm.config.SecondOrder, second.Error))
+		}
+		return result
+	}
+	second.Evidence = fmt.Sprintf("二阶SSRF，验证地址 %s 的响应: %s", sm.config.SecondOrder, second.Evidence)
+	second.Secrets = result.Secrets
+	return second
+}
+
+// secondOrderPause 等待 -second-order-delay 指定的间隔，ctx 取消时返回 false
+func (sm *ScanManager) secondOrderPause(ctx context.Context) bool {
+	if sm.config.SecondOrderDelay <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-time.After(time.Duration(sm.config.SecondOrderDelay) * time.Millisecond):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// fetchSecondBaseline 在注入点已设置为基线地址后请求验证地址，作为验证响应的基线，未指定 -second-order 时返回nil
+func (sm *ScanManager) fetchSecondBaseline(ctx context.Context) *detector.Baseline {
+	if sm.config.SecondOrder == "" || !sm.secondOrderPause(ctx) {
+		return nil
+	}
+	baseline, err := sm.detector.FetchBaseline(ctx, "GET", sm.config.SecondOrder, "")
+	if err != nil {
+		slog.Info(fmt.Sprintf("获取二阶SSRF验证地址的基线响应失败，使用默认规则判定: %v", err))
+		return nil
+	}
+	return baseline
+}
+
+// secondBaseline 返回注入点对应的验证地址基线响应，未获取时返回nil
+func (sm *ScanManager) secondBaseline(target, param string) *detector.Baseline {
+	sm.baselineMux.RLock()
+	defer sm.baselineMux.RUnlock()
+	return sm.secondPages[target+"\x00"+param]
+}
+
+// deferredEvidence 回连明显晚于注入请求时在证据中说明延迟，提示为后续处理触发的二阶SSRF
+func deferredEvidence(evidence string, created, received time.Time) string {
+	if created.IsZero() || received.Sub(created) < deferredCallback {
+		return evidence
+	}
+	return fmt.Sprintf("%s（注入后 %s 才收到，延迟处理触发的二阶SSRF）", evidence, received.Sub(created).Round(time.Second))
+}
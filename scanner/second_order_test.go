@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/payloads"
+)
+
+func TestDetectSecondOrder(t *testing.T) {
+	// 模拟二阶SSRF：资料更新接口只保存头像地址，资料页面渲染时才请求该地址
+	var mu sync.Mutex
+	avatar := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/update":
+			avatar = r.URL.Query().Get("avatar")
+			w.Write([]byte("saved"))
+		case "/profile":
+			if avatar == "file:///etc/passwd" {
+				w.Write([]byte("<img>root:x:0:0:root:/root:/bin/bash</img>"))
+				return
+			}
+			w.Write([]byte("<img>" + avatar + "</img>"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Method: http.MethodGet, Timeout: 5, SecondOrder: server.URL + "/profile"}
+	var out bytes.Buffer
+	sm := NewScanManager(cfg, detector.NewDetector(cfg), logging.NewConsole(&out, &out), nil, nil, nil)
+	payload := payloads.Payload{Value: "file:///etc/passwd", Type: "文件读取", Keywords: []string{"root:x:0:0"}}
+
+	result := sm.detect(context.Background(), http.MethodGet, server.URL+"/update?avatar=file:///etc/passwd", "", payload, nil, nil)
+	if !result.Vulnerable || !strings.Contains(result.Evidence, "二阶SSRF") || !strings.Contains(result.Evidence, "/profile") {
+		t.Errorf("detect() = %+v，期望按验证地址的响应判定为二阶SSRF", result)
+	}
+
+	// 未指定验证地址时只检测注入请求的响应
+	cfg.SecondOrder = ""
+	if result := sm.detect(context.Background(), http.MethodGet, server.URL+"/update?avatar=file:///etc/passwd", "", payload, nil, nil); result.Vulnerable {
+		t.Errorf("detect() = %+v，注入请求的响应中没有特征", result)
+	}
+}
+
+func TestDeferredEvidence(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		created  time.Time
+		received time.Time
+		want     string
+	}{
+		{"立即回连", created, created.Add(time.Second), "收到回连"},
+		{"延迟回连", created, created.Add(95 * time.Second), "收到回连（注入后 1m35s 才收到，延迟处理触发的二阶SSRF）"},
+		{"没有登记时间", time.Time{}, created, "收到回连"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deferredEvidence("收到回连", tt.created, tt.received); got != tt.want {
+				t.Errorf("deferredEvidence() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
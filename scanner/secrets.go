@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"fmt"
+	"gosssrf-client/detector"
+	"log/slog"
+)
+
+// reportSecrets 将响应中泄露的敏感信息作为单独的漏洞报告，同一目标的相同内容只报告一次
+// base 为该请求的扫描结果，exchange 为完整的请求和响应（未指定 -evidence-dir 时为空）
+func (sm *ScanManager) reportSecrets(base ScanResult, secrets []detector.Secret, exchange string) {
+	for _, secret := range secrets {
+		key := base.Target + "\x00" + secret.Value
+		sm.vulnCountMux.Lock()
+		first := !sm.secretsSeen[key]
+		sm.secretsSeen[key] = true
+		sm.vulnCountMux.Unlock()
+		if !first {
+			continue
+		}
+
+		result := base
+		result.PayloadType = "敏感信息泄露"
+		result.Evidence = fmt.Sprintf("响应中泄露%s: %s", secret.Rule, detector.MaskSecret(secret.Value))
+		result.Kind = detector.EvidenceSecret
+		result.Severity = sm.config.SeverityMap.Severity(result.PayloadType, result.Kind, secret.Severity)
+		result.Confidence = detector.ConfidenceProbable
+		result.Credentials = nil
+		if result.EvidenceFile == "" && exchange != "" {
+			if path, err := sm.saveEvidence(result, exchange); err != nil {
+				slog.Warn(err.Error(), "target", base.Target, "param", base.Parameter)
+			} else {
+				base.EvidenceFile = path
+				result.EvidenceFile = path
+			}
+		}
+
+		sm.printFinding(result, findingLine(result))
+		sm.recordVuln(result)
+	}
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// stateSaveInterval 每完成多少个payload保存一次状态文件
+const stateSaveInterval = 100
+
+// stateCursor 单个 目标+参数+阶段 的扫描进度
+// payload按固定顺序生成，只需记录从头开始连续完成的数量，而不是每个payload
+type stateCursor struct {
+	Done      int          `json:"done"` // 从头开始连续完成的payload数量
+	completed map[int]bool // 已完成但尚未连续的序号（并发乱序完成）
+}
+
+// ScanState 扫描进度，用于中断后继续扫描
+type ScanState struct {
+	Cursors map[string]*stateCursor `json:"cursors"` // 每个 目标+参数+阶段 的进度
+	Vulns   map[string]int          `json:"vulns"`   // 每个目标已发现的漏洞数量
+
+	path    string
+	pending int
+	mux     sync.Mutex
+	saveMux sync.Mutex // 串行化写入，避免多个保存同时写临时文件
+}
+
+// loadScanState 加载状态文件，文件不存在时返回空状态
+func loadScanState(path string) (*ScanState, error) {
+	state := &ScanState{
+		Cursors: make(map[string]*stateCursor),
+		Vulns:   make(map[string]int),
+		path:    path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %v", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("解析状态文件失败: %v", err)
+	}
+	if state.Cursors == nil {
+		state.Cursors = make(map[string]*stateCursor)
+	}
+	if state.Vulns == nil {
+		state.Vulns = make(map[string]int)
+	}
+
+	return state, nil
+}
+
+// stateKey 生成进度在状态文件中的标识
+func stateKey(target, param, phase string) string {
+	return target + "\x00" + param + "\x00" + phase
+}
+
+// Completed 返回已发送的payload总数
+func (s *ScanState) Completed() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	total := 0
+	for _, cursor := range s.Cursors {
+		total += cursor.Done
+	}
+	return total
+}
+
+// ResumeIndex 返回该进度从头开始连续完成的数量，序号小于它的payload可以跳过
+func (s *ScanState) ResumeIndex(key string) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if cursor, ok := s.Cursors[key]; ok {
+		return cursor.Done
+	}
+	return 0
+}
+
+// MarkDone 记录第 index 个payload已发送，达到保存间隔时写入状态文件
+func (s *ScanState) MarkDone(key string, index int) error {
+	s.mux.Lock()
+	cursor, ok := s.Cursors[key]
+	if !ok {
+		cursor = &stateCursor{}
+		s.Cursors[key] = cursor
+	}
+	if cursor.completed == nil {
+		cursor.completed = make(map[int]bool)
+	}
+
+	// 推进连续完成的序号
+	if index >= cursor.Done {
+		cursor.completed[index] = true
+		for cursor.completed[cursor.Done] {
+			delete(cursor.completed, cursor.Done)
+			cursor.Done++
+		}
+	}
+
+	s.pending++
+	shouldSave := s.pending >= stateSaveInterval
+	if shouldSave {
+		s.pending = 0
+	}
+	s.mux.Unlock()
+
+	if shouldSave {
+		return s.Save()
+	}
+	return nil
+}
+
+// AddVuln 记录目标发现的漏洞
+func (s *ScanState) AddVuln(target string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.Vulns[target]++
+}
+
+// Save 写入状态文件（先写临时文件再重命名，避免中断时文件损坏）
+func (s *ScanState) Save() error {
+	s.saveMux.Lock()
+	defer s.saveMux.Unlock()
+
+	s.mux.Lock()
+	data, err := json.Marshal(s)
+	s.mux.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化状态失败: %v", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	return nil
+}
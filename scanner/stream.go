@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+
+	"gosssrf-client/config"
+)
+
+// ReadTargets 设置逐行读取扫描目标的输入（从标准输入读取目标时），RunScan 扫描完 config.Targets 后
+// 边读取边扫描，读取到的目标追加到 config.Targets，不需要等待输入结束
+func (sm *ScanManager) ReadTargets(r io.Reader) {
+	sm.input = r
+}
+
+// targets 按扫描顺序返回目标：先是 config.Targets，然后是从输入中读取的新目标（已去重）
+func (sm *ScanManager) targets(ctx context.Context) <-chan string {
+	ch := make(chan string, len(sm.config.Targets))
+	for _, target := range sm.config.Targets {
+		ch <- target
+	}
+	if sm.input == nil {
+		close(ch)
+		return ch
+	}
+
+	seen := make(map[string]bool, len(sm.config.Targets))
+	for _, target := range sm.config.Targets {
+		seen[target] = true
+	}
+	go func() {
+		defer close(ch)
+		lines := bufio.NewScanner(sm.input)
+		lines.Buffer(make([]byte, 64*1024), 1024*1024)
+		for lines.Scan() {
+			target, ok := config.TargetLine(lines.Text())
+			if !ok || seen[target] {
+				continue
+			}
+			seen[target] = true
+			if _, err := url.Parse(target); err != nil {
+				slog.Warn(fmt.Sprintf("跳过无效的目标 %s: %v", target, err))
+				continue
+			}
+			select {
+			case ch <- target:
+			case <-ctx.Done():
+				return
+			case <-sm.stopCh:
+				return
+			}
+		}
+		if err := lines.Err(); err != nil {
+			slog.Warn(fmt.Sprintf("读取目标失败: %v", err))
+		}
+	}()
+	return ch
+}
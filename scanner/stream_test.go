@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+)
+
+func TestTargetsFromInput(t *testing.T) {
+	cfg := &config.Config{Targets: []string{"http://a.example/?url=1"}}
+	var out bytes.Buffer
+	sm := NewScanManager(cfg, detector.NewDetector(cfg), logging.NewConsole(&out, &out), nil, nil, nil)
+	sm.ReadTargets(strings.NewReader("http://b.example/?url=1\n\n# 注释\n  http://a.example/?url=1\nhttp://b.example/?url=1\nhttp://%zz/\nhttp://c.example/?u=1"))
+
+	var got []string
+	for target := range sm.targets(context.Background()) {
+		got = append(got, target)
+	}
+	want := []string{"http://a.example/?url=1", "http://b.example/?url=1", "http://c.example/?u=1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 时间盲注探测使用的参考地址
+const (
+	timingClosedURL      = "http://127.0.0.1:1/" // 本机关闭端口，连接会被立即拒绝
+	timingUnreachableURL = "http://192.0.2.1/"   // TEST-NET-1 不可路由地址，连接会一直等待到超时
+	timingSamples        = 3                     // 每个参考地址的采样次数，取中位数
+)
+
+// scanTiming 时间盲注检测：比较目标访问不可达地址和关闭端口时的响应时间
+// 目标服务器确实发起了请求时，不可达地址会明显更慢；确认后再按端口比较响应时间推断端口状态
+func (sm *ScanManager) scanTiming(ctx context.Context, target string, params map[string]string) {
+	threshold := time.Duration(sm.config.TimingThreshold) * time.Millisecond
+
+	if sm.outOfScope(timingClosedURL) || sm.outOfScope(timingUnreachableURL) {
+		return
+	}
+
+	for param := range params {
+		if sm.stopped(ctx) {
+			return
+		}
+		closed := sm.measureLatency(ctx, target, param, timingClosedURL, timingSamples)
+		unreachable := sm.measureLatency(ctx, target, param, timingUnreachableURL, timingSamples)
+		if sm.stopped(ctx) {
+			return
+		}
+		delta := unreachable - closed
+
+		if delta < threshold {
+			slog.Info(fmt.Sprintf("[%s] 参数 %s 响应时间无明显差异: 不可达地址 %dms，关闭端口 %dms",
+				target, param, unreachable.Milliseconds(), closed.Milliseconds()), "target", target, "param", param)
+			continue
+		}
+
+		evidence := fmt.Sprintf("不可达地址 %dms，关闭端口 %dms，差值 %dms", unreachable.Milliseconds(), closed.Milliseconds(), delta.Milliseconds())
+		result := ScanResult{
+			Target:      target,
+			Parameter:   param,
+			Payload:     timingUnreachableURL,
+			PayloadType: "时间盲注",
+			Evidence:    evidence,
+			Kind:        detector.EvidenceTiming,
+			Severity:    sm.config.SeverityMap.Severity("时间盲注", detector.EvidenceTiming, detector.SeverityMedium),
+			Confidence:  detector.ConfidenceProbable,
+		}
+		sm.printFinding(result, fmt.Sprintf("[TIME] [%s] 参数 %s 疑似存在无回显SSRF: %s [%s/%s]\n",
+			target, param, evidence, result.Severity, result.Confidence))
+		sm.recordVuln(result)
+
+		// 指定 -timing-hosts 时先按响应时间发现存活主机，只对存活主机比较端口
+		var hosts payloads.IPSource = sm.config.InternalIPs
+		if sm.config.TimingHosts {
+			live := sm.discoverHosts(ctx, target, param)
+			if len(live) == 0 {
+				continue
+			}
+			hosts = live
+		}
+		sm.scanPortTiming(ctx, target, param, hosts, closed, threshold)
+	}
+}
+
+// scanPortTiming 按端口比较响应时间，与关闭端口相差超过阈值的端口可能开放或被过滤
+func (sm *ScanManager) scanPortTiming(ctx context.Context, target, param string, hosts payloads.IPSource, closed, threshold time.Duration) {
+	var wg sync.WaitGroup
+	semaphore := sm.semaphore(target)
+
+	payloads.EachPortScanPayload(hosts, sm.config.PortList, func(payload payloads.Payload) bool {
+		if sm.stopped(ctx) {
+			return false
+		}
+		if sm.outOfScope(payload.Value) {
+			return true
+		}
+		wg.Add(1)
+		semaphore.acquire()
+		go func(value string) {
+			defer func() {
+				semaphore.release()
+				wg.Done()
+			}()
+
+			latency := sm.measureLatency(ctx, target, param, value, 1)
+			if latency-closed >= threshold {
+				slog.Info(fmt.Sprintf("[TIME] [%s] %s=%s 响应时间 %dms（关闭端口 %dms），端口可能开放或被过滤",
+					target, param, value, latency.Milliseconds(), closed.Milliseconds()), "target", target, "param", param)
+			}
+		}(payload.Value)
+		return true
+	})
+
+	wg.Wait()
+}
+
+// measureLatency 多次发送payload，返回响应时间的中位数（请求失败时同样计时）
+func (sm *ScanManager) measureLatency(ctx context.Context, target, param, value string, samples int) time.Duration {
+	return medianLatency(sm.sampleLatency(ctx, target, param, value, samples))
+}
+
+// sampleLatency 多次发送payload，返回每次的响应时间（请求失败时同样计时）
+func (sm *ScanManager) sampleLatency(ctx context.Context, target, param, value string, samples int) []time.Duration {
+	method, testURL, body, err := sm.buildRequest(target, param, value)
+	if err != nil {
+		return nil
+	}
+
+	payload := payloads.Payload{Value: value, Type: "时间盲注"}
+	latencies := make([]time.Duration, 0, samples)
+	for i := 0; i < samples && !sm.stopped(ctx); i++ {
+		start := time.Now()
+		sm.detector.DetectWithMethod(ctx, method, testURL, body, payload, nil)
+		latencies = append(latencies, time.Since(start))
+	}
+	return latencies
+}
+
+// medianLatency 返回响应时间的中位数，没有采样时返回0
+func medianLatency(latencies []time.Duration) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
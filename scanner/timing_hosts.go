@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"gosssrf-client/payloads"
+)
+
+// 时间盲注主机发现（-timing-hosts参数）的参考地址和判定规则
+const (
+	timingHostMinDelta = 300 * time.Millisecond // 与不存在的地址的响应时间至少相差该值
+	timingHostSigma    = 3                      // 并且超过不存在的地址响应时间标准差的倍数
+)
+
+// timingNonexistentURLs 保证不存在的参考地址（TEST-NET-1/2/3），目标访问不存在的内网主机时的响应时间与其相近
+var timingNonexistentURLs = []string{"http://192.0.2.1/", "http://198.51.100.1/", "http://203.0.113.1/"}
+
+// latencyProfile 一组响应时间的中位数和标准差
+type latencyProfile struct {
+	median time.Duration
+	stddev time.Duration
+}
+
+// newLatencyProfile 计算响应时间的中位数和标准差
+func newLatencyProfile(latencies []time.Duration) latencyProfile {
+	if len(latencies) == 0 {
+		return latencyProfile{}
+	}
+	var sum float64
+	for _, l := range latencies {
+		sum += float64(l)
+	}
+	mean := sum / float64(len(latencies))
+	var variance float64
+	for _, l := range latencies {
+		variance += (float64(l) - mean) * (float64(l) - mean)
+	}
+	variance /= float64(len(latencies))
+	return latencyProfile{median: medianLatency(latencies), stddev: time.Duration(math.Sqrt(variance))}
+}
+
+// differs 判断响应时间是否与该分布有显著差异：相差至少 timingHostMinDelta，且超过 timingHostSigma 倍标准差
+func (p latencyProfile) differs(latency time.Duration) bool {
+	delta := latency - p.median
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta >= timingHostMinDelta && delta >= timingHostSigma*p.stddev
+}
+
+// hostList 按响应时间发现的存活主机，作为端口比较的IP列表
+type hostList []string
+
+// Len 返回主机数量
+func (l hostList) Len() int { return len(l) }
+
+// Each 依次处理每个主机，fn 返回 false 时停止
+func (l hostList) Each(fn func(ip string) bool) {
+	for _, ip := range l {
+		if !fn(ip) {
+			return
+		}
+	}
+}
+
+// discoverHosts 时间盲注主机发现：先采样目标访问不存在的地址时的响应时间分布，
+// 再对 -i 中的每个候选地址多次采样，中位数与该分布有显著差异的主机可能存活（存活主机通常会立即接受或拒绝连接）
+func (sm *ScanManager) discoverHosts(ctx context.Context, target, param string) hostList {
+	var reference []time.Duration
+	for _, value := range timingNonexistentURLs {
+		if sm.outOfScope(value) {
+			return nil
+		}
+		reference = append(reference, sm.sampleLatency(ctx, target, param, value, timingSamples)...)
+	}
+	if sm.stopped(ctx) || len(reference) == 0 {
+		return nil
+	}
+	profile := newLatencyProfile(reference)
+	slog.Info(fmt.Sprintf("[%s] 参数 %s 访问不存在的地址的响应时间 %dms±%dms，开始按响应时间发现 %d 个候选地址中的存活主机",
+		target, param, profile.median.Milliseconds(), profile.stddev.Milliseconds(), sm.config.InternalIPs.Len()), "target", target, "param", param)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		live hostList
+	)
+	semaphore := sm.semaphore(target)
+	sm.config.InternalIPs.Each(func(ip string) bool {
+		if sm.stopped(ctx) {
+			return false
+		}
+		value := "http://" + payloads.URLHost(ip) + "/"
+		if sm.outOfScope(value) {
+			return true
+		}
+		wg.Add(1)
+		semaphore.acquire()
+		go func(ip, value string) {
+			defer func() {
+				semaphore.release()
+				wg.Done()
+			}()
+
+			latency := sm.measureLatency(ctx, target, param, value, timingSamples)
+			if sm.stopped(ctx) || !profile.differs(latency) {
+				return
+			}
+			slog.Info(fmt.Sprintf("[TIME] [%s] %s=%s 响应时间 %dms（不存在的地址 %dms），主机可能存活",
+				target, param, value, latency.Milliseconds(), profile.median.Milliseconds()), "target", target, "param", param)
+			mu.Lock()
+			live = append(live, ip)
+			mu.Unlock()
+		}(ip, value)
+		return true
+	})
+	wg.Wait()
+
+	if len(live) == 0 {
+		slog.Info(fmt.Sprintf("[%s] 参数 %s 没有响应时间与不存在的地址明显不同的候选地址", target, param), "target", target, "param", param)
+		return nil
+	}
+	sortIPs(live)
+	slog.Info(fmt.Sprintf("[TIME] [%s] 参数 %s 按响应时间发现 %d 个可能存活的主机: %s", target, param, len(live), strings.Join(live, ", ")), "target", target, "param", param)
+	return live
+}
@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyProfileDiffers(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name      string
+		reference []time.Duration
+		latency   time.Duration
+		want      bool
+	}{
+		{"存活主机立即拒绝连接", []time.Duration{3000 * ms, 3010 * ms, 2990 * ms}, 40 * ms, true},
+		{"与不存在的地址相近", []time.Duration{3000 * ms, 3010 * ms, 2990 * ms}, 2950 * ms, false},
+		{"差值小于最小差值", []time.Duration{500 * ms, 500 * ms, 500 * ms}, 300 * ms, false},
+		{"参考地址波动较大", []time.Duration{1000 * ms, 2000 * ms, 3000 * ms, 1500 * ms}, 400 * ms, false},
+		{"比不存在的地址更慢", []time.Duration{100 * ms, 110 * ms, 90 * ms}, 5000 * ms, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newLatencyProfile(tt.reference)
+			if got := p.differs(tt.latency); got != tt.want {
+				t.Errorf("differs(%v) = %v (中位数 %v，标准差 %v), want %v", tt.latency, got, p.median, p.stddev, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLatencyProfile(t *testing.T) {
+	p := newLatencyProfile([]time.Duration{4 * time.Second, 2 * time.Second, 6 * time.Second, 4 * time.Second})
+	if p.median != 4*time.Second {
+		t.Errorf("median = %v, want 4s", p.median)
+	}
+	if want := 1414 * time.Millisecond; p.stddev < want || p.stddev > want+time.Millisecond {
+		t.Errorf("stddev = %v, want ≈%v", p.stddev, want)
+	}
+	if p := newLatencyProfile(nil); p.median != 0 || p.stddev != 0 {
+		t.Errorf("没有采样时应该返回零值, got %+v", p)
+	}
+}
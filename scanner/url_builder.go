@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"fmt"
+	"gosssrf-client/config"
 	"net/url"
 	"strings"
 )
@@ -21,23 +22,80 @@ func buildTestURL(baseURL string, paramName string, payload string) (string, err
 }
 
 // buildTestBody 构造测试Body（POST方式）
-func buildTestBody(paramName string, payload string) string {
+// bodyTemplate 不为空时保留其中的其他参数，只替换目标参数
+func buildTestBody(bodyTemplate string, paramName string, payload string) string {
 	// 构造 application/x-www-form-urlencoded 格式
-	values := url.Values{}
+	values, err := url.ParseQuery(bodyTemplate)
+	if err != nil {
+		values = url.Values{}
+	}
 	values.Set(paramName, payload)
 	return values.Encode()
 }
 
+// hasQueryParam 判断URL查询参数中是否包含指定参数
+func hasQueryParam(baseURL string, paramName string) bool {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	_, ok := parsedURL.Query()[paramName]
+	return ok
+}
+
+// hasBodyParam 判断请求体模板中是否包含指定参数
+func hasBodyParam(bodyType, bodyTemplate string, paramName string) bool {
+	if bodyType == config.BodyTypeJSON {
+		return hasJSONPath(bodyTemplate, paramName)
+	}
+	if bodyType == config.BodyTypeXML {
+		return hasXMLParam(bodyTemplate, paramName)
+	}
+	if bodyType == config.BodyTypeGraphQL {
+		return hasGraphQLVariable(bodyTemplate, paramName)
+	}
+
+	values, err := url.ParseQuery(bodyTemplate)
+	if err != nil {
+		return false
+	}
+	_, ok := values[paramName]
+	return ok
+}
+
 // buildTestRequest 构造测试请求数据
-func buildTestRequest(method, baseURL, paramName, payload string) (string, string, error) {
+// 对于带请求体的方法，如果参数只出现在URL查询参数中，则注入到URL中
+// bodyType 为 json 时 paramName 为点路径（例如 data.avatar.url），为 xml 时为元素路径或属性（例如 image@src）
+func buildTestRequest(method, baseURL, bodyType, bodyTemplate, paramName, payload string) (string, string, error) {
 	method = strings.ToUpper(method)
 
+	// 注入标记（*1、*2 ...）直接替换标记位置，与请求方式无关
+	if index := markerIndex(paramName); index > 0 {
+		return buildMarkerRequest(baseURL, bodyType, bodyTemplate, index, payload)
+	}
+
 	switch method {
 	case "GET":
 		testURL, err := buildTestURL(baseURL, paramName, payload)
 		return testURL, "", err
 	case "POST", "PUT", "PATCH":
-		body := buildTestBody(paramName, payload)
+		if hasQueryParam(baseURL, paramName) && !hasBodyParam(bodyType, bodyTemplate, paramName) {
+			testURL, err := buildTestURL(baseURL, paramName, payload)
+			return testURL, bodyTemplate, err
+		}
+		if bodyType == config.BodyTypeJSON {
+			body, err := buildJSONBody(bodyTemplate, paramName, payload)
+			return baseURL, body, err
+		}
+		if bodyType == config.BodyTypeXML {
+			body, err := buildXMLBody(bodyTemplate, paramName, payload)
+			return baseURL, body, err
+		}
+		if bodyType == config.BodyTypeGraphQL {
+			body, err := buildGraphQLBody(bodyTemplate, paramName, payload)
+			return baseURL, body, err
+		}
+		body := buildTestBody(bodyTemplate, paramName, payload)
 		return baseURL, body, nil
 	default:
 		return "", "", fmt.Errorf("不支持的HTTP方法: %s", method)
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+)
+
+// 复测结果（-verify参数）
+const (
+	StatusVulnerable = "still-vulnerable" // 重新发送原请求后仍然判定为漏洞
+	StatusFixed      = "fixed"            // 重新发送原请求后未再判定为漏洞
+	StatusUnverified = "unverified"       // 无法复测：请求失败，或OOB回连等漏洞没有记录请求
+)
+
+// keywordEvidence 关键字命中时的证据前缀，找不到内置payload时从证据中恢复关键字
+const keywordEvidence = "响应中包含特征关键字: "
+
+// Verify 复测一个已保存的漏洞：重新发送原请求，按原payload的特征和新的基线响应判定漏洞是否仍然存在
+// 返回的结果中 Status 为复测结果，仍存在漏洞时证据、严重程度和响应更新为本次复测的内容
+func (sm *ScanManager) Verify(ctx context.Context, finding ScanResult) ScanResult {
+	finding.Error = ""
+	if finding.URL == "" {
+		finding.Status, finding.Error = StatusUnverified, "没有记录请求（OOB回连等漏洞需要重新扫描确认）"
+		return finding
+	}
+	if finding.Method == "" {
+		finding.Method = "GET"
+	}
+
+	payload, ok := payloads.Lookup(finding.Payload, finding.PayloadType)
+	if !ok {
+		payload = payloads.Payload{Value: finding.Payload, Type: finding.PayloadType}
+		if strings.HasPrefix(finding.Evidence, keywordEvidence) {
+			payload.Keywords = []string{strings.TrimPrefix(finding.Evidence, keywordEvidence)}
+		}
+	}
+
+	// 将原请求中的参数替换为无害地址获取基线响应，无法替换（例如注入标记）时不使用基线
+	// 指定 -second-order 时同时获取验证地址的基线响应，复测二阶SSRF
+	var baseline, secondBaseline *detector.Baseline
+	if testURL, body, err := buildTestRequest(finding.Method, finding.URL, sm.config.BodyType, finding.RequestBody, finding.Parameter, baselineURL); err == nil {
+		if baseline, err = sm.detector.FetchBaseline(ctx, finding.Method, testURL, body); err == nil {
+			secondBaseline = sm.fetchSecondBaseline(ctx)
+		}
+	}
+
+	result := sm.detect(ctx, finding.Method, finding.URL, finding.RequestBody, payload, baseline, secondBaseline)
+	switch {
+	case result.Error != "":
+		finding.Status, finding.Error = StatusUnverified, result.Error
+		return finding
+	case result.Vulnerable:
+		finding.Status = StatusVulnerable
+		finding.Evidence, finding.Severity, finding.Confidence = result.Evidence, result.Severity, result.Confidence
+		finding.Response, finding.Redirects, finding.Credentials = result.Response, result.Redirects, result.Credentials
+	default:
+		finding.Status = StatusFixed
+		finding.Response = result.Response
+	}
+	finding.StatusCode, finding.ResponseLen, finding.ResponseTime = result.StatusCode, result.ResponseLen, result.ResponseTime
+	return finding
+}
+
+// VerifyAll 依次复测已保存的漏洞并输出复测结果，中断后剩余的漏洞标记为 unverified
+func (sm *ScanManager) VerifyAll(ctx context.Context, findings []ScanResult) []ScanResult {
+	results := make([]ScanResult, 0, len(findings))
+	for _, finding := range findings {
+		result := finding
+		if ctx.Err() != nil || sm.Stopped() {
+			result.Status, result.Error = StatusUnverified, "复测已中断"
+		} else {
+			result = sm.Verify(ctx, finding)
+		}
+
+		color := config.ColorYellow
+		switch result.Status {
+		case StatusVulnerable:
+			color = config.ColorRed
+		case StatusFixed:
+			color = config.ColorGreen
+		}
+		sm.console.Result(color, verifyLine(result))
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+)
+
+func TestVerify(t *testing.T) {
+	// 模拟部分修复的目标：文件读取已修复，内网应用仍可访问
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "http://10.0.0.5/":
+			w.Write([]byte("<h1>INTERNAL-ADMIN</h1>"))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Method: http.MethodGet, Timeout: 5}
+	var out bytes.Buffer
+	sm := NewScanManager(cfg, detector.NewDetector(cfg), logging.NewConsole(&out, &out), nil, nil, nil)
+	target := server.URL + "/?url=x"
+
+	tests := []struct {
+		name    string
+		finding ScanResult
+		want    string
+	}{
+		{
+			name:    "内置payload已修复",
+			finding: ScanResult{URL: server.URL + "/?url=file:///etc/passwd", Payload: "file:///etc/passwd", PayloadType: "文件读取", Evidence: "响应中包含特征关键字: root:"},
+			want:    StatusFixed,
+		},
+		{
+			name:    "从证据恢复关键字",
+			finding: ScanResult{URL: server.URL + "/?url=http://10.0.0.5/", Payload: "http://10.0.0.5/", PayloadType: "自定义字典", Evidence: "响应中包含特征关键字: INTERNAL-ADMIN"},
+			want:    StatusVulnerable,
+		},
+		{
+			name:    "没有记录请求",
+			finding: ScanResult{Payload: "http://oob.example/x", PayloadType: "OOB检测"},
+			want:    StatusUnverified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.finding.Target, tt.finding.Parameter, tt.finding.Vulnerable = target, "url", true
+			got := sm.Verify(context.Background(), tt.finding)
+			if got.Status != tt.want {
+				t.Errorf("Verify() = %s (%s)，期望 %s", got.Status, got.Error, tt.want)
+			}
+		})
+	}
+
+	// 中断后剩余的漏洞标记为 unverified
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := sm.VerifyAll(ctx, []ScanResult{tests[1].finding})
+	if len(results) != 1 || results[0].Status != StatusUnverified || !strings.Contains(out.String(), "[unverified]") {
+		t.Errorf("VerifyAll() = %+v\n%s", results, out.String())
+	}
+}
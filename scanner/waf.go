@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// WAF拦截判定和绕过升级的限制
+const (
+	wafMinBlocked = 5  // 判定参数被拦截所需的最少拦截次数
+	wafMaxMutated = 50 // 每个参数最多对多少个被拦截的payload生成编码变种
+)
+
+// blockStats 单个注入点的拦截统计
+type blockStats struct {
+	sent     int                // 收到响应的常规payload数量
+	blocked  int                // 其中被拦截的数量
+	reason   string             // 最近一次拦截的原因
+	payloads []payloads.Payload // 被拦截的payload（最多 wafMaxMutated 个），用于生成编码变种
+}
+
+// consistent 判断拦截是否稳定出现：至少 wafMinBlocked 次且占一半以上
+func (s *blockStats) consistent() bool {
+	return s.blocked >= wafMinBlocked && s.blocked*2 >= s.sent
+}
+
+// recordBlock 统计注入点的常规payload被拦截的情况，绕过升级发送的payload不计入
+func (sm *ScanManager) recordBlock(target, param string, payload payloads.Payload, result detector.Result) {
+	if result.Error != "" || payload.Bypass != "" {
+		return
+	}
+
+	sm.blockMux.Lock()
+	defer sm.blockMux.Unlock()
+	key := target + "\x00" + param
+	stats := sm.blocks[key]
+	if stats == nil {
+		stats = &blockStats{}
+		sm.blocks[key] = stats
+	}
+	stats.sent++
+	if result.Blocked == "" {
+		return
+	}
+	stats.blocked++
+	stats.reason = result.Blocked
+	if len(stats.payloads) < wafMaxMutated && !strings.Contains(payload.Value, payloads.VarOOB) {
+		stats.payloads = append(stats.payloads, payload)
+	}
+}
+
+// blockedStats 返回注入点稳定被拦截时的统计，未被拦截时返回nil
+func (sm *ScanManager) blockedStats(target, param string) *blockStats {
+	sm.blockMux.Lock()
+	defer sm.blockMux.Unlock()
+	stats := sm.blocks[target+"\x00"+param]
+	if stats == nil || !stats.consistent() {
+		return nil
+	}
+	copied := *stats
+	copied.payloads = append([]payloads.Payload(nil), stats.payloads...)
+	return &copied
+}
+
+// scanWAFBypass 对payload稳定被拦截的参数自动升级：发送内置绕过字典和被拦截payload的编码变种，命中时报告使用的绕过技术
+func (sm *ScanManager) scanWAFBypass(ctx context.Context, target string, params map[string]string) {
+	for param, value := range params {
+		if sm.stopped(ctx) {
+			return
+		}
+		stats := sm.blockedStats(target, param)
+		if stats == nil {
+			continue
+		}
+		slog.Warn(fmt.Sprintf("[%s] 参数 %s 的payload被拦截（%s，%d/%d），尝试绕过字典和编码变种",
+			target, param, stats.reason, stats.blocked, stats.sent), "target", target, "param", param)
+		sm.sendStream(ctx, target, "waf", map[string]string{param: value}, sm.bypassStream(target, stats.payloads))
+	}
+}
+
+// bypassStream 生成绕过升级的payload：未启用 bypass 模块时先发送内置字典，再发送被拦截payload在 -encoders 之外的编码变种
+// 被拦截的payload按值排序，保证断点续扫时顺序一致
+func (sm *ScanManager) bypassStream(target string, blocked []payloads.Payload) func(fn func(payloads.Payload) bool) {
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].Value < blocked[j].Value })
+
+	var encoders []string
+	for _, name := range payloads.Encoders {
+		if !containsString(sm.config.EncoderList, name) {
+			encoders = append(encoders, name)
+		}
+	}
+
+	var dict func(fn func(payloads.Payload) bool)
+	if !sm.config.HasTag(config.TagBypass) {
+		dict = sm.listPhase(target, "waf", payloads.GetAllDictPayloads()).each
+	}
+
+	return func(fn func(payloads.Payload) bool) {
+		if dict != nil {
+			stopped := false
+			dict(func(payload payloads.Payload) bool {
+				if sm.excluded(payload) {
+					return true
+				}
+				payload.Bypass = "内置字典（" + payload.Type + "）"
+				if !fn(payload) {
+					stopped = true
+					return false
+				}
+				return true
+			})
+			if stopped {
+				return
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, payload := range blocked {
+			for _, name := range encoders {
+				variant := payload
+				variant.Value = payloads.Encode(payload.Value, name)
+				if variant.Value == payload.Value || seen[variant.Value] {
+					continue
+				}
+				seen[variant.Value] = true
+				variant.Bypass = "编码 " + name
+				if !fn(variant) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// containsString 判断列表中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
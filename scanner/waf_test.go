@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/payloads"
+)
+
+func TestBlockStats(t *testing.T) {
+	sm := NewScanManager(&config.Config{}, nil, nil, nil, nil, nil)
+	payload := payloads.Payload{Value: "http://127.0.0.1/", Type: "内网探测"}
+	blocked := detector.Result{Blocked: "状态码 403"}
+
+	for i := 0; i < wafMinBlocked-1; i++ {
+		sm.recordBlock("t", "url", payload, blocked)
+	}
+	if sm.blockedStats("t", "url") != nil {
+		t.Fatal("拦截次数不足时不应判定")
+	}
+
+	// 绕过升级发送的payload和请求失败不计入
+	sm.recordBlock("t", "url", payloads.Payload{Value: "x", Bypass: "编码 url"}, blocked)
+	sm.recordBlock("t", "url", payload, detector.Result{Error: "请求超时"})
+	if sm.blockedStats("t", "url") != nil {
+		t.Fatal("绕过payload不应计入拦截次数")
+	}
+
+	sm.recordBlock("t", "url", payload, blocked)
+	stats := sm.blockedStats("t", "url")
+	if stats == nil || stats.blocked != wafMinBlocked || stats.reason != "状态码 403" {
+		t.Fatalf("拦截统计 = %+v", stats)
+	}
+
+	// 大部分payload未被拦截时不判定
+	for i := 0; i < wafMinBlocked*2; i++ {
+		sm.recordBlock("t", "url", payload, detector.Result{})
+	}
+	if sm.blockedStats("t", "url") != nil {
+		t.Error("拦截不足一半时不应判定")
+	}
+}
+
+func TestBypassStream(t *testing.T) {
+	cfg := &config.Config{EncoderList: []string{payloads.EncoderURL}, TagSet: map[string]bool{config.TagBypass: true}}
+	sm := NewScanManager(cfg, nil, nil, nil, nil, nil)
+	blocked := []payloads.Payload{{Value: "http://127.0.0.1/", Type: "内网探测"}}
+
+	var got []string
+	sm.bypassStream("http://example.com/", blocked)(func(p payloads.Payload) bool {
+		got = append(got, p.Bypass+" "+p.Value)
+		return true
+	})
+
+	// 已启用 bypass 模块时不重复发送内置字典，-encoders 中已有的编码不重复生成
+	want := []string{
+		"编码 double-url " + payloads.Encode("http://127.0.0.1/", payloads.EncoderDoubleURL),
+		"编码 unicode " + payloads.Encode("http://127.0.0.1/", payloads.EncoderUnicode),
+		"编码 case " + payloads.Encode("http://127.0.0.1/", payloads.EncoderCase),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("绕过payload =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
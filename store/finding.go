@@ -0,0 +1,81 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gosssrf-client/scanner"
+)
+
+// findingQuery 查询一条漏洞记录及其对应的请求和响应状态码
+const findingQuery = `SELECT t.url AS target, f.parameter, f.payload, f.payload_type, f.severity, f.confidence, f.evidence,
+	coalesce(r.method, '') AS method, coalesce(r.url, '') AS url, coalesce(r.body, '') AS body, coalesce(s.status_code, 0) AS status_code
+FROM findings f JOIN targets t ON t.id = f.target_id
+LEFT JOIN requests r ON r.id = f.request_id LEFT JOIN responses s ON s.request_id = f.request_id
+WHERE f.id = %d;`
+
+// LoadFinding 读取结果数据库中的一条漏洞记录（findings 表的 id），需要 sqlite3 命令行程序
+func LoadFinding(path string, id int64) (scanner.ScanResult, error) {
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return scanner.ScanResult{}, fmt.Errorf("未找到 sqlite3 命令，读取 -db 需要安装 SQLite 命令行程序: %v", err)
+	}
+	// sqlite3 打开不存在的文件时会创建空数据库
+	if _, err := os.Stat(path); err != nil {
+		return scanner.ScanResult{}, fmt.Errorf("读取数据库失败: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(sqlite, "-readonly", "-json", path, fmt.Sprintf(findingQuery, id))
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return scanner.ScanResult{}, fmt.Errorf("读取数据库 %s 失败: %s", path, msg)
+		}
+		return scanner.ScanResult{}, fmt.Errorf("读取数据库 %s 失败: %v", path, err)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return scanner.ScanResult{}, fmt.Errorf("数据库 %s 中没有ID为 %d 的漏洞", path, id)
+	}
+
+	var rows []struct {
+		Target      string `json:"target"`
+		Parameter   string `json:"parameter"`
+		Payload     string `json:"payload"`
+		PayloadType string `json:"payload_type"`
+		Severity    string `json:"severity"`
+		Confidence  string `json:"confidence"`
+		Evidence    string `json:"evidence"`
+		Method      string `json:"method"`
+		URL         string `json:"url"`
+		Body        string `json:"body"`
+		StatusCode  int    `json:"status_code"`
+	}
+	if err := json.Unmarshal(out, &rows); err != nil {
+		return scanner.ScanResult{}, fmt.Errorf("解析数据库查询结果失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return scanner.ScanResult{}, errors.New("数据库查询结果为空")
+	}
+	row := rows[0]
+	return scanner.ScanResult{
+		Target:      row.Target,
+		Method:      row.Method,
+		URL:         row.URL,
+		RequestBody: row.Body,
+		Parameter:   row.Parameter,
+		Payload:     row.Payload,
+		PayloadType: row.PayloadType,
+		StatusCode:  row.StatusCode,
+		Vulnerable:  true,
+		Severity:    row.Severity,
+		Confidence:  row.Confidence,
+		Evidence:    row.Evidence,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestLoadFinding(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("未安装 sqlite3")
+	}
+	path := filepath.Join(t.TempDir(), "results.sqlite")
+	target := "http://a.example/?url=x"
+	finding := scanner.ScanResult{
+		Target: target, Method: "POST", URL: "http://a.example/", RequestBody: `{"url":"file:///etc/passwd"}`, Parameter: "url",
+		Payload: "file:///etc/passwd", PayloadType: "文件读取", StatusCode: 200, Vulnerable: true,
+		Severity: "critical", Confidence: "confirmed", Evidence: "root:x:0:0",
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.BeginScan([]string{target}, time.Unix(1700000000, 0))
+	db.Record(finding)
+	db.EndScan(time.Unix(1700000060, 0), false, 1)
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := LoadFinding(path, 1)
+	if err != nil {
+		t.Fatalf("LoadFinding() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, finding) {
+		t.Errorf("LoadFinding() = %+v, want %+v", got, finding)
+	}
+
+	if _, err := LoadFinding(path, 2); err == nil || !strings.Contains(err.Error(), "没有ID为 2 的漏洞") {
+		t.Errorf("不存在的漏洞应返回错误，得到 %v", err)
+	}
+	if _, err := LoadFinding(filepath.Join(t.TempDir(), "missing.sqlite"), 1); err == nil {
+		t.Error("数据库文件不存在时应返回错误")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package store
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach 将 sqlite3 放入独立的进程组，避免 Ctrl+C 同时中断 sqlite3 导致剩余结果无法写入
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gosssrf-client/scanner"
+)
+
+// schema 数据库表结构（-db参数）
+// 每次扫描一条 scans 记录；同一目标+参数+payload 的漏洞在多次扫描间只保留一条 findings 记录，
+// first_scan_id/last_scan_id 和 times_seen 记录首次、最近发现的扫描和发现次数
+const schema = `
+PRAGMA foreign_keys = ON;
+PRAGMA journal_mode = WAL;
+CREATE TABLE IF NOT EXISTS scans (
+	id          TEXT PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT,
+	interrupted INTEGER NOT NULL DEFAULT 0,
+	vuln_count  INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS targets (
+	id  INTEGER PRIMARY KEY,
+	url TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS scan_targets (
+	scan_id   TEXT NOT NULL REFERENCES scans(id),
+	target_id INTEGER NOT NULL REFERENCES targets(id),
+	PRIMARY KEY (scan_id, target_id)
+);
+CREATE TABLE IF NOT EXISTS requests (
+	id           INTEGER PRIMARY KEY,
+	scan_id      TEXT NOT NULL REFERENCES scans(id),
+	target_id    INTEGER NOT NULL REFERENCES targets(id),
+	method       TEXT NOT NULL,
+	url          TEXT NOT NULL,
+	body         TEXT NOT NULL,
+	parameter    TEXT NOT NULL,
+	payload      TEXT NOT NULL,
+	payload_type TEXT NOT NULL,
+	sent_at      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_requests_scan ON requests(scan_id);
+CREATE TABLE IF NOT EXISTS responses (
+	request_id  INTEGER PRIMARY KEY REFERENCES requests(id),
+	status_code INTEGER NOT NULL,
+	length      INTEGER NOT NULL,
+	time_ms     INTEGER NOT NULL,
+	error       TEXT NOT NULL,
+	excerpt     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS findings (
+	id            INTEGER PRIMARY KEY,
+	target_id     INTEGER NOT NULL REFERENCES targets(id),
+	parameter     TEXT NOT NULL,
+	payload       TEXT NOT NULL,
+	payload_type  TEXT NOT NULL,
+	severity      TEXT NOT NULL,
+	confidence    TEXT NOT NULL,
+	evidence      TEXT NOT NULL,
+	request_id    INTEGER REFERENCES requests(id),
+	first_scan_id TEXT NOT NULL REFERENCES scans(id),
+	last_scan_id  TEXT NOT NULL REFERENCES scans(id),
+	first_seen    TEXT NOT NULL,
+	last_seen     TEXT NOT NULL,
+	times_seen    INTEGER NOT NULL DEFAULT 1,
+	UNIQUE (target_id, parameter, payload)
+);
+`
+
+// commitInterval 每写入多少条结果提交一次事务
+const commitInterval = 200
+
+// timeLayout 数据库中的时间格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// DB 扫描结果数据库
+// 不引入数据库驱动，通过 sqlite3 命令行程序写入：SQL语句经标准输入依次发送，由同一个进程串行执行
+type DB struct {
+	path    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	mux     sync.Mutex
+	scanID  string
+	pending int   // 当前事务中未提交的结果数量
+	err     error // 第一次写入失败的错误
+}
+
+// Open 打开（不存在时创建）结果数据库，需要系统中安装 sqlite3 命令行程序（3.24 及以上版本）
+func Open(path string) (*DB, error) {
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return nil, fmt.Errorf("未找到 sqlite3 命令，-db 需要安装 SQLite 命令行程序: %v", err)
+	}
+
+	db := &DB{path: path}
+	db.cmd = exec.Command(sqlite, "-batch", path)
+	db.cmd.Stdout = io.Discard
+	db.cmd.Stderr = &db.stderr
+	detach(db.cmd)
+	db.stdin, err = db.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %v", err)
+	}
+	if err := db.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %v", err)
+	}
+
+	db.exec(schema)
+	if db.err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化数据库失败: %v", db.err)
+	}
+	return db, nil
+}
+
+// BeginScan 记录一次新的扫描及其目标
+func (db *DB) BeginScan(targets []string, start time.Time) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+
+	db.scanID = fmt.Sprintf("%s-%d", start.Format("20060102150405"), os.Getpid())
+	var sql strings.Builder
+	fmt.Fprintf(&sql, "INSERT INTO scans (id, started_at) VALUES (%s, %s);\n", quote(db.scanID), quote(start.Format(timeLayout)))
+	for _, target := range targets {
+		fmt.Fprintf(&sql, "INSERT OR IGNORE INTO targets (url) VALUES (%s);\n", quote(target))
+		fmt.Fprintf(&sql, "INSERT OR IGNORE INTO scan_targets (scan_id, target_id) VALUES (%s, %s);\n", quote(db.scanID), targetID(target))
+	}
+	sql.WriteString("BEGIN;\n")
+	db.exec(sql.String())
+}
+
+// Record 记录一个测试请求及其响应；发现漏洞时同时写入或更新漏洞记录，可作为 ScanManager.OnResult 的处理函数
+func (db *DB) Record(result scanner.ScanResult) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+
+	now := quote(time.Now().Format(timeLayout))
+	scanID := quote(db.scanID)
+	target := targetID(result.Target)
+
+	var sql strings.Builder
+	fmt.Fprintf(&sql, "INSERT OR IGNORE INTO targets (url) VALUES (%s);\n", quote(result.Target))
+	// 从标准输入读取的目标在扫描开始时还不知道，第一次记录时关联到本次扫描
+	fmt.Fprintf(&sql, "INSERT OR IGNORE INTO scan_targets (scan_id, target_id) VALUES (%s, %s);\n", scanID, target)
+
+	// OOB回连等没有对应请求的漏洞只写入漏洞记录
+	requestID := "NULL"
+	if result.URL != "" {
+		fmt.Fprintf(&sql, "INSERT INTO requests (scan_id, target_id, method, url, body, parameter, payload, payload_type, sent_at) "+
+			"VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s);\n",
+			scanID, target, quote(result.Method), quote(result.URL), quote(result.RequestBody),
+			quote(result.Parameter), quote(result.Payload), quote(result.PayloadType), now)
+		fmt.Fprintf(&sql, "INSERT INTO responses (request_id, status_code, length, time_ms, error, excerpt) "+
+			"VALUES (last_insert_rowid(), %d, %d, %d, %s, %s);\n",
+			result.StatusCode, result.ResponseLen, result.ResponseTime, quote(result.Error), quote(result.Response))
+		// responses 的主键即 request_id，此时 last_insert_rowid() 仍为刚写入的请求
+		requestID = "last_insert_rowid()"
+	}
+
+	if result.Vulnerable {
+		fmt.Fprintf(&sql, "INSERT INTO findings (target_id, parameter, payload, payload_type, severity, confidence, evidence, "+
+			"request_id, first_scan_id, last_scan_id, first_seen, last_seen) "+
+			"VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s) "+
+			"ON CONFLICT (target_id, parameter, payload) DO UPDATE SET "+
+			"payload_type = excluded.payload_type, severity = excluded.severity, confidence = excluded.confidence, "+
+			"evidence = excluded.evidence, request_id = coalesce(excluded.request_id, request_id), "+
+			"last_scan_id = excluded.last_scan_id, last_seen = excluded.last_seen, times_seen = times_seen + 1;\n",
+			target, quote(result.Parameter), quote(result.Payload), quote(result.PayloadType),
+			quote(result.Severity), quote(result.Confidence), quote(result.Evidence),
+			requestID, scanID, scanID, now, now)
+	}
+
+	db.pending++
+	if db.pending >= commitInterval {
+		sql.WriteString("COMMIT;\nBEGIN;\n")
+		db.pending = 0
+	}
+	db.exec(sql.String())
+}
+
+// EndScan 提交未写入的结果并记录扫描结束时间
+func (db *DB) EndScan(end time.Time, interrupted bool, vulnCount int) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+
+	flag := 0
+	if interrupted {
+		flag = 1
+	}
+	db.exec(fmt.Sprintf("COMMIT;\nUPDATE scans SET finished_at = %s, interrupted = %d, vuln_count = %d WHERE id = %s;\n",
+		quote(end.Format(timeLayout)), flag, vulnCount, quote(db.scanID)))
+	db.pending = 0
+}
+
+// Close 关闭数据库，返回写入过程中的错误（包括 sqlite3 输出的错误信息）
+func (db *DB) Close() error {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+
+	db.stdin.Close()
+	if err := db.cmd.Wait(); err != nil && db.err == nil {
+		db.err = err
+	}
+	if msg := strings.TrimSpace(db.stderr.String()); msg != "" {
+		return fmt.Errorf("写入数据库 %s 失败: %s", db.path, msg)
+	}
+	if db.err != nil {
+		return fmt.Errorf("写入数据库 %s 失败: %v", db.path, db.err)
+	}
+	return nil
+}
+
+// exec 发送SQL语句（调用方需持有锁），只记录第一次失败
+func (db *DB) exec(sql string) {
+	if db.err != nil {
+		return
+	}
+	if _, err := io.WriteString(db.stdin, sql); err != nil {
+		db.err = err
+	}
+}
+
+// targetID 按URL查询目标ID的子查询
+func targetID(target string) string {
+	return "(SELECT id FROM targets WHERE url = " + quote(target) + ")"
+}
+
+// quote 转义为SQL字符串字面量
+// 包含NUL等控制字符或非法UTF-8时使用十六进制形式，避免 sqlite3 命令行截断输入
+func quote(s string) string {
+	if !utf8.ValidString(s) || strings.ContainsRune(s, 0) {
+		return "CAST(X'" + hex.EncodeToString([]byte(s)) + "' AS TEXT)"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gosssrf-client/scanner"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"abc", "'abc'"},
+		{"it's", "'it''s'"},
+		{"a\nb", "'a\nb'"},
+		{"a\x00b", "CAST(X'610062' AS TEXT)"},
+		{"\xff", "CAST(X'ff' AS TEXT)"},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.in); got != tt.want {
+			t.Errorf("quote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDBRecord(t *testing.T) {
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("未安装 sqlite3")
+	}
+	path := filepath.Join(t.TempDir(), "results.sqlite")
+	target := "http://a.example/?url=x"
+	finding := scanner.ScanResult{
+		Target: target, Method: "GET", URL: "http://a.example/?url=file:///etc/passwd", Parameter: "url",
+		Payload: "file:///etc/passwd", PayloadType: "文件读取", StatusCode: 200, Vulnerable: true,
+		Severity: "critical", Confidence: "confirmed", Evidence: "root:x:0:0", Response: "root:x:0:0\n.tables\n'; DROP TABLE scans; --",
+	}
+
+	// 两次扫描发现同一漏洞，漏洞记录合并
+	for i, start := range []time.Time{time.Unix(1700000000, 0), time.Unix(1700000100, 0)} {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		db.BeginScan([]string{target}, start)
+		db.Record(scanner.ScanResult{Target: target, Method: "GET", URL: "http://a.example/?url=http://127.0.0.1/",
+			Parameter: "url", Payload: "http://127.0.0.1/", PayloadType: "内网探测", Error: "timeout"})
+		db.Record(finding)
+		if i == 1 {
+			// OOB回连没有对应的请求
+			db.Record(scanner.ScanResult{Target: target, Parameter: "url", Payload: "http://oob/x", PayloadType: "OOB检测",
+				Vulnerable: true, Severity: "high", Confidence: "confirmed"})
+		}
+		db.EndScan(start.Add(time.Minute), false, 1)
+		if err := db.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT count(*) FROM scans WHERE finished_at IS NOT NULL", "2"},
+		{"SELECT count(*) FROM targets", "1"},
+		{"SELECT count(*) FROM requests", "4"},
+		{"SELECT count(*) FROM responses WHERE error = 'timeout'", "2"},
+		{"SELECT count(*) FROM findings", "2"},
+		{"SELECT times_seen FROM findings WHERE payload = 'file:///etc/passwd'", "2"},
+		{"SELECT first_scan_id != last_scan_id FROM findings WHERE payload = 'file:///etc/passwd'", "1"},
+		{"SELECT r.url FROM findings f JOIN requests r ON r.id = f.request_id WHERE f.payload = 'file:///etc/passwd'", finding.URL},
+		{"SELECT excerpt = " + quote(finding.Response) + " FROM responses r JOIN findings f ON f.request_id = r.request_id", "1"},
+		{"SELECT request_id IS NULL FROM findings WHERE payload_type = 'OOB检测'", "1"},
+	}
+	for _, tt := range tests {
+		out, err := exec.Command(sqlite, path, tt.query).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v %s", tt.query, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"gosssrf-client/config"
+	"gosssrf-client/detector"
+	"gosssrf-client/logging"
+	"gosssrf-client/notify"
+	"gosssrf-client/oob"
+	"gosssrf-client/rebind"
+	"gosssrf-client/report"
+	"gosssrf-client/scanner"
+	"gosssrf-client/store"
+)
+
+// runWatch 持续监控模式（-watch）：按间隔重复扫描相同的目标，每轮结果写入数据库和报告，只通知与之前相比新出现的漏洞
+// Ctrl+C 时等待当前一轮进行中的请求完成后退出，再次中断时立即退出
+func runWatch(cfg *config.Config, console *logging.Console, oobServer *oob.Server, oobRegistry *oob.Registry, dnsServer *rebind.Server) {
+	det := detector.NewDetector(cfg)
+	if cfg.Script != "" {
+		if err := det.StartScript(); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer det.Close()
+	}
+	if cfg.LoginRequest != nil || cfg.LoginScript != "" {
+		if err := det.Login(context.Background()); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info("登录成功")
+	}
+
+	// 文本输出文件持续追加每一轮的输出，JSON行文件持续追加新出现的漏洞，其他格式的报告每轮结束后覆盖为最近一轮的结果
+	var jsonl *report.JSONL
+	if cfg.OutputFormat == config.FormatText || cfg.OutputFormat == config.FormatJSONL {
+		if outputFile := openOutput(cfg, console); outputFile != nil {
+			defer outputFile.Close()
+			if cfg.OutputFormat == config.FormatJSONL {
+				jsonl = report.NewJSONL(outputFile)
+			}
+		}
+	}
+
+	var db *store.DB
+	if cfg.DBFile != "" {
+		var err error
+		if db, err = store.Open(cfg.DBFile); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		defer func() {
+			if err := db.Close(); err != nil {
+				slog.Error(err.Error())
+			}
+		}()
+	}
+
+	bucket := newBucket(cfg)
+
+	notifiers, err := newNotifiers(cfg)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	defer func() {
+		for _, n := range notifiers {
+			if err := n.Close(); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+	}()
+
+	// Ctrl+C 或 SIGTERM：停止当前一轮扫描并结束监控
+	var (
+		mu      sync.Mutex
+		current *scanner.ScanManager
+	)
+	stop := make(chan struct{})
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		slog.Warn("收到中断信号，等待进行中的请求完成后结束监控（再次按 Ctrl+C 立即退出）...")
+		mu.Lock()
+		close(stop)
+		if current != nil {
+			current.Stop()
+		}
+		mu.Unlock()
+		<-sigCh
+		os.Exit(130)
+	}()
+
+	// 每轮都从相同的目标开始，-crawl 新增的目标不会累积到下一轮
+	seeds := append([]string(nil), cfg.Targets...)
+	var known []scanner.ScanResult // 已经通知过的漏洞，新一轮扫描只通知其中没有的漏洞
+	for round := 1; ; round++ {
+		cfg.Targets = append([]string(nil), seeds...)
+		sm := scanner.NewScanManager(cfg, det, console, oobServer, oobRegistry, dnsServer)
+		if db != nil {
+			sm.OnResult(db.Record)
+		}
+		if jsonl != nil {
+			sm.OnResult(jsonl.Record)
+		}
+		mu.Lock()
+		current = sm
+		mu.Unlock()
+
+		slog.Info(fmt.Sprintf("开始第 %d 轮扫描", round))
+		r := watchRound(cfg, sm, db)
+		if jsonl != nil {
+			if err := jsonl.Err(); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+		if len(cfg.EmailTo) > 0 {
+			sendEmailReport(cfg, r)
+		}
+		// 每轮结束后上传报告，上传失败不影响下一轮扫描
+		if bucket != nil {
+			if err := uploadArtifacts(cfg, bucket, r.StartTime, r.Results); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+		d := report.Compare(known, r.Results)
+		if r.Interrupted {
+			// 中断的一轮结果不完整，只把新发现的漏洞加入已通知的漏洞
+			known = append(known, d.New...)
+		} else {
+			known = r.Results
+		}
+
+		for _, f := range d.New {
+			console.Result(config.ColorRed, fmt.Sprintf("[新增] [%s/%s] [%s] %s %s=%s\n", f.Severity, f.Confidence, f.PayloadType, f.Target, f.Parameter, f.Payload))
+		}
+		msg := fmt.Sprintf("第 %d 轮扫描完成，存在 %d 个SSRF测试点，新增 %d 个", round, len(r.Results), len(d.New))
+		if !r.Interrupted {
+			msg += fmt.Sprintf("，已修复 %d 个", len(d.Fixed))
+		}
+		slog.Info(msg)
+
+		// 只通知新出现的漏洞，没有新漏洞时不发送扫描结束事件
+		if len(d.New) > 0 {
+			summary := notify.NewSummary(r.Targets, r.StartTime, r.EndTime, r.Interrupted, d.New)
+			for _, n := range notifiers {
+				for _, f := range d.New {
+					n.Finding(f)
+				}
+				n.Complete(summary)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		slog.Info(fmt.Sprintf("下一轮扫描时间 %s", time.Now().Add(cfg.Watch).Format("2006-01-02 15:04:05")))
+		select {
+		case <-stop:
+			return
+		case <-time.After(cfg.Watch):
+		}
+	}
+}
+
+// watchRound 执行一轮扫描，结果写入数据库，指定 -o 时写入报告，返回本轮的扫描报告
+func watchRound(cfg *config.Config, sm *scanner.ScanManager, db *store.DB) *report.Report {
+	ctx := context.Background()
+	if cfg.MaxScanTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.MaxScanTime)
+		defer cancel()
+	}
+
+	startTime := time.Now()
+	if cfg.Crawl {
+		added := sm.Crawl(ctx)
+		slog.Info(fmt.Sprintf("爬取完成，新增 %d 个扫描目标，共 %d 个目标", added, len(cfg.Targets)))
+	}
+	if db != nil {
+		db.BeginScan(cfg.Targets, startTime)
+	}
+	vulnerableCount := sm.RunScan(ctx)
+	endTime := time.Now()
+	interrupted := sm.Stopped() || ctx.Err() != nil
+	if db != nil {
+		db.EndScan(endTime, interrupted, vulnerableCount)
+	}
+
+	targets, _ := sm.TargetVulnCounts()
+	r := &report.Report{
+		Targets:     targets,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Interrupted: interrupted,
+		Results:     sm.Results(),
+		Headers:     cfg.CustomHeaders,
+		ContentType: cfg.BodyContentType(),
+	}
+	if cfg.OutputFile != "" && cfg.OutputFormat != config.FormatText && cfg.OutputFormat != config.FormatJSONL {
+		file, err := os.Create(cfg.OutputFile)
+		if err != nil {
+			slog.Error(fmt.Sprintf("创建输出文件失败: %v", err))
+			os.Exit(1)
+		}
+		writeReport(cfg, file, r)
+		file.Close()
+	}
+	return r
+}